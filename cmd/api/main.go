@@ -11,11 +11,13 @@ import (
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/consumer"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/eventbus"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/limiter"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/logger"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/registry"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/storage"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/token"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/transcoder"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/whatsapp/meow"
 	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
 	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/handler"
@@ -64,8 +66,19 @@ func main() {
 	// Storage
 	l.Info("🗃️  Setting up storage...")
 	storageConfig := config.LoadStorageConfig()
+	if storageConfig.NeedsSigningKey(appConfig.PRESIGNED_UPLOAD_EXPIRATION) && storageConfig.SigningKey == "" {
+		panic("STORAGE_SIGNING_KEY is required to sign the URLs of the local storage")
+	}
 	storage := storage.New(storageConfig)
 
+	// Transcoder
+	l.Info("🎞️  Setting up transcoder...")
+	transcoderConfig := config.LoadTranscoderConfig()
+	transcoder, err := transcoder.New(transcoderConfig)
+	if err != nil {
+		l.Warn("⚠️  Transcoder disabled, media is sent as given", "error", err)
+	}
+
 	// Whatsmeow
 	l.Info("😻 Setting up whatsmeow...")
 	whatsapp := meow.New(ctx, config.LoadWhatsmeowDatabaseConfig(), storage, bus, cache)
@@ -81,6 +94,19 @@ func main() {
 	tokenRepo := repository.NewTokenRepository(whappyDB)
 	fileRepo := repository.NewFileRepository(whappyDB)
 	webhookRepo := repository.NewWebhookRepository(whappyDB)
+	messageRepo := repository.NewMessageRepository(whappyDB)
+	chatRepo := repository.NewChatRepository(whappyDB)
+	receiptRepo := repository.NewReceiptRepository(whappyDB)
+	queueRepo := repository.NewQueueRepository(whappyDB)
+	rateLimitRepo := repository.NewRateLimitRepository(whappyDB)
+	mediaPolicyRepo := repository.NewMediaPolicyRepository(whappyDB)
+	campaignRepo := repository.NewCampaignRepository(whappyDB)
+	recipientRepo := repository.NewCampaignRecipientRepository(whappyDB)
+	templateRepo := repository.NewTemplateRepository(whappyDB)
+	idempotencyRepo := repository.NewIdempotencyRepository(whappyDB)
+	tusRepo := repository.NewTusUploadRepository(whappyDB)
+	retentionRepo := repository.NewRetentionRuleRepository(whappyDB)
+	quotaRepo := repository.NewQuotaRepository(whappyDB)
 
 	// Services / Use Cases
 	l.Info("🔧 Setting up services...")
@@ -89,12 +115,28 @@ func main() {
 	instService := service.NewInstanceService(tokenService, instRepo, instRegistry, bus)
 	sessionService := service.NewSessionService(instRepo, whatsapp, bus)
 	fileService := service.NewFileService(storage, fileRepo)
-	messageService := service.NewMessageService(whatsapp, storage, fileService, cache, appConfig.CACHE_FILE_UPLOAD_TTL)
-	chatService := service.NewChatService(whatsapp)
+	previewService := service.NewPreviewService(cache, appConfig.CACHE_LINK_PREVIEW_TTL)
+	transcodeService := service.NewTranscodeService(transcoder, cache, transcoderConfig.GetCacheTTL())
+	thumbnailService := service.NewThumbnailService(transcodeService, fileService, fileRepo, storage, cache, appConfig.CACHE_FILE_UPLOAD_TTL)
+	rateLimitService := service.NewRateLimitService(whatsapp, rateLimitRepo, limiter.NewSharedLimiter(rateLimitRepo), appConfig.RateLimitDefaults())
+	messageService := service.NewMessageService(whatsapp, messageRepo, receiptRepo, chatRepo, storage, fileService, previewService, rateLimitService, transcodeService, thumbnailService, cache, appConfig.CACHE_FILE_UPLOAD_TTL)
+	chatService := service.NewChatService(whatsapp, messageRepo, chatRepo, bus)
+	historyService := service.NewHistoryService(whatsapp, messageRepo, chatRepo, bus, appConfig.HISTORY_SYNC_IDLE)
+	scheduleService := service.NewScheduleService(queueRepo, bus)
+	queueService := service.NewQueueService(queueRepo, instRepo, instRegistry, sessionService, messageService, bus, appConfig.QUEUE_POLL_INTERVAL, appConfig.QUEUE_MAX_ATTEMPTS, appConfig.QUEUE_CONCURRENCY)
+	campaignService := service.NewCampaignService(campaignRepo, recipientRepo, instRepo, instRegistry, whatsapp, sessionService, messageService, bus, appConfig.CAMPAIGN_POLL_INTERVAL, appConfig.CAMPAIGN_CONCURRENCY)
+	templateService := service.NewTemplateService(templateRepo, messageService)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, appConfig.IDEMPOTENCY_TTL)
 	contactService := service.NewContactService(whatsapp)
 	groupService := service.NewGroupService(whatsapp, bus, fileService)
 	pictureService := service.NewPictureService(whatsapp)
-	uploadService := service.NewUploadService(fileService, fileRepo, storage, bus)
+	quotaService := service.NewQuotaService(quotaRepo, fileRepo, instRepo, appConfig.QuotaDefaults())
+	uploadService := service.NewUploadService(fileService, thumbnailService, fileRepo, storage, quotaService, bus)
+	presignService := service.NewPresignService(uploadService, storage, cache, appConfig.PRESIGNED_UPLOAD_EXPIRATION)
+	tusService := service.NewTusService(tusRepo, storage, uploadService, uint64(appConfig.TUS_MAX_SIZE), appConfig.TUS_EXPIRATION)
+	downloadService := service.NewDownloadService(whatsapp, messageService)
+	mediaService := service.NewMediaService(whatsapp, mediaPolicyRepo, fileService, fileRepo, storage, quotaService, bus, appConfig.MediaPolicyDefaults(), appConfig.MEDIA_AUTO_SAVE_TIMEOUT)
+	retentionService := service.NewRetentionService(retentionRepo, instRepo, fileRepo, storage, bus, appConfig.RetentionDefaults(), appConfig.RETENTION_INTERVAL, appConfig.RETENTION_ORPHAN_AGE, appConfig.RETENTION_ORPHAN_BLOBS)
 	blocklistService := service.NewBlocklistService(whatsapp, bus)
 
 	// Consumers
@@ -104,23 +146,65 @@ func main() {
 	}
 
 	bus.SubscribeAll(consumer.NewWebhookConsumer(webhookRepo, cache).Handle)
+	bus.SubscribeAll(consumer.NewMessageConsumer(messageRepo, receiptRepo).Handle)
+	bus.SubscribeAll(consumer.NewChatConsumer(chatRepo).Handle)
+
+	// History syncs are handed to the history worker as they arrive from the phone
+	whatsapp.OnHistorySync(historyService.Ingest)
+
+	// Received media is saved after its event goes out, following the media policy of the instance
+	whatsapp.OnMedia(mediaService.Persist)
+
+	// Workers
+	l.Info("🕰️  Starting history sync ingestion...")
+	go historyService.Run(ctx)
+
+	l.Info("💾 Starting media auto-save...")
+	go mediaService.Run(ctx)
+
+	l.Info("📮 Starting outbound queue...")
+	go queueService.Run(ctx)
+
+	l.Info("📣 Starting campaigns...")
+	go campaignService.Run(ctx)
+
+	l.Info("🔁 Starting idempotency keys purge...")
+	go idempotencyService.Run(ctx)
+
+	l.Info("⏯️  Starting expired uploads sweep...")
+	go tusService.Run(ctx)
+
+	l.Info("🧹 Starting retention sweep...")
+	go retentionService.Run(ctx)
 
 	// Middleware
 	l.Info("🛡️  Setting up middleware...")
 	authMiddleware := middleware.NewAuthMiddleware(appConfig.ADMIN_TOKEN, tokenService)
 	instMiddleware := middleware.NewInstanceMiddleware(instRegistry, instRepo, sessionService)
+	idemMiddleware := middleware.NewIdempotencyMiddleware(idempotencyService)
 
 	// Handlers
 	l.Info("🖥️  Setting up HTTP handlers...")
 	healthHandler := handler.NewHealthHandler()
 	instHandler := handler.NewInstanceHandler(instService, instRegistry)
 	sessionHandler := handler.NewSessionHandler(sessionService)
-	messageHandler := handler.NewMessageHandler(messageService)
-	chatHandler := handler.NewChatHandler(chatService)
+	messageHandler := handler.NewMessageHandler(messageService, queueService, rateLimitService)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService)
+	queueHandler := handler.NewQueueHandler(queueService)
+	rateLimitHandler := handler.NewRateLimitHandler(rateLimitService)
+	campaignHandler := handler.NewCampaignHandler(campaignService)
+	templateHandler := handler.NewTemplateHandler(templateService)
+	chatHandler := handler.NewChatHandler(chatService, historyService)
 	contactHandler := handler.NewContactHandler(contactService)
 	groupHandler := handler.NewGroupHandler(groupService, bus)
 	pictureHandler := handler.NewPictureHandler(pictureService)
 	uploadHandler := handler.NewUploadHandler(uploadService)
+	tusHandler := handler.NewTusHandler(tusService)
+	presignHandler := handler.NewPresignHandler(presignService)
+	downloadHandler := handler.NewDownloadHandler(downloadService)
+	mediaPolicyHandler := handler.NewMediaPolicyHandler(mediaService)
+	retentionHandler := handler.NewRetentionHandler(retentionService)
+	quotaHandler := handler.NewQuotaHandler(quotaService)
 	blocklistHandler := handler.NewBlocklistHandler(blocklistService)
 	webhookHandler := handler.NewWebhookHandler(webhookService)
 
@@ -131,17 +215,33 @@ func main() {
 	healthHandler.RegisterRoutes(r)
 	instHandler.RegisterRoutes(r, authMiddleware)
 	sessionHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
-	messageHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	scheduleHandler.RegisterRoutes(r, authMiddleware, instMiddleware, idemMiddleware)
+	messageHandler.RegisterRoutes(r, authMiddleware, instMiddleware, idemMiddleware)
+	queueHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	rateLimitHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	campaignHandler.RegisterRoutes(r, authMiddleware, instMiddleware, idemMiddleware)
+	templateHandler.RegisterRoutes(r, authMiddleware, instMiddleware, idemMiddleware)
 	chatHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
 	contactHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
 	groupHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
 	pictureHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	tusHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	presignHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	quotaHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
 	uploadHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	downloadHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	mediaPolicyHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
+	retentionHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
 	blocklistHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
 	webhookHandler.RegisterRoutes(r, authMiddleware, instMiddleware)
 
-	if storageConfig.IsLocal() {
-		r.Get("/storage/*", static.New(storageConfig.Path))
+	if storageConfig.IsServedByAPI() {
+		storageHandler := handler.NewStorageHandler(storage)
+		if storageConfig.IsLocal() {
+			storageHandler.RegisterRoutes(r, static.New(storageConfig.Path))
+		} else {
+			storageHandler.RegisterRoutes(r, storageHandler.Serve)
+		}
 	}
 
 	l.Info("🔥 Server is running on port " + appConfig.APP_PORT)
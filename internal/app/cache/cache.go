@@ -12,11 +12,14 @@ var (
 const (
 	DefaultTTL = 5 * time.Minute
 
-	CacheKeyFileUploadPrefix = "file:upload:"
-	CacheKeyThumbnailPrefix  = "file:thumb:"
-	CacheKeyGroupTypePrefix  = "group:type:"
-	CacheKeyTokenPrefix      = "token:"
-	CacheKeyWebhooksPrefix   = "webhooks:"
+	CacheKeyFileUploadPrefix  = "file:upload:"
+	CacheKeyThumbnailPrefix   = "file:thumb:"
+	CacheKeyTranscodePrefix   = "file:transcode:"
+	CacheKeyPresignedPrefix   = "file:presigned:"
+	CacheKeyLinkPreviewPrefix = "link:preview:"
+	CacheKeyGroupTypePrefix   = "group:type:"
+	CacheKeyTokenPrefix       = "token:"
+	CacheKeyWebhooksPrefix    = "webhooks:"
 )
 
 type Cache interface {
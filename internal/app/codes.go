@@ -57,10 +57,105 @@ const (
 	CodeFileCannotBeDeleted AppCode = "FILE_CANNOT_BE_DELETED"
 	CodeFileSourceEmpty     AppCode = "FILE_SOURCE_EMPTY"
 
+	CodeInvalidMediaType     AppCode = "INVALID_MEDIA_TYPE"
+	CodeMediaNotDownloadable AppCode = "MEDIA_NOT_DOWNLOADABLE"
+	CodeInvalidMediaURL      AppCode = "INVALID_MEDIA_URL"
+	CodeMediaTypeMismatch    AppCode = "MEDIA_TYPE_MISMATCH"
+	CodeMediaHashMismatch    AppCode = "MEDIA_HASH_MISMATCH"
+	CodeMediaExpired         AppCode = "MEDIA_EXPIRED"
+
 	GLOBAL_STORAGE_UNAVAILABLE AppCode = "GLOBAL_STORAGE_UNAVAILABLE"
 
 	CodeWebhookNotFound           AppCode = "WEBHOOK_NOT_FOUND"
 	CodeWebhookInvalidURL         AppCode = "WEBHOOK_INVALID_URL"
 	CodeWebhookInvalidID          AppCode = "WEBHOOK_INVALID_ID"
 	CodeWebhookMaxWebhooksReached AppCode = "WEBHOOK_MAX_WEBHOOKS_REACHED"
+
+	CodeMessageNotFound   AppCode = "MESSAGE_NOT_FOUND"
+	CodeNoHistoryAnchor   AppCode = "NO_HISTORY_ANCHOR"
+	CodeEditWindowExpired AppCode = "EDIT_WINDOW_EXPIRED"
+
+	CodeInvalidChatJID           AppCode = "INVALID_CHAT_JID"
+	CodeInvalidDisappearingTimer AppCode = "INVALID_DISAPPEARING_TIMER"
+	CodeNotDirectChat            AppCode = "NOT_DIRECT_CHAT"
+
+	CodeQueuedMessageNotFound      AppCode = "QUEUED_MESSAGE_NOT_FOUND"
+	CodeQueuedMessageNotCancelable AppCode = "QUEUED_MESSAGE_NOT_CANCELABLE"
+	CodeQueueUnsupportedKind       AppCode = "QUEUE_UNSUPPORTED_KIND"
+	CodeQueueInvalidPayload        AppCode = "QUEUE_INVALID_PAYLOAD"
+
+	CodeScheduledMessageNotFound    AppCode = "SCHEDULED_MESSAGE_NOT_FOUND"
+	CodeScheduledMessageNotEditable AppCode = "SCHEDULED_MESSAGE_NOT_EDITABLE"
+	CodeInvalidSendAt               AppCode = "INVALID_SEND_AT"
+	CodeInvalidTimezone             AppCode = "INVALID_TIMEZONE"
+	CodeSendAtInPast                AppCode = "SEND_AT_IN_PAST"
+	CodeSendAtTooFar                AppCode = "SEND_AT_TOO_FAR"
+
+	CodeRateLimited        AppCode = "RATE_LIMITED"
+	CodeRecipientCooldown  AppCode = "RECIPIENT_COOLDOWN"
+	CodeInvalidRateLimit   AppCode = "INVALID_RATE_LIMIT"
+	CodeInvalidDelay       AppCode = "INVALID_DELAY"
+	CodeInvalidLimitAction AppCode = "INVALID_LIMIT_ACTION"
+
+	CodeCampaignNotFound          AppCode = "CAMPAIGN_NOT_FOUND"
+	CodeInvalidCampaignName       AppCode = "INVALID_CAMPAIGN_NAME"
+	CodeCampaignNoRecipients      AppCode = "CAMPAIGN_NO_RECIPIENTS"
+	CodeCampaignTooManyRecipients AppCode = "CAMPAIGN_TOO_MANY_RECIPIENTS"
+	CodeInvalidCampaignRecipient  AppCode = "INVALID_CAMPAIGN_RECIPIENT"
+	CodeInvalidCampaignPacing     AppCode = "INVALID_CAMPAIGN_PACING"
+	CodeCampaignNotRunning        AppCode = "CAMPAIGN_NOT_RUNNING"
+	CodeCampaignNotPaused         AppCode = "CAMPAIGN_NOT_PAUSED"
+	CodeCampaignFinished          AppCode = "CAMPAIGN_FINISHED"
+	CodeInvalidRecipientStatus    AppCode = "INVALID_RECIPIENT_STATUS"
+	CodeInvalidRecipientsLimit    AppCode = "INVALID_RECIPIENTS_LIMIT"
+	CodeCampaignConflict          AppCode = "CAMPAIGN_CONFLICT"
+
+	CodeTemplateNotFound        AppCode = "TEMPLATE_NOT_FOUND"
+	CodeInvalidTemplateName     AppCode = "INVALID_TEMPLATE_NAME"
+	CodeTemplateNameTaken       AppCode = "TEMPLATE_NAME_TAKEN"
+	CodeTemplateUnsupportedKind AppCode = "TEMPLATE_UNSUPPORTED_KIND"
+	CodeTemplateEmptyBody       AppCode = "TEMPLATE_EMPTY_BODY"
+	CodeTemplateBodyTooLong     AppCode = "TEMPLATE_BODY_TOO_LONG"
+	CodeTemplateMediaRequired   AppCode = "TEMPLATE_MEDIA_REQUIRED"
+	CodeTemplateMediaNotAllowed AppCode = "TEMPLATE_MEDIA_NOT_ALLOWED"
+	CodeInvalidTemplateSyntax   AppCode = "INVALID_TEMPLATE_SYNTAX"
+	CodeMissingTemplateVariable AppCode = "MISSING_TEMPLATE_VARIABLE"
+	CodeTemplateNothingToUpdate AppCode = "TEMPLATE_NOTHING_TO_UPDATE"
+
+	CodeInvalidIdempotencyKey AppCode = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyInUse   AppCode = "IDEMPOTENCY_KEY_IN_USE"
+	CodeIdempotencyKeyReused  AppCode = "IDEMPOTENCY_KEY_REUSED"
+
+	CodeInvalidMediaMaxSize AppCode = "INVALID_MEDIA_MAX_SIZE"
+	CodeInvalidMediaChat    AppCode = "INVALID_MEDIA_CHAT"
+	CodeTooManyMediaChats   AppCode = "TOO_MANY_MEDIA_CHATS"
+
+	CodeTranscodeFailed   AppCode = "TRANSCODE_FAILED"
+	CodeTranscodeTooLarge AppCode = "TRANSCODE_TOO_LARGE"
+	CodeTranscodeTooLong  AppCode = "TRANSCODE_TOO_LONG"
+	CodeMediaWithoutAudio AppCode = "MEDIA_WITHOUT_AUDIO"
+	CodeMediaWithoutVideo AppCode = "MEDIA_WITHOUT_VIDEO"
+
+	CodeUploadNotFound        AppCode = "UPLOAD_NOT_FOUND"
+	CodeUploadExpired         AppCode = "UPLOAD_EXPIRED"
+	CodeInvalidUploadLength   AppCode = "INVALID_UPLOAD_LENGTH"
+	CodeUploadTooLarge        AppCode = "UPLOAD_TOO_LARGE"
+	CodeUploadOffsetMismatch  AppCode = "UPLOAD_OFFSET_MISMATCH"
+	CodeUploadLocked          AppCode = "UPLOAD_LOCKED"
+	CodeUploadChunkTooLarge   AppCode = "UPLOAD_CHUNK_TOO_LARGE"
+	CodeInvalidUploadMetadata AppCode = "INVALID_UPLOAD_METADATA"
+	CodeUnsupportedTusVersion AppCode = "UNSUPPORTED_TUS_VERSION"
+	CodeInvalidChunkType      AppCode = "INVALID_CHUNK_TYPE"
+
+	CodeMimeRequired            AppCode = "MIME_REQUIRED"
+	CodePresignUnsupported      AppCode = "PRESIGN_UNSUPPORTED"
+	CodePresignedUploadNotFound AppCode = "PRESIGNED_UPLOAD_NOT_FOUND"
+	CodePresignedUploadMissing  AppCode = "PRESIGNED_UPLOAD_MISSING"
+	CodeInvalidSignature        AppCode = "INVALID_SIGNATURE"
+	CodeSignatureExpired        AppCode = "SIGNATURE_EXPIRED"
+
+	CodeRetentionWithoutLimit AppCode = "RETENTION_WITHOUT_LIMIT"
+
+	CodeQuotaBytesExceeded AppCode = "QUOTA_BYTES_EXCEEDED"
+	CodeQuotaFilesExceeded AppCode = "QUOTA_FILES_EXCEEDED"
 )
@@ -1,10 +1,23 @@
 package app
 
 import (
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/transcoder"
 	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/idempotency"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/retention"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/template"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/token"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/webhook"
 )
 
@@ -42,6 +55,7 @@ var errorCodeMap = map[error]AppCode{
 	file.ErrFileTooLarge:        CodeFileTooLarge,
 	file.ErrUnsupported:         CodeUnsupportedFileType,
 	file.ErrCorruptedFile:       CodeCorruptedFile,
+	file.ErrImageTooLarge:       CodeFileTooLarge,
 	file.ErrUploadFailed:        CodeFileUploadFailed,
 	file.ErrDownloadFailed:      CodeFileDownloadFailed,
 	file.ErrStorageFailed:       CodeFileStorageFailed,
@@ -53,10 +67,104 @@ var errorCodeMap = map[error]AppCode{
 	file.ErrFileCannotBeDeleted: CodeFileCannotBeDeleted,
 	file.ErrFileSourceEmpty:     CodeFileSourceEmpty,
 
+	file.ErrInvalidMediaType:     CodeInvalidMediaType,
+	file.ErrMediaNotDownloadable: CodeMediaNotDownloadable,
+	file.ErrInvalidMediaURL:      CodeInvalidMediaURL,
+	file.ErrMediaTypeMismatch:    CodeMediaTypeMismatch,
+	file.ErrMediaHashMismatch:    CodeMediaHashMismatch,
+	file.ErrMediaExpired:         CodeMediaExpired,
+
 	webhook.ErrNotFound:           CodeWebhookNotFound,
 	webhook.ErrInvalidURL:         CodeWebhookInvalidURL,
 	webhook.ErrInvalidID:          CodeWebhookInvalidID,
 	webhook.ErrMaxWebhooksReached: CodeWebhookMaxWebhooksReached,
+
+	message.ErrMessageNotFound:   CodeMessageNotFound,
+	message.ErrInvalidCursor:     CodeInvalidCursor,
+	message.ErrNoHistoryAnchor:   CodeNoHistoryAnchor,
+	message.ErrEditWindowExpired: CodeEditWindowExpired,
+
+	chat.ErrInvalidJID:          CodeInvalidChatJID,
+	chat.ErrInvalidDisappearing: CodeInvalidDisappearingTimer,
+	chat.ErrNotDirectChat:       CodeNotDirectChat,
+
+	queue.ErrQueuedMessageNotFound: CodeQueuedMessageNotFound,
+	queue.ErrNotCancelable:         CodeQueuedMessageNotCancelable,
+	queue.ErrUnsupportedKind:       CodeQueueUnsupportedKind,
+	queue.ErrInvalidPayload:        CodeQueueInvalidPayload,
+
+	queue.ErrScheduledMessageNotFound: CodeScheduledMessageNotFound,
+	queue.ErrNotEditable:              CodeScheduledMessageNotEditable,
+	queue.ErrInvalidSendAt:            CodeInvalidSendAt,
+	queue.ErrInvalidTimezone:          CodeInvalidTimezone,
+	queue.ErrSendAtInPast:             CodeSendAtInPast,
+	queue.ErrSendAtTooFar:             CodeSendAtTooFar,
+
+	ratelimit.ErrRateLimited:       CodeRateLimited,
+	ratelimit.ErrRecipientCooldown: CodeRecipientCooldown,
+	ratelimit.ErrInvalidLimit:      CodeInvalidRateLimit,
+	ratelimit.ErrInvalidDelay:      CodeInvalidDelay,
+	ratelimit.ErrInvalidAction:     CodeInvalidLimitAction,
+
+	campaign.ErrCampaignNotFound:  CodeCampaignNotFound,
+	campaign.ErrInvalidName:       CodeInvalidCampaignName,
+	campaign.ErrNoRecipients:      CodeCampaignNoRecipients,
+	campaign.ErrTooManyRecipients: CodeCampaignTooManyRecipients,
+	campaign.ErrInvalidRecipient:  CodeInvalidCampaignRecipient,
+	campaign.ErrInvalidPacing:     CodeInvalidCampaignPacing,
+	campaign.ErrNotRunning:        CodeCampaignNotRunning,
+	campaign.ErrNotPaused:         CodeCampaignNotPaused,
+	campaign.ErrFinished:          CodeCampaignFinished,
+	campaign.ErrInvalidStatus:     CodeInvalidRecipientStatus,
+	campaign.ErrInvalidLimit:      CodeInvalidRecipientsLimit,
+	campaign.ErrConflict:          CodeCampaignConflict,
+
+	template.ErrTemplateNotFound: CodeTemplateNotFound,
+	template.ErrInvalidName:      CodeInvalidTemplateName,
+	template.ErrNameTaken:        CodeTemplateNameTaken,
+	template.ErrUnsupportedKind:  CodeTemplateUnsupportedKind,
+	template.ErrEmptyBody:        CodeTemplateEmptyBody,
+	template.ErrBodyTooLong:      CodeTemplateBodyTooLong,
+	template.ErrMediaRequired:    CodeTemplateMediaRequired,
+	template.ErrMediaNotAllowed:  CodeTemplateMediaNotAllowed,
+	template.ErrInvalidSyntax:    CodeInvalidTemplateSyntax,
+	template.ErrMissingVariable:  CodeMissingTemplateVariable,
+	template.ErrNothingToUpdate:  CodeTemplateNothingToUpdate,
+
+	idempotency.ErrInvalidKey: CodeInvalidIdempotencyKey,
+	idempotency.ErrKeyInUse:   CodeIdempotencyKeyInUse,
+	idempotency.ErrKeyReused:  CodeIdempotencyKeyReused,
+
+	media.ErrInvalidMaxSize: CodeInvalidMediaMaxSize,
+	media.ErrInvalidChat:    CodeInvalidMediaChat,
+	media.ErrTooManyChats:   CodeTooManyMediaChats,
+
+	transcoder.ErrTranscodeFailed: CodeTranscodeFailed,
+	transcoder.ErrMediaTooLarge:   CodeTranscodeTooLarge,
+	transcoder.ErrMediaTooLong:    CodeTranscodeTooLong,
+	transcoder.ErrNoAudioStream:   CodeMediaWithoutAudio,
+	transcoder.ErrNoVideoStream:   CodeMediaWithoutVideo,
+
+	tus.ErrUploadNotFound:  CodeUploadNotFound,
+	tus.ErrUploadExpired:   CodeUploadExpired,
+	tus.ErrInvalidLength:   CodeInvalidUploadLength,
+	tus.ErrUploadTooLarge:  CodeUploadTooLarge,
+	tus.ErrOffsetMismatch:  CodeUploadOffsetMismatch,
+	tus.ErrUploadLocked:    CodeUploadLocked,
+	tus.ErrChunkTooLarge:   CodeUploadChunkTooLarge,
+	tus.ErrInvalidMetadata: CodeInvalidUploadMetadata,
+
+	file.ErrMimeRequired:            CodeMimeRequired,
+	file.ErrPresignedUploadNotFound: CodePresignedUploadNotFound,
+	file.ErrPresignedUploadMissing:  CodePresignedUploadMissing,
+	storage.ErrPresignUnsupported:   CodePresignUnsupported,
+	storage.ErrInvalidSignature:     CodeInvalidSignature,
+	storage.ErrSignatureExpired:     CodeSignatureExpired,
+
+	retention.ErrNoLimit: CodeRetentionWithoutLimit,
+
+	quota.ErrBytesExceeded: CodeQuotaBytesExceeded,
+	quota.ErrFilesExceeded: CodeQuotaFilesExceeded,
 }
 
 func TranslateError(location string, err error) *AppError {
@@ -0,0 +1,84 @@
+package input
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+)
+
+type CreateCampaignInput struct {
+	Name       string              `json:"name"`
+	Type       message.MessageKind `json:"type"`
+	Message    json.RawMessage     `json:"message"`    // body of the send endpoint of the type, without "to"
+	Recipients []string            `json:"recipients"` // phones or jids
+	Pacing     *campaign.Pacing    `json:"pacing"`
+}
+
+func (inp *CreateCampaignInput) Validate() error {
+	inp.Name = strings.TrimSpace(inp.Name)
+	if inp.Name == "" || len(inp.Name) > campaign.MaxNameLength {
+		return campaign.ErrInvalidName
+	}
+
+	if inp.Type == "" {
+		return queue.ErrUnsupportedKind
+	}
+
+	if len(inp.Message) == 0 {
+		return queue.ErrInvalidPayload
+	}
+
+	if len(inp.Recipients) == 0 {
+		return campaign.ErrNoRecipients
+	}
+
+	if len(inp.Recipients) > campaign.MaxRecipients {
+		return campaign.ErrTooManyRecipients
+	}
+
+	for i, recipient := range inp.Recipients {
+		recipient = strings.TrimSpace(recipient)
+		if recipient == "" {
+			return campaign.ErrInvalidRecipient
+		}
+
+		if user, server, isJID := strings.Cut(recipient, "@"); isJID && (user == "" || server == "") {
+			return campaign.ErrInvalidRecipient
+		}
+
+		inp.Recipients[i] = recipient
+	}
+
+	if inp.Pacing == nil {
+		pacing := campaign.DefaultPacing()
+		inp.Pacing = &pacing
+	}
+
+	return inp.Pacing.Validate()
+}
+
+type ListCampaignRecipientsInput struct {
+	Status *campaign.RecipientStatus `json:"status"`
+	After  *string                   `json:"after"` // id of the last recipient of the previous page
+	Limit  *int                      `json:"limit"`
+}
+
+func (inp *ListCampaignRecipientsInput) Validate() error {
+	if inp.Status != nil && !inp.Status.IsValid() {
+		return campaign.ErrInvalidStatus
+	}
+
+	if inp.Limit != nil && (*inp.Limit < 1 || *inp.Limit > campaign.MaxRecipientsLimit) {
+		return campaign.ErrInvalidLimit
+	}
+
+	if inp.Limit == nil {
+		limit := campaign.DefaultRecipientsLimit
+		inp.Limit = &limit
+	}
+
+	return nil
+}
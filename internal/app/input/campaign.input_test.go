@@ -0,0 +1,90 @@
+package input_test
+
+import (
+	"strings"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Campaign Inputs", func() {
+	body := []byte(`{"text":"hi"}`)
+
+	Describe("CreateCampaignInput Input", func() {
+		It("should validate successfully and default the pacing", func() {
+			inp := &input.CreateCampaignInput{
+				Name:       " Black friday ",
+				Type:       message.MessageKindText,
+				Message:    body,
+				Recipients: []string{" +5511999999999", "123@s.whatsapp.net"},
+			}
+			Expect(inp.Validate()).To(BeNil())
+			Expect(inp.Name).To(Equal("Black friday"))
+			Expect(inp.Recipients[0]).To(Equal("+5511999999999"))
+			Expect(*inp.Pacing).To(Equal(campaign.DefaultPacing()))
+		})
+
+		It("should fail validation for an invalid name", func() {
+			inp := &input.CreateCampaignInput{Name: " ", Type: message.MessageKindText, Message: body, Recipients: []string{"123"}}
+			Expect(inp.Validate()).To(Equal(campaign.ErrInvalidName))
+
+			inp.Name = strings.Repeat("a", campaign.MaxNameLength+1)
+			Expect(inp.Validate()).To(Equal(campaign.ErrInvalidName))
+		})
+
+		It("should fail validation without type or message", func() {
+			inp := &input.CreateCampaignInput{Name: "promo", Message: body, Recipients: []string{"123"}}
+			Expect(inp.Validate()).To(Equal(queue.ErrUnsupportedKind))
+
+			inp = &input.CreateCampaignInput{Name: "promo", Type: message.MessageKindText, Recipients: []string{"123"}}
+			Expect(inp.Validate()).To(Equal(queue.ErrInvalidPayload))
+		})
+
+		It("should fail validation for missing, too many or invalid recipients", func() {
+			inp := &input.CreateCampaignInput{Name: "promo", Type: message.MessageKindText, Message: body}
+			Expect(inp.Validate()).To(Equal(campaign.ErrNoRecipients))
+
+			inp.Recipients = make([]string, campaign.MaxRecipients+1)
+			Expect(inp.Validate()).To(Equal(campaign.ErrTooManyRecipients))
+
+			inp.Recipients = []string{"123", ""}
+			Expect(inp.Validate()).To(Equal(campaign.ErrInvalidRecipient))
+
+			inp.Recipients = []string{"@s.whatsapp.net"}
+			Expect(inp.Validate()).To(Equal(campaign.ErrInvalidRecipient))
+		})
+
+		It("should fail validation for an invalid pacing", func() {
+			inp := &input.CreateCampaignInput{
+				Name:       "promo",
+				Type:       message.MessageKindText,
+				Message:    body,
+				Recipients: []string{"123"},
+				Pacing:     &campaign.Pacing{MinDelayMs: 5000, MaxDelayMs: 1000},
+			}
+			Expect(inp.Validate()).To(Equal(campaign.ErrInvalidPacing))
+		})
+	})
+
+	Describe("ListCampaignRecipientsInput Input", func() {
+		It("should validate successfully and default the limit", func() {
+			inp := &input.ListCampaignRecipientsInput{}
+			Expect(inp.Validate()).To(BeNil())
+			Expect(*inp.Limit).To(Equal(campaign.DefaultRecipientsLimit))
+		})
+
+		It("should fail validation for an invalid status or limit", func() {
+			status := campaign.RecipientStatus("unknown")
+			inp := &input.ListCampaignRecipientsInput{Status: &status}
+			Expect(inp.Validate()).To(Equal(campaign.ErrInvalidStatus))
+
+			limit := campaign.MaxRecipientsLimit + 1
+			inp = &input.ListCampaignRecipientsInput{Limit: &limit}
+			Expect(inp.Validate()).To(Equal(campaign.ErrInvalidLimit))
+		})
+	})
+})
@@ -20,3 +20,25 @@ func (inp *SendChatPresenceInput) Validate() error {
 
 	return nil
 }
+
+type UpdateChatDisappearingInput struct {
+	Chat     string                 `json:"chat"`
+	Duration chat.DisappearingTimer `json:"duration"`
+}
+
+func (inp *UpdateChatDisappearingInput) Validate() error {
+	if inp.Chat == "" {
+		return chat.ErrInvalidJID
+	}
+
+	// groups have their own timer, see PATCH /groups/:group/disappearing
+	if !chat.IsDirectJID(inp.Chat) {
+		return chat.ErrNotDirectChat
+	}
+
+	if !inp.Duration.IsValid() {
+		return chat.ErrInvalidDisappearing
+	}
+
+	return nil
+}
@@ -33,4 +33,37 @@ var _ = Describe("Chat Inputs", func() {
 			Expect(inp.Validate()).To(Equal(chat.ErrInvalidChatPresenceType))
 		})
 	})
+
+	Describe("UpdateChatDisappearing Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.UpdateChatDisappearingInput{
+				Chat:     "5514987654321@s.whatsapp.net",
+				Duration: chat.DisappearingTimer7Days,
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for empty Chat field", func() {
+			inp := &input.UpdateChatDisappearingInput{
+				Duration: chat.DisappearingTimerOff,
+			}
+			Expect(inp.Validate()).To(Equal(chat.ErrInvalidJID))
+		})
+
+		It("should fail validation for a group chat", func() {
+			inp := &input.UpdateChatDisappearingInput{
+				Chat:     "120363025246125486@g.us",
+				Duration: chat.DisappearingTimer24Hours,
+			}
+			Expect(inp.Validate()).To(Equal(chat.ErrNotDirectChat))
+		})
+
+		It("should fail validation for invalid Duration field", func() {
+			inp := &input.UpdateChatDisappearingInput{
+				Chat:     "5514987654321@s.whatsapp.net",
+				Duration: "1h",
+			}
+			Expect(inp.Validate()).To(Equal(chat.ErrInvalidDisappearing))
+		})
+	})
 })
@@ -0,0 +1,67 @@
+package input
+
+import (
+	"encoding/hex"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+)
+
+// DownloadMediaInput points to a media either by a stored message or by the fields of the file of an incoming
+// media event
+type DownloadMediaInput struct {
+	Type    file.MediaType `query:"-"`
+	Message *string        `query:"message"` // id or WhatsApp id of a stored message
+
+	URL        string `query:"url"`
+	DirectPath string `query:"direct_path"`
+	MediaKey   string `query:"media_key"`
+	Sha256     string `query:"sha256"`
+	Sha256Enc  string `query:"sha256_enc"`
+	Mime       string `query:"mime"`
+	Name       string `query:"name"`
+	Size       uint64 `query:"size"`
+}
+
+func (inp *DownloadMediaInput) HasMessage() bool {
+	return inp.Message != nil && *inp.Message != ""
+}
+
+func (inp *DownloadMediaInput) Validate() error {
+	if !inp.Type.IsValid() {
+		return file.ErrInvalidMediaType
+	}
+
+	if inp.HasMessage() {
+		return nil
+	}
+
+	if !inp.ToFile().IsDownloadable() {
+		return file.ErrMediaNotDownloadable
+	}
+
+	// the direct path is resolved against the media hosts of WhatsApp, a url is only followed to one of them
+	if inp.DirectPath == "" && !file.IsWhatsAppMediaURL(inp.URL) {
+		return file.ErrInvalidMediaURL
+	}
+
+	for _, value := range []string{inp.MediaKey, inp.Sha256, inp.Sha256Enc} {
+		if _, err := hex.DecodeString(value); err != nil {
+			return file.ErrMediaNotDownloadable
+		}
+	}
+
+	return nil
+}
+
+func (inp *DownloadMediaInput) ToFile() *file.File {
+	return &file.File{
+		Name:       inp.Name,
+		Mime:       inp.Mime,
+		Size:       inp.Size,
+		Sha256:     inp.Sha256,
+		Sha256Enc:  inp.Sha256Enc,
+		MediaKey:   inp.MediaKey,
+		DirectPath: inp.DirectPath,
+		URL:        inp.URL,
+	}
+}
@@ -0,0 +1,65 @@
+package input_test
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Download Inputs", func() {
+	Describe("DownloadMedia Input", func() {
+		validInput := func() *input.DownloadMediaInput {
+			return &input.DownloadMediaInput{
+				Type:       file.MediaTypeImage,
+				DirectPath: "/v/t62.7118-24/12345",
+				MediaKey:   "0a1b2c3d",
+				Sha256:     "4e5f6a7b",
+				Sha256Enc:  "8c9d0e1f",
+			}
+		}
+
+		It("should validate successfully with file fields", func() {
+			Expect(validInput().Validate()).To(BeNil())
+		})
+
+		It("should validate successfully with a stored message", func() {
+			message := "3EB0C767D26A1D4B2E"
+			inp := &input.DownloadMediaInput{
+				Type:    file.MediaTypeDocument,
+				Message: &message,
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for invalid Type field", func() {
+			inp := validInput()
+			inp.Type = "gif"
+			Expect(inp.Validate()).To(Equal(file.ErrInvalidMediaType))
+		})
+
+		It("should fail validation without message nor path", func() {
+			inp := validInput()
+			inp.DirectPath = ""
+			Expect(inp.Validate()).To(Equal(file.ErrMediaNotDownloadable))
+		})
+
+		It("should only accept urls of the media hosts of whatsapp", func() {
+			inp := validInput()
+			inp.DirectPath = ""
+			inp.URL = "https://mmg.whatsapp.net/v/t62.7118-24/12345?oh=1"
+			Expect(inp.Validate()).To(BeNil())
+
+			for _, url := range []string{"http://mmg.whatsapp.net/v/1", "https://169.254.169.254/latest", "https://whatsapp.net.evil.com/v/1", "https://user@mmg.whatsapp.net/v/1"} {
+				inp.URL = url
+				Expect(inp.Validate()).To(Equal(file.ErrInvalidMediaURL), url)
+			}
+		})
+
+		It("should fail validation for a non hex media key", func() {
+			inp := validInput()
+			inp.MediaKey = "not-hex"
+			Expect(inp.Validate()).To(Equal(file.ErrMediaNotDownloadable))
+		})
+	})
+})
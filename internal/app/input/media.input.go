@@ -0,0 +1,35 @@
+package input
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+)
+
+// UpdateMediaPolicyInput changes only the given fields of the policy
+type UpdateMediaPolicyInput struct {
+	Enabled      *bool             `json:"enabled"`
+	Types        *[]file.MediaType `json:"types"`
+	MaxSize      *uint64           `json:"max_size"`
+	Chats        *[]string         `json:"chats"`
+	IgnoreGroups *bool             `json:"ignore_groups"`
+}
+
+func (inp *UpdateMediaPolicyInput) Validate() error {
+	if inp.Types != nil {
+		for _, t := range *inp.Types {
+			if !t.IsValid() {
+				return file.ErrInvalidMediaType
+			}
+		}
+	}
+
+	if inp.MaxSize != nil && *inp.MaxSize > media.MaxSizeLimit {
+		return media.ErrInvalidMaxSize
+	}
+
+	if inp.Chats != nil && len(*inp.Chats) > media.MaxChats {
+		return media.ErrTooManyChats
+	}
+
+	return nil
+}
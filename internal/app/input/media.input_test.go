@@ -0,0 +1,42 @@
+package input_test
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Media Inputs", func() {
+	Describe("UpdateMediaPolicy Input", func() {
+		It("should validate successfully", func() {
+			enabled := true
+			maxSize := uint64(1024 * 1024)
+			inp := &input.UpdateMediaPolicyInput{
+				Enabled: &enabled,
+				Types:   &[]file.MediaType{file.MediaTypeImage, file.MediaTypeDocument},
+				MaxSize: &maxSize,
+				Chats:   &[]string{"5511999999999@s.whatsapp.net"},
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for invalid Types field", func() {
+			inp := &input.UpdateMediaPolicyInput{Types: &[]file.MediaType{file.MediaTypeImage, "gif"}}
+			Expect(inp.Validate()).To(Equal(file.ErrInvalidMediaType))
+		})
+
+		It("should fail validation for MaxSize above the limit", func() {
+			maxSize := uint64(media.MaxSizeLimit + 1)
+			inp := &input.UpdateMediaPolicyInput{MaxSize: &maxSize}
+			Expect(inp.Validate()).To(Equal(media.ErrInvalidMaxSize))
+		})
+
+		It("should fail validation for too many chats", func() {
+			chats := make([]string, media.MaxChats+1)
+			inp := &input.UpdateMediaPolicyInput{Chats: &chats}
+			Expect(inp.Validate()).To(Equal(media.ErrTooManyChats))
+		})
+	})
+})
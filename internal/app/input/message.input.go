@@ -35,3 +35,139 @@ func (inp *GenerateMessageIDs) Validate() error {
 
 	return nil
 }
+
+type EditMessageInput struct {
+	ID   string              `json:"id"`
+	Chat string              `json:"chat"`
+	Kind message.MessageKind `json:"kind"`
+	Text string              `json:"text"`
+}
+
+func (inp *EditMessageInput) Validate() error {
+	if inp.ID == "" {
+		return message.ErrInvalidMessageID
+	}
+
+	if inp.Chat == "" {
+		return message.ErrInvalidJID
+	}
+
+	if inp.Kind == "" {
+		inp.Kind = message.MessageKindText
+	}
+
+	if !message.IsEditable(inp.Kind) {
+		return message.ErrNotEditable
+	}
+
+	if inp.Text == "" {
+		return message.ErrEmptyText
+	}
+
+	if inp.Kind == message.MessageKindText && len(inp.Text) > message.MaxMessageTextLength {
+		return message.ErrTextTooLong
+	}
+
+	if inp.Kind != message.MessageKindText && len(inp.Text) > message.MaxCaptionLength {
+		return message.ErrCaptionTooLong
+	}
+
+	return nil
+}
+
+type RevokeMessageInput struct {
+	ID     string  `json:"id"`
+	Chat   string  `json:"chat"`
+	Sender *string `json:"sender"` // only needed to revoke someone else's message as group admin
+}
+
+func (inp *RevokeMessageInput) Validate() error {
+	if inp.ID == "" {
+		return message.ErrInvalidMessageID
+	}
+
+	if inp.Chat == "" {
+		return message.ErrInvalidJID
+	}
+
+	return nil
+}
+
+type ForwardMessageInput struct {
+	ID      *string          `json:"id"`      // id of a stored message, used when message is not given
+	Message *message.Message `json:"message"` // raw message reference, as received in events
+	To      []string         `json:"to"`
+}
+
+func (inp *ForwardMessageInput) Validate() error {
+	if inp.Message == nil {
+		if inp.ID == nil || *inp.ID == "" {
+			return message.ErrInvalidContent
+		}
+	} else {
+		if inp.Message.Content == nil {
+			return message.ErrInvalidContent
+		}
+
+		if inp.Message.Type == message.MessageKindReaction {
+			return message.ErrNotForwardable
+		}
+	}
+
+	if len(inp.To) == 0 {
+		return message.ErrEmptyForwardChats
+	}
+
+	if len(inp.To) > message.MaxForwardChats {
+		return message.ErrTooManyChats
+	}
+
+	for _, to := range inp.To {
+		if to == "" {
+			return message.ErrInvalidJID
+		}
+	}
+
+	return nil
+}
+
+type ListChatMessagesInput struct {
+	Chat   string  `json:"chat"`
+	Cursor *string `json:"cursor"`
+	Limit  *int    `json:"limit"`
+}
+
+func (inp *ListChatMessagesInput) Validate() error {
+	if inp.Chat == "" {
+		return message.ErrInvalidJID
+	}
+
+	if inp.Limit != nil && (*inp.Limit < 1 || *inp.Limit > message.MaxHistoryLimit) {
+		return message.ErrInvalidLimit
+	}
+
+	if inp.Cursor != nil {
+		if _, err := message.DecodeCursor(*inp.Cursor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type RequestChatHistoryInput struct {
+	Chat  string `json:"chat"`
+	Count *int   `json:"count"`
+}
+
+func (inp *RequestChatHistoryInput) Validate() error {
+	if inp.Chat == "" {
+		return message.ErrInvalidJID
+	}
+
+	if inp.Count != nil && (*inp.Count < 1 || *inp.Count > message.MaxHistoryRequestCount) {
+		return message.ErrInvalidHistoryCount
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package input_test
 import (
 	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -75,4 +76,225 @@ var _ = Describe("Message Inputs", func() {
 			Expect(inp.Validate()).To(Equal(message.ErrInvalidQuantity))
 		})
 	})
+
+	Describe("EditMessageInput Input", func() {
+		It("should validate successfully and default kind to text", func() {
+			inp := &input.EditMessageInput{
+				ID:   "valid_message_id",
+				Chat: "valid_chat_id",
+				Text: "edited text",
+			}
+			Expect(inp.Validate()).To(BeNil())
+			Expect(inp.Kind).To(Equal(message.MessageKindText))
+		})
+
+		It("should validate a caption edit successfully", func() {
+			inp := &input.EditMessageInput{
+				ID:   "valid_message_id",
+				Chat: "valid_chat_id",
+				Kind: message.MessageKindImage,
+				Text: "edited caption",
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for empty ID field", func() {
+			inp := &input.EditMessageInput{
+				Chat: "valid_chat_id",
+				Text: "edited text",
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidMessageID))
+		})
+
+		It("should fail validation for empty Chat field", func() {
+			inp := &input.EditMessageInput{
+				ID:   "valid_message_id",
+				Text: "edited text",
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidJID))
+		})
+
+		It("should fail validation for non editable kind", func() {
+			inp := &input.EditMessageInput{
+				ID:   "valid_message_id",
+				Chat: "valid_chat_id",
+				Kind: message.MessageKindAudio,
+				Text: "edited text",
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrNotEditable))
+		})
+
+		It("should fail validation for empty Text field", func() {
+			inp := &input.EditMessageInput{
+				ID:   "valid_message_id",
+				Chat: "valid_chat_id",
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrEmptyText))
+		})
+
+		It("should fail validation for caption too long", func() {
+			caption := make([]byte, message.MaxCaptionLength+1)
+			for i := range caption {
+				caption[i] = 'a'
+			}
+			inp := &input.EditMessageInput{
+				ID:   "valid_message_id",
+				Chat: "valid_chat_id",
+				Kind: message.MessageKindVideo,
+				Text: string(caption),
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrCaptionTooLong))
+		})
+	})
+
+	Describe("RevokeMessageInput Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.RevokeMessageInput{
+				ID:   "valid_message_id",
+				Chat: "valid_chat_id",
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for empty ID field", func() {
+			inp := &input.RevokeMessageInput{
+				Chat: "valid_chat_id",
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidMessageID))
+		})
+
+		It("should fail validation for empty Chat field", func() {
+			inp := &input.RevokeMessageInput{
+				ID: "valid_message_id",
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidJID))
+		})
+	})
+
+	Describe("ForwardMessageInput Input", func() {
+		var source *message.Message
+
+		BeforeEach(func() {
+			source = message.NewMessage(nil, "valid_sender_id", "valid_chat_id", message.NewTextContent("hello", nil), nil, nil, false)
+		})
+
+		It("should validate successfully", func() {
+			inp := &input.ForwardMessageInput{
+				Message: source,
+				To:      []string{"valid_chat_id_1", "valid_chat_id_2"},
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for missing Message", func() {
+			inp := &input.ForwardMessageInput{
+				To: []string{"valid_chat_id_1"},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidContent))
+		})
+
+		It("should fail validation for reaction Message", func() {
+			inp := &input.ForwardMessageInput{
+				Message: message.NewMessage(nil, "valid_sender_id", "valid_chat_id", message.NewReactionContent("👍", "valid_message_id"), nil, nil, false),
+				To:      []string{"valid_chat_id_1"},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrNotForwardable))
+		})
+
+		It("should fail validation for empty To field", func() {
+			inp := &input.ForwardMessageInput{
+				Message: source,
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrEmptyForwardChats))
+		})
+
+		It("should fail validation for too many chats", func() {
+			to := make([]string, message.MaxForwardChats+1)
+			for i := range to {
+				to[i] = "valid_chat_id"
+			}
+			inp := &input.ForwardMessageInput{
+				Message: source,
+				To:      to,
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrTooManyChats))
+		})
+
+		It("should fail validation for empty chat", func() {
+			inp := &input.ForwardMessageInput{
+				Message: source,
+				To:      []string{"valid_chat_id_1", ""},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidJID))
+		})
+
+		It("should validate successfully with a stored message id", func() {
+			inp := &input.ForwardMessageInput{
+				ID: utils.StringPtr("0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"),
+				To: []string{"valid_chat_id"},
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+	})
+
+	Describe("ListChatMessagesInput Input", func() {
+		It("should validate successfully", func() {
+			cursor := message.Cursor{Timestamp: 1760000000000, ID: "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"}.Encode()
+			inp := &input.ListChatMessagesInput{
+				Chat:   "5514987654321@s.whatsapp.net",
+				Cursor: &cursor,
+				Limit:  utils.IntPtr(20),
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for empty Chat field", func() {
+			inp := &input.ListChatMessagesInput{}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidJID))
+		})
+
+		It("should fail validation for invalid limit", func() {
+			inp := &input.ListChatMessagesInput{
+				Chat:  "5514987654321@s.whatsapp.net",
+				Limit: utils.IntPtr(message.MaxHistoryLimit + 1),
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidLimit))
+		})
+
+		It("should fail validation for invalid cursor", func() {
+			inp := &input.ListChatMessagesInput{
+				Chat:   "5514987654321@s.whatsapp.net",
+				Cursor: utils.StringPtr("not a cursor"),
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidCursor))
+		})
+	})
+
+	Describe("RequestChatHistoryInput Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.RequestChatHistoryInput{
+				Chat:  "5514987654321@s.whatsapp.net",
+				Count: utils.IntPtr(100),
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should validate successfully without count", func() {
+			inp := &input.RequestChatHistoryInput{Chat: "5514987654321@s.whatsapp.net"}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for empty Chat field", func() {
+			inp := &input.RequestChatHistoryInput{}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidJID))
+		})
+
+		It("should fail validation for invalid count", func() {
+			inp := &input.RequestChatHistoryInput{
+				Chat:  "5514987654321@s.whatsapp.net",
+				Count: utils.IntPtr(message.MaxHistoryRequestCount + 1),
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidHistoryCount))
+		})
+	})
 })
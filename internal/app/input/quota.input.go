@@ -0,0 +1,7 @@
+package input
+
+// UpdateQuotaInput changes only the given limits of the quota, zero leaves a limit off
+type UpdateQuotaInput struct {
+	MaxBytes *uint64 `json:"max_bytes"`
+	MaxFiles *uint64 `json:"max_files"`
+}
@@ -0,0 +1,48 @@
+package input
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+)
+
+// UpdateRateLimitInput changes only the given fields of the policy
+type UpdateRateLimitInput struct {
+	PerSecond           *int              `json:"per_second"`
+	PerMinute           *int              `json:"per_minute"`
+	RecipientCooldownMs *int              `json:"recipient_cooldown_ms"`
+	MinDelayMs          *int              `json:"min_delay_ms"`
+	MaxDelayMs          *int              `json:"max_delay_ms"`
+	Typing              *bool             `json:"typing"`
+	OnLimit             *ratelimit.Action `json:"on_limit"`
+}
+
+func (inp *UpdateRateLimitInput) Validate() error {
+	if inp.PerSecond != nil && (*inp.PerSecond < 0 || *inp.PerSecond > ratelimit.MaxPerSecond) {
+		return ratelimit.ErrInvalidLimit
+	}
+
+	if inp.PerMinute != nil && (*inp.PerMinute < 0 || *inp.PerMinute > ratelimit.MaxPerMinute) {
+		return ratelimit.ErrInvalidLimit
+	}
+
+	if inp.RecipientCooldownMs != nil && (*inp.RecipientCooldownMs < 0 || *inp.RecipientCooldownMs > int(ratelimit.MaxRecipientCooldown/time.Millisecond)) {
+		return ratelimit.ErrInvalidLimit
+	}
+
+	maxDelayMs := int(ratelimit.MaxDelay / time.Millisecond)
+
+	if inp.MinDelayMs != nil && (*inp.MinDelayMs < 0 || *inp.MinDelayMs > maxDelayMs) {
+		return ratelimit.ErrInvalidDelay
+	}
+
+	if inp.MaxDelayMs != nil && (*inp.MaxDelayMs < 0 || *inp.MaxDelayMs > maxDelayMs) {
+		return ratelimit.ErrInvalidDelay
+	}
+
+	if inp.OnLimit != nil && !inp.OnLimit.IsValid() {
+		return ratelimit.ErrInvalidAction
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package input_test
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate Limit Inputs", func() {
+	Describe("UpdateRateLimitInput Input", func() {
+		intPtr := func(v int) *int { return &v }
+
+		It("should validate successfully", func() {
+			action := ratelimit.ActionQueue
+			inp := &input.UpdateRateLimitInput{
+				PerSecond:  intPtr(1),
+				PerMinute:  intPtr(20),
+				MinDelayMs: intPtr(1000),
+				MaxDelayMs: intPtr(3000),
+				OnLimit:    &action,
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for limits out of range", func() {
+			inp := &input.UpdateRateLimitInput{PerSecond: intPtr(-1)}
+			Expect(inp.Validate()).To(Equal(ratelimit.ErrInvalidLimit))
+
+			inp = &input.UpdateRateLimitInput{PerMinute: intPtr(ratelimit.MaxPerMinute + 1)}
+			Expect(inp.Validate()).To(Equal(ratelimit.ErrInvalidLimit))
+		})
+
+		It("should fail validation for delays out of range", func() {
+			inp := &input.UpdateRateLimitInput{MaxDelayMs: intPtr(120_000)}
+			Expect(inp.Validate()).To(Equal(ratelimit.ErrInvalidDelay))
+		})
+
+		It("should fail validation for an unknown action", func() {
+			action := ratelimit.Action("drop")
+			inp := &input.UpdateRateLimitInput{OnLimit: &action}
+			Expect(inp.Validate()).To(Equal(ratelimit.ErrInvalidAction))
+		})
+	})
+})
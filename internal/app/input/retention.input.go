@@ -0,0 +1,10 @@
+package input
+
+// UpdateRetentionRuleInput changes only the given fields of the rule, the rule is validated as a whole once changed
+type UpdateRetentionRuleInput struct {
+	Enabled    *bool   `json:"enabled"`
+	MaxAge     *uint64 `json:"max_age"` // in seconds
+	MaxBytes   *uint64 `json:"max_bytes"`
+	MaxCount   *uint64 `json:"max_count"`
+	KeepPinned *bool   `json:"keep_pinned"`
+}
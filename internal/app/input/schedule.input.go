@@ -0,0 +1,71 @@
+package input
+
+import (
+	"encoding/json"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+)
+
+type ScheduleMessageInput struct {
+	Type     message.MessageKind `json:"type"`
+	SendAt   string              `json:"send_at"`
+	Timezone string              `json:"timezone"`
+	Message  json.RawMessage     `json:"message"` // body of the send endpoint of the type
+}
+
+func (inp *ScheduleMessageInput) Validate() error {
+	if inp.Type == "" {
+		return queue.ErrUnsupportedKind
+	}
+
+	if len(inp.Message) == 0 {
+		return queue.ErrInvalidPayload
+	}
+
+	at, err := queue.ParseSendAt(inp.SendAt, inp.Timezone)
+	if err != nil {
+		return err
+	}
+
+	if err := queue.ValidateSendAt(at); err != nil {
+		return err
+	}
+
+	if inp.Timezone == "" {
+		inp.Timezone = queue.DefaultTimezone
+	}
+
+	return nil
+}
+
+type UpdateScheduledMessageInput struct {
+	SendAt   *string         `json:"send_at"`
+	Timezone *string         `json:"timezone"`
+	Message  json.RawMessage `json:"message"` // replaces the body, keeping the type of the scheduled message
+}
+
+func (inp *UpdateScheduledMessageInput) Validate() error {
+	if inp.SendAt == nil && inp.Timezone == nil && len(inp.Message) == 0 {
+		return queue.ErrInvalidPayload
+	}
+
+	// a timezone alone only makes sense together with the local time it applies to
+	if inp.SendAt == nil && inp.Timezone != nil {
+		return queue.ErrInvalidSendAt
+	}
+
+	// the moment itself is checked by the service, a send_at without timezone is read in the one stored
+	if inp.SendAt != nil {
+		timezone := queue.DefaultTimezone
+		if inp.Timezone != nil {
+			timezone = *inp.Timezone
+		}
+
+		if _, err := queue.ParseSendAt(*inp.SendAt, timezone); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package input_test
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schedule Inputs", func() {
+	tomorrow := time.Now().Add(24 * time.Hour).Format("2006-01-02T15:04:05")
+	body := []byte(`{"to":"123@s.whatsapp.net","text":"hi"}`)
+
+	Describe("ScheduleMessageInput Input", func() {
+		It("should validate successfully and default the timezone", func() {
+			inp := &input.ScheduleMessageInput{
+				Type:    message.MessageKindText,
+				SendAt:  tomorrow,
+				Message: body,
+			}
+			Expect(inp.Validate()).To(BeNil())
+			Expect(inp.Timezone).To(Equal(queue.DefaultTimezone))
+		})
+
+		It("should fail validation without type or message", func() {
+			inp := &input.ScheduleMessageInput{SendAt: tomorrow, Message: body}
+			Expect(inp.Validate()).To(Equal(queue.ErrUnsupportedKind))
+
+			inp = &input.ScheduleMessageInput{Type: message.MessageKindText, SendAt: tomorrow}
+			Expect(inp.Validate()).To(Equal(queue.ErrInvalidPayload))
+		})
+
+		It("should fail validation for an invalid timezone", func() {
+			inp := &input.ScheduleMessageInput{
+				Type:     message.MessageKindText,
+				SendAt:   tomorrow,
+				Timezone: "Nowhere/City",
+				Message:  body,
+			}
+			Expect(inp.Validate()).To(Equal(queue.ErrInvalidTimezone))
+		})
+
+		It("should fail validation for a send_at in the past", func() {
+			inp := &input.ScheduleMessageInput{
+				Type:    message.MessageKindText,
+				SendAt:  time.Now().Add(-time.Hour).Format(time.RFC3339),
+				Message: body,
+			}
+			Expect(inp.Validate()).To(Equal(queue.ErrSendAtInPast))
+		})
+	})
+
+	Describe("UpdateScheduledMessageInput Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.UpdateScheduledMessageInput{SendAt: utils.StringPtr(tomorrow), Timezone: utils.StringPtr("Asia/Tokyo")}
+			Expect(inp.Validate()).To(BeNil())
+
+			inp = &input.UpdateScheduledMessageInput{Message: body}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation when nothing changes", func() {
+			inp := &input.UpdateScheduledMessageInput{}
+			Expect(inp.Validate()).To(Equal(queue.ErrInvalidPayload))
+		})
+
+		It("should fail validation for a timezone without send_at", func() {
+			inp := &input.UpdateScheduledMessageInput{Timezone: utils.StringPtr("Asia/Tokyo")}
+			Expect(inp.Validate()).To(Equal(queue.ErrInvalidSendAt))
+		})
+	})
+})
@@ -1,15 +1,21 @@
 package input
 
 import (
+	"strings"
+
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
 )
 
 type SendTextMessageInput struct {
-	ID         *string   `json:"id"`
-	To         string    `json:"to"`
-	Text       string    `json:"text"`
-	Mentions   *[]string `json:"mentions"`
-	Expiration *uint32   `json:"expiration"`
+	ID          *string              `json:"id"`
+	To          string               `json:"to"`
+	Text        string               `json:"text"`
+	Mentions    *[]string            `json:"mentions"`
+	Expiration  *uint32              `json:"expiration"`
+	ReplyTo     *message.ReplyTo     `json:"reply_to"`
+	LinkPreview *bool                `json:"link_preview"` // fetch the preview of the first url in the text
+	Preview     *message.LinkPreview `json:"preview"`      // preview supplied by the caller, thumbnail can be an url, upload id or base64
+	Cache       *bool                `json:"cache"`
 }
 
 func (inp *SendTextMessageInput) Validate() error {
@@ -25,23 +31,44 @@ func (inp *SendTextMessageInput) Validate() error {
 		return message.ErrTextTooLong
 	}
 
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if inp.Preview != nil {
+		if err := inp.Preview.Validate(); err != nil {
+			return err
+		}
+
+		if !strings.Contains(inp.Text, inp.Preview.URL) {
+			return message.ErrPreviewURLNotInText
+		}
+	}
+
 	return nil
 }
 
+func (inp *SendTextMessageInput) WantsPreview() bool {
+	return inp.Preview != nil || (inp.LinkPreview != nil && *inp.LinkPreview)
+}
+
 type SendImageMessageInput struct {
-	ID         *string   `json:"id"`
-	To         string    `json:"to"`
-	Image      string    `json:"image"`
-	Name       *string   `json:"name"`
-	Mime       *string   `json:"mime"`
-	Width      *uint32   `json:"width"`
-	Height     *uint32   `json:"height"`
-	Thumbnail  *string   `json:"thumbnail"`
-	Caption    *string   `json:"caption"`
-	Mentions   *[]string `json:"mentions"`
-	Expiration *uint32   `json:"expiration"`
-	ViewOnce   *bool     `json:"view_once"`
-	Cache      *bool     `json:"cache"`
+	ID         *string          `json:"id"`
+	To         string           `json:"to"`
+	Image      string           `json:"image"`
+	Name       *string          `json:"name"`
+	Mime       *string          `json:"mime"`
+	Width      *uint32          `json:"width"`
+	Height     *uint32          `json:"height"`
+	Thumbnail  *string          `json:"thumbnail"`
+	Caption    *string          `json:"caption"`
+	Mentions   *[]string        `json:"mentions"`
+	Expiration *uint32          `json:"expiration"`
+	ReplyTo    *message.ReplyTo `json:"reply_to"`
+	ViewOnce   *bool            `json:"view_once"`
+	Cache      *bool            `json:"cache"`
 }
 
 func (inp *SendImageMessageInput) Validate() error {
@@ -57,24 +84,31 @@ func (inp *SendImageMessageInput) Validate() error {
 		return message.ErrCaptionTooLong
 	}
 
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 type SendVideoMessageInput struct {
-	ID         *string   `json:"id"`
-	To         string    `json:"to"`
-	Video      string    `json:"video"`
-	Name       *string   `json:"name"`
-	Mime       *string   `json:"mime"`
-	Width      *uint32   `json:"width"`
-	Height     *uint32   `json:"height"`
-	Duration   *uint32   `json:"duration"`
-	Thumbnail  *string   `json:"thumbnail"`
-	Caption    *string   `json:"caption"`
-	Mentions   *[]string `json:"mentions"`
-	ViewOnce   *bool     `json:"view_once"`
-	Expiration *uint32   `json:"expiration"`
-	Cache      *bool     `json:"cache"`
+	ID         *string          `json:"id"`
+	To         string           `json:"to"`
+	Video      string           `json:"video"`
+	Name       *string          `json:"name"`
+	Mime       *string          `json:"mime"`
+	Width      *uint32          `json:"width"`
+	Height     *uint32          `json:"height"`
+	Duration   *uint32          `json:"duration"`
+	Thumbnail  *string          `json:"thumbnail"`
+	Caption    *string          `json:"caption"`
+	Mentions   *[]string        `json:"mentions"`
+	ViewOnce   *bool            `json:"view_once"`
+	Expiration *uint32          `json:"expiration"`
+	ReplyTo    *message.ReplyTo `json:"reply_to"`
+	Cache      *bool            `json:"cache"`
 }
 
 func (inp *SendVideoMessageInput) Validate() error {
@@ -90,18 +124,25 @@ func (inp *SendVideoMessageInput) Validate() error {
 		return message.ErrCaptionTooLong
 	}
 
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 type SendAudioMessageInput struct {
-	ID         *string `json:"id"`
-	To         string  `json:"to"`
-	Audio      string  `json:"audio"`
-	Name       *string `json:"name"`
-	Mime       *string `json:"mime"`
-	Duration   *uint32 `json:"duration"`
-	Expiration *uint32 `json:"expiration"`
-	Cache      *bool   `json:"cache"`
+	ID         *string          `json:"id"`
+	To         string           `json:"to"`
+	Audio      string           `json:"audio"`
+	Name       *string          `json:"name"`
+	Mime       *string          `json:"mime"`
+	Duration   *uint32          `json:"duration"`
+	Expiration *uint32          `json:"expiration"`
+	ReplyTo    *message.ReplyTo `json:"reply_to"`
+	Cache      *bool            `json:"cache"`
 }
 
 func (inp *SendAudioMessageInput) Validate() error {
@@ -113,19 +154,26 @@ func (inp *SendAudioMessageInput) Validate() error {
 		return message.ErrAudioRequired
 	}
 
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 type SendVoiceMessageInput struct {
-	ID         *string `json:"id"`
-	To         string  `json:"to"`
-	Voice      string  `json:"voice"`
-	Name       *string `json:"name"`
-	Mime       *string `json:"mime"`
-	Duration   *uint32 `json:"duration"`
-	ViewOnce   *bool   `json:"view_once"`
-	Expiration *uint32 `json:"expiration"`
-	Cache      *bool   `json:"cache"`
+	ID         *string          `json:"id"`
+	To         string           `json:"to"`
+	Voice      string           `json:"voice"`
+	Name       *string          `json:"name"`
+	Mime       *string          `json:"mime"`
+	Duration   *uint32          `json:"duration"`
+	ViewOnce   *bool            `json:"view_once"`
+	Expiration *uint32          `json:"expiration"`
+	ReplyTo    *message.ReplyTo `json:"reply_to"`
+	Cache      *bool            `json:"cache"`
 }
 
 func (inp *SendVoiceMessageInput) Validate() error {
@@ -137,21 +185,28 @@ func (inp *SendVoiceMessageInput) Validate() error {
 		return message.ErrVoiceRequired
 	}
 
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 type SendDocumentMessageInput struct {
-	ID         *string   `json:"id"`
-	To         string    `json:"to"`
-	Document   string    `json:"document"`
-	Name       *string   `json:"name"`
-	Mime       *string   `json:"mime"`
-	Pages      *uint32   `json:"pages"`
-	Thumbnail  *string   `json:"thumbnail"`
-	Caption    *string   `json:"caption"`
-	Mentions   *[]string `json:"mentions"`
-	Expiration *uint32   `json:"expiration"`
-	Cache      *bool     `json:"cache"`
+	ID         *string          `json:"id"`
+	To         string           `json:"to"`
+	Document   string           `json:"document"`
+	Name       *string          `json:"name"`
+	Mime       *string          `json:"mime"`
+	Pages      *uint32          `json:"pages"`
+	Thumbnail  *string          `json:"thumbnail"`
+	Caption    *string          `json:"caption"`
+	Mentions   *[]string        `json:"mentions"`
+	Expiration *uint32          `json:"expiration"`
+	ReplyTo    *message.ReplyTo `json:"reply_to"`
+	Cache      *bool            `json:"cache"`
 }
 
 func (inp *SendDocumentMessageInput) Validate() error {
@@ -167,6 +222,12 @@ func (inp *SendDocumentMessageInput) Validate() error {
 		return message.ErrCaptionTooLong
 	}
 
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -206,3 +267,157 @@ func (inp *SendReactionInput) Validate() error {
 
 	return nil
 }
+
+type SendButtonsMessageInput struct {
+	ID         *string          `json:"id"`
+	To         string           `json:"to"`
+	Header     *string          `json:"header"`
+	Text       string           `json:"text"`
+	Footer     *string          `json:"footer"`
+	Buttons    []message.Button `json:"buttons"`
+	Expiration *uint32          `json:"expiration"`
+	ReplyTo    *message.ReplyTo `json:"reply_to"`
+}
+
+func (inp *SendButtonsMessageInput) Validate() error {
+	if inp.To == "" {
+		return message.ErrInvalidJID
+	}
+
+	if inp.Text == "" {
+		return message.ErrEmptyText
+	}
+
+	if len(inp.Buttons) == 0 {
+		return message.ErrButtonsRequired
+	}
+
+	if len(inp.Buttons) > message.MaxButtons {
+		return message.ErrTooManyButtons
+	}
+
+	ids := make(map[string]bool, len(inp.Buttons))
+	for _, button := range inp.Buttons {
+		if button.ID == "" || button.Text == "" {
+			return message.ErrInvalidButton
+		}
+
+		if ids[button.ID] {
+			return message.ErrDuplicatedButtonID
+		}
+		ids[button.ID] = true
+	}
+
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type SendListMessageInput struct {
+	ID         *string               `json:"id"`
+	To         string                `json:"to"`
+	Title      string                `json:"title"`
+	Text       string                `json:"text"`
+	ButtonText string                `json:"button_text"`
+	Footer     *string               `json:"footer"`
+	Sections   []message.ListSection `json:"sections"`
+	Expiration *uint32               `json:"expiration"`
+	ReplyTo    *message.ReplyTo      `json:"reply_to"`
+}
+
+func (inp *SendListMessageInput) Validate() error {
+	if inp.To == "" {
+		return message.ErrInvalidJID
+	}
+
+	if inp.Title == "" {
+		return message.ErrTitleRequired
+	}
+
+	if inp.Text == "" {
+		return message.ErrEmptyText
+	}
+
+	if inp.ButtonText == "" {
+		return message.ErrButtonTextRequired
+	}
+
+	rows := 0
+	ids := make(map[string]bool)
+	for _, section := range inp.Sections {
+		for _, row := range section.Rows {
+			if row.ID == "" || row.Title == "" {
+				return message.ErrInvalidListRow
+			}
+
+			if ids[row.ID] {
+				return message.ErrDuplicatedButtonID
+			}
+			ids[row.ID] = true
+			rows++
+		}
+	}
+
+	if rows == 0 {
+		return message.ErrSectionsRequired
+	}
+
+	if rows > message.MaxListRows {
+		return message.ErrTooManyRows
+	}
+
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type SendTemplateMessageInput struct {
+	ID         *string                  `json:"id"`
+	To         string                   `json:"to"`
+	Title      *string                  `json:"title"`
+	Text       string                   `json:"text"`
+	Footer     *string                  `json:"footer"`
+	Buttons    []message.TemplateButton `json:"buttons"`
+	Expiration *uint32                  `json:"expiration"`
+	ReplyTo    *message.ReplyTo         `json:"reply_to"`
+}
+
+func (inp *SendTemplateMessageInput) Validate() error {
+	if inp.To == "" {
+		return message.ErrInvalidJID
+	}
+
+	if inp.Text == "" {
+		return message.ErrEmptyText
+	}
+
+	if len(inp.Buttons) == 0 {
+		return message.ErrButtonsRequired
+	}
+
+	if len(inp.Buttons) > message.MaxTemplateButtons {
+		return message.ErrTooManyButtons
+	}
+
+	for _, button := range inp.Buttons {
+		if !button.IsValid() {
+			return message.ErrInvalidTemplateButton
+		}
+	}
+
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
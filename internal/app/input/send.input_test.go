@@ -1,6 +1,8 @@
 package input_test
 
 import (
+	"strings"
+
 	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
 	"github.com/mauriciorobertodev/whappy-go/internal/utils"
@@ -57,6 +59,88 @@ var _ = Describe("Send Inputs", func() {
 			}
 			Expect(inp.Validate()).To(Equal(message.ErrTextTooLong))
 		})
+
+		It("should validate successfully with reply", func() {
+			inp := &input.SendTextMessageInput{
+				To:   "551412345678",
+				Text: "Hello, World!",
+				ReplyTo: &message.ReplyTo{
+					ID:      "3EB0C767D26A1D3B5B4B",
+					Sender:  "5514987654321@s.whatsapp.net",
+					Content: utils.StringPtr("Original message"),
+				},
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation due to reply without ID", func() {
+			inp := &input.SendTextMessageInput{
+				To:      "551412345678",
+				Text:    "Hello, World!",
+				ReplyTo: &message.ReplyTo{Sender: "5514987654321@s.whatsapp.net"},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidReplyID))
+		})
+
+		It("should fail validation due to reply without Sender", func() {
+			inp := &input.SendTextMessageInput{
+				To:      "551412345678",
+				Text:    "Hello, World!",
+				ReplyTo: &message.ReplyTo{ID: "3EB0C767D26A1D3B5B4B"},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidReplySender))
+		})
+
+		It("should validate successfully with a supplied preview", func() {
+			inp := &input.SendTextMessageInput{
+				To:   "551412345678",
+				Text: "Look at https://example.com/post",
+				Preview: &message.LinkPreview{
+					URL:   "https://example.com/post",
+					Title: utils.StringPtr("A post"),
+				},
+			}
+			Expect(inp.Validate()).To(BeNil())
+			Expect(inp.WantsPreview()).To(BeTrue())
+		})
+
+		It("should fail validation due to preview without URL", func() {
+			inp := &input.SendTextMessageInput{
+				To:      "551412345678",
+				Text:    "Look at https://example.com/post",
+				Preview: &message.LinkPreview{Title: utils.StringPtr("A post")},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidURL))
+		})
+
+		It("should fail validation due to preview URL not present in the text", func() {
+			inp := &input.SendTextMessageInput{
+				To:      "551412345678",
+				Text:    "Hello, World!",
+				Preview: &message.LinkPreview{URL: "https://example.com/post"},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrPreviewURLNotInText))
+		})
+
+		It("should fail validation due to preview title too long", func() {
+			inp := &input.SendTextMessageInput{
+				To:   "551412345678",
+				Text: "Look at https://example.com/post",
+				Preview: &message.LinkPreview{
+					URL:   "https://example.com/post",
+					Title: utils.StringPtr(strings.Repeat("a", message.MaxPreviewTitleLength+1)),
+				},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrPreviewTitleTooLong))
+		})
+
+		It("should only want a preview when asked for", func() {
+			inp := &input.SendTextMessageInput{To: "551412345678", Text: "Look at https://example.com/post"}
+			Expect(inp.WantsPreview()).To(BeFalse())
+
+			inp.LinkPreview = utils.BoolPtr(true)
+			Expect(inp.WantsPreview()).To(BeTrue())
+		})
 	})
 
 	Describe("SendImageMessageInput Input", func() {
@@ -342,4 +426,138 @@ var _ = Describe("Send Inputs", func() {
 			Expect(inp.Validate()).To(Equal(message.ErrInvalidJID))
 		})
 	})
+
+	Describe("SendButtonsMessageInput Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.SendButtonsMessageInput{
+				To:      "551412345678",
+				Text:    "Choose an option",
+				Buttons: []message.Button{{ID: "yes", Text: "Yes"}, {ID: "no", Text: "No"}},
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation due to empty Buttons", func() {
+			inp := &input.SendButtonsMessageInput{
+				To:   "551412345678",
+				Text: "Choose an option",
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrButtonsRequired))
+		})
+
+		It("should fail validation due to too many Buttons", func() {
+			inp := &input.SendButtonsMessageInput{
+				To:   "551412345678",
+				Text: "Choose an option",
+				Buttons: []message.Button{
+					{ID: "1", Text: "One"}, {ID: "2", Text: "Two"}, {ID: "3", Text: "Three"}, {ID: "4", Text: "Four"},
+				},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrTooManyButtons))
+		})
+
+		It("should fail validation due to duplicated button ID", func() {
+			inp := &input.SendButtonsMessageInput{
+				To:      "551412345678",
+				Text:    "Choose an option",
+				Buttons: []message.Button{{ID: "yes", Text: "Yes"}, {ID: "yes", Text: "Sure"}},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrDuplicatedButtonID))
+		})
+
+		It("should fail validation due to button without text", func() {
+			inp := &input.SendButtonsMessageInput{
+				To:      "551412345678",
+				Text:    "Choose an option",
+				Buttons: []message.Button{{ID: "yes"}},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidButton))
+		})
+	})
+
+	Describe("SendListMessageInput Input", func() {
+		sections := []message.ListSection{
+			{Title: "Pizzas", Rows: []message.ListRow{{ID: "margherita", Title: "Margherita"}, {ID: "pepperoni", Title: "Pepperoni"}}},
+		}
+
+		It("should validate successfully", func() {
+			inp := &input.SendListMessageInput{
+				To:         "551412345678",
+				Title:      "Menu",
+				Text:       "Pick your pizza",
+				ButtonText: "See menu",
+				Sections:   sections,
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation due to empty ButtonText", func() {
+			inp := &input.SendListMessageInput{
+				To:       "551412345678",
+				Title:    "Menu",
+				Text:     "Pick your pizza",
+				Sections: sections,
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrButtonTextRequired))
+		})
+
+		It("should fail validation due to empty Sections", func() {
+			inp := &input.SendListMessageInput{
+				To:         "551412345678",
+				Title:      "Menu",
+				Text:       "Pick your pizza",
+				ButtonText: "See menu",
+				Sections:   []message.ListSection{{Title: "Empty"}},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrSectionsRequired))
+		})
+
+		It("should fail validation due to too many rows", func() {
+			rows := make([]message.ListRow, message.MaxListRows+1)
+			for i := range rows {
+				rows[i] = message.ListRow{ID: string(rune('a' + i)), Title: "Row"}
+			}
+			inp := &input.SendListMessageInput{
+				To:         "551412345678",
+				Title:      "Menu",
+				Text:       "Pick your pizza",
+				ButtonText: "See menu",
+				Sections:   []message.ListSection{{Title: "Many", Rows: rows}},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrTooManyRows))
+		})
+	})
+
+	Describe("SendTemplateMessageInput Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.SendTemplateMessageInput{
+				To:   "551412345678",
+				Text: "Your order is ready",
+				Buttons: []message.TemplateButton{
+					{Type: message.TemplateButtonReply, Text: "Thanks", ID: utils.StringPtr("thanks")},
+					{Type: message.TemplateButtonURL, Text: "Track", URL: utils.StringPtr("https://example.com/track")},
+					{Type: message.TemplateButtonCall, Text: "Call us", Phone: utils.StringPtr("+551412345678")},
+				},
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation due to url button without URL", func() {
+			inp := &input.SendTemplateMessageInput{
+				To:      "551412345678",
+				Text:    "Your order is ready",
+				Buttons: []message.TemplateButton{{Type: message.TemplateButtonURL, Text: "Track"}},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidTemplateButton))
+		})
+
+		It("should fail validation due to unknown button type", func() {
+			inp := &input.SendTemplateMessageInput{
+				To:      "551412345678",
+				Text:    "Your order is ready",
+				Buttons: []message.TemplateButton{{Type: "unknown", Text: "Track", ID: utils.StringPtr("track")}},
+			}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidTemplateButton))
+		})
+	})
 })
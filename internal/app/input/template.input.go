@@ -0,0 +1,80 @@
+package input
+
+import (
+	"strings"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/template"
+)
+
+type CreateTemplateInput struct {
+	Name  string              `json:"name"`
+	Type  message.MessageKind `json:"type"`
+	Body  string              `json:"body"`  // text, or caption of the media
+	Media *string             `json:"media"` // url or upload id, required for image, video and document
+}
+
+// Validate checks the shape of the input, the template itself is checked by the service once built
+func (inp *CreateTemplateInput) Validate() error {
+	if strings.TrimSpace(inp.Name) == "" {
+		return template.ErrInvalidName
+	}
+
+	if !template.IsSupportedKind(inp.Type) {
+		return template.ErrUnsupportedKind
+	}
+
+	return nil
+}
+
+type UpdateTemplateInput struct {
+	Name  *string `json:"name"`
+	Body  *string `json:"body"`
+	Media *string `json:"media"`
+}
+
+func (inp *UpdateTemplateInput) Validate() error {
+	if inp.Name == nil && inp.Body == nil && inp.Media == nil {
+		return template.ErrNothingToUpdate
+	}
+
+	return nil
+}
+
+type RenderTemplateInput struct {
+	Variables map[string]string `json:"variables"`
+}
+
+func (inp *RenderTemplateInput) Validate() error {
+	if inp.Variables == nil {
+		inp.Variables = map[string]string{}
+	}
+
+	return nil
+}
+
+type SendFromTemplateInput struct {
+	To         string            `json:"to"`
+	Variables  map[string]string `json:"variables"`
+	Mentions   *[]string         `json:"mentions"`
+	Expiration *uint32           `json:"expiration"`
+	ReplyTo    *message.ReplyTo  `json:"reply_to"`
+}
+
+func (inp *SendFromTemplateInput) Validate() error {
+	if inp.To == "" {
+		return message.ErrInvalidJID
+	}
+
+	if inp.Variables == nil {
+		inp.Variables = map[string]string{}
+	}
+
+	if inp.ReplyTo != nil {
+		if err := inp.ReplyTo.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
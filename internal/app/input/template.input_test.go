@@ -0,0 +1,50 @@
+package input_test
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/template"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Template Inputs", func() {
+	Describe("CreateTemplateInput Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.CreateTemplateInput{Name: "welcome", Type: message.MessageKindText, Body: "Hi {{name}}"}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation without name or with an unsupported type", func() {
+			inp := &input.CreateTemplateInput{Name: " ", Type: message.MessageKindText, Body: "Hi"}
+			Expect(inp.Validate()).To(Equal(template.ErrInvalidName))
+
+			inp = &input.CreateTemplateInput{Name: "welcome", Type: message.MessageKindButtons, Body: "Hi"}
+			Expect(inp.Validate()).To(Equal(template.ErrUnsupportedKind))
+		})
+	})
+
+	Describe("UpdateTemplateInput Input", func() {
+		It("should fail validation when nothing changes", func() {
+			inp := &input.UpdateTemplateInput{}
+			Expect(inp.Validate()).To(Equal(template.ErrNothingToUpdate))
+
+			inp.Body = utils.StringPtr("Hello")
+			Expect(inp.Validate()).To(BeNil())
+		})
+	})
+
+	Describe("SendFromTemplateInput Input", func() {
+		It("should validate successfully and default the variables", func() {
+			inp := &input.SendFromTemplateInput{To: "123@s.whatsapp.net"}
+			Expect(inp.Validate()).To(BeNil())
+			Expect(inp.Variables).ToNot(BeNil())
+		})
+
+		It("should fail validation without recipient", func() {
+			inp := &input.SendFromTemplateInput{}
+			Expect(inp.Validate()).To(Equal(message.ErrInvalidJID))
+		})
+	})
+})
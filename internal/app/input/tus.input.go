@@ -0,0 +1,36 @@
+package input
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+)
+
+type CreateTusUpload struct {
+	Length   uint64
+	Metadata string // the Upload-Metadata header as sent
+}
+
+func (i *CreateTusUpload) Validate() error {
+	if i.Length == 0 {
+		return tus.ErrInvalidLength
+	}
+
+	if _, err := tus.ParseMetadata(i.Metadata); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type AppendTusUpload struct {
+	ID     string
+	Offset uint64
+	Chunk  []byte
+}
+
+func (i *AppendTusUpload) Validate() error {
+	if !utils.IsUUID(i.ID) {
+		return tus.ErrUploadNotFound
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package input_test
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tus Inputs", func() {
+	Describe("CreateTusUpload Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.CreateTusUpload{Length: 1024, Metadata: "filename cmVwb3J0LnBkZg=="}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail without a length", func() {
+			inp := &input.CreateTusUpload{}
+			Expect(inp.Validate()).To(Equal(tus.ErrInvalidLength))
+		})
+
+		It("should fail with broken metadata", func() {
+			inp := &input.CreateTusUpload{Length: 1024, Metadata: "filename ???"}
+			Expect(inp.Validate()).To(Equal(tus.ErrInvalidMetadata))
+		})
+	})
+
+	Describe("AppendTusUpload Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.AppendTusUpload{ID: "550e8400-e29b-41d4-a716-446655440000"}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail for an invalid id", func() {
+			inp := &input.AppendTusUpload{ID: "invalid-uuid"}
+			Expect(inp.Validate()).To(Equal(tus.ErrUploadNotFound))
+		})
+	})
+})
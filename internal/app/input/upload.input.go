@@ -2,6 +2,7 @@ package input
 
 import (
 	"io"
+	"strings"
 
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
 	"github.com/mauriciorobertodev/whappy-go/internal/utils"
@@ -61,3 +62,40 @@ func (i *DeleteUpload) Validate() error {
 	}
 	return nil
 }
+
+// PinUpload pins or unpins an upload, pinned uploads are kept by the retention rule
+type PinUpload struct {
+	FileID string
+	Pinned bool
+}
+
+func (i *PinUpload) Validate() error {
+	if !utils.IsUUID(i.FileID) {
+		return file.ErrInvalidFileID
+	}
+	return nil
+}
+
+type PresignUpload struct {
+	Mime string
+}
+
+func (i *PresignUpload) Validate() error {
+	if !strings.Contains(i.Mime, "/") {
+		return file.ErrMimeRequired
+	}
+	return nil
+}
+
+type CompletePresignedUpload struct {
+	FileID      string
+	Metadata    file.Metadata
+	ThumbnailID *string
+}
+
+func (i *CompletePresignedUpload) Validate() error {
+	if !utils.IsUUID(i.FileID) {
+		return file.ErrPresignedUploadNotFound
+	}
+	return nil
+}
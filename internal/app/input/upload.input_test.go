@@ -77,4 +77,45 @@ var _ = Describe("Upload Inputs", func() {
 			Expect(inp.Validate()).To(Equal(file.ErrInvalidFileID))
 		})
 	})
+
+	Describe("PinUpload Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.PinUpload{
+				FileID: "550e8400-e29b-41d4-a716-446655440000",
+				Pinned: true,
+			}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should fail validation for invalid UUID", func() {
+			inp := &input.PinUpload{
+				FileID: "invalid-uuid",
+			}
+			Expect(inp.Validate()).To(Equal(file.ErrInvalidFileID))
+		})
+	})
+
+	Describe("PresignUpload Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.PresignUpload{Mime: "video/mp4"}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should require a mime type", func() {
+			Expect((&input.PresignUpload{}).Validate()).To(Equal(file.ErrMimeRequired))
+			Expect((&input.PresignUpload{Mime: "mp4"}).Validate()).To(Equal(file.ErrMimeRequired))
+		})
+	})
+
+	Describe("CompletePresignedUpload Input", func() {
+		It("should validate successfully", func() {
+			inp := &input.CompletePresignedUpload{FileID: "550e8400-e29b-41d4-a716-446655440000"}
+			Expect(inp.Validate()).To(BeNil())
+		})
+
+		It("should not find an upload with an invalid UUID", func() {
+			inp := &input.CompletePresignedUpload{FileID: "invalid-uuid"}
+			Expect(inp.Validate()).To(Equal(file.ErrPresignedUploadNotFound))
+		})
+	})
 })
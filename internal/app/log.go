@@ -18,26 +18,45 @@ func GetLogger(name string) logger.Logger {
 }
 
 const (
-	LogKeyCache            = "cache"
-	LogKeyService          = "service"
-	LogKeyInstanceService  = "instance_service"
-	LogKeyFileService      = "file_service"
-	LogKeyMessageService   = "message_service"
-	LogKeyChatService      = "chat_service"
-	LogKeyContactService   = "contact_service"
-	LogKeyGroupService     = "group_service"
-	LogKeyPictureService   = "picture_service"
-	LogKeyUploadService    = "upload_service"
-	LogKeyBlocklistService = "blocklist_service"
-	LogKeyTokenService     = "token_service"
-	LogKeyWebhookService   = "webhook_service"
-	LogKeyWebhook          = "webhook"
-	LogKeyWhatsapp         = "whatsapp"
-	LogKeyDatabase         = "database"
-	LogKeyMiddleware       = "middleware"
-	LogKeyEventBus         = "eventbus"
-	LogKeyMigrator         = "migrator"
-	LogKeyCacheService     = "cache_service"
+	LogKeyCache              = "cache"
+	LogKeyService            = "service"
+	LogKeyInstanceService    = "instance_service"
+	LogKeyFileService        = "file_service"
+	LogKeyMessageService     = "message_service"
+	LogKeyPreviewService     = "preview_service"
+	LogKeyChatService        = "chat_service"
+	LogKeyHistoryService     = "history_service"
+	LogKeyQueueService       = "queue_service"
+	LogKeyScheduleService    = "schedule_service"
+	LogKeyRateLimitService   = "rate_limit_service"
+	LogKeyCampaignService    = "campaign_service"
+	LogKeyTemplateService    = "template_service"
+	LogKeyIdempotencyService = "idempotency_service"
+	LogKeyContactService     = "contact_service"
+	LogKeyGroupService       = "group_service"
+	LogKeyPictureService     = "picture_service"
+	LogKeyUploadService      = "upload_service"
+	LogKeyDownloadService    = "download_service"
+	LogKeyMediaService       = "media_service"
+	LogKeyTranscodeService   = "transcode_service"
+	LogKeyTranscoder         = "transcoder"
+	LogKeyThumbnailService   = "thumbnail_service"
+	LogKeyTusService         = "tus_service"
+	LogKeyPresignService     = "presign_service"
+	LogKeyRetentionService   = "retention_service"
+	LogKeyQuotaService       = "quota_service"
+	LogKeyBlocklistService   = "blocklist_service"
+	LogKeyTokenService       = "token_service"
+	LogKeyWebhookService     = "webhook_service"
+	LogKeyWebhook            = "webhook"
+	LogKeyMessageConsumer    = "message_consumer"
+	LogKeyChatConsumer       = "chat_consumer"
+	LogKeyWhatsapp           = "whatsapp"
+	LogKeyDatabase           = "database"
+	LogKeyMiddleware         = "middleware"
+	LogKeyEventBus           = "eventbus"
+	LogKeyMigrator           = "migrator"
+	LogKeyCacheService       = "cache_service"
 )
 
 func GetCacheLogger() logger.Logger {
@@ -72,10 +91,42 @@ func GetMessageServiceLogger() logger.Logger {
 	return GetLogger(LogKeyMessageService)
 }
 
+func GetPreviewServiceLogger() logger.Logger {
+	return GetLogger(LogKeyPreviewService)
+}
+
 func GetChatServiceLogger() logger.Logger {
 	return GetLogger(LogKeyChatService)
 }
 
+func GetHistoryServiceLogger() logger.Logger {
+	return GetLogger(LogKeyHistoryService)
+}
+
+func GetQueueServiceLogger() logger.Logger {
+	return GetLogger(LogKeyQueueService)
+}
+
+func GetScheduleServiceLogger() logger.Logger {
+	return GetLogger(LogKeyScheduleService)
+}
+
+func GetRateLimitServiceLogger() logger.Logger {
+	return GetLogger(LogKeyRateLimitService)
+}
+
+func GetCampaignServiceLogger() logger.Logger {
+	return GetLogger(LogKeyCampaignService)
+}
+
+func GetTemplateServiceLogger() logger.Logger {
+	return GetLogger(LogKeyTemplateService)
+}
+
+func GetIdempotencyServiceLogger() logger.Logger {
+	return GetLogger(LogKeyIdempotencyService)
+}
+
 func GetContactServiceLogger() logger.Logger {
 	return GetLogger(LogKeyContactService)
 }
@@ -96,6 +147,42 @@ func GetUploadServiceLogger() logger.Logger {
 	return GetLogger(LogKeyUploadService)
 }
 
+func GetDownloadServiceLogger() logger.Logger {
+	return GetLogger(LogKeyDownloadService)
+}
+
+func GetMediaServiceLogger() logger.Logger {
+	return GetLogger(LogKeyMediaService)
+}
+
+func GetTranscodeServiceLogger() logger.Logger {
+	return GetLogger(LogKeyTranscodeService)
+}
+
+func GetTranscoderLogger() logger.Logger {
+	return GetLogger(LogKeyTranscoder)
+}
+
+func GetThumbnailServiceLogger() logger.Logger {
+	return GetLogger(LogKeyThumbnailService)
+}
+
+func GetTusServiceLogger() logger.Logger {
+	return GetLogger(LogKeyTusService)
+}
+
+func GetPresignServiceLogger() logger.Logger {
+	return GetLogger(LogKeyPresignService)
+}
+
+func GetRetentionServiceLogger() logger.Logger {
+	return GetLogger(LogKeyRetentionService)
+}
+
+func GetQuotaServiceLogger() logger.Logger {
+	return GetLogger(LogKeyQuotaService)
+}
+
 func GetBlocklistServiceLogger() logger.Logger {
 	return GetLogger(LogKeyBlocklistService)
 }
@@ -115,3 +202,11 @@ func GetWebhookServiceLogger() logger.Logger {
 func GetWebhookLogger() logger.Logger {
 	return GetLogger(LogKeyWebhook)
 }
+
+func GetMessageConsumerLogger() logger.Logger {
+	return GetLogger(LogKeyMessageConsumer)
+}
+
+func GetChatConsumerLogger() logger.Logger {
+	return GetLogger(LogKeyChatConsumer)
+}
@@ -0,0 +1,558 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	// a recipient left as sending for longer than this belongs to a worker that stopped, it goes back to pending,
+	// a worker still sending renews its claim well before
+	campaignStaleAfter = 5 * time.Minute
+
+	// a change of a campaign is retried this many times when another one got stored first
+	campaignUpdateAttempts = 3
+
+	// only used to validate the message of a campaign, each recipient gets its own "to"
+	campaignPlaceholderChat = "0@s.whatsapp.net"
+)
+
+// CampaignService sends the same message to a list of recipients in the background, one at a time following the
+// pacing of the campaign, the state lives in the database so it survives restarts and is shared by every replica
+type CampaignService struct {
+	campaignRepo  campaign.CampaignRepository
+	recipientRepo campaign.RecipientRepository
+	whatsapp      whatsapp.WhatsAppGateway
+	delivery      *delivery
+	eventbus      events.EventBus
+
+	interval    time.Duration
+	concurrency int
+	releasedAt  time.Time
+}
+
+func NewCampaignService(
+	campaignRepo campaign.CampaignRepository,
+	recipientRepo campaign.RecipientRepository,
+	instRepo instance.InstanceRepository,
+	registry instance.InstanceRegistry,
+	whatsapp whatsapp.WhatsAppGateway,
+	sessionService *SessionService,
+	messageService *MessageService,
+	eventbus events.EventBus,
+	interval time.Duration,
+	concurrency int,
+) *CampaignService {
+	return &CampaignService{
+		campaignRepo:  campaignRepo,
+		recipientRepo: recipientRepo,
+		whatsapp:      whatsapp,
+		delivery:      newDelivery(instRepo, registry, sessionService, messageService, interval),
+		eventbus:      eventbus,
+		interval:      interval,
+		concurrency:   concurrency,
+	}
+}
+
+// Create checks the recipients on whatsapp and starts the campaign, the phones not on whatsapp are kept as
+// invalid recipients so they show in the results
+func (s *CampaignService) Create(ctx context.Context, inst *instance.Instance, inp input.CreateCampaignInput) (*campaign.Campaign, *app.AppError) {
+	l := app.GetCampaignServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("campaign service", err)
+	}
+
+	payload, err := withChat(inp.Message, campaignPlaceholderChat)
+	if err != nil {
+		return nil, app.TranslateError("campaign service", err)
+	}
+
+	if err := validateQueuedPayload(inp.Type, payload); err != nil {
+		return nil, app.TranslateError("campaign service", err)
+	}
+
+	c := campaign.New(inst.ID, inp.Name, inp.Type, inp.Message, *inp.Pacing)
+
+	recipients, err := s.resolve(ctx, inst, c.ID, inp.Recipients)
+	if err != nil {
+		l.Error("Error checking campaign recipients", "instance", inst.ID, "error", err)
+		return nil, app.TranslateError("campaign service", err)
+	}
+
+	if err := s.campaignRepo.Insert(c); err != nil {
+		l.Error("Error creating campaign", "error", err)
+		return nil, app.NewAppError("campaign service", app.CodeDatabaseError, err)
+	}
+
+	if err := s.recipientRepo.InsertMany(recipients); err != nil {
+		l.Error("Error creating campaign recipients", "campaign", c.ID, "error", err)
+
+		// a campaign without its recipients would complete right away, it is better not to run it at all
+		if c.Cancel() == nil {
+			if _, err := s.campaignRepo.Update(c); err != nil {
+				l.Error("Error canceling campaign without recipients", "campaign", c.ID, "error", err)
+			}
+		}
+
+		return nil, app.NewAppError("campaign service", app.CodeDatabaseError, err)
+	}
+
+	c.Progress = &campaign.Progress{}
+	for _, r := range recipients {
+		c.Progress.Add(r.Status, 1)
+	}
+
+	l.Info("Campaign started", "instance", inst.ID, "campaign", c.ID, "recipients", c.Progress.Total, "invalid", c.Progress.Invalid)
+	s.eventbus.Publish(c.Event(campaign.EventStarted))
+
+	return c, nil
+}
+
+// resolve turns the given phones and jids into recipients, a chat given twice is sent only once
+func (s *CampaignService) resolve(ctx context.Context, inst *instance.Instance, campaignID string, entries []string) ([]*campaign.Recipient, error) {
+	recipients := make([]*campaign.Recipient, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	phones := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+
+		if strings.Contains(entry, "@") {
+			continue
+		}
+
+		phones = append(phones, entry)
+	}
+
+	checked := make(map[string]whatsapp.PhoneStatus, len(phones))
+	for start := 0; start < len(phones); start += campaign.CheckBatchSize {
+		end := min(start+campaign.CheckBatchSize, len(phones))
+
+		statuses, err := s.whatsapp.CheckPhones(ctx, inst, phones[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, status := range statuses {
+			checked[status.Original] = status
+		}
+	}
+
+	sent := make(map[string]bool, len(entries))
+	clear(seen)
+
+	for _, entry := range entries {
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+
+		to := entry
+		if strings.Contains(entry, "@") {
+			jid, ok := parseRecipientJID(entry)
+			if !ok {
+				recipients = append(recipients, campaign.NewInvalidRecipient(campaignID, entry))
+				continue
+			}
+			to = jid
+		} else {
+			status, ok := checked[entry]
+			if !ok || !status.Exists {
+				recipients = append(recipients, campaign.NewInvalidRecipient(campaignID, entry))
+				continue
+			}
+			to = status.JID
+		}
+
+		// a phone and its jid, or two spellings of a phone, are the same chat
+		if sent[to] {
+			continue
+		}
+		sent[to] = true
+
+		recipients = append(recipients, campaign.NewRecipient(campaignID, entry, to))
+	}
+
+	return recipients, nil
+}
+
+// parseRecipientJID returns the normalized jid of a user or group recipient, false when it is malformed
+func parseRecipientJID(entry string) (string, bool) {
+	jid, err := types.ParseJID(entry)
+	if err != nil || jid.User == "" {
+		return "", false
+	}
+
+	switch jid.Server {
+	case types.DefaultUserServer, types.HiddenUserServer, types.GroupServer:
+		return jid.String(), true
+	default:
+		return "", false
+	}
+}
+
+func (s *CampaignService) Get(ctx context.Context, inst *instance.Instance, id string) (*campaign.Campaign, *app.AppError) {
+	c, err := s.campaignRepo.Get(campaign.WhereInstanceID(inst.ID), campaign.WhereID(id))
+	if err != nil {
+		app.GetCampaignServiceLogger().Error("Error getting campaign", "id", id, "error", err)
+		return nil, app.NewAppError("campaign service", app.CodeDatabaseError, err)
+	}
+
+	if c == nil {
+		return nil, app.TranslateError("campaign service", campaign.ErrCampaignNotFound)
+	}
+
+	if appErr := s.withProgress(c); appErr != nil {
+		return nil, appErr
+	}
+
+	return c, nil
+}
+
+// List returns the campaigns of the instance with their progress, the newest first
+func (s *CampaignService) List(ctx context.Context, inst *instance.Instance) ([]*campaign.Campaign, *app.AppError) {
+	campaigns, err := s.campaignRepo.List(campaign.WhereInstanceID(inst.ID))
+	if err != nil {
+		app.GetCampaignServiceLogger().Error("Error listing campaigns", "error", err)
+		return nil, app.NewAppError("campaign service", app.CodeDatabaseError, err)
+	}
+
+	for _, c := range campaigns {
+		if appErr := s.withProgress(c); appErr != nil {
+			return nil, appErr
+		}
+	}
+
+	return campaigns, nil
+}
+
+// Recipients returns a page of the recipients of the campaign with the result of each send
+func (s *CampaignService) Recipients(ctx context.Context, inst *instance.Instance, id string, inp input.ListCampaignRecipientsInput) ([]*campaign.Recipient, *app.AppError) {
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("campaign service", err)
+	}
+
+	c, appErr := s.Get(ctx, inst, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	opts := []campaign.RecipientQueryOption{campaign.WhereCampaignID(c.ID), campaign.RecipientsLimit(*inp.Limit)}
+	if inp.Status != nil {
+		opts = append(opts, campaign.WhereRecipientStatus(*inp.Status))
+	}
+	if inp.After != nil {
+		opts = append(opts, campaign.WhereAfter(*inp.After))
+	}
+
+	recipients, err := s.recipientRepo.List(opts...)
+	if err != nil {
+		app.GetCampaignServiceLogger().Error("Error listing campaign recipients", "campaign", id, "error", err)
+		return nil, app.NewAppError("campaign service", app.CodeDatabaseError, err)
+	}
+
+	return recipients, nil
+}
+
+func (s *CampaignService) Pause(ctx context.Context, inst *instance.Instance, id string) (*campaign.Campaign, *app.AppError) {
+	return s.change(ctx, inst, id, (*campaign.Campaign).Pause, campaign.EventPaused)
+}
+
+func (s *CampaignService) Resume(ctx context.Context, inst *instance.Instance, id string) (*campaign.Campaign, *app.AppError) {
+	return s.change(ctx, inst, id, (*campaign.Campaign).Resume, campaign.EventResumed)
+}
+
+// Cancel stops the campaign, the recipients not sent yet are skipped, a send already on its way still finishes
+func (s *CampaignService) Cancel(ctx context.Context, inst *instance.Instance, id string) (*campaign.Campaign, *app.AppError) {
+	return s.change(ctx, inst, id, (*campaign.Campaign).Cancel, campaign.EventCanceled)
+}
+
+func (s *CampaignService) change(ctx context.Context, inst *instance.Instance, id string, apply func(*campaign.Campaign) error, event events.EventName) (*campaign.Campaign, *app.AppError) {
+	l := app.GetCampaignServiceLogger()
+
+	if _, appErr := s.Get(ctx, inst, id); appErr != nil {
+		return nil, appErr
+	}
+
+	c, appErr := s.update(id, apply)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if c.Status == campaign.StatusCanceled {
+		if _, err := s.recipientRepo.Skip(c.ID); err != nil {
+			l.Error("Error skipping campaign recipients", "campaign", c.ID, "error", err)
+			return nil, app.NewAppError("campaign service", app.CodeDatabaseError, err)
+		}
+	}
+
+	if appErr := s.withProgress(c); appErr != nil {
+		return nil, appErr
+	}
+
+	l.Info("Campaign updated", "instance", inst.ID, "campaign", c.ID, "status", c.Status)
+	s.eventbus.Publish(c.Event(event))
+
+	return c, nil
+}
+
+// update applies the change to the stored campaign, loading it again when a worker stored it first
+func (s *CampaignService) update(id string, apply func(*campaign.Campaign) error) (*campaign.Campaign, *app.AppError) {
+	l := app.GetCampaignServiceLogger()
+
+	for range campaignUpdateAttempts {
+		c, err := s.campaignRepo.Get(campaign.WhereID(id))
+		if err != nil {
+			l.Error("Error getting campaign", "id", id, "error", err)
+			return nil, app.NewAppError("campaign service", app.CodeDatabaseError, err)
+		}
+
+		if c == nil {
+			return nil, app.TranslateError("campaign service", campaign.ErrCampaignNotFound)
+		}
+
+		if err := apply(c); err != nil {
+			return nil, app.TranslateError("campaign service", err)
+		}
+
+		updated, err := s.campaignRepo.Update(c)
+		if err != nil {
+			l.Error("Error updating campaign", "id", id, "error", err)
+			return nil, app.NewAppError("campaign service", app.CodeDatabaseError, err)
+		}
+
+		if updated {
+			return c, nil
+		}
+	}
+
+	return nil, app.TranslateError("campaign service", campaign.ErrConflict)
+}
+
+func (s *CampaignService) withProgress(c *campaign.Campaign) *app.AppError {
+	progress, err := s.recipientRepo.Progress(c.ID)
+	if err != nil {
+		app.GetCampaignServiceLogger().Error("Error counting campaign recipients", "campaign", c.ID, "error", err)
+		return app.NewAppError("campaign service", app.CodeDatabaseError, err)
+	}
+
+	c.Progress = progress
+	return nil
+}
+
+// Run sends the due recipients of the running campaigns until the context is done, it is safe to run on
+// several replicas
+func (s *CampaignService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.release()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatch(ctx)
+		}
+	}
+}
+
+// release puts back the recipients left as sending by a replica that stopped, they would hold their campaign
+func (s *CampaignService) release() {
+	if time.Since(s.releasedAt) < campaignStaleAfter/2 {
+		return
+	}
+	s.releasedAt = time.Now()
+
+	l := app.GetCampaignServiceLogger()
+	if released, err := s.recipientRepo.Release(time.Now().Add(-campaignStaleAfter)); err != nil {
+		l.Error("Error releasing stale campaign recipients", "error", err)
+	} else if released > 0 {
+		l.Warn("Stale campaign recipients released", "count", released)
+	}
+}
+
+func (s *CampaignService) dispatch(ctx context.Context) {
+	l := app.GetCampaignServiceLogger()
+
+	due, err := s.campaignRepo.Due(time.Now(), s.concurrency)
+	if err != nil {
+		l.Error("Error listing due campaigns", "error", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range due {
+		r, err := s.recipientRepo.Next(c.ID)
+		if err != nil {
+			l.Error("Error getting next campaign recipient", "campaign", c.ID, "error", err)
+			continue
+		}
+
+		if r == nil {
+			s.completeIfDone(c.ID)
+			continue
+		}
+
+		// taking the turn through the version of the campaign keeps a single worker on it
+		c.Advance()
+		claimed, err := s.campaignRepo.Update(c)
+		if err != nil {
+			l.Error("Error claiming campaign turn", "campaign", c.ID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		r.MarkSending()
+		claimed, err = s.recipientRepo.UpdateFrom(r, campaign.RecipientPending)
+		if err != nil {
+			l.Error("Error claiming campaign recipient", "campaign", c.ID, "recipient", r.ID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		wg.Add(1)
+		go func(c *campaign.Campaign, r *campaign.Recipient) {
+			defer wg.Done()
+			s.deliver(ctx, c, r)
+		}(c, r)
+	}
+	wg.Wait()
+}
+
+func (s *CampaignService) deliver(ctx context.Context, c *campaign.Campaign, r *campaign.Recipient) {
+	l := app.GetCampaignServiceLogger()
+
+	payload, err := withChat(c.Payload, r.To)
+	if err != nil {
+		r.Fail(err)
+		s.finish(c, r)
+		return
+	}
+
+	stop := keepClaim(ctx, campaignStaleAfter/3, func() { s.renew(c, r) })
+	result := s.delivery.send(ctx, c.InstanceID, c.Kind, payload)
+	stop()
+
+	switch {
+	case result.delay > 0:
+		l.Debug("Campaign postponed", "campaign", c.ID, "instance", c.InstanceID, "delay", result.delay, "error", result.err)
+		s.hold(c, r, result.delay)
+
+	case result.err != nil:
+		l.Warn("Error sending campaign message", "campaign", c.ID, "recipient", r.ID, "to", r.To, "error", result.err)
+		r.Fail(result.err)
+		s.finish(c, r)
+
+	default:
+		r.MarkSent(result.msg)
+		s.finish(c, r)
+	}
+}
+
+func (s *CampaignService) renew(c *campaign.Campaign, r *campaign.Recipient) {
+	l := app.GetCampaignServiceLogger()
+
+	renewed, err := s.recipientRepo.Renew(r.ID, time.Now())
+	if err != nil {
+		l.Error("Error renewing claim of campaign recipient", "campaign", c.ID, "recipient", r.ID, "error", err)
+	} else if !renewed {
+		l.Warn("Campaign recipient no longer claimed while being sent", "campaign", c.ID, "recipient", r.ID)
+	}
+}
+
+// hold gives the turn of the recipient back and postpones the campaign
+func (s *CampaignService) hold(c *campaign.Campaign, r *campaign.Recipient, delay time.Duration) {
+	l := app.GetCampaignServiceLogger()
+
+	r.Release()
+	if err := s.recipientRepo.Update(r); err != nil {
+		l.Error("Error releasing campaign recipient", "campaign", c.ID, "recipient", r.ID, "error", err)
+	}
+
+	_, appErr := s.update(c.ID, func(c *campaign.Campaign) error {
+		if c.Status != campaign.StatusRunning {
+			return campaign.ErrNotRunning
+		}
+
+		c.Postpone(delay)
+		return nil
+	})
+	if appErr != nil && appErr.Code != app.CodeCampaignNotRunning {
+		l.Error("Error postponing campaign", "campaign", c.ID, "error", appErr)
+	}
+}
+
+// finish stores the result of the recipient and tells the listeners how the campaign is going
+func (s *CampaignService) finish(c *campaign.Campaign, r *campaign.Recipient) {
+	l := app.GetCampaignServiceLogger()
+
+	if err := s.recipientRepo.Update(r); err != nil {
+		l.Error("Error updating campaign recipient", "campaign", c.ID, "recipient", r.ID, "error", err)
+		return
+	}
+
+	if appErr := s.withProgress(c); appErr != nil {
+		return
+	}
+
+	l.Debug("Campaign recipient handled", "campaign", c.ID, "recipient", r.ID, "to", r.To, "status", r.Status)
+	s.eventbus.Publish(c.EventProgress(r))
+
+	if c.Progress.Pending == 0 && c.Progress.Sending == 0 {
+		s.completeIfDone(c.ID)
+	}
+}
+
+// completeIfDone completes the campaign once every recipient was handled
+func (s *CampaignService) completeIfDone(id string) {
+	l := app.GetCampaignServiceLogger()
+
+	progress, err := s.recipientRepo.Progress(id)
+	if err != nil {
+		l.Error("Error counting campaign recipients", "campaign", id, "error", err)
+		return
+	}
+
+	if progress.Pending > 0 || progress.Sending > 0 {
+		return
+	}
+
+	c, appErr := s.update(id, func(c *campaign.Campaign) error {
+		if c.IsFinished() {
+			return campaign.ErrFinished
+		}
+
+		c.Complete()
+		return nil
+	})
+	if appErr != nil {
+		if appErr.Code != app.CodeCampaignFinished {
+			l.Error("Error completing campaign", "campaign", id, "error", appErr)
+		}
+		return
+	}
+
+	c.Progress = progress
+	l.Info("Campaign completed", "instance", c.InstanceID, "campaign", c.ID, "sent", progress.Sent, "failed", progress.Failed)
+	s.eventbus.Publish(c.Event(campaign.EventCompleted))
+}
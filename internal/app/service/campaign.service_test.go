@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/limiter"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/registry"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Campaign Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	campaignRepo := repository.NewCampaignRepository(db)
+	recipientRepo := repository.NewCampaignRecipientRepository(db)
+	instRegistry := registry.NewInMemoryInstanceRegistry()
+	gateway := fake.NewFakeWhatsAppGateway()
+	bus := fake.NewFakeEventBus()
+
+	sessionService := service.NewSessionService(instRepo, gateway, bus)
+	rateLimitService := service.NewRateLimitService(gateway, repository.NewRateLimitRepository(db), limiter.NewInMemoryLimiter(), ratelimit.Policy{})
+	messageService := service.NewMessageService(gateway, repository.NewMessageRepository(db), repository.NewReceiptRepository(db), repository.NewChatRepository(db), nil, nil, nil, rateLimitService, nil, nil, nil, 0)
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	var (
+		inst   *instance.Instance
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	start := func(instRepo instance.InstanceRepository) *service.CampaignService {
+		campaigns := service.NewCampaignService(campaignRepo, recipientRepo, instRepo, instRegistry, gateway, sessionService, messageService, bus, 10*time.Millisecond, 1)
+		go campaigns.Run(ctx)
+		return campaigns
+	}
+
+	create := func(campaigns *service.CampaignService, recipients ...string) *campaign.Campaign {
+		c, appErr := campaigns.Create(ctx, inst, input.CreateCampaignInput{
+			Name:       "launch",
+			Type:       message.MessageKindText,
+			Message:    []byte(`{"text":"hello"}`),
+			Recipients: recipients,
+			Pacing:     &campaign.Pacing{},
+		})
+		Expect(appErr).To(BeNil())
+		return c
+	}
+
+	recipients := func(c *campaign.Campaign) map[string]campaign.RecipientStatus {
+		list, err := recipientRepo.List(campaign.WhereCampaignID(c.ID))
+		Expect(err).ToNot(HaveOccurred())
+
+		statuses := make(map[string]campaign.RecipientStatus, len(list))
+		for _, r := range list {
+			statuses[r.Original] = r.Status
+		}
+		return statuses
+	}
+
+	status := func(c *campaign.Campaign) func() campaign.Status {
+		return func() campaign.Status {
+			stored, err := campaignRepo.Get(campaign.WhereID(c.ID))
+			Expect(err).ToNot(HaveOccurred())
+			return stored.Status
+		}
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		instRegistry.Clear()
+		gateway.Clear()
+		bus.Clear()
+		bus.ClearPublished()
+
+		inst = fake.InstanceFactory().Connected().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should send to the valid recipients once and keep the malformed jids as invalid", func() {
+		c := create(start(instRepo), "5511999999999", "5511999999999@s.whatsapp.net", "123456-789@g.us", "a@b@c", "user@example.com")
+
+		Eventually(status(c)).Should(Equal(campaign.StatusCompleted))
+		Expect(gateway.Sent()).To(HaveLen(2))
+
+		statuses := recipients(c)
+		Expect(statuses).To(HaveLen(4))
+		Expect(statuses["5511999999999"]).To(Equal(campaign.RecipientSent))
+		Expect(statuses["123456-789@g.us"]).To(Equal(campaign.RecipientSent))
+		Expect(statuses["a@b@c"]).To(Equal(campaign.RecipientInvalid))
+		Expect(statuses["user@example.com"]).To(Equal(campaign.RecipientInvalid))
+	})
+
+	It("should fail the recipients of a deleted instance instead of postponing the campaign", func() {
+		c := create(start(deletedInstances{instRepo}), "5511999999999", "5511888888888")
+
+		Eventually(status(c)).Should(Equal(campaign.StatusCompleted))
+		Expect(gateway.Sent()).To(BeEmpty())
+		Expect(recipients(c)).To(HaveEach(campaign.RecipientFailed))
+	})
+
+	It("should postpone the campaign while the instance is disconnected", func() {
+		gateway.FailSends(instance.ErrInstanceNotConnected)
+		c := create(start(instRepo), "5511999999999")
+
+		Eventually(func() time.Time {
+			stored, err := campaignRepo.Get(campaign.WhereID(c.ID))
+			Expect(err).ToNot(HaveOccurred())
+			return stored.NextSendAt
+		}).Should(BeTemporally(">", time.Now().Add(time.Second)))
+
+		Expect(recipients(c)["5511999999999"]).To(Equal(campaign.RecipientPending))
+	})
+})
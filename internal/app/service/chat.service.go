@@ -8,16 +8,24 @@ import (
 	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
 	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
 )
 
 type ChatService struct {
 	whatsapp whatsapp.WhatsAppGateway
+	msgRepo  message.MessageRepository
+	chatRepo chat.ChatRepository
+	eventbus events.EventBus
 }
 
-func NewChatService(whatsapp whatsapp.WhatsAppGateway) *ChatService {
+func NewChatService(whatsapp whatsapp.WhatsAppGateway, msgRepo message.MessageRepository, chatRepo chat.ChatRepository, eventbus events.EventBus) *ChatService {
 	return &ChatService{
 		whatsapp,
+		msgRepo,
+		chatRepo,
+		eventbus,
 	}
 }
 
@@ -80,3 +88,95 @@ func (s *ChatService) SendPresence(ctx context.Context, inst *instance.Instance,
 	l.Debug("Presence handling completed", "instance", inst.ID)
 	return nil
 }
+
+// ListMessages returns the stored history of the chat, newest first, paginated by cursor
+func (s *ChatService) ListMessages(ctx context.Context, inst *instance.Instance, inp input.ListChatMessagesInput) (*message.HistoryPage, *app.AppError) {
+	l := app.GetChatServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("chat service", err)
+	}
+
+	limit := message.DefaultHistoryLimit
+	if inp.Limit != nil {
+		limit = *inp.Limit
+	}
+
+	l.Debug("Listing chat messages", "instance", inst.ID, "chat", inp.Chat, "limit", limit)
+
+	opts := []message.MessageQueryOption{
+		message.WhereInstanceID(inst.ID),
+		message.WhereChat(inp.Chat),
+		message.Limit(limit + 1), // one more to know if there is a next page
+	}
+
+	if inp.Cursor != nil {
+		cursor, _ := message.DecodeCursor(*inp.Cursor)
+		opts = append(opts, message.WhereBefore(*cursor))
+	}
+
+	messages, err := s.msgRepo.List(opts...)
+	if err != nil {
+		l.Error("Error listing chat messages", "error", err)
+		return nil, app.NewAppError("chat service", app.CodeDatabaseError, err)
+	}
+
+	page := &message.HistoryPage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		next := message.NewCursor(page.Messages[limit-1]).Encode()
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+// UpdateDisappearing sets the disappearing messages timer of a one-to-one chat, the stored chat keeps the timer so
+// the next messages sent without an expiration use it
+func (s *ChatService) UpdateDisappearing(ctx context.Context, inst *instance.Instance, inp input.UpdateChatDisappearingInput) (*chat.Chat, *app.AppError) {
+	l := app.GetChatServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("chat service", err)
+	}
+
+	l.Debug("Updating chat disappearing timer", "instance", inst.ID, "chat", inp.Chat, "duration", inp.Duration)
+
+	if err := s.whatsapp.SetChatDisappearingTimer(ctx, inst, inp.Chat, inp.Duration); err != nil {
+		l.Error("Error setting disappearing timer", "error", err)
+		return nil, app.TranslateError("chat service", err)
+	}
+
+	c, err := s.chatRepo.Get(chat.WhereInstanceID(inst.ID), chat.WhereJID(inp.Chat))
+	if err != nil {
+		l.Error("Error getting chat", "chat", inp.Chat, "error", err)
+		return nil, app.NewAppError("chat service", app.CodeDatabaseError, err)
+	}
+
+	if c == nil {
+		c = chat.New(inp.Chat, inst.ID)
+		c.SetExpiration(inp.Duration.ToExpiration())
+		err = s.chatRepo.Save(c)
+	} else if c.SetExpiration(inp.Duration.ToExpiration()) {
+		err = s.chatRepo.Update(c)
+	}
+
+	if err != nil {
+		l.Error("Error storing chat disappearing timer", "chat", inp.Chat, "error", err)
+		return nil, app.NewAppError("chat service", app.CodeDatabaseError, err)
+	}
+
+	s.eventbus.Publish(events.New(
+		chat.ChatChangedDisappearing,
+		chat.PayloadChatChangedDisappearing{
+			Chat:       inp.Chat,
+			Sender:     inst.JID,
+			Expiration: inp.Duration.ToExpiration(),
+			Timestamp:  time.Now().UTC(),
+		},
+		&inst.ID,
+	))
+
+	l.Info("Chat disappearing timer updated", "instance", inst.ID, "chat", inp.Chat, "duration", inp.Duration)
+	return c, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+)
+
+type DownloadService struct {
+	whatsapp       whatsapp.WhatsAppGateway
+	messageService *MessageService
+}
+
+func NewDownloadService(whatsapp whatsapp.WhatsAppGateway, messageService *MessageService) *DownloadService {
+	return &DownloadService{
+		whatsapp:       whatsapp,
+		messageService: messageService,
+	}
+}
+
+// Download fetches and decrypts a media from WhatsApp, it returns the file with its mime, name and size filled and
+// the decrypted content, which is checked against the hash of the file. The content is kept in a temporary file
+// removed once it is closed
+func (s *DownloadService) Download(ctx context.Context, inst *instance.Instance, inp input.DownloadMediaInput) (*file.File, io.ReadCloser, *app.AppError) {
+	l := app.GetDownloadServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, nil, app.TranslateError("download service", err)
+	}
+
+	f := inp.ToFile()
+
+	if inp.HasMessage() {
+		msg, appErr := s.messageService.GetMessage(ctx, inst, *inp.Message)
+		if appErr != nil {
+			return nil, nil, appErr
+		}
+
+		media, mediaType := msg.MediaFile()
+		if media == nil {
+			return nil, nil, app.TranslateError("download service", file.ErrMediaNotDownloadable)
+		}
+
+		if mediaType != inp.Type {
+			return nil, nil, app.TranslateError("download service", file.ErrMediaTypeMismatch)
+		}
+
+		copied := *media
+		f = &copied
+	}
+
+	if !f.IsDownloadable() {
+		return nil, nil, app.TranslateError("download service", file.ErrMediaNotDownloadable)
+	}
+
+	l.Debug("Downloading media", "instance", inst.ID, "type", inp.Type, "size", f.Size)
+
+	content, err := downloadMedia(ctx, s.whatsapp, inst, f, mediaTypeToKind(inp.Type), 0)
+	if err != nil {
+		l.Error("Error downloading media", "instance", inst.ID, "error", err)
+		return nil, nil, app.TranslateError("download service", err)
+	}
+
+	if f.Mime == "" {
+		f.Mime = content.mime()
+	}
+	f.Size = uint64(content.size)
+
+	l.Info("Media downloaded", "instance", inst.ID, "type", inp.Type, "size", f.Size)
+	return f, content, nil
+}
+
+// downloadMedia downloads and decrypts the media into a temporary file checked against the hash of the file, the
+// returned content is read from its start. A download growing past maxSize is stopped, zero downloads any size
+func downloadMedia(ctx context.Context, gateway whatsapp.WhatsAppGateway, inst *instance.Instance, f *file.File, kind whatsapp.MediaKind, maxSize uint64) (*spooledFile, error) {
+	content, err := newSpooledFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var dst whatsapp.DownloadTarget = content.File
+	if maxSize > 0 {
+		dst = &cappedFile{File: content.File, max: int64(maxSize) + mediaEncryptionOverhead}
+	}
+
+	if err := gateway.DownloadFile(ctx, inst, f, kind, dst); err != nil {
+		content.Close()
+		return nil, err
+	}
+
+	if err := content.hash(); err != nil {
+		content.Close()
+		return nil, err
+	}
+
+	if !f.MatchesSha256Sum(content.sha256) {
+		content.Close()
+		return nil, file.ErrMediaHashMismatch
+	}
+
+	return content, nil
+}
+
+// mediaEncryptionOverhead is what the encrypted media adds to the content, the padding of a block and the mac
+const mediaEncryptionOverhead = 16 + 10
+
+// cappedFile refuses the writes past max, so a media larger than announced stops downloading once it is too large
+type cappedFile struct {
+	*os.File
+	max int64
+}
+
+func (f *cappedFile) Write(p []byte) (int, error) {
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	if offset+int64(len(p)) > f.max {
+		return 0, file.ErrFileTooLarge
+	}
+
+	return f.File.Write(p)
+}
+
+func (f *cappedFile) WriteAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > f.max {
+		return 0, file.ErrFileTooLarge
+	}
+
+	return f.File.WriteAt(p, off)
+}
+
+// ReadFrom hides the one of the file, io.Copy would write through it around the cap
+func (f *cappedFile) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(struct{ io.Writer }{f}, r)
+}
+
+// mediaTypeToKind picks the keys the media was encrypted with, stickers are encrypted as images
+func mediaTypeToKind(t file.MediaType) whatsapp.MediaKind {
+	switch t {
+	case file.MediaTypeImage, file.MediaTypeSticker:
+		return whatsapp.MediaImage
+	case file.MediaTypeVideo:
+		return whatsapp.MediaVideo
+	case file.MediaTypeAudio:
+		return whatsapp.MediaAudio
+	default:
+		return whatsapp.MediaDocument
+	}
+}
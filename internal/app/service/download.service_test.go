@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Download Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	gateway := fake.NewFakeWhatsAppGateway()
+	downloads := service.NewDownloadService(gateway, nil)
+	inst := fake.InstanceFactory().Connected().Create()
+
+	content := []byte("%PDF-1.4 a small document")
+	sum := sha256.Sum256(content)
+
+	request := func() input.DownloadMediaInput {
+		return input.DownloadMediaInput{
+			Type:       file.MediaTypeDocument,
+			DirectPath: "/v/t62.7119-24/12345",
+			MediaKey:   "0a1b2c3d",
+			Sha256:     hex.EncodeToString(sum[:]),
+			Sha256Enc:  "8c9d0e1f",
+		}
+	}
+
+	BeforeEach(func() {
+		gateway.Clear()
+		gateway.ServeMedia(content)
+	})
+
+	It("should stream the decrypted media and remove it once closed", func() {
+		f, stream, appErr := downloads.Download(context.Background(), inst, request())
+		Expect(appErr).To(BeNil())
+		Expect(f.Size).To(Equal(uint64(len(content))))
+		Expect(f.Mime).To(Equal("application/pdf"))
+
+		data, err := io.ReadAll(stream)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(content))
+
+		path := stream.(interface{ Name() string }).Name()
+		Expect(stream.Close()).To(Succeed())
+		Expect(path).ToNot(BeAnExistingFile())
+	})
+
+	It("should refuse a media that does not match its hash", func() {
+		gateway.ServeMedia([]byte("something else"))
+
+		_, _, appErr := downloads.Download(context.Background(), inst, request())
+		Expect(appErr).ToNot(BeNil())
+		Expect(appErr.Code).To(Equal(app.CodeMediaHashMismatch))
+	})
+
+	It("should not follow a url outside the media hosts of whatsapp", func() {
+		inp := request()
+		inp.DirectPath = ""
+		inp.URL = "https://internal.example.com/secret"
+
+		_, _, appErr := downloads.Download(context.Background(), inst, inp)
+		Expect(appErr).ToNot(BeNil())
+		Expect(appErr.Code).To(Equal(app.CodeInvalidMediaURL))
+	})
+})
@@ -6,9 +6,11 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/mauriciorobertodev/whappy-go/internal/app"
@@ -99,6 +101,93 @@ func (p *FileService) SaveFromBytes(ctx context.Context, data []byte, mimeType *
 	return p.SaveStream(ctx, bytes.NewReader(data), mimeType)
 }
 
+// Sign refreshes the URL of a file and its thumbnail, storage URLs expire so the stored ones cannot be handed out
+func (p *FileService) Sign(ctx context.Context, f *file.File) {
+	l := app.GetFileServiceLogger()
+
+	if p.storage == nil || f == nil {
+		return
+	}
+
+	if f.Path != "" {
+		if url, err := p.storage.URL(ctx, f.Path); err == nil {
+			f.URL = url
+		} else {
+			l.Error("Error getting file URL from storage", "file", f.ID, "error", err.Error())
+		}
+	}
+
+	if f.Thumbnail != nil && f.Thumbnail.Path != "" {
+		if url, err := p.storage.URL(ctx, f.Thumbnail.Path); err == nil {
+			f.Thumbnail.URL = url
+		}
+	}
+}
+
+// FindByContent returns the file of the instance with the given content, nil when there is none
+func (p *FileService) FindByContent(instanceID, sha256 string) (*file.File, error) {
+	f, err := p.fileRepo.Get(file.WhereInstanceID(instanceID), file.WhereSha256(sha256), file.WhereIsThumbnail(false), file.WithThumbnail())
+	if errors.Is(err, file.ErrFileNotFound) {
+		return nil, nil
+	}
+
+	return f, err
+}
+
+// Reuse counts one more reference of the file of the instance with the given content and returns it, nil when the
+// instance has no such file, so the content does not have to be stored again
+func (p *FileService) Reuse(instanceID, sha256 string) (*file.File, error) {
+	existing, err := p.FindByContent(instanceID, sha256)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+
+	// the file may have lost its last reference and been deleted since it was found
+	if err := p.fileRepo.Reference(existing.ID); err != nil {
+		if errors.Is(err, file.ErrFileNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	existing.References++
+
+	return existing, nil
+}
+
+// Write stores a content already hashed at the path of the file, which is unique to the file
+func (p *FileService) Write(ctx context.Context, f *file.File, r io.Reader) error {
+	if err := p.storage.Save(ctx, f.Path, r); err != nil {
+		return err
+	}
+
+	if url, err := p.storage.URL(ctx, f.Path); err == nil {
+		f.URL = url
+	} else {
+		app.GetFileServiceLogger().Error("Error getting file URL from storage", "error", err.Error())
+	}
+
+	return nil
+}
+
+// Keep records a file written to the storage, when the same content of the instance was stored meanwhile the existing
+// file gains a reference and is returned in its place, the object of the new file is removed
+func (p *FileService) Keep(ctx context.Context, f *file.File) (*file.File, bool, error) {
+	stored, err := p.fileRepo.InsertOrReference(f)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if stored.ID == f.ID {
+		return f, false, nil
+	}
+
+	if err := p.storage.Delete(ctx, f.Path); err != nil {
+		app.GetFileServiceLogger().Error("Error removing duplicated file from storage", "path", f.Path, "error", err)
+	}
+
+	return stored, true, nil
+}
+
 func (s *FileService) LoadFrom(ctx context.Context, source string) (*file.File, io.ReadCloser, error) {
 	l := app.GetFileServiceLogger()
 
@@ -308,6 +397,76 @@ func (p *FileService) GetFromUploads(ctx context.Context, fileID string) (*file.
 
 // --------------------- HELPERS ---------------------
 
+// spooledFile is a content kept in a temporary file, hashed and measured before it is stored, closing it removes
+// the file
+type spooledFile struct {
+	*os.File
+	size   int64
+	sha256 string
+}
+
+func newSpooledFile() (*spooledFile, error) {
+	tmp, err := os.CreateTemp("", "whappy-spool-*")
+	if err != nil {
+		return nil, err
+	}
+	return &spooledFile{File: tmp}, nil
+}
+
+// spool copies the stream into a temporary file, the returned content is hashed and read from its start
+func spool(r io.Reader) (*spooledFile, error) {
+	content, err := newSpooledFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(content.File, r); err != nil {
+		content.Close()
+		return nil, err
+	}
+
+	if err := content.hash(); err != nil {
+		content.Close()
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// hash reads the whole content to hash and measure it, then rewinds it
+func (c *spooledFile) hash() error {
+	if _, err := c.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, c.File)
+	if err != nil {
+		return err
+	}
+
+	c.size = size
+	c.sha256 = hex.EncodeToString(hasher.Sum(nil))
+
+	_, err = c.Seek(0, io.SeekStart)
+	return err
+}
+
+// mime detects the type of the content from its first bytes
+func (c *spooledFile) mime() string {
+	header := make([]byte, 512)
+	n, _ := c.ReadAt(header, 0)
+	return http.DetectContentType(header[:n])
+}
+
+func (c *spooledFile) Close() error {
+	err := c.File.Close()
+	if removeErr := os.Remove(c.Name()); err == nil && !errors.Is(removeErr, os.ErrNotExist) {
+		err = removeErr
+	}
+	return err
+}
+
 type countWriter struct {
 	Count int64
 }
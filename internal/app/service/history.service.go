@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+// historyBuffer is how many chunks wait for the worker before the gateway handler blocks
+const historyBuffer = 64
+
+type historyChunk struct {
+	inst    *instance.Instance
+	history whatsapp.HistorySync
+}
+
+type historyTotals struct {
+	inst     *instance.Instance
+	syncType string
+	chats    int
+	messages int
+	lastAt   time.Time
+}
+
+type HistoryService struct {
+	whatsapp whatsapp.WhatsAppGateway
+	msgRepo  message.MessageRepository
+	chatRepo chat.ChatRepository
+	eventbus events.EventBus
+	idle     time.Duration
+
+	chunks chan historyChunk
+	totals map[string]*historyTotals // stored so far per instance and sync type, only touched by the worker
+}
+
+// NewHistoryService builds the service, a sync that does not report its progress is complete once no chunk
+// of it arrived for the idle duration
+func NewHistoryService(whatsapp whatsapp.WhatsAppGateway, msgRepo message.MessageRepository, chatRepo chat.ChatRepository, eventbus events.EventBus, idle time.Duration) *HistoryService {
+	return &HistoryService{
+		whatsapp: whatsapp,
+		msgRepo:  msgRepo,
+		chatRepo: chatRepo,
+		eventbus: eventbus,
+		idle:     idle,
+		chunks:   make(chan historyChunk, historyBuffer),
+		totals:   map[string]*historyTotals{},
+	}
+}
+
+// Ingest hands a history sync chunk to the worker, it is registered as the gateway history sync handler
+// and only waits when the worker is a whole buffer behind
+func (s *HistoryService) Ingest(inst *instance.Instance, history whatsapp.HistorySync) {
+	s.chunks <- historyChunk{inst: inst, history: history}
+}
+
+// Run stores the ingested chunks until the context is canceled
+func (s *HistoryService) Run(ctx context.Context) {
+	// a ticker panics on zero, which an idle time of a nanosecond halves to
+	ticker := time.NewTicker(max(s.idle/2, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case chunk := <-s.chunks:
+			s.store(chunk.inst, chunk.history)
+		case now := <-ticker.C:
+			s.completeIdle(now)
+		}
+	}
+}
+
+// store saves the chats and messages of a chunk, each in one transaction, and publishes the sync progress
+func (s *HistoryService) store(inst *instance.Instance, history whatsapp.HistorySync) {
+	l := app.GetHistoryServiceLogger()
+	l.Debug("Ingesting history", "instance", inst.ID, "type", history.Type, "chunk", history.Chunk, "chats", len(history.Chats), "messages", len(history.Messages))
+
+	chats := len(history.Chats)
+	if err := s.chatRepo.SaveMany(history.Chats); err != nil {
+		l.Error("Error storing history chats", "instance", inst.ID, "type", history.Type, "chunk", history.Chunk, "error", err)
+		chats = 0
+	}
+
+	messages := len(history.Messages)
+	if err := s.msgRepo.InsertMany(history.Messages); err != nil {
+		l.Error("Error storing history messages", "instance", inst.ID, "type", history.Type, "chunk", history.Chunk, "error", err)
+		messages = 0
+	}
+
+	syncType := string(history.Type)
+	s.eventbus.Publish(inst.EventHistoryProgress(syncType, history.Chunk, history.Progress, chats, messages))
+
+	key := inst.ID + ":" + syncType
+	total, ok := s.totals[key]
+	if !ok {
+		total = &historyTotals{inst: inst, syncType: syncType}
+		s.totals[key] = total
+	}
+
+	total.chats += chats
+	total.messages += messages
+	total.lastAt = time.Now()
+
+	if history.IsComplete() {
+		s.complete(key, total)
+	}
+}
+
+// completeIdle completes the syncs without a new chunk for the idle duration, the initial bootstrap
+// does not report its progress
+func (s *HistoryService) completeIdle(now time.Time) {
+	for key, total := range s.totals {
+		if now.Sub(total.lastAt) >= s.idle {
+			s.complete(key, total)
+		}
+	}
+}
+
+func (s *HistoryService) complete(key string, total *historyTotals) {
+	delete(s.totals, key)
+
+	app.GetHistoryServiceLogger().Info("History sync complete", "instance", total.inst.ID, "type", total.syncType, "chats", total.chats, "messages", total.messages)
+	s.eventbus.Publish(total.inst.EventHistoryComplete(total.syncType, total.chats, total.messages))
+}
+
+// RequestHistory asks the phone for messages older than the oldest stored message of the chat, they are
+// stored when the on demand history sync arrives
+func (s *HistoryService) RequestHistory(ctx context.Context, inst *instance.Instance, inp input.RequestChatHistoryInput) *app.AppError {
+	l := app.GetHistoryServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return app.TranslateError("history service", err)
+	}
+
+	count := message.DefaultHistoryRequestCount
+	if inp.Count != nil {
+		count = *inp.Count
+	}
+
+	l.Debug("Requesting chat history", "instance", inst.ID, "chat", inp.Chat, "count", count)
+
+	anchor, err := s.msgRepo.Get(
+		message.WhereInstanceID(inst.ID),
+		message.WhereChat(inp.Chat),
+		message.OrderBy("timestamp", message.SortByAsc),
+	)
+	if err != nil {
+		l.Error("Error finding history anchor", "error", err)
+		return app.NewAppError("history service", app.CodeDatabaseError, err)
+	}
+
+	if anchor == nil || anchor.ExternalID == nil || *anchor.ExternalID == "" {
+		return app.TranslateError("history service", message.ErrNoHistoryAnchor)
+	}
+
+	if err := s.whatsapp.RequestHistory(ctx, inst, anchor, count); err != nil {
+		l.Error("Error requesting chat history", "error", err)
+		return app.TranslateError("history service", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("History Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	chatRepo := repository.NewChatRepository(db)
+	msgRepo := repository.NewMessageRepository(db)
+	bus := fake.NewFakeEventBus()
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	var (
+		inst    *instance.Instance
+		history *service.HistoryService
+		cancel  context.CancelFunc
+	)
+
+	chunk := func(kind whatsapp.HistorySyncType, n uint32, progress *uint32, jid string, ids ...string) whatsapp.HistorySync {
+		sync := whatsapp.HistorySync{Type: kind, Chunk: n, Progress: progress, Chats: []*chat.Chat{chat.New(jid, inst.ID)}}
+		for _, id := range ids {
+			m := message.NewMessage(nil, jid, jid, message.NewTextContent("hi", nil), &inst.ID, nil, false)
+			m.ExternalID = utils.StringPtr(id)
+			sync.Messages = append(sync.Messages, m)
+		}
+		return sync
+	}
+
+	completed := func() []instance.PayloadInstanceHistoryComplete {
+		var payloads []instance.PayloadInstanceHistoryComplete
+		for _, evt := range bus.Published() {
+			if evt.Name == instance.EventHistoryComplete {
+				payloads = append(payloads, evt.Payload.(instance.PayloadInstanceHistoryComplete))
+			}
+		}
+		return payloads
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		bus.Clear()
+		bus.ClearPublished()
+
+		inst = fake.InstanceFactory().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		history = service.NewHistoryService(nil, msgRepo, chatRepo, bus, 100*time.Millisecond)
+		go history.Run(ctx)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should store the chunks and complete a sync that does not report its progress", func() {
+		history.Ingest(inst, chunk(whatsapp.HistorySyncInitial, 1, nil, "1@s.whatsapp.net", "A1", "A2"))
+		history.Ingest(inst, chunk(whatsapp.HistorySyncInitial, 2, nil, "1@s.whatsapp.net", "A2", "A3"))
+
+		Eventually(completed).Should(HaveLen(1))
+		Expect(completed()[0].Chats).To(Equal(2))
+		Expect(completed()[0].Messages).To(Equal(4))
+
+		chats, err := chatRepo.List(chat.WhereInstanceID(inst.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(chats).To(HaveLen(1))
+
+		count, err := msgRepo.Count(message.WhereInstanceID(inst.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(uint64(3)))
+	})
+
+	It("should complete a sync as soon as its progress reaches 100", func() {
+		history.Ingest(inst, chunk(whatsapp.HistorySyncFull, 1, utils.Uint32Ptr(50), "1@s.whatsapp.net", "A1"))
+		history.Ingest(inst, chunk(whatsapp.HistorySyncFull, 2, utils.Uint32Ptr(100), "2@s.whatsapp.net", "B1"))
+
+		Eventually(completed, 50*time.Millisecond, 5*time.Millisecond).Should(HaveLen(1))
+		Expect(completed()[0].Type).To(Equal(string(whatsapp.HistorySyncFull)))
+		Expect(completed()[0].Messages).To(Equal(2))
+
+		Consistently(completed, 300*time.Millisecond).Should(HaveLen(1))
+	})
+
+	It("should publish the progress of every chunk", func() {
+		history.Ingest(inst, chunk(whatsapp.HistorySyncRecent, 1, nil, "1@s.whatsapp.net", "A1"))
+
+		Eventually(func() bool { return bus.HasPublished(instance.EventHistoryProgress) }).Should(BeTrue())
+		Expect(bus.HasPublished(instance.EventHistoryComplete)).To(BeFalse())
+	})
+})
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/idempotency"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+)
+
+// idempotencyPurgeInterval is how often the expired keys are removed, an expired key is already ignored when
+// it is reused, the purge only keeps the table small
+const idempotencyPurgeInterval = time.Hour
+
+type IdempotencyService struct {
+	idempotencyRepo idempotency.IdempotencyRepository
+	ttl             time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo idempotency.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+	}
+}
+
+// Begin takes the key for the request, the returned record is completed when the request was already answered
+// and its response must be replayed, otherwise the request goes on and is finished with Complete or Release
+func (s *IdempotencyService) Begin(ctx context.Context, inst *instance.Instance, key string, fingerprint string) (*idempotency.Record, *app.AppError) {
+	l := app.GetIdempotencyServiceLogger()
+
+	if err := idempotency.ValidateKey(key); err != nil {
+		return nil, app.TranslateError("idempotency service", err)
+	}
+
+	// the second attempt happens when the stored key was stale or removed in between
+	for attempt := 0; attempt < 2; attempt++ {
+		rec := idempotency.New(inst.ID, key, fingerprint, s.ttl)
+
+		stored, err := s.idempotencyRepo.Insert(rec)
+		if err != nil {
+			l.Error("Error storing idempotency key", "instance", inst.ID, "error", err)
+			return nil, app.NewAppError("idempotency service", app.CodeDatabaseError, err)
+		}
+
+		if stored {
+			return rec, nil
+		}
+
+		existing, err := s.idempotencyRepo.Get(idempotency.WhereInstanceID(inst.ID), idempotency.WhereKey(key))
+		if err != nil {
+			l.Error("Error getting idempotency key", "instance", inst.ID, "error", err)
+			return nil, app.NewAppError("idempotency service", app.CodeDatabaseError, err)
+		}
+
+		if existing == nil {
+			continue
+		}
+
+		if existing.IsStale(time.Now()) {
+			l.Debug("Taking over stale idempotency key", "instance", inst.ID, "status", existing.Status)
+			if err := s.idempotencyRepo.Delete(idempotency.WhereID(existing.ID)); err != nil {
+				l.Error("Error removing stale idempotency key", "instance", inst.ID, "error", err)
+				return nil, app.NewAppError("idempotency service", app.CodeDatabaseError, err)
+			}
+			continue
+		}
+
+		if !existing.Matches(fingerprint) {
+			return nil, app.TranslateError("idempotency service", idempotency.ErrKeyReused)
+		}
+
+		if !existing.IsCompleted() {
+			return nil, app.TranslateError("idempotency service", idempotency.ErrKeyInUse)
+		}
+
+		l.Debug("Replaying idempotent response", "instance", inst.ID, "status", existing.ResponseStatus)
+		return existing, nil
+	}
+
+	return nil, app.TranslateError("idempotency service", idempotency.ErrKeyInUse)
+}
+
+// Complete stores the response of the request, retries with the same key receive it from now on
+func (s *IdempotencyService) Complete(ctx context.Context, rec *idempotency.Record, status int, body []byte) {
+	rec.Complete(status, body)
+
+	if err := s.idempotencyRepo.Update(rec); err != nil {
+		app.GetIdempotencyServiceLogger().Error("Error storing idempotent response", "instance", rec.InstanceID, "error", err)
+	}
+}
+
+// Release frees the key of a request that failed, so it can be retried with the same key
+func (s *IdempotencyService) Release(ctx context.Context, rec *idempotency.Record) {
+	if err := s.idempotencyRepo.Delete(idempotency.WhereID(rec.ID)); err != nil {
+		app.GetIdempotencyServiceLogger().Error("Error releasing idempotency key", "instance", rec.InstanceID, "error", err)
+	}
+}
+
+// Run removes the expired keys until the context is done
+func (s *IdempotencyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.purge()
+		}
+	}
+}
+
+func (s *IdempotencyService) purge() {
+	l := app.GetIdempotencyServiceLogger()
+
+	removed, err := s.idempotencyRepo.DeleteExpired(time.Now())
+	if err != nil {
+		l.Error("Error removing expired idempotency keys", "error", err)
+		return
+	}
+
+	if removed > 0 {
+		l.Debug("Expired idempotency keys removed", "count", removed)
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+// mediaBuffer is how many received media wait for the worker before new ones are dropped
+const mediaBuffer = 64
+
+type receivedMedia struct {
+	inst *instance.Instance
+	msg  *message.Message
+}
+
+// MediaService saves the media the instances receive to the storage, following the policy of each instance
+type MediaService struct {
+	whatsapp     whatsapp.WhatsAppGateway
+	repo         media.PolicyRepository
+	fileService  *FileService
+	fileRepo     file.FileRepository
+	storage      storage.Storage
+	quotaService *QuotaService
+	bus          events.EventBus
+	defaults     media.Policy
+	timeout      time.Duration
+
+	received chan receivedMedia
+}
+
+func NewMediaService(whatsapp whatsapp.WhatsAppGateway, repo media.PolicyRepository, fileService *FileService, fileRepo file.FileRepository, storage storage.Storage, quotaService *QuotaService, bus events.EventBus, defaults media.Policy, timeout time.Duration) *MediaService {
+	return &MediaService{
+		whatsapp:     whatsapp,
+		repo:         repo,
+		fileService:  fileService,
+		fileRepo:     fileRepo,
+		storage:      storage,
+		quotaService: quotaService,
+		bus:          bus,
+		defaults:     defaults,
+		timeout:      timeout,
+		received:     make(chan receivedMedia, mediaBuffer),
+	}
+}
+
+// GetPolicy returns the policy of the instance, the defaults when it never changed it
+func (s *MediaService) GetPolicy(ctx context.Context, inst *instance.Instance) (*media.Policy, *app.AppError) {
+	policy, err := s.repo.Get(inst.ID)
+	if err != nil {
+		app.GetMediaServiceLogger().Error("Error getting media policy", "instance", inst.ID, "error", err)
+		return nil, app.NewAppError("media service", app.CodeDatabaseError, err)
+	}
+
+	if policy == nil {
+		policy = media.NewPolicy(inst.ID, s.defaults)
+	}
+
+	return policy, nil
+}
+
+func (s *MediaService) UpdatePolicy(ctx context.Context, inst *instance.Instance, inp input.UpdateMediaPolicyInput) (*media.Policy, *app.AppError) {
+	l := app.GetMediaServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("media service", err)
+	}
+
+	policy, appErr := s.GetPolicy(ctx, inst)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if inp.Enabled != nil {
+		policy.Enabled = *inp.Enabled
+	}
+	if inp.Types != nil {
+		policy.Types = *inp.Types
+	}
+	if inp.MaxSize != nil {
+		policy.MaxSize = *inp.MaxSize
+	}
+	if inp.Chats != nil {
+		policy.Chats = *inp.Chats
+	}
+	if inp.IgnoreGroups != nil {
+		policy.IgnoreGroups = *inp.IgnoreGroups
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, app.TranslateError("media service", err)
+	}
+
+	if policy.Enabled && s.storage == nil {
+		l.Error("Global storage is not configured")
+		return nil, app.NewAppError("media service", app.GLOBAL_STORAGE_UNAVAILABLE, storage.ErrStorageNotConfigured)
+	}
+
+	policy.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Save(policy); err != nil {
+		l.Error("Error saving media policy", "instance", inst.ID, "error", err)
+		return nil, app.NewAppError("media service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Media policy updated", "instance", inst.ID, "enabled", policy.Enabled, "max_size", policy.MaxSize)
+	return policy, nil
+}
+
+// Persist is the media handler of the gateway, it hands the received message to the worker without waiting, the
+// media is dropped when the worker is a whole buffer behind
+func (s *MediaService) Persist(inst *instance.Instance, msg *message.Message) {
+	if s.storage == nil {
+		return
+	}
+
+	select {
+	case s.received <- receivedMedia{inst: inst, msg: msg}:
+	default:
+		app.GetMediaServiceLogger().Warn("Received media dropped, the worker is behind", "instance", inst.ID, "chat", msg.Chat)
+	}
+}
+
+// Run saves the received media until the context is canceled, each saved media is published as a follow up
+// of its message event
+func (s *MediaService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case received := <-s.received:
+			stored := s.save(received.inst, received.msg)
+			if stored == nil {
+				continue
+			}
+
+			id := received.msg.ID
+			if received.msg.ExternalID != nil {
+				id = *received.msg.ExternalID
+			}
+
+			s.bus.Publish(events.New(
+				message.EventMessageMediaSaved,
+				message.PayloadMessageMediaSaved{
+					Message:   id,
+					Chat:      received.msg.Chat,
+					Media:     *stored,
+					Timestamp: time.Now(),
+				},
+				&received.inst.ID,
+			))
+		}
+	}
+}
+
+// save downloads the media of a received message allowed by the policy and saves it as an upload of the
+// instance, it is best effort and returns nil when the media was not saved
+func (s *MediaService) save(inst *instance.Instance, msg *message.Message) *message.StoredMedia {
+	l := app.GetMediaServiceLogger()
+
+	source, mediaType := msg.MediaFile()
+	if source == nil || !source.IsDownloadable() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	policy, appErr := s.GetPolicy(ctx, inst)
+	if appErr != nil || !policy.Allows(msg.Chat, mediaType, source.Size) {
+		return nil
+	}
+
+	// the size announced by the sender can be missing or wrong, the download stops past the cap of the policy
+	content, err := downloadMedia(ctx, s.whatsapp, inst, source, mediaTypeToKind(mediaType), policy.MaxSize)
+	if err != nil {
+		l.Warn("Error downloading received media", "instance", inst.ID, "chat", msg.Chat, "type", mediaType, "error", err)
+		return nil
+	}
+	defer content.Close()
+
+	// the cap leaves room for the encryption around the content, it is checked again on the content itself
+	if !policy.Fits(uint64(content.size)) {
+		l.Debug("Received media is larger than the policy allows", "instance", inst.ID, "size", content.size)
+		return nil
+	}
+
+	// the same media forwarded around is stored once per instance
+	existing, err := s.fileService.Reuse(inst.ID, content.sha256)
+	if err != nil {
+		l.Error("Error looking for received media in database", "instance", inst.ID, "error", err)
+		return nil
+	}
+
+	if existing != nil {
+		l.Info("Received media already saved", "instance", inst.ID, "chat", msg.Chat, "file", existing.ID)
+		s.fileService.Sign(ctx, existing)
+		return &message.StoredMedia{ID: existing.ID, URL: existing.URL}
+	}
+
+	if appErr := s.quotaService.Check(ctx, inst.ID, uint64(content.size)); appErr != nil {
+		l.Warn("Received media not saved, refused by the storage quota", "instance", inst.ID, "chat", msg.Chat, "size", content.size, "code", appErr.Code, "error", appErr.Err)
+		return nil
+	}
+
+	mime := source.Mime
+	if mime == "" {
+		mime = content.mime()
+	}
+
+	f := file.NewFromMime(mime)
+	f.Size = uint64(content.size)
+	f.Sha256 = content.sha256
+	f.UpdateMeta(file.Metadata{
+		Name:     &source.Name,
+		Width:    source.Width,
+		Height:   source.Height,
+		Duration: source.Duration,
+		Pages:    source.Pages,
+	})
+	f.InstanceID = &inst.ID
+
+	if err := s.fileService.Write(ctx, f, content); err != nil {
+		l.Error("Error saving received media to storage", "instance", inst.ID, "error", err)
+		return nil
+	}
+
+	stored, reused, err := s.fileService.Keep(ctx, f)
+	if err != nil {
+		l.Error("Error saving received media to database", "instance", inst.ID, "error", err)
+		if err := s.storage.Delete(ctx, f.Path); err != nil {
+			l.Error("Error removing orphan media from storage", "path", f.Path, "error", err)
+		}
+		return nil
+	}
+
+	if reused {
+		l.Info("Received media saved meanwhile", "instance", inst.ID, "chat", msg.Chat, "file", stored.ID)
+		s.fileService.Sign(ctx, stored)
+		return &message.StoredMedia{ID: stored.ID, URL: stored.URL}
+	}
+
+	go s.bus.Publish(f.EventUploaded(&inst.ID))
+
+	l.Info("Received media saved", "instance", inst.ID, "chat", msg.Chat, "file", f.ID, "type", mediaType, "size", f.Size)
+	return &message.StoredMedia{ID: f.ID, URL: f.URL}
+}
@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Media Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	fileRepo := repository.NewFileRepository(db)
+	gateway := fake.NewFakeWhatsAppGateway()
+	bus := fake.NewFakeEventBus()
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	content := []byte("%PDF-1.4 a received document")
+	sum := sha256.Sum256(content)
+
+	var (
+		inst   *instance.Instance
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	start := func(limits quota.Quota, policy media.Policy) *service.MediaService {
+		store := storage.NewLocalStorage(&config.StorageConfig{Driver: config.StorageDriverLocal, Path: GinkgoT().TempDir(), URL: "http://localhost/storage"})
+		fileService := service.NewFileService(store, fileRepo)
+		quotaService := service.NewQuotaService(repository.NewQuotaRepository(db), fileRepo, instRepo, limits)
+
+		medias := service.NewMediaService(gateway, repository.NewMediaPolicyRepository(db), fileService, fileRepo, store, quotaService, bus, policy, time.Second)
+		go medias.Run(ctx)
+		return medias
+	}
+
+	received := func() *message.Message {
+		return message.NewMessage(utils.StringPtr("3EB0000000000001"), "5511999999999@s.whatsapp.net", "5511999999999@s.whatsapp.net", &message.DocumentContent{
+			Document: file.File{
+				Name:       "report.pdf",
+				Mime:       "application/pdf",
+				DirectPath: "/v/t62.7119-24/12345",
+				MediaKey:   "0a1b2c3d",
+				Sha256:     hex.EncodeToString(sum[:]),
+				Sha256Enc:  "8c9d0e1f",
+			},
+		}, &inst.ID, nil, false)
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		gateway.Clear()
+		gateway.ServeMedia(content)
+		bus.Clear()
+		bus.ClearPublished()
+
+		inst = fake.InstanceFactory().Connected().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should save the media in the background and publish where it was stored", func() {
+		medias := start(quota.Quota{}, media.Policy{Enabled: true})
+		medias.Persist(inst, received())
+
+		Eventually(func() bool { return bus.HasPublished(message.EventMessageMediaSaved) }).Should(BeTrue())
+
+		var saved message.PayloadMessageMediaSaved
+		for _, evt := range bus.Published() {
+			if evt.Name == message.EventMessageMediaSaved {
+				saved = evt.Payload.(message.PayloadMessageMediaSaved)
+			}
+		}
+
+		Expect(saved.Message).To(Equal("3EB0000000000001"))
+		Expect(saved.Chat).To(Equal("5511999999999@s.whatsapp.net"))
+		Expect(saved.Media.URL).ToNot(BeEmpty())
+
+		f, err := fileRepo.Get(file.WhereID(saved.Media.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Size).To(Equal(uint64(len(content))))
+		Expect(*f.InstanceID).To(Equal(inst.ID))
+	})
+
+	It("should not save a media refused by the quota", func() {
+		medias := start(quota.Quota{MaxBytes: 1}, media.Policy{Enabled: true})
+		medias.Persist(inst, received())
+
+		Consistently(func() bool { return bus.HasPublished(message.EventMessageMediaSaved) }, 300*time.Millisecond).Should(BeFalse())
+		Expect(bus.HasPublished(file.EventFileUploaded)).To(BeFalse())
+
+		files, err := fileRepo.List(file.WhereInstanceID(inst.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+
+	It("should stop downloading a media larger than the policy allows when its size is not announced", func() {
+		gateway.ServeMedia(make([]byte, 1024))
+
+		medias := start(quota.Quota{}, media.Policy{Enabled: true, MaxSize: 100})
+		medias.Persist(inst, received())
+
+		Consistently(func() bool { return bus.HasPublished(message.EventMessageMediaSaved) }, 300*time.Millisecond).Should(BeFalse())
+
+		files, err := fileRepo.List(file.WhereInstanceID(inst.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+})
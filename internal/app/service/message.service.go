@@ -1,6 +1,7 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
@@ -13,25 +14,41 @@ import (
 	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
 	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
 	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
 	c "github.com/mauriciorobertodev/whappy-go/internal/infra/cache"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
 )
 
 type MessageService struct {
 	whatsapp           whatsapp.WhatsAppGateway
+	msgRepo            message.MessageRepository
+	receiptRepo        message.ReceiptRepository
+	chatRepo           chat.ChatRepository
 	storage            storage.Storage
 	fileService        *FileService
+	previewService     *PreviewService
+	rateLimitService   *RateLimitService
+	transcodeService   *TranscodeService
+	thumbnailService   *ThumbnailService
 	cache              cache.Cache
 	cacheFileUploadTTL time.Duration
 }
 
-func NewMessageService(whatsapp whatsapp.WhatsAppGateway, storage storage.Storage, fileService *FileService, cache cache.Cache, cacheFileUploadTTL time.Duration) *MessageService {
+func NewMessageService(whatsapp whatsapp.WhatsAppGateway, msgRepo message.MessageRepository, receiptRepo message.ReceiptRepository, chatRepo chat.ChatRepository, storage storage.Storage, fileService *FileService, previewService *PreviewService, rateLimitService *RateLimitService, transcodeService *TranscodeService, thumbnailService *ThumbnailService, cache cache.Cache, cacheFileUploadTTL time.Duration) *MessageService {
 	return &MessageService{
 		whatsapp,
+		msgRepo,
+		receiptRepo,
+		chatRepo,
 		storage,
 		fileService,
+		previewService,
+		rateLimitService,
+		transcodeService,
+		thumbnailService,
 		cache,
 		cacheFileUploadTTL,
 	}
@@ -67,13 +84,32 @@ func (s *MessageService) SendTextMessage(ctx context.Context, inst *instance.Ins
 	l.Debug("Sending text message", "instance", inst.ID, "phone", inst.Phone, "chat", inp.To)
 
 	content := message.NewTextContent(inp.Text, inp.Mentions)
-	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, inp.Expiration, true)
+	if inp.WantsPreview() {
+		preview, err := s.getLinkPreview(ctx, inst, inp)
+		if err != nil {
+			// a preview supplied by the caller must be honored, a fetched one is best effort
+			if inp.Preview != nil {
+				return nil, app.NewAppError("message service", app.CodeInvalidThumbnail, err)
+			}
+			l.Error("Error generating link preview, sending without it", "error", err)
+		}
+		content.Preview = preview
+	}
+
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceTyping); appErr != nil {
+		return nil, appErr
+	}
+
 	message, err := s.whatsapp.SendTextMessage(ctx, inst, message)
 	if err != nil {
 		l.Error("Error sending text message", "error", err)
 		return nil, app.TranslateError("message service", err)
 	}
 
+	s.storeMessage(message)
 	return message, nil
 }
 
@@ -87,6 +123,7 @@ func (s *MessageService) SendImageMessage(ctx context.Context, inst *instance.In
 	l.Debug("Sending image message", "instance", inst.ID, "phone", inst.Phone, "chat", inp.To)
 
 	var imageFile *file.ImageFile
+	var generated *string
 
 	useCache := inp.Cache == nil || *inp.Cache
 	source256 := sha256.Sum256([]byte(inp.Image))
@@ -112,6 +149,13 @@ func (s *MessageService) SendImageMessage(ctx context.Context, inst *instance.In
 			Height: inp.Height,
 		})
 
+		if inp.Thumbnail == nil && !loadedFile.HasThumbnail() && s.thumbnailService.Supports(loadedFile.Mime) {
+			generated, stream, err = s.generateThumbnail(ctx, inp.Image, loadedFile, stream, useCache)
+			if err != nil {
+				return nil, app.TranslateError("message service", err)
+			}
+		}
+
 		imageFile, err = loadedFile.ToImageFile()
 		if err != nil {
 			l.Error("Error converting to image file", "error", err)
@@ -150,10 +194,24 @@ func (s *MessageService) SendImageMessage(ctx context.Context, inst *instance.In
 		}
 	}
 
+	if thumbnail == nil {
+		// media taken from the upload cache is not read again, its generated thumbnail was cached with it
+		if generated == nil && useCache {
+			generated = s.thumbnailService.Cached(inp.Image)
+		}
+		thumbnail = generated
+	}
+
 	content := message.NewImageContent(imageFile, thumbnail, inp.Caption, inp.Mentions, inp.ViewOnce)
-	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, inp.Expiration, true)
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
 
 	l.Debug("Sending image message", "instance", inst.ID, "chat", inp.To)
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceTyping); appErr != nil {
+		return nil, appErr
+	}
+
 	msg, err := s.whatsapp.SendImageMessage(ctx, inst, message)
 	if err != nil {
 		l.Error("Error sending image message", "error", err)
@@ -161,6 +219,7 @@ func (s *MessageService) SendImageMessage(ctx context.Context, inst *instance.In
 	}
 
 	l.Info("Image message sent successfully", "id", msg.ID, "instance", *msg.InstanceID, "sender", msg.Sender, "chat", msg.Chat)
+	s.storeMessage(msg)
 	return msg, nil
 }
 
@@ -174,6 +233,7 @@ func (s *MessageService) SendVideoMessage(ctx context.Context, inst *instance.In
 	l.Debug("Sending video message", "instance", inst.ID, "phone", inst.Phone, "chat", inp.To)
 
 	var videoFile *file.VideoFile
+	var generated *string
 
 	useCache := inp.Cache == nil || *inp.Cache
 	source256 := sha256.Sum256([]byte(inp.Video))
@@ -199,6 +259,20 @@ func (s *MessageService) SendVideoMessage(ctx context.Context, inst *instance.In
 			Height: inp.Height,
 		})
 
+		if s.transcodeService.Enabled() {
+			stream, err = s.transcodeVideo(ctx, loadedFile, stream)
+			if err != nil {
+				return nil, app.TranslateError("message service", err)
+			}
+		}
+
+		if inp.Thumbnail == nil && !loadedFile.HasThumbnail() && s.thumbnailService.Supports(loadedFile.Mime) {
+			generated, stream, err = s.generateThumbnail(ctx, inp.Video, loadedFile, stream, useCache)
+			if err != nil {
+				return nil, app.TranslateError("message service", err)
+			}
+		}
+
 		videoFile, err = loadedFile.ToVideoFile()
 		if err != nil {
 			l.Error("Error converting to image file", "error", err)
@@ -237,10 +311,24 @@ func (s *MessageService) SendVideoMessage(ctx context.Context, inst *instance.In
 		}
 	}
 
+	if thumbnail == nil {
+		// media taken from the upload cache is not read again, its generated thumbnail was cached with it
+		if generated == nil && useCache {
+			generated = s.thumbnailService.Cached(inp.Video)
+		}
+		thumbnail = generated
+	}
+
 	content := message.NewVideoContent(*videoFile, thumbnail, inp.Caption, inp.Mentions, inp.ViewOnce)
-	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, inp.Expiration, true)
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
 
 	l.Debug("Sending video message", "instance", inst.ID, "chat", inp.To)
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceTyping); appErr != nil {
+		return nil, appErr
+	}
+
 	msg, err := s.whatsapp.SendVideoMessage(ctx, inst, message)
 	if err != nil {
 		l.Error("Error sending video message", "error", err)
@@ -248,6 +336,7 @@ func (s *MessageService) SendVideoMessage(ctx context.Context, inst *instance.In
 	}
 
 	l.Info("Video message sent successfully", "id", msg.ID, "instance", *msg.InstanceID, "sender", msg.Sender, "chat", msg.Chat)
+	s.storeMessage(msg)
 	return msg, nil
 }
 
@@ -285,6 +374,14 @@ func (s *MessageService) SendAudioMessage(ctx context.Context, inst *instance.In
 			Duration: inp.Duration,
 		})
 
+		if !loadedFile.HasDuration() && s.transcodeService.Enabled() {
+			stream, err = s.probeDuration(ctx, loadedFile, stream)
+			if err != nil {
+				l.Error("Error reading audio file", "error", err)
+				return nil, app.TranslateError("message service", err)
+			}
+		}
+
 		audioFile, err = loadedFile.ToAudioFile()
 		if err != nil {
 			l.Error("Error converting to image file", "error", err)
@@ -310,9 +407,15 @@ func (s *MessageService) SendAudioMessage(ctx context.Context, inst *instance.In
 	}
 
 	content := message.NewAudioContent(*audioFile)
-	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, inp.Expiration, true)
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
 
 	l.Debug("Sending audio message", "instance", inst.ID, "chat", inp.To)
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceRecording); appErr != nil {
+		return nil, appErr
+	}
+
 	msg, err := s.whatsapp.SendAudioMessage(ctx, inst, message)
 	if err != nil {
 		l.Error("Error sending audio message", "error", err)
@@ -320,6 +423,7 @@ func (s *MessageService) SendAudioMessage(ctx context.Context, inst *instance.In
 	}
 
 	l.Info("Audio message sent successfully", "id", msg.ID, "instance", *msg.InstanceID, "sender", msg.Sender, "chat", msg.Chat)
+	s.storeMessage(msg)
 	return msg, nil
 }
 
@@ -357,6 +461,13 @@ func (s *MessageService) SendVoiceMessage(ctx context.Context, inst *instance.In
 			Duration: inp.Duration,
 		})
 
+		if s.transcodeService.Enabled() {
+			stream, err = s.transcodeVoice(ctx, loadedFile, stream)
+			if err != nil {
+				return nil, app.TranslateError("message service", err)
+			}
+		}
+
 		voiceFile, err = loadedFile.ToVoiceFile()
 		if err != nil {
 			l.Error("Error converting to voice file", "error", err)
@@ -382,9 +493,15 @@ func (s *MessageService) SendVoiceMessage(ctx context.Context, inst *instance.In
 	}
 
 	content := message.NewVoiceContent(*voiceFile, inp.ViewOnce)
-	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, inp.Expiration, true)
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
 
 	l.Debug("Sending voice message", "instance", inst.ID, "chat", inp.To)
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceRecording); appErr != nil {
+		return nil, appErr
+	}
+
 	msg, err := s.whatsapp.SendVoiceMessage(ctx, inst, message)
 	if err != nil {
 		l.Error("Error sending voice message", "error", err)
@@ -392,6 +509,7 @@ func (s *MessageService) SendVoiceMessage(ctx context.Context, inst *instance.In
 	}
 
 	l.Info("Voice message sent successfully", "id", msg.ID, "instance", *msg.InstanceID, "sender", msg.Sender, "chat", msg.Chat)
+	s.storeMessage(msg)
 	return msg, nil
 }
 
@@ -405,6 +523,7 @@ func (s *MessageService) SendDocumentMessage(ctx context.Context, inst *instance
 	l.Debug("Sending document message", "instance", inst.ID, "chat", inp.To)
 
 	var docFile *file.File
+	var generated *string
 
 	useCache := inp.Cache == nil || *inp.Cache
 	source256 := sha256.Sum256([]byte(inp.Document))
@@ -429,6 +548,13 @@ func (s *MessageService) SendDocumentMessage(ctx context.Context, inst *instance
 			Pages: inp.Pages,
 		})
 
+		if inp.Thumbnail == nil && !loadedFile.HasThumbnail() && s.thumbnailService.Supports(loadedFile.Mime) {
+			generated, stream, err = s.generateThumbnail(ctx, inp.Document, loadedFile, stream, useCache)
+			if err != nil {
+				return nil, app.TranslateError("message service", err)
+			}
+		}
+
 		docFile = loadedFile
 
 		uploadedFile, err := s.uploadFile(ctx, inst, inp.Document, stream, whatsapp.MediaDocument, docFile.Mime)
@@ -463,10 +589,24 @@ func (s *MessageService) SendDocumentMessage(ctx context.Context, inst *instance
 		}
 	}
 
+	if thumbnail == nil {
+		// media taken from the upload cache is not read again, its generated thumbnail was cached with it
+		if generated == nil && useCache {
+			generated = s.thumbnailService.Cached(inp.Document)
+		}
+		thumbnail = generated
+	}
+
 	content := message.NewDocumentContent(*docFile, thumbnail, inp.Caption, inp.Mentions)
-	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, inp.Expiration, true)
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
 
 	l.Debug("Sending document message", "instance", inst.ID, "chat", inp.To)
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceTyping); appErr != nil {
+		return nil, appErr
+	}
+
 	msg, err := s.whatsapp.SendDocumentMessage(ctx, inst, message)
 	if err != nil {
 		l.Error("Error sending document message", "error", err)
@@ -474,6 +614,7 @@ func (s *MessageService) SendDocumentMessage(ctx context.Context, inst *instance
 	}
 
 	l.Info("Document message sent successfully", "id", msg.ID, "instance", *msg.InstanceID, "sender", msg.Sender, "chat", msg.Chat)
+	s.storeMessage(msg)
 	return msg, nil
 }
 
@@ -496,6 +637,191 @@ func (s *MessageService) MarkMessagesAsRead(ctx context.Context, inst *instance.
 	return nil
 }
 
+func (s *MessageService) SendButtonsMessage(ctx context.Context, inst *instance.Instance, inp input.SendButtonsMessageInput) (*message.Message, *app.AppError) {
+	l := app.GetMessageServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("message service", err)
+	}
+
+	l.Debug("Sending buttons message", "instance", inst.ID, "phone", inst.Phone, "chat", inp.To)
+
+	content := message.NewButtonsContent(inp.Header, inp.Text, inp.Footer, inp.Buttons)
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceTyping); appErr != nil {
+		return nil, appErr
+	}
+
+	msg, err := s.whatsapp.SendButtonsMessage(ctx, inst, message)
+	if err != nil {
+		l.Error("Error sending buttons message", "error", err)
+		return nil, app.TranslateError("message service", err)
+	}
+
+	l.Info("Buttons message sent successfully", "id", msg.ID, "instance", *msg.InstanceID, "sender", msg.Sender, "chat", msg.Chat)
+	s.storeMessage(msg)
+	return msg, nil
+}
+
+func (s *MessageService) SendListMessage(ctx context.Context, inst *instance.Instance, inp input.SendListMessageInput) (*message.Message, *app.AppError) {
+	l := app.GetMessageServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("message service", err)
+	}
+
+	l.Debug("Sending list message", "instance", inst.ID, "phone", inst.Phone, "chat", inp.To)
+
+	content := message.NewListContent(inp.Title, inp.Text, inp.ButtonText, inp.Footer, inp.Sections)
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceTyping); appErr != nil {
+		return nil, appErr
+	}
+
+	msg, err := s.whatsapp.SendListMessage(ctx, inst, message)
+	if err != nil {
+		l.Error("Error sending list message", "error", err)
+		return nil, app.TranslateError("message service", err)
+	}
+
+	l.Info("List message sent successfully", "id", msg.ID, "instance", *msg.InstanceID, "sender", msg.Sender, "chat", msg.Chat)
+	s.storeMessage(msg)
+	return msg, nil
+}
+
+func (s *MessageService) SendTemplateMessage(ctx context.Context, inst *instance.Instance, inp input.SendTemplateMessageInput) (*message.Message, *app.AppError) {
+	l := app.GetMessageServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("message service", err)
+	}
+
+	l.Debug("Sending template message", "instance", inst.ID, "phone", inst.Phone, "chat", inp.To)
+
+	content := message.NewTemplateContent(inp.Title, inp.Text, inp.Footer, inp.Buttons)
+	message := message.NewMessage(inp.ID, inst.JID, inp.To, content, &inst.ID, s.expiration(inst, inp.To, inp.Expiration), true)
+	message.ReplyTo = inp.ReplyTo
+
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, chat.ChatPresenceTyping); appErr != nil {
+		return nil, appErr
+	}
+
+	msg, err := s.whatsapp.SendTemplateMessage(ctx, inst, message)
+	if err != nil {
+		l.Error("Error sending template message", "error", err)
+		return nil, app.TranslateError("message service", err)
+	}
+
+	l.Info("Template message sent successfully", "id", msg.ID, "instance", *msg.InstanceID, "sender", msg.Sender, "chat", msg.Chat)
+	s.storeMessage(msg)
+	return msg, nil
+}
+
+func (s *MessageService) ForwardMessage(ctx context.Context, inst *instance.Instance, inp input.ForwardMessageInput) ([]message.ForwardResult, *app.AppError) {
+	l := app.GetMessageServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("message service", err)
+	}
+
+	source := inp.Message
+	if source == nil {
+		stored, appErr := s.GetMessage(ctx, inst, *inp.ID)
+		if appErr != nil {
+			return nil, appErr
+		}
+		source = stored
+	}
+
+	if source.Content == nil || source.Type == message.MessageKindReaction {
+		return nil, app.TranslateError("message service", message.ErrNotForwardable)
+	}
+
+	l.Debug("Forwarding message", "instance", inst.ID, "type", source.Type, "chats", inp.To)
+
+	results := make([]message.ForwardResult, 0, len(inp.To))
+	for _, to := range inp.To {
+		if appErr := s.rateLimitService.Pace(ctx, inst, to, chat.ChatPresenceTyping); appErr != nil {
+			reason := appErr.Error()
+			results = append(results, message.ForwardResult{Chat: to, Error: &reason})
+			continue
+		}
+
+		msg, err := s.whatsapp.ForwardMessage(ctx, inst, source.Forward(inst.JID, to, &inst.ID))
+		if err != nil {
+			l.Error("Error forwarding message", "chat", to, "error", err)
+			reason := err.Error()
+			results = append(results, message.ForwardResult{Chat: to, Error: &reason})
+			continue
+		}
+
+		s.storeMessage(msg)
+		results = append(results, message.ForwardResult{Chat: to, Message: msg})
+	}
+
+	l.Info("Message forwarded", "instance", inst.ID, "chats", len(inp.To))
+	return results, nil
+}
+
+func (s *MessageService) EditMessage(ctx context.Context, inst *instance.Instance, inp input.EditMessageInput) *app.AppError {
+	l := app.GetMessageServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return app.TranslateError("message service", err)
+	}
+
+	l.Debug("Editing message", "instance", inst.ID, "chat", inp.Chat, "id", inp.ID, "kind", inp.Kind)
+
+	// messages that are not stored are left for WhatsApp to judge
+	stored := s.findStoredMessage(inst, inp.Chat, inp.ID)
+	if stored != nil {
+		if err := stored.CanEdit(time.Now()); err != nil {
+			return app.TranslateError("message service", err)
+		}
+	}
+
+	err := s.whatsapp.EditMessage(ctx, inst, inp.Chat, inp.ID, inp.Kind, inp.Text)
+	if err != nil {
+		l.Error("Error editing message", "error", err)
+		return app.TranslateError("message service", err)
+	}
+
+	if stored != nil && stored.SetText(inp.Text) {
+		s.updateMessage(stored)
+	}
+
+	l.Info("Message edited successfully", "instance", inst.ID, "chat", inp.Chat, "id", inp.ID)
+	return nil
+}
+
+func (s *MessageService) RevokeMessage(ctx context.Context, inst *instance.Instance, inp input.RevokeMessageInput) *app.AppError {
+	l := app.GetMessageServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return app.TranslateError("message service", err)
+	}
+
+	l.Debug("Revoking message", "instance", inst.ID, "chat", inp.Chat, "id", inp.ID)
+
+	err := s.whatsapp.RevokeMessage(ctx, inst, inp.Chat, inp.ID, inp.Sender)
+	if err != nil {
+		l.Error("Error revoking message", "error", err)
+		return app.TranslateError("message service", err)
+	}
+
+	if stored := s.findStoredMessage(inst, inp.Chat, inp.ID); stored != nil {
+		stored.MarkAsDeleted()
+		s.updateMessage(stored)
+	}
+
+	l.Info("Message revoked successfully", "instance", inst.ID, "chat", inp.Chat, "id", inp.ID)
+	return nil
+}
+
 func (s *MessageService) SendReaction(ctx context.Context, inst *instance.Instance, inp input.SendReactionInput) (*message.Message, *app.AppError) {
 	l := app.GetMessageServiceLogger()
 
@@ -510,6 +836,10 @@ func (s *MessageService) SendReaction(ctx context.Context, inst *instance.Instan
 
 	l.Debug("Sending reaction message", "instance", inst.ID, "chat", inp.To)
 
+	if appErr := s.rateLimitService.Pace(ctx, inst, inp.To, ""); appErr != nil {
+		return nil, appErr
+	}
+
 	msg, err := s.whatsapp.SendReaction(ctx, inst, message)
 	if err != nil {
 		l.Error("Error sending reaction", "error", err)
@@ -520,6 +850,97 @@ func (s *MessageService) SendReaction(ctx context.Context, inst *instance.Instan
 	return msg, nil
 }
 
+// GetMessage finds a stored message by its id or by the whatsapp id
+func (s *MessageService) GetMessage(ctx context.Context, inst *instance.Instance, id string) (*message.Message, *app.AppError) {
+	l := app.GetMessageServiceLogger()
+
+	if id == "" {
+		return nil, app.TranslateError("message service", message.ErrInvalidMessageID)
+	}
+
+	where := message.WhereExternalID(id)
+	if utils.IsUUID(id) {
+		where = message.WhereID(id)
+	}
+
+	msg, err := s.msgRepo.Get(message.WhereInstanceID(inst.ID), where)
+	if err != nil {
+		l.Error("Error getting message", "id", id, "error", err)
+		return nil, app.NewAppError("message service", app.CodeDatabaseError, err)
+	}
+
+	if msg == nil {
+		return nil, app.TranslateError("message service", message.ErrMessageNotFound)
+	}
+
+	return msg, nil
+}
+
+// GetMessageStatus returns the delivery state of a stored message with the receipts of every participant
+func (s *MessageService) GetMessageStatus(ctx context.Context, inst *instance.Instance, id string) (*message.StatusTimeline, *app.AppError) {
+	l := app.GetMessageServiceLogger()
+
+	msg, appErr := s.GetMessage(ctx, inst, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	receipts, err := s.receiptRepo.ListByMessage(msg.ID)
+	if err != nil {
+		l.Error("Error listing message receipts", "id", msg.ID, "error", err)
+		return nil, app.NewAppError("message service", app.CodeDatabaseError, err)
+	}
+
+	return message.NewStatusTimeline(msg, receipts), nil
+}
+
+// expiration returns the expiration given by the caller or, when it is omitted, the disappearing timer of the
+// chat, so messages follow the timer the chat has been set to
+func (s *MessageService) expiration(inst *instance.Instance, to string, expiration *uint32) *uint32 {
+	if expiration != nil {
+		return expiration
+	}
+
+	stored, err := s.chatRepo.Get(chat.WhereInstanceID(inst.ID), chat.WhereJID(to))
+	if err != nil {
+		app.GetMessageServiceLogger().Error("Error getting chat expiration, sending without it", "chat", to, "error", err)
+		return nil
+	}
+
+	if stored == nil || !stored.HasExpiration() {
+		return nil
+	}
+
+	return stored.Expiration
+}
+
+// storeMessage keeps the sent message in the history, a failure here must not fail the send
+func (s *MessageService) storeMessage(msg *message.Message) {
+	if err := s.msgRepo.Insert(msg); err != nil {
+		app.GetMessageServiceLogger().Error("Error storing message", "id", msg.ID, "error", err)
+	}
+}
+
+func (s *MessageService) updateMessage(msg *message.Message) {
+	if err := s.msgRepo.Update(msg); err != nil {
+		app.GetMessageServiceLogger().Error("Error updating stored message", "id", msg.ID, "error", err)
+	}
+}
+
+func (s *MessageService) findStoredMessage(inst *instance.Instance, chat string, externalID string) *message.Message {
+	msg, err := s.msgRepo.Get(
+		message.WhereInstanceID(inst.ID),
+		message.WhereChat(chat),
+		message.WhereExternalID(externalID),
+	)
+	if err != nil {
+		app.GetMessageServiceLogger().Error("Error finding stored message", "id", externalID, "error", err)
+		return nil
+	}
+
+	return msg
+}
+
 func (s *MessageService) getThumbnailFromCache(ctx context.Context, cacheKey string) *string {
 	l := app.GetMessageServiceLogger()
 
@@ -565,6 +986,81 @@ func (s *MessageService) uploadFile(ctx context.Context, inst *instance.Instance
 	return uploadedFile, nil
 }
 
+// transcodeVoice converts the voice to an opus voice note with its waveform, the stream is consumed and the
+// converted one is returned
+func (s *MessageService) transcodeVoice(ctx context.Context, f *file.File, stream io.ReadCloser) (io.ReadCloser, error) {
+	data, err := readAndClose(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.transcodeService.Voice(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	f.SetMime(result.Mime)
+	f.Waveform = result.Waveform
+	if !f.HasDuration() {
+		f.Duration = result.Duration
+	}
+
+	return io.NopCloser(bytes.NewReader(result.Data)), nil
+}
+
+// transcodeVideo converts the video to a streamable h264 mp4, the dimensions given by the caller are kept
+func (s *MessageService) transcodeVideo(ctx context.Context, f *file.File, stream io.ReadCloser) (io.ReadCloser, error) {
+	data, err := readAndClose(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.transcodeService.Video(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	f.SetMime(result.Mime)
+	if !f.HasDimensions() {
+		f.Width = result.Width
+		f.Height = result.Height
+	}
+	if !f.HasDuration() {
+		f.Duration = result.Duration
+	}
+
+	return io.NopCloser(bytes.NewReader(result.Data)), nil
+}
+
+// probeDuration fills the duration of an audio sent as is, a failed probe only leaves it empty
+func (s *MessageService) probeDuration(ctx context.Context, f *file.File, stream io.ReadCloser) (io.ReadCloser, error) {
+	data, err := readAndClose(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	if probe, err := s.transcodeService.Probe(ctx, data); err == nil {
+		f.Duration = probe.Duration
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// generateThumbnail previews media sent without a thumbnail, the stream is read whole and given back intact
+func (s *MessageService) generateThumbnail(ctx context.Context, source string, f *file.File, stream io.ReadCloser, useCache bool) (*string, io.ReadCloser, error) {
+	data, err := readAndClose(stream)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.thumbnailService.ForSource(ctx, source, f.Mime, data, useCache), io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func readAndClose(stream io.ReadCloser) ([]byte, error) {
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
 func (s *MessageService) getThumbnail(ctx context.Context, source string, useCache bool) (*string, error) {
 	l := app.GetMessageServiceLogger()
 
@@ -592,3 +1088,93 @@ func (s *MessageService) getThumbnail(ctx context.Context, source string, useCac
 
 	return &thumbnailBase64, nil
 }
+
+func (s *MessageService) getLinkPreview(ctx context.Context, inst *instance.Instance, inp input.SendTextMessageInput) (*message.LinkPreview, error) {
+	l := app.GetMessageServiceLogger()
+
+	useCache := inp.Cache == nil || *inp.Cache
+
+	var preview *message.LinkPreview
+	if inp.Preview != nil {
+		supplied := *inp.Preview
+		supplied.Image = nil
+
+		if supplied.HasThumbnail() {
+			_, data, err := s.fileService.GetFrom(ctx, *supplied.Thumbnail)
+			if err != nil {
+				l.Error("Error getting link preview thumbnail", "error", err)
+				return nil, err
+			}
+
+			thumbnail, _, _, err := file.GenerateThumbnail(*data, file.ThumbnailMaxSize)
+			if err != nil {
+				l.Error("Error generating link preview thumbnail", "error", err)
+				return nil, err
+			}
+
+			encoded := base64.StdEncoding.EncodeToString(thumbnail)
+			supplied.Thumbnail = &encoded
+		}
+
+		preview = &supplied
+	} else {
+		link, found := message.FindFirstURL(inp.Text)
+		if !found {
+			l.Debug("No url found in text, skipping link preview")
+			return nil, nil
+		}
+
+		fetched, err := s.previewService.Fetch(ctx, link)
+		if err != nil {
+			return nil, err
+		}
+
+		preview = fetched
+	}
+
+	if preview.HasThumbnail() {
+		image, err := s.uploadLinkThumbnail(ctx, inst, *preview.Thumbnail, useCache)
+		if err != nil {
+			// the inline thumbnail is still shown, only in lower quality
+			l.Error("Error uploading link preview thumbnail", "error", err)
+		} else {
+			preview.Image = image
+		}
+	}
+
+	return preview, nil
+}
+
+func (s *MessageService) uploadLinkThumbnail(ctx context.Context, inst *instance.Instance, thumbnail string, useCache bool) (*file.ImageFile, error) {
+	l := app.GetMessageServiceLogger()
+
+	source256 := sha256.Sum256([]byte(thumbnail))
+	cacheKey := cache.CacheKeyFileUploadPrefix + hex.EncodeToString(source256[:])
+
+	if useCache {
+		cachedFile := s.getFileFromCache(ctx, cacheKey)
+		if cachedFile != nil {
+			return cachedFile.ToImageFile()
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(thumbnail)
+	if err != nil {
+		return nil, file.ErrCorruptedFile
+	}
+
+	uploadedFile, err := s.uploadFile(ctx, inst, thumbnail, io.NopCloser(bytes.NewReader(data)), whatsapp.MediaLinkThumbnail, "image/jpeg")
+	if err != nil {
+		return nil, err
+	}
+
+	uploadedFile.Mime = "image/jpeg"
+	uploadedFile.Width, uploadedFile.Height, _ = file.DetectDimensions(&data)
+
+	if useCache {
+		l.Debug("Caching uploaded link thumbnail", "cacheKey", cacheKey)
+		c.Set(s.cache, cacheKey, *uploadedFile, s.cacheFileUploadTTL)
+	}
+
+	return uploadedFile.ToImageFile()
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/cache"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+)
+
+// presignGrace keeps a presigned upload completable for a while after its URL expires, a PUT started just
+// before the expiration may take long to finish
+const presignGrace = time.Hour
+
+// pendingUpload is what is kept of a presigned upload until it is completed
+type pendingUpload struct {
+	InstanceID string `json:"instance_id"`
+	Path       string `json:"path"`
+	Mime       string `json:"mime"`
+}
+
+// PresignService lets clients write uploads straight to the storage, the API only signs the URL and registers the
+// file once the client tells it is done
+type PresignService struct {
+	uploadService *UploadService
+	storage       storage.Storage
+	cache         cache.Cache
+	ttl           time.Duration
+}
+
+func NewPresignService(uploadService *UploadService, storage storage.Storage, cache cache.Cache, ttl time.Duration) *PresignService {
+	return &PresignService{
+		uploadService: uploadService,
+		storage:       storage,
+		cache:         cache,
+		ttl:           ttl,
+	}
+}
+
+func (s *PresignService) Presign(ctx context.Context, inst *instance.Instance, inp input.PresignUpload) (*file.PresignedUpload, *app.AppError) {
+	l := app.GetPresignServiceLogger()
+
+	if s.storage == nil {
+		l.Error("Global storage is not configured")
+		return nil, app.NewAppError("presign service", app.GLOBAL_STORAGE_UNAVAILABLE, storage.ErrStorageNotConfigured)
+	}
+
+	presigner := storage.Presigned(s.storage)
+	if presigner == nil || s.ttl <= 0 {
+		return nil, app.TranslateError("presign service", storage.ErrPresignUnsupported)
+	}
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("presign service", err)
+	}
+
+	if appErr := s.uploadService.CheckQuota(ctx, inst, 0); appErr != nil {
+		return nil, appErr
+	}
+
+	u := file.NewPresignedUpload(inp.Mime, s.ttl)
+
+	url, headers, err := presigner.PresignPut(ctx, u.Path, u.Mime, s.ttl)
+	if errors.Is(err, storage.ErrPresignUnsupported) {
+		return nil, app.TranslateError("presign service", err)
+	}
+	if err != nil {
+		l.Error("Error presigning upload", "instance", inst.ID, "error", err)
+		return nil, app.TranslateError("presign service", file.ErrStorageFailed)
+	}
+	u.URL = url
+	for k, v := range headers {
+		u.Headers[k] = v
+	}
+
+	pending, _ := json.Marshal(pendingUpload{InstanceID: inst.ID, Path: u.Path, Mime: u.Mime})
+	if err := s.cache.Set(presignKey(u.ID), pending, s.ttl+presignGrace); err != nil {
+		l.Error("Error caching presigned upload", "instance", inst.ID, "error", err)
+		return nil, app.NewAppError("presign service", app.CodeInternalError, err)
+	}
+
+	l.Info("Upload presigned", "instance", inst.ID, "upload", u.ID, "mime", u.Mime)
+	return u, nil
+}
+
+// Complete registers what the client wrote to the presigned URL as an upload, reading it once to copy and hash it
+func (s *PresignService) Complete(ctx context.Context, inst *instance.Instance, inp input.CompletePresignedUpload) (*file.File, *app.AppError) {
+	l := app.GetPresignServiceLogger()
+
+	if s.storage == nil {
+		l.Error("Global storage is not configured")
+		return nil, app.NewAppError("presign service", app.GLOBAL_STORAGE_UNAVAILABLE, storage.ErrStorageNotConfigured)
+	}
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("presign service", err)
+	}
+
+	raw, err := s.cache.Get(presignKey(inp.FileID))
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			l.Error("Error getting presigned upload from cache", "upload", inp.FileID, "error", err)
+		}
+		return nil, app.TranslateError("presign service", file.ErrPresignedUploadNotFound)
+	}
+
+	var pending pendingUpload
+	if err := json.Unmarshal(raw, &pending); err != nil || pending.InstanceID != inst.ID {
+		return nil, app.TranslateError("presign service", file.ErrPresignedUploadNotFound)
+	}
+
+	r, err := s.storage.Load(ctx, pending.Path)
+	if err != nil {
+		l.Warn("Presigned upload completed before anything was uploaded", "upload", inp.FileID, "error", err)
+		return nil, app.TranslateError("presign service", file.ErrPresignedUploadMissing)
+	}
+	defer r.Close()
+
+	// the presigned URL stays valid until it expires, the content is copied to a key of its own while it is
+	// hashed so writing to the URL again cannot change the registered file
+	f := file.NewFromMime(pending.Mime)
+	f.ID = inp.FileID
+	f.Name = f.Path
+
+	hash := sha256.New()
+	var size writtenBytes
+	if err := s.storage.Save(ctx, f.Path, io.TeeReader(r, io.MultiWriter(hash, &size))); err != nil {
+		l.Error("Error copying presigned upload in storage", "upload", inp.FileID, "error", err)
+		s.storage.Delete(ctx, f.Path)
+		return nil, app.TranslateError("presign service", file.ErrFileUnreachable)
+	}
+
+	if err := s.storage.Delete(ctx, pending.Path); err != nil {
+		l.Error("Error removing presigned upload from storage", "upload", inp.FileID, "path", pending.Path, "error", err)
+	}
+
+	f.Size = uint64(size)
+	f.Sha256 = hex.EncodeToString(hash.Sum(nil))
+
+	if url, err := s.storage.URL(ctx, f.Path); err == nil {
+		f.URL = url
+	}
+
+	registered, appErr := s.uploadService.Register(ctx, inst, f, inp.Metadata, inp.ThumbnailID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	s.cache.Delete(presignKey(inp.FileID))
+
+	l.Info("Presigned upload completed", "instance", inst.ID, "upload", inp.FileID, "file", registered.ID, "size", registered.Size)
+	return registered, nil
+}
+
+// writtenBytes counts what goes through it
+type writtenBytes int64
+
+func (w *writtenBytes) Write(p []byte) (int, error) {
+	*w += writtenBytes(len(p))
+	return len(p), nil
+}
+
+func presignKey(id string) string {
+	return cache.CacheKeyPresignedPrefix + id
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	infrastorage "github.com/mauriciorobertodev/whappy-go/internal/infra/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Presign Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	fileRepo := repository.NewFileRepository(db)
+	bus := fake.NewFakeEventBus()
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	var (
+		inst     *instance.Instance
+		store    *infrastorage.LocalStorage
+		presigns *service.PresignService
+		ctx      = context.Background()
+	)
+
+	BeforeEach(func() {
+		migrator.Reset()
+		bus.Clear()
+		bus.ClearPublished()
+
+		inst = fake.InstanceFactory().Connected().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		store = infrastorage.NewLocalStorage(&config.StorageConfig{Driver: config.StorageDriverLocal, Path: GinkgoT().TempDir(), URL: "http://localhost", SigningKey: "signing-key"})
+		fileService := service.NewFileService(store, fileRepo)
+		quotaService := service.NewQuotaService(repository.NewQuotaRepository(db), fileRepo, instRepo, quota.Quota{})
+		uploads := service.NewUploadService(fileService, nil, fileRepo, store, quotaService, bus)
+		presigns = service.NewPresignService(uploads, store, fake.NewFakeCache(), time.Minute)
+	})
+
+	It("should keep the completed upload when the presigned URL is written again", func() {
+		u, appErr := presigns.Presign(ctx, inst, input.PresignUpload{Mime: "text/plain"})
+		Expect(appErr).To(BeNil())
+		Expect(store.Put(ctx, u.Path, []byte("the uploaded content"))).To(Succeed())
+
+		f, appErr := presigns.Complete(ctx, inst, input.CompletePresignedUpload{FileID: u.ID})
+		Expect(appErr).To(BeNil())
+		Expect(f.Path).ToNot(Equal(u.Path))
+
+		found, err := store.Exists(ctx, u.Path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		Expect(store.Put(ctx, u.Path, []byte("written after completion"))).To(Succeed())
+
+		content, err := store.Get(ctx, f.Path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("the uploaded content"))
+	})
+})
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/cache"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	c "github.com/mauriciorobertodev/whappy-go/internal/infra/cache"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+)
+
+const (
+	previewTimeout      = 5 * time.Second
+	previewMaxPageSize  = 1 << 20 // 1MB, the head of the page is enough to find the open graph tags
+	previewMaxImageSize = 5 << 20 // 5MB
+	previewUserAgent    = "Mozilla/5.0 (compatible; WhappyBot/1.0; +https://github.com/mauriciorobertodev/whappy-go)"
+)
+
+var errPreviewAddressNotAllowed = errors.New("address is not public")
+
+var (
+	metaTagRegex   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attributeRegex = regexp.MustCompile(`(?is)([a-z][a-z0-9:_-]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	titleTagRegex  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+type PreviewService struct {
+	cache  cache.Cache
+	client *http.Client
+	ttl    time.Duration
+}
+
+func NewPreviewService(cache cache.Cache, ttl time.Duration) *PreviewService {
+	return &PreviewService{
+		cache:  cache,
+		client: newPublicHTTPClient(previewTimeout),
+		ttl:    ttl,
+	}
+}
+
+// Fetch loads the page and builds the preview from its open graph tags, the image becomes a base64 jpeg
+// thumbnail, uploading it to whatsapp is up to the caller because the upload depends on the instance
+func (s *PreviewService) Fetch(ctx context.Context, link string) (*message.LinkPreview, error) {
+	l := app.GetPreviewServiceLogger()
+
+	link256 := sha256.Sum256([]byte(link))
+	cacheKey := cache.CacheKeyLinkPreviewPrefix + hex.EncodeToString(link256[:])
+
+	cached, err := c.Get[message.LinkPreview](s.cache, cacheKey)
+	if err == nil {
+		l.Debug("Found cached link preview", "url", link)
+		return &cached, nil
+	}
+
+	l.Debug("Fetching link preview", "url", link)
+	page, base, err := s.get(ctx, link, previewMaxPageSize)
+	if err != nil {
+		l.Error("Error fetching link preview page", "url", link, "error", err)
+		return nil, message.ErrPreviewUnavailable
+	}
+
+	meta := ParseOpenGraph(string(page))
+
+	preview := &message.LinkPreview{URL: link}
+	if title := meta["og:title"]; title != "" {
+		preview.Title = utils.StringPtr(truncate(title, message.MaxPreviewTitleLength))
+	}
+	if description := meta["og:description"]; description != "" {
+		preview.Description = utils.StringPtr(truncate(description, message.MaxPreviewDescriptionLength))
+	}
+
+	if image := meta["og:image"]; image != "" {
+		thumbnail, err := s.fetchThumbnail(ctx, base, image)
+		if err != nil {
+			l.Error("Error fetching link preview image, sending without thumbnail", "url", link, "image", image, "error", err)
+		} else {
+			preview.Thumbnail = thumbnail
+		}
+	}
+
+	if preview.Title == nil && preview.Description == nil && preview.Thumbnail == nil {
+		return nil, message.ErrPreviewUnavailable
+	}
+
+	c.Set(s.cache, cacheKey, *preview, s.ttl)
+
+	l.Info("Link preview fetched successfully", "url", link)
+	return preview, nil
+}
+
+func (s *PreviewService) fetchThumbnail(ctx context.Context, base *url.URL, image string) (*string, error) {
+	ref, err := url.Parse(image)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _, err := s.get(ctx, base.ResolveReference(ref).String(), previewMaxImageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail, _, _, err := file.GenerateThumbnail(data, file.ThumbnailMaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(thumbnail)
+	return &encoded, nil
+}
+
+// newPublicHTTPClient returns a client that only connects to public addresses, links come from message
+// texts and the fetched page ends up in the chat. The address is checked after the DNS resolution on
+// every dial, so redirects and hosts resolving to internal addresses are refused too
+func newPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", errPreviewAddressNotAllowed, host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		// no proxy, the dialer would check the address of the proxy instead of the target
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// get returns the body and the final url after redirects, so relative image paths resolve correctly
+func (s *PreviewService) get(ctx context.Context, link string, limit int64) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, nil, message.ErrInvalidURL
+	}
+	req.Header.Set("User-Agent", previewUserAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, file.ErrFileUnreachable
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, resp.Request.URL, nil
+}
+
+// ParseOpenGraph returns the open graph properties of the page, falling back to the title tag and
+// the description meta tag when the page has no open graph title or description
+func ParseOpenGraph(page string) map[string]string {
+	meta := make(map[string]string)
+
+	for _, tag := range metaTagRegex.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, attr := range attributeRegex.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(attr[1])] = attr[2] + attr[3]
+		}
+
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+
+		content := strings.TrimSpace(html.UnescapeString(attrs["content"]))
+		if key == "" || content == "" {
+			continue
+		}
+
+		// the first occurrence wins, pages usually repeat og:image with smaller variants
+		if _, exists := meta[key]; !exists {
+			meta[key] = content
+		}
+	}
+
+	if meta["og:title"] == "" {
+		if match := titleTagRegex.FindStringSubmatch(page); match != nil {
+			meta["og:title"] = strings.TrimSpace(html.UnescapeString(match[1]))
+		}
+	}
+
+	if meta["og:description"] == "" {
+		meta["og:description"] = meta["description"]
+	}
+
+	return meta
+}
+
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+
+	// cut on bytes and drop the rune that may have been split in half
+	text = text[:max]
+	for !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+
+	return text
+}
@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Preview Service", func() {
+	It("should parse the open graph tags", func() {
+		page := `<html><head>
+			<title>Fallback</title>
+			<meta property="og:title" content="Whappy &amp; Go">
+			<meta content='A WhatsApp API' property='og:description' />
+			<meta property="og:image" content="/cover.png">
+			<meta property="og:image" content="/cover-small.png">
+		</head></html>`
+
+		meta := service.ParseOpenGraph(page)
+		Expect(meta["og:title"]).To(Equal("Whappy & Go"))
+		Expect(meta["og:description"]).To(Equal("A WhatsApp API"))
+		Expect(meta["og:image"]).To(Equal("/cover.png"))
+	})
+
+	It("should fallback to the title and description tags", func() {
+		page := `<html><head>
+			<title> Plain page </title>
+			<meta name="description" content="Without open graph">
+		</head></html>`
+
+		meta := service.ParseOpenGraph(page)
+		Expect(meta["og:title"]).To(Equal("Plain page"))
+		Expect(meta["og:description"]).To(Equal("Without open graph"))
+		Expect(meta["og:image"]).To(BeEmpty())
+	})
+
+	It("should refuse to fetch internal addresses", func() {
+		config.LoadLoggers(logger.LevelNone)
+
+		page := `<html><head><meta property="og:title" content="Internal"></head></html>`
+		internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(page))
+		}))
+		defer internal.Close()
+
+		previews := service.NewPreviewService(fake.NewFakeCache(), time.Minute)
+
+		for _, link := range []string{internal.URL, "http://169.254.169.254/latest/meta-data", "file:///etc/passwd"} {
+			_, err := previews.Fetch(GinkgoT().Context(), link)
+			Expect(err).To(MatchError(message.ErrPreviewUnavailable), link)
+		}
+	})
+})
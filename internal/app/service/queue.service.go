@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+)
+
+const (
+	// a send left as sending for longer than this belongs to a worker that stopped, it goes back to the queue,
+	// a worker still sending renews its claim well before
+	queueStaleAfter = 5 * time.Minute
+)
+
+type QueueService struct {
+	queueRepo queue.QueueRepository
+	instRepo  instance.InstanceRepository
+	delivery  *delivery
+	eventbus  events.EventBus
+
+	interval    time.Duration
+	maxAttempts int
+	concurrency int
+	releasedAt  time.Time
+}
+
+func NewQueueService(
+	queueRepo queue.QueueRepository,
+	instRepo instance.InstanceRepository,
+	registry instance.InstanceRegistry,
+	sessionService *SessionService,
+	messageService *MessageService,
+	eventbus events.EventBus,
+	interval time.Duration,
+	maxAttempts int,
+	concurrency int,
+) *QueueService {
+	return &QueueService{
+		queueRepo:   queueRepo,
+		instRepo:    instRepo,
+		delivery:    newDelivery(instRepo, registry, sessionService, messageService, interval),
+		eventbus:    eventbus,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		concurrency: concurrency,
+	}
+}
+
+// Enqueue validates the body of a send endpoint and stores it to be sent in the background
+func (s *QueueService) Enqueue(ctx context.Context, inst *instance.Instance, kind message.MessageKind, payload []byte) (*queue.QueuedMessage, *app.AppError) {
+	l := app.GetQueueServiceLogger()
+
+	if err := validateQueuedPayload(kind, payload); err != nil {
+		return nil, app.TranslateError("queue service", err)
+	}
+
+	queued := queue.New(inst.ID, queuedChat(payload), kind, payload)
+	if err := s.queueRepo.Insert(queued); err != nil {
+		l.Error("Error queueing message", "error", err)
+		return nil, app.NewAppError("queue service", app.CodeDatabaseError, err)
+	}
+
+	l.Debug("Message queued", "instance", inst.ID, "id", queued.ID, "kind", kind, "chat", queued.Chat)
+	s.eventbus.Publish(queued.EventQueued())
+
+	return queued, nil
+}
+
+func (s *QueueService) Get(ctx context.Context, inst *instance.Instance, id string) (*queue.QueuedMessage, *app.AppError) {
+	queued, err := s.queueRepo.Get(queue.WhereInstanceID(inst.ID), queue.WhereID(id))
+	if err != nil {
+		app.GetQueueServiceLogger().Error("Error getting queued message", "id", id, "error", err)
+		return nil, app.NewAppError("queue service", app.CodeDatabaseError, err)
+	}
+
+	if queued == nil {
+		return nil, app.TranslateError("queue service", queue.ErrQueuedMessageNotFound)
+	}
+
+	return queued, nil
+}
+
+// List returns the messages of the instance still waiting to be sent, oldest first, scheduled messages are
+// listed by the schedule service
+func (s *QueueService) List(ctx context.Context, inst *instance.Instance) ([]*queue.QueuedMessage, *app.AppError) {
+	queued, err := s.queueRepo.List(queue.WhereInstanceID(inst.ID), queue.WhereStatus(queue.StatusQueued), queue.WhereScheduled(false))
+	if err != nil {
+		app.GetQueueServiceLogger().Error("Error listing queued messages", "error", err)
+		return nil, app.NewAppError("queue service", app.CodeDatabaseError, err)
+	}
+
+	return queued, nil
+}
+
+func (s *QueueService) Cancel(ctx context.Context, inst *instance.Instance, id string) (*queue.QueuedMessage, *app.AppError) {
+	l := app.GetQueueServiceLogger()
+
+	queued, appErr := s.Get(ctx, inst, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if err := queued.Cancel(); err != nil {
+		return nil, app.TranslateError("queue service", err)
+	}
+
+	// the worker may have claimed it in the meantime
+	canceled, err := s.queueRepo.UpdateFrom(queued, queue.StatusQueued)
+	if err != nil {
+		l.Error("Error canceling queued message", "id", id, "error", err)
+		return nil, app.NewAppError("queue service", app.CodeDatabaseError, err)
+	}
+
+	if !canceled {
+		return nil, app.TranslateError("queue service", queue.ErrNotCancelable)
+	}
+
+	l.Info("Queued message canceled", "instance", inst.ID, "id", id)
+	return queued, nil
+}
+
+// Run delivers the queued messages until the context is done, it is safe to run on several replicas
+func (s *QueueService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.release()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatch(ctx)
+		}
+	}
+}
+
+// release puts back the sends left as sending by a replica that stopped, they would hold their chat
+func (s *QueueService) release() {
+	if time.Since(s.releasedAt) < queueStaleAfter/2 {
+		return
+	}
+	s.releasedAt = time.Now()
+
+	l := app.GetQueueServiceLogger()
+	if released, err := s.queueRepo.Release(time.Now().Add(-queueStaleAfter)); err != nil {
+		l.Error("Error releasing stale queued messages", "error", err)
+	} else if released > 0 {
+		l.Warn("Stale queued messages released", "count", released)
+	}
+}
+
+func (s *QueueService) dispatch(ctx context.Context) {
+	l := app.GetQueueServiceLogger()
+
+	ready, err := s.queueRepo.Ready(time.Now(), s.concurrency)
+	if err != nil {
+		l.Error("Error listing ready queued messages", "error", err)
+		return
+	}
+
+	// ready messages belong to different chats, so they can be sent side by side without breaking the order
+	var wg sync.WaitGroup
+	for _, queued := range ready {
+		queued.MarkSending()
+		claimed, err := s.queueRepo.UpdateFrom(queued, queue.StatusQueued)
+		if err != nil {
+			l.Error("Error claiming queued message", "id", queued.ID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		wg.Add(1)
+		go func(queued *queue.QueuedMessage) {
+			defer wg.Done()
+			s.deliver(ctx, queued)
+		}(queued)
+	}
+	wg.Wait()
+}
+
+func (s *QueueService) deliver(ctx context.Context, queued *queue.QueuedMessage) {
+	l := app.GetQueueServiceLogger()
+
+	stop := keepClaim(ctx, queueStaleAfter/3, func() { s.renew(queued) })
+	result := s.delivery.send(ctx, queued.InstanceID, queued.Kind, queued.Payload)
+	stop()
+
+	switch {
+	case result.delay > 0:
+		l.Debug("Queued message postponed", "id", queued.ID, "instance", queued.InstanceID, "delay", result.delay, "error", result.err)
+		queued.Postpone(result.delay)
+		s.save(queued)
+
+	case result.err != nil && result.final:
+		queued.Fail(result.err)
+		s.finish(queued)
+
+	case result.err != nil:
+		l.Error("Error sending queued message", "id", queued.ID, "attempt", queued.Attempts+1, "error", result.err)
+		if queued.Retry(result.err, s.maxAttempts) {
+			s.save(queued)
+			return
+		}
+		s.finish(queued)
+
+	default:
+		queued.MarkSent(result.msg)
+		s.finish(queued)
+	}
+}
+
+// finish stores a message that left the queue and tells the listeners how it ended
+func (s *QueueService) finish(queued *queue.QueuedMessage) {
+	s.save(queued)
+
+	if queued.Status == queue.StatusSent {
+		app.GetQueueServiceLogger().Info("Queued message sent", "id", queued.ID, "instance", queued.InstanceID, "chat", queued.Chat)
+		s.eventbus.Publish(queued.EventSent())
+		return
+	}
+
+	app.GetQueueServiceLogger().Warn("Queued message failed", "id", queued.ID, "instance", queued.InstanceID, "attempts", queued.Attempts)
+	s.eventbus.Publish(queued.EventFailed())
+}
+
+func (s *QueueService) renew(queued *queue.QueuedMessage) {
+	l := app.GetQueueServiceLogger()
+
+	renewed, err := s.queueRepo.Renew(queued.ID, time.Now())
+	if err != nil {
+		l.Error("Error renewing claim of queued message", "id", queued.ID, "error", err)
+	} else if !renewed {
+		l.Warn("Queued message no longer claimed while being sent", "id", queued.ID, "instance", queued.InstanceID)
+	}
+}
+
+func (s *QueueService) save(queued *queue.QueuedMessage) {
+	if err := s.queueRepo.Update(queued); err != nil {
+		app.GetQueueServiceLogger().Error("Error updating queued message", "id", queued.ID, "error", err)
+	}
+}
+
+// delivery sends the payloads of the queue and the campaigns, connecting the instance when it is offline and
+// holding the send back while the instance can not send
+type delivery struct {
+	instRepo       instance.InstanceRepository
+	registry       instance.InstanceRegistry
+	sessionService *SessionService
+	messageService *MessageService
+	interval       time.Duration
+}
+
+func newDelivery(instRepo instance.InstanceRepository, registry instance.InstanceRegistry, sessionService *SessionService, messageService *MessageService, interval time.Duration) *delivery {
+	return &delivery{
+		instRepo:       instRepo,
+		registry:       registry,
+		sessionService: sessionService,
+		messageService: messageService,
+		interval:       interval,
+	}
+}
+
+// keepClaim calls renew every given time while a send runs, until the returned func is called, a send taking
+// longer than the stale time would otherwise be released and sent again by another worker
+func keepClaim(ctx context.Context, every time.Duration, renew func()) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				renew()
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// deliveryResult is the sent message, or a delay to wait without spending an attempt, or the error of the
+// send, final when trying again can not help
+type deliveryResult struct {
+	msg   *message.Message
+	delay time.Duration
+	err   error
+	final bool
+}
+
+func (d *delivery) send(ctx context.Context, instanceID string, kind message.MessageKind, payload []byte) deliveryResult {
+	inst, err := loadInstance(d.registry, d.instRepo, instanceID)
+	if err != nil {
+		return deliveryResult{delay: queue.ReconnectDelay, err: err}
+	}
+
+	if inst == nil {
+		return deliveryResult{err: instance.ErrInstanceNotFound, final: true}
+	}
+
+	// a banned instance will not come back, waiting for it would hold the sends forever
+	if inst.Status.IsBanned() {
+		return deliveryResult{err: instance.ErrInstanceIsBanned, final: true}
+	}
+
+	if !inst.Status.IsConnected() {
+		if appErr := d.sessionService.Connect(ctx, inst); appErr != nil {
+			return deliveryResult{delay: queue.ReconnectDelay, err: appErr}
+		}
+	}
+
+	msg, err := sendPayload(ctx, d.messageService, inst, kind, payload)
+	if err != nil {
+		if errors.Is(err, instance.ErrInstanceNotConnected) {
+			return deliveryResult{delay: queue.ReconnectDelay, err: err}
+		}
+
+		// a limited send waits for the limit
+		if errors.Is(err, ratelimit.ErrRateLimited) || errors.Is(err, ratelimit.ErrRecipientCooldown) {
+			return deliveryResult{delay: max(RetryAfter(err), d.interval), err: err}
+		}
+
+		return deliveryResult{err: err}
+	}
+
+	return deliveryResult{msg: msg}
+}
+
+// loadInstance returns the instance from the registry, loading it from the repository when the registry does
+// not have it, nil when it does not exist
+func loadInstance(registry instance.InstanceRegistry, instRepo instance.InstanceRepository, id string) (*instance.Instance, error) {
+	if inst, ok := registry.Get(id); ok && inst != nil {
+		return inst, nil
+	}
+
+	inst, err := instRepo.Get(instance.WhereID(id))
+	if errors.Is(err, instance.ErrInstanceNotFound) {
+		return nil, nil
+	}
+	if err != nil || inst == nil {
+		return nil, err
+	}
+
+	registry.Add(inst)
+	return inst, nil
+}
+
+// sendPayload sends the body of the send endpoint of the kind through the message service
+func sendPayload(ctx context.Context, messageService *MessageService, inst *instance.Instance, kind message.MessageKind, payload []byte) (*message.Message, error) {
+	inp, err := decodeQueuedInput(kind, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg *message.Message
+	var appErr *app.AppError
+
+	switch inp := inp.(type) {
+	case *input.SendTextMessageInput:
+		msg, appErr = messageService.SendTextMessage(ctx, inst, *inp)
+	case *input.SendButtonsMessageInput:
+		msg, appErr = messageService.SendButtonsMessage(ctx, inst, *inp)
+	case *input.SendListMessageInput:
+		msg, appErr = messageService.SendListMessage(ctx, inst, *inp)
+	case *input.SendTemplateMessageInput:
+		msg, appErr = messageService.SendTemplateMessage(ctx, inst, *inp)
+	case *input.SendImageMessageInput:
+		return messageService.SendImageMessage(ctx, inst, *inp)
+	case *input.SendVideoMessageInput:
+		return messageService.SendVideoMessage(ctx, inst, *inp)
+	case *input.SendAudioMessageInput:
+		return messageService.SendAudioMessage(ctx, inst, *inp)
+	case *input.SendVoiceMessageInput:
+		return messageService.SendVoiceMessage(ctx, inst, *inp)
+	case *input.SendDocumentMessageInput:
+		return messageService.SendDocumentMessage(ctx, inst, *inp)
+	default:
+		return nil, queue.ErrUnsupportedKind
+	}
+
+	// a nil *app.AppError must not become a non nil error
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return msg, nil
+}
+
+// validateQueuedPayload checks the send body as the send endpoint of the kind would
+func validateQueuedPayload(kind message.MessageKind, payload []byte) error {
+	inp, err := decodeQueuedInput(kind, payload)
+	if err != nil {
+		return err
+	}
+
+	return inp.Validate()
+}
+
+// queuedChat reads the chat of a send body, every send body addresses it with "to"
+func queuedChat(payload []byte) string {
+	var target struct {
+		To string `json:"to"`
+	}
+	_ = json.Unmarshal(payload, &target)
+	return target.To
+}
+
+// withChat sets the "to" of a send body
+func withChat(payload []byte, chat string) ([]byte, error) {
+	body := map[string]json.RawMessage{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, queue.ErrInvalidPayload
+	}
+
+	to, err := json.Marshal(chat)
+	if err != nil {
+		return nil, err
+	}
+	body["to"] = to
+
+	return json.Marshal(body)
+}
+
+type queuedInput interface {
+	Validate() error
+}
+
+// decodeQueuedInput decodes the body of the send endpoint of the given kind
+func decodeQueuedInput(kind message.MessageKind, payload []byte) (queuedInput, error) {
+	var inp queuedInput
+
+	switch kind {
+	case message.MessageKindText:
+		inp = &input.SendTextMessageInput{}
+	case message.MessageKindImage:
+		inp = &input.SendImageMessageInput{}
+	case message.MessageKindVideo:
+		inp = &input.SendVideoMessageInput{}
+	case message.MessageKindAudio:
+		inp = &input.SendAudioMessageInput{}
+	case message.MessageKindVoice:
+		inp = &input.SendVoiceMessageInput{}
+	case message.MessageKindDocument:
+		inp = &input.SendDocumentMessageInput{}
+	case message.MessageKindButtons:
+		inp = &input.SendButtonsMessageInput{}
+	case message.MessageKindList:
+		inp = &input.SendListMessageInput{}
+	case message.MessageKindTemplate:
+		inp = &input.SendTemplateMessageInput{}
+	default:
+		return nil, queue.ErrUnsupportedKind
+	}
+
+	if err := json.Unmarshal(payload, inp); err != nil {
+		return nil, queue.ErrInvalidPayload
+	}
+
+	return inp, nil
+}
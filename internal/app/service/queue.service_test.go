@@ -0,0 +1,154 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/limiter"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/registry"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Queue Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	queueRepo := repository.NewQueueRepository(db)
+	instRegistry := registry.NewInMemoryInstanceRegistry()
+	gateway := fake.NewFakeWhatsAppGateway()
+	bus := fake.NewFakeEventBus()
+
+	sessionService := service.NewSessionService(instRepo, gateway, bus)
+	rateLimitService := service.NewRateLimitService(gateway, repository.NewRateLimitRepository(db), limiter.NewInMemoryLimiter(), ratelimit.Policy{})
+	messageService := service.NewMessageService(gateway, repository.NewMessageRepository(db), repository.NewReceiptRepository(db), repository.NewChatRepository(db), nil, nil, nil, rateLimitService, nil, nil, nil, 0)
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	var (
+		inst   *instance.Instance
+		queues *service.QueueService
+		cancel context.CancelFunc
+	)
+
+	enqueue := func(instanceID string) *queue.QueuedMessage {
+		queued := queue.New(instanceID, "123@s.whatsapp.net", message.MessageKindText, []byte(`{"to":"123@s.whatsapp.net","text":"hello"}`))
+		Expect(queueRepo.Insert(queued)).To(Succeed())
+		return queued
+	}
+
+	status := func(id string) func() queue.Status {
+		return func() queue.Status {
+			queued, err := queueRepo.Get(queue.WhereID(id))
+			Expect(err).ToNot(HaveOccurred())
+			return queued.Status
+		}
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		instRegistry.Clear()
+		gateway.Clear()
+		bus.Clear()
+		bus.ClearPublished()
+
+		inst = fake.InstanceFactory().Connected().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		// a single attempt, so a failed send leaves the queue right away
+		queues = service.NewQueueService(queueRepo, instRepo, instRegistry, sessionService, messageService, bus, 10*time.Millisecond, 1, 1)
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		go queues.Run(ctx)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should send a queued message", func() {
+		queued := enqueue(inst.ID)
+
+		Eventually(status(queued.ID)).Should(Equal(queue.StatusSent))
+		Expect(gateway.Sent()).To(HaveLen(1))
+		Eventually(func() bool { return bus.HasPublished(events.EventMessageSent) }).Should(BeTrue())
+	})
+
+	It("should fail the sends of a deleted instance instead of postponing them", func() {
+		cancel()
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		queues = service.NewQueueService(queueRepo, deletedInstances{instRepo}, instRegistry, sessionService, messageService, bus, 10*time.Millisecond, 1, 1)
+		go queues.Run(ctx)
+
+		queued := enqueue(inst.ID)
+
+		Eventually(status(queued.ID)).Should(Equal(queue.StatusFailed))
+		Expect(gateway.Sent()).To(BeEmpty())
+		Eventually(func() bool { return bus.HasPublished(events.EventMessageFailed) }).Should(BeTrue())
+	})
+
+	It("should fail the sends of a banned instance", func() {
+		inst.MarkPermanentlyBanned()
+		Expect(instRepo.Update(inst)).To(Succeed())
+
+		queued := enqueue(inst.ID)
+
+		Eventually(status(queued.ID)).Should(Equal(queue.StatusFailed))
+		Expect(gateway.Sent()).To(BeEmpty())
+	})
+
+	It("should fail a send after its last attempt", func() {
+		gateway.FailSends(errors.New("boom"))
+		queued := enqueue(inst.ID)
+
+		Eventually(status(queued.ID)).Should(Equal(queue.StatusFailed))
+
+		failed, err := queueRepo.Get(queue.WhereID(queued.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed.Attempts).To(Equal(1))
+	})
+
+	It("should postpone the sends of a disconnected instance without spending an attempt", func() {
+		gateway.FailSends(instance.ErrInstanceNotConnected)
+		queued := enqueue(inst.ID)
+
+		Eventually(func() time.Time {
+			postponed, err := queueRepo.Get(queue.WhereID(queued.ID))
+			Expect(err).ToNot(HaveOccurred())
+			return postponed.NextAttemptAt
+		}).Should(BeTemporally(">", time.Now().Add(queue.ReconnectDelay/2)))
+
+		postponed, err := queueRepo.Get(queue.WhereID(queued.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(postponed.Status).To(Equal(queue.StatusQueued))
+		Expect(postponed.Attempts).To(Equal(0))
+	})
+})
+
+// deletedInstances answers as the repository does once the instance is deleted
+type deletedInstances struct {
+	instance.InstanceRepository
+}
+
+func (deletedInstances) Get(opts ...instance.InstanceQueryOption) (*instance.Instance, error) {
+	return nil, instance.ErrInstanceNotFound
+}
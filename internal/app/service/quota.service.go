@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+)
+
+// QuotaService keeps each instance within its share of the storage and reports how much each one takes
+type QuotaService struct {
+	repo     quota.QuotaRepository
+	fileRepo file.FileRepository
+	instRepo instance.InstanceRepository
+	defaults quota.Quota
+}
+
+func NewQuotaService(repo quota.QuotaRepository, fileRepo file.FileRepository, instRepo instance.InstanceRepository, defaults quota.Quota) *QuotaService {
+	return &QuotaService{
+		repo:     repo,
+		fileRepo: fileRepo,
+		instRepo: instRepo,
+		defaults: defaults,
+	}
+}
+
+// GetQuota returns the quota of the instance, the defaults when it has none of its own
+func (s *QuotaService) GetQuota(ctx context.Context, instanceID string) (*quota.Quota, *app.AppError) {
+	q, err := s.repo.Get(instanceID)
+	if err != nil {
+		app.GetQuotaServiceLogger().Error("Error getting storage quota", "instance", instanceID, "error", err)
+		return nil, app.NewAppError("quota service", app.CodeDatabaseError, err)
+	}
+
+	if q == nil {
+		q = quota.NewQuota(instanceID, s.defaults)
+	}
+
+	return q, nil
+}
+
+func (s *QuotaService) UpdateQuota(ctx context.Context, instanceID string, inp input.UpdateQuotaInput) (*quota.Quota, *app.AppError) {
+	l := app.GetQuotaServiceLogger()
+
+	if _, err := s.instRepo.Get(instance.WhereID(instanceID)); err != nil {
+		if errors.Is(err, instance.ErrInstanceNotFound) {
+			return nil, app.TranslateError("quota service", err)
+		}
+		l.Error("Error getting instance", "instance", instanceID, "error", err)
+		return nil, app.NewAppError("quota service", app.CodeDatabaseError, err)
+	}
+
+	q, appErr := s.GetQuota(ctx, instanceID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if inp.MaxBytes != nil {
+		q.MaxBytes = *inp.MaxBytes
+	}
+	if inp.MaxFiles != nil {
+		q.MaxFiles = *inp.MaxFiles
+	}
+
+	q.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Save(q); err != nil {
+		l.Error("Error saving storage quota", "instance", instanceID, "error", err)
+		return nil, app.NewAppError("quota service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Storage quota updated", "instance", instanceID, "max_bytes", q.MaxBytes, "max_files", q.MaxFiles)
+	return q, nil
+}
+
+// Usage returns how much of the storage the instance takes next to its quota
+func (s *QuotaService) Usage(ctx context.Context, instanceID string) (*quota.Summary, *app.AppError) {
+	usage, err := s.fileRepo.Usage(instanceID)
+	if err != nil {
+		app.GetQuotaServiceLogger().Error("Error summing storage usage", "instance", instanceID, "error", err)
+		return nil, app.NewAppError("quota service", app.CodeDatabaseError, err)
+	}
+
+	q, appErr := s.GetQuota(ctx, instanceID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return &quota.Summary{Usage: usage, Quota: q}, nil
+}
+
+// UsageByInstance returns the usage of every instance, the ones without files included, and the total of all
+func (s *QuotaService) UsageByInstance(ctx context.Context) ([]*quota.Summary, *file.Usage, *app.AppError) {
+	l := app.GetQuotaServiceLogger()
+
+	instances, err := s.instRepo.List()
+	if err != nil {
+		l.Error("Error listing instances", "error", err)
+		return nil, nil, app.NewAppError("quota service", app.CodeDatabaseError, err)
+	}
+
+	usages, err := s.fileRepo.UsageByInstance()
+	if err != nil {
+		l.Error("Error summing storage usage", "error", err)
+		return nil, nil, app.NewAppError("quota service", app.CodeDatabaseError, err)
+	}
+
+	byInstance := make(map[string]*file.Usage, len(usages))
+	for _, u := range usages {
+		byInstance[u.InstanceID] = u
+	}
+
+	total := &file.Usage{}
+	summaries := make([]*quota.Summary, 0, len(instances))
+	for _, inst := range instances {
+		usage, ok := byInstance[inst.ID]
+		if !ok {
+			usage = &file.Usage{InstanceID: inst.ID}
+		}
+
+		q, appErr := s.GetQuota(ctx, inst.ID)
+		if appErr != nil {
+			return nil, nil, appErr
+		}
+
+		total.Files += usage.Files
+		total.Bytes += usage.Bytes
+		summaries = append(summaries, &quota.Summary{Usage: usage, Quota: q})
+	}
+
+	return summaries, total, nil
+}
+
+// Check tells whether the instance can store one more file of the size, the size may be zero when it is not
+// known yet
+func (s *QuotaService) Check(ctx context.Context, instanceID string, size uint64) *app.AppError {
+	summary, appErr := s.Usage(ctx, instanceID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := summary.Quota.Allows(summary.Usage, size); err != nil {
+		app.GetQuotaServiceLogger().Warn("Storage quota exceeded", "instance", instanceID, "files", summary.Usage.Files, "bytes", summary.Usage.Bytes, "size", size)
+		return app.TranslateError("quota service", err)
+	}
+
+	return nil
+}
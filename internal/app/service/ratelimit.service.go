@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+)
+
+// RateLimitService paces the sends of each instance so bulk sends don't look automated
+type RateLimitService struct {
+	whatsapp whatsapp.WhatsAppGateway
+	repo     ratelimit.PolicyRepository
+	limiter  ratelimit.Limiter
+	defaults ratelimit.Policy
+}
+
+func NewRateLimitService(whatsapp whatsapp.WhatsAppGateway, repo ratelimit.PolicyRepository, limiter ratelimit.Limiter, defaults ratelimit.Policy) *RateLimitService {
+	return &RateLimitService{
+		whatsapp: whatsapp,
+		repo:     repo,
+		limiter:  limiter,
+		defaults: defaults,
+	}
+}
+
+// Get returns the policy of the instance, the defaults when it never changed it
+func (s *RateLimitService) Get(ctx context.Context, inst *instance.Instance) (*ratelimit.Policy, *app.AppError) {
+	policy, err := s.repo.Get(inst.ID)
+	if err != nil {
+		app.GetRateLimitServiceLogger().Error("Error getting rate limit", "instance", inst.ID, "error", err)
+		return nil, app.NewAppError("rate limit service", app.CodeDatabaseError, err)
+	}
+
+	if policy == nil {
+		policy = ratelimit.NewPolicy(inst.ID, s.defaults)
+	}
+
+	return policy, nil
+}
+
+func (s *RateLimitService) Update(ctx context.Context, inst *instance.Instance, inp input.UpdateRateLimitInput) (*ratelimit.Policy, *app.AppError) {
+	l := app.GetRateLimitServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("rate limit service", err)
+	}
+
+	policy, appErr := s.Get(ctx, inst)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if inp.PerSecond != nil {
+		policy.PerSecond = *inp.PerSecond
+	}
+	if inp.PerMinute != nil {
+		policy.PerMinute = *inp.PerMinute
+	}
+	if inp.RecipientCooldownMs != nil {
+		policy.RecipientCooldownMs = *inp.RecipientCooldownMs
+	}
+	if inp.MinDelayMs != nil {
+		policy.MinDelayMs = *inp.MinDelayMs
+	}
+	if inp.MaxDelayMs != nil {
+		policy.MaxDelayMs = *inp.MaxDelayMs
+	}
+	if inp.Typing != nil {
+		policy.Typing = *inp.Typing
+	}
+	if inp.OnLimit != nil {
+		policy.OnLimit = *inp.OnLimit
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, app.TranslateError("rate limit service", err)
+	}
+
+	policy.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Save(policy); err != nil {
+		l.Error("Error saving rate limit", "instance", inst.ID, "error", err)
+		return nil, app.NewAppError("rate limit service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Rate limit updated", "instance", inst.ID, "per_second", policy.PerSecond, "per_minute", policy.PerMinute)
+	return policy, nil
+}
+
+// Pace reserves a send of the instance to the chat, then waits the human like delay of the policy showing
+// the presence in the chat (none when empty), when a limit is hit it returns right away with a rate limit error
+func (s *RateLimitService) Pace(ctx context.Context, inst *instance.Instance, to string, presence chat.ChatPresenceType) *app.AppError {
+	l := app.GetRateLimitServiceLogger()
+
+	policy, appErr := s.Get(ctx, inst)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := s.limiter.Take(policy, to); err != nil {
+		code := app.CodeRateLimited
+		if errors.Is(err, ratelimit.ErrRecipientCooldown) {
+			code = app.CodeRecipientCooldown
+		}
+
+		l.Warn("Send limited", "instance", inst.ID, "chat", to, "error", err)
+		return app.NewAppError("rate limit service", code, err)
+	}
+
+	delay := policy.Delay()
+	if delay <= 0 {
+		return nil
+	}
+
+	if policy.Typing && presence != "" {
+		s.presence(ctx, inst, to, presence)
+		defer s.presence(ctx, inst, to, chat.ChatPresencePaused)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return app.TranslateError("rate limit service", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// QueuesOnLimit tells whether a limited send of the instance should be queued instead of failing
+func (s *RateLimitService) QueuesOnLimit(ctx context.Context, inst *instance.Instance) bool {
+	policy, appErr := s.Get(ctx, inst)
+	return appErr == nil && policy.QueuesOnLimit()
+}
+
+// RetryAfter returns how long a limited send has to wait, zero when the error is not a rate limit
+func RetryAfter(err error) time.Duration {
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr.RetryAfter
+	}
+	return 0
+}
+
+// presence is best effort, a send is never held back because the typing indicator failed
+func (s *RateLimitService) presence(ctx context.Context, inst *instance.Instance, to string, presence chat.ChatPresenceType) {
+	if err := s.whatsapp.SendChatPresence(ctx, inst, chat.Presence{To: to, Type: presence}); err != nil {
+		app.GetRateLimitServiceLogger().Debug("Error sending presence before send", "instance", inst.ID, "chat", to, "error", err)
+	}
+}
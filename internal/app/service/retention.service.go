@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/retention"
+)
+
+const retentionOrphanBatch = 100
+
+// RetentionService deletes the uploads the retention rule of each instance no longer keeps, along with the
+// thumbnails no file uses and, when orphanBlobs is set, the stored objects no file points to
+type RetentionService struct {
+	repo        retention.RuleRepository
+	instRepo    instance.InstanceRepository
+	fileRepo    file.FileRepository
+	storage     storage.Storage
+	bus         events.EventBus
+	defaults    retention.Rule
+	interval    time.Duration
+	orphanAge   time.Duration
+	orphanBlobs bool
+}
+
+func NewRetentionService(repo retention.RuleRepository, instRepo instance.InstanceRepository, fileRepo file.FileRepository, storage storage.Storage, bus events.EventBus, defaults retention.Rule, interval, orphanAge time.Duration, orphanBlobs bool) *RetentionService {
+	return &RetentionService{
+		repo:        repo,
+		instRepo:    instRepo,
+		fileRepo:    fileRepo,
+		storage:     storage,
+		bus:         bus,
+		defaults:    defaults,
+		interval:    interval,
+		orphanAge:   orphanAge,
+		orphanBlobs: orphanBlobs,
+	}
+}
+
+// GetRule returns the rule of the instance, the defaults when it never changed it
+func (s *RetentionService) GetRule(ctx context.Context, inst *instance.Instance) (*retention.Rule, *app.AppError) {
+	rule, err := s.repo.Get(inst.ID)
+	if err != nil {
+		app.GetRetentionServiceLogger().Error("Error getting retention rule", "instance", inst.ID, "error", err)
+		return nil, app.NewAppError("retention service", app.CodeDatabaseError, err)
+	}
+
+	if rule == nil {
+		rule = retention.NewRule(inst.ID, s.defaults)
+	}
+
+	return rule, nil
+}
+
+func (s *RetentionService) UpdateRule(ctx context.Context, inst *instance.Instance, inp input.UpdateRetentionRuleInput) (*retention.Rule, *app.AppError) {
+	l := app.GetRetentionServiceLogger()
+
+	rule, appErr := s.GetRule(ctx, inst)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if inp.Enabled != nil {
+		rule.Enabled = *inp.Enabled
+	}
+	if inp.MaxAge != nil {
+		rule.MaxAge = *inp.MaxAge
+	}
+	if inp.MaxBytes != nil {
+		rule.MaxBytes = *inp.MaxBytes
+	}
+	if inp.MaxCount != nil {
+		rule.MaxCount = *inp.MaxCount
+	}
+	if inp.KeepPinned != nil {
+		rule.KeepPinned = *inp.KeepPinned
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, app.TranslateError("retention service", err)
+	}
+
+	rule.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Save(rule); err != nil {
+		l.Error("Error saving retention rule", "instance", inst.ID, "error", err)
+		return nil, app.NewAppError("retention service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Retention rule updated", "instance", inst.ID, "enabled", rule.Enabled, "max_age", rule.MaxAge, "max_bytes", rule.MaxBytes, "max_count", rule.MaxCount)
+	return rule, nil
+}
+
+func (s *RetentionService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sweep(ctx, false)
+		}
+	}
+}
+
+// Sweep deletes the files expired by the rule of every instance, then the orphan thumbnails and, when enabled, the
+// orphan blobs, a dry run only reports what would go
+func (s *RetentionService) Sweep(ctx context.Context, dryRun bool) (*retention.Report, *app.AppError) {
+	l := app.GetRetentionServiceLogger()
+
+	if s.storage == nil {
+		return nil, app.NewAppError("retention service", app.GLOBAL_STORAGE_UNAVAILABLE, storage.ErrStorageNotConfigured)
+	}
+
+	report := retention.NewReport(dryRun)
+
+	instances, err := s.instRepo.List()
+	if err != nil {
+		l.Error("Error listing instances", "error", err)
+		return nil, app.NewAppError("retention service", app.CodeDatabaseError, err)
+	}
+
+	for _, inst := range instances {
+		if appErr := s.sweepInstance(ctx, inst, report); appErr != nil {
+			return nil, appErr
+		}
+	}
+
+	if appErr := s.sweepThumbnails(ctx, report); appErr != nil {
+		return nil, appErr
+	}
+
+	if s.orphanBlobs {
+		if appErr := s.sweepBlobs(ctx, report); appErr != nil {
+			return nil, appErr
+		}
+	}
+
+	report.Finish()
+
+	if len(report.Items) > 0 {
+		l.Info("Retention sweep finished", "dry_run", dryRun, "files", report.Files, "blobs", report.Blobs, "bytes", report.Bytes)
+	}
+
+	return report, nil
+}
+
+func (s *RetentionService) sweepInstance(ctx context.Context, inst *instance.Instance, report *retention.Report) *app.AppError {
+	l := app.GetRetentionServiceLogger()
+
+	rule, appErr := s.GetRule(ctx, inst)
+	if appErr != nil {
+		return appErr
+	}
+
+	if !rule.Enabled {
+		return nil
+	}
+
+	// thumbnails go with the files that use them, as orphans
+	files, err := s.fileRepo.List(file.WhereInstanceID(inst.ID), file.WhereIsThumbnail(false))
+	if err != nil {
+		l.Error("Error listing files", "instance", inst.ID, "error", err)
+		return app.NewAppError("retention service", app.CodeDatabaseError, err)
+	}
+
+	byID := make(map[string]*file.File, len(files))
+	for _, f := range files {
+		byID[f.ID] = f
+	}
+
+	for _, item := range rule.Expired(files, time.Now().UTC()) {
+		if report.DryRun || s.delete(ctx, byID[item.FileID]) {
+			report.Add(item)
+		}
+	}
+
+	return nil
+}
+
+func (s *RetentionService) sweepThumbnails(ctx context.Context, report *retention.Report) *app.AppError {
+	l := app.GetRetentionServiceLogger()
+
+	// thumbnails of the files deleted above are orphans too, a dry run cannot see them yet
+	thumbnails, err := s.fileRepo.OrphanThumbnails(time.Now().Add(-s.orphanAge), retentionOrphanBatch)
+	if err != nil {
+		l.Error("Error listing orphan thumbnails", "error", err)
+		return app.NewAppError("retention service", app.CodeDatabaseError, err)
+	}
+
+	for _, f := range thumbnails {
+		if report.DryRun || s.delete(ctx, f) {
+			report.Add(retention.NewFileItem(f, retention.ReasonOrphanThumbnail))
+		}
+	}
+
+	return nil
+}
+
+// sweepBlobs deletes the stored objects without a file, only the keys named like the files of this service are
+// looked at, so the hidden keys of unfinished uploads and whatever else shares the storage are left alone, and
+// objects younger than the orphan age may still get a file
+func (s *RetentionService) sweepBlobs(ctx context.Context, report *retention.Report) *app.AppError {
+	l := app.GetRetentionServiceLogger()
+
+	listable := storage.Listable(s.storage)
+	if listable == nil {
+		return nil
+	}
+
+	before := time.Now().Add(-s.orphanAge)
+
+	err := listable.Walk(ctx, func(o storage.Object) error {
+		if !file.IsStoragePath(o.Key) || o.ModifiedAt.After(before) {
+			return nil
+		}
+
+		count, err := s.fileRepo.Count(file.WherePath(o.Key))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if !report.DryRun {
+			if err := s.storage.Delete(ctx, o.Key); err != nil {
+				l.Error("Error deleting orphan blob from storage", "path", o.Key, "error", err)
+				return nil
+			}
+		}
+
+		report.Add(retention.Item{Path: o.Key, Size: o.Size, Reason: retention.ReasonOrphanBlob})
+		return nil
+	})
+	if err != nil {
+		l.Error("Error walking storage", "error", err)
+		return app.NewAppError("retention service", app.CodeFileStorageFailed, err)
+	}
+
+	return nil
+}
+
+// delete releases the references the file had when it was listed and removes it with its stored object once none
+// is left, an upload of the same content may have referenced it since and keeps it, a file whose object cannot be
+// removed is left to the orphan blob sweep
+func (s *RetentionService) delete(ctx context.Context, f *file.File) bool {
+	l := app.GetRetentionServiceLogger()
+
+	for range f.References {
+		if _, err := s.fileRepo.Release(f.ID); err != nil {
+			l.Error("Error releasing file reference in database", "file", f.ID, "error", err)
+			return false
+		}
+	}
+
+	deleted, err := s.fileRepo.DeleteReleased(f.ID)
+	if err != nil {
+		l.Error("Error deleting file from database", "file", f.ID, "error", err)
+		return false
+	}
+
+	if !deleted {
+		l.Debug("File was referenced again, keeping it", "file", f.ID)
+		return false
+	}
+
+	if err := s.storage.Delete(ctx, f.Path); err != nil {
+		l.Warn("Error deleting file from storage", "file", f.ID, "path", f.Path, "error", err)
+	}
+
+	l.Debug("File deleted by retention", "file", f.ID, "size", f.Size)
+
+	go s.bus.Publish(f.EventDeleted(f.InstanceID))
+
+	return true
+}
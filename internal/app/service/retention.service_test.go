@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/retention"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	infrastorage "github.com/mauriciorobertodev/whappy-go/internal/infra/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Retention Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	fileRepo := repository.NewFileRepository(db)
+	ruleRepo := repository.NewRetentionRuleRepository(db)
+	bus := fake.NewFakeEventBus()
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	var (
+		inst    *instance.Instance
+		dir     string
+		store   *infrastorage.LocalStorage
+		uploads *service.UploadService
+		ctx     = context.Background()
+	)
+
+	retentionOf := func(defaults retention.Rule, orphanBlobs bool) *service.RetentionService {
+		return service.NewRetentionService(ruleRepo, instRepo, fileRepo, store, bus, defaults, time.Hour, time.Hour, orphanBlobs)
+	}
+
+	upload := func(content string) *file.File {
+		f, appErr := uploads.UploadWithStream(ctx, inst, input.UploadFile{Stream: strings.NewReader(content)})
+		Expect(appErr).To(BeNil())
+		return f
+	}
+
+	// put writes an object straight to the storage, as old as given
+	put := func(key string, age time.Duration) {
+		Expect(store.Put(ctx, key, []byte("orphan"))).To(Succeed())
+		at := time.Now().Add(-age)
+		Expect(os.Chtimes(filepath.Join(dir, key), at, at)).To(Succeed())
+	}
+
+	exists := func(key string) bool {
+		found, err := store.Exists(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+		return found
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		bus.Clear()
+		bus.ClearPublished()
+
+		inst = fake.InstanceFactory().Connected().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		dir = GinkgoT().TempDir()
+		store = infrastorage.NewLocalStorage(&config.StorageConfig{Driver: config.StorageDriverLocal, Path: dir, URL: "http://localhost/storage"})
+		fileService := service.NewFileService(store, fileRepo)
+		quotaService := service.NewQuotaService(repository.NewQuotaRepository(db), fileRepo, instRepo, quota.Quota{})
+		uploads = service.NewUploadService(fileService, nil, fileRepo, store, quotaService, bus)
+	})
+
+	It("should leave the stored objects without a file alone unless the orphan sweep is enabled", func() {
+		orphan := file.NewFromMime("image/png").Path
+		put(orphan, 2*time.Hour)
+
+		report, appErr := retentionOf(retention.Rule{}, false).Sweep(ctx, false)
+		Expect(appErr).To(BeNil())
+		Expect(report.Blobs).To(BeZero())
+		Expect(exists(orphan)).To(BeTrue())
+	})
+
+	It("should only delete old orphans named like the files of the service", func() {
+		kept := upload("a stored file")
+		Expect(os.Chtimes(filepath.Join(dir, kept.Path), time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour))).To(Succeed())
+
+		orphan := file.NewFromMime("image/png").Path
+		young := file.NewFromMime("image/png").Path
+		put(orphan, 2*time.Hour)
+		put(young, time.Minute)
+		put("backups/whappy.sql", 2*time.Hour)
+		put("notes.txt", 2*time.Hour)
+
+		report, appErr := retentionOf(retention.Rule{}, true).Sweep(ctx, false)
+		Expect(appErr).To(BeNil())
+		Expect(report.Blobs).To(Equal(uint64(1)))
+		Expect(report.Items[0].Path).To(Equal(orphan))
+
+		Expect(exists(orphan)).To(BeFalse())
+		Expect(exists(young)).To(BeTrue())
+		Expect(exists(kept.Path)).To(BeTrue())
+		Expect(exists("backups/whappy.sql")).To(BeTrue())
+		Expect(exists("notes.txt")).To(BeTrue())
+	})
+
+	It("should release every reference of an expired file before deleting it", func() {
+		shared := upload("the same content")
+		upload("the same content")
+		time.Sleep(10 * time.Millisecond)
+		newest := upload("the newest content")
+
+		report, appErr := retentionOf(retention.Rule{Enabled: true, MaxCount: 1}, false).Sweep(ctx, false)
+		Expect(appErr).To(BeNil())
+		Expect(report.Files).To(Equal(uint64(1)))
+
+		_, err := fileRepo.Get(file.WhereID(shared.ID))
+		Expect(err).To(MatchError(file.ErrFileNotFound))
+		Expect(exists(shared.Path)).To(BeFalse())
+
+		_, err = fileRepo.Get(file.WhereID(newest.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists(newest.Path)).To(BeTrue())
+	})
+})
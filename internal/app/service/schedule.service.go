@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+)
+
+// ScheduleService stores messages to be sent at a given moment, they are delivered by the queue worker once
+// due, so the schedule survives restarts and is shared by every replica
+type ScheduleService struct {
+	queueRepo queue.QueueRepository
+	eventbus  events.EventBus
+}
+
+func NewScheduleService(queueRepo queue.QueueRepository, eventbus events.EventBus) *ScheduleService {
+	return &ScheduleService{
+		queueRepo: queueRepo,
+		eventbus:  eventbus,
+	}
+}
+
+func (s *ScheduleService) Schedule(ctx context.Context, inst *instance.Instance, inp input.ScheduleMessageInput) (*queue.QueuedMessage, *app.AppError) {
+	l := app.GetScheduleServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("schedule service", err)
+	}
+
+	if err := validateQueuedPayload(inp.Type, inp.Message); err != nil {
+		return nil, app.TranslateError("schedule service", err)
+	}
+
+	at, err := queue.ParseSendAt(inp.SendAt, inp.Timezone)
+	if err != nil {
+		return nil, app.TranslateError("schedule service", err)
+	}
+
+	scheduled := queue.NewScheduled(inst.ID, queuedChat(inp.Message), inp.Type, inp.Message, at, inp.Timezone)
+	if err := s.queueRepo.Insert(scheduled); err != nil {
+		l.Error("Error scheduling message", "error", err)
+		return nil, app.NewAppError("schedule service", app.CodeDatabaseError, err)
+	}
+
+	l.Debug("Message scheduled", "instance", inst.ID, "id", scheduled.ID, "kind", inp.Type, "chat", scheduled.Chat, "at", at)
+	s.eventbus.Publish(scheduled.EventQueued())
+
+	return scheduled, nil
+}
+
+func (s *ScheduleService) Get(ctx context.Context, inst *instance.Instance, id string) (*queue.QueuedMessage, *app.AppError) {
+	scheduled, err := s.queueRepo.Get(queue.WhereInstanceID(inst.ID), queue.WhereID(id), queue.WhereScheduled(true))
+	if err != nil {
+		app.GetScheduleServiceLogger().Error("Error getting scheduled message", "id", id, "error", err)
+		return nil, app.NewAppError("schedule service", app.CodeDatabaseError, err)
+	}
+
+	if scheduled == nil {
+		return nil, app.TranslateError("schedule service", queue.ErrScheduledMessageNotFound)
+	}
+
+	return scheduled, nil
+}
+
+// List returns the scheduled messages of the instance not sent yet, the next to be sent first
+func (s *ScheduleService) List(ctx context.Context, inst *instance.Instance) ([]*queue.QueuedMessage, *app.AppError) {
+	scheduled, err := s.queueRepo.List(queue.WhereInstanceID(inst.ID), queue.WhereStatus(queue.StatusQueued), queue.WhereScheduled(true))
+	if err != nil {
+		app.GetScheduleServiceLogger().Error("Error listing scheduled messages", "error", err)
+		return nil, app.NewAppError("schedule service", app.CodeDatabaseError, err)
+	}
+
+	return scheduled, nil
+}
+
+// Update changes the moment or the body of a scheduled message while it is still waiting
+func (s *ScheduleService) Update(ctx context.Context, inst *instance.Instance, id string, inp input.UpdateScheduledMessageInput) (*queue.QueuedMessage, *app.AppError) {
+	l := app.GetScheduleServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("schedule service", err)
+	}
+
+	scheduled, appErr := s.Get(ctx, inst, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if len(inp.Message) > 0 {
+		if err := validateQueuedPayload(scheduled.Kind, inp.Message); err != nil {
+			return nil, app.TranslateError("schedule service", err)
+		}
+
+		if err := scheduled.Replace(queuedChat(inp.Message), inp.Message); err != nil {
+			return nil, app.TranslateError("schedule service", err)
+		}
+	}
+
+	if inp.SendAt != nil {
+		timezone := *scheduled.Timezone
+		if inp.Timezone != nil {
+			timezone = *inp.Timezone
+		}
+
+		at, err := queue.ParseSendAt(*inp.SendAt, timezone)
+		if err != nil {
+			return nil, app.TranslateError("schedule service", err)
+		}
+
+		if err := queue.ValidateSendAt(at); err != nil {
+			return nil, app.TranslateError("schedule service", err)
+		}
+
+		if err := scheduled.Reschedule(at, timezone); err != nil {
+			return nil, app.TranslateError("schedule service", err)
+		}
+	}
+
+	// the worker may have claimed it in the meantime
+	updated, err := s.queueRepo.UpdateFrom(scheduled, queue.StatusQueued)
+	if err != nil {
+		l.Error("Error updating scheduled message", "id", id, "error", err)
+		return nil, app.NewAppError("schedule service", app.CodeDatabaseError, err)
+	}
+
+	if !updated {
+		return nil, app.TranslateError("schedule service", queue.ErrNotEditable)
+	}
+
+	l.Info("Scheduled message updated", "instance", inst.ID, "id", id, "at", scheduled.ScheduledAt)
+	return scheduled, nil
+}
+
+func (s *ScheduleService) Cancel(ctx context.Context, inst *instance.Instance, id string) (*queue.QueuedMessage, *app.AppError) {
+	l := app.GetScheduleServiceLogger()
+
+	scheduled, appErr := s.Get(ctx, inst, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if err := scheduled.Cancel(); err != nil {
+		return nil, app.TranslateError("schedule service", err)
+	}
+
+	canceled, err := s.queueRepo.UpdateFrom(scheduled, queue.StatusQueued)
+	if err != nil {
+		l.Error("Error canceling scheduled message", "id", id, "error", err)
+		return nil, app.NewAppError("schedule service", app.CodeDatabaseError, err)
+	}
+
+	if !canceled {
+		return nil, app.TranslateError("schedule service", queue.ErrNotCancelable)
+	}
+
+	l.Info("Scheduled message canceled", "instance", inst.ID, "id", id)
+	return scheduled, nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/template"
+)
+
+type TemplateService struct {
+	templateRepo   template.TemplateRepository
+	messageService *MessageService
+}
+
+func NewTemplateService(templateRepo template.TemplateRepository, messageService *MessageService) *TemplateService {
+	return &TemplateService{
+		templateRepo:   templateRepo,
+		messageService: messageService,
+	}
+}
+
+func (s *TemplateService) Create(ctx context.Context, inst *instance.Instance, inp input.CreateTemplateInput) (*template.Template, *app.AppError) {
+	l := app.GetTemplateServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("template service", err)
+	}
+
+	t := template.New(inst.ID, inp.Name, inp.Type, inp.Body, inp.Media)
+	if err := t.Validate(); err != nil {
+		return nil, translateTemplateError(err)
+	}
+
+	if appErr := s.checkName(inst, t); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.templateRepo.Insert(t); err != nil {
+		l.Error("Error creating template", "error", err)
+		return nil, app.NewAppError("template service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Template created", "instance", inst.ID, "template", t.ID, "name", t.Name)
+	return t, nil
+}
+
+func (s *TemplateService) Get(ctx context.Context, inst *instance.Instance, id string) (*template.Template, *app.AppError) {
+	t, err := s.templateRepo.Get(template.WhereInstanceID(inst.ID), template.WhereID(id))
+	if err != nil {
+		app.GetTemplateServiceLogger().Error("Error getting template", "id", id, "error", err)
+		return nil, app.NewAppError("template service", app.CodeDatabaseError, err)
+	}
+
+	if t == nil {
+		return nil, app.TranslateError("template service", template.ErrTemplateNotFound)
+	}
+
+	return t, nil
+}
+
+func (s *TemplateService) List(ctx context.Context, inst *instance.Instance) ([]*template.Template, *app.AppError) {
+	templates, err := s.templateRepo.List(template.WhereInstanceID(inst.ID))
+	if err != nil {
+		app.GetTemplateServiceLogger().Error("Error listing templates", "error", err)
+		return nil, app.NewAppError("template service", app.CodeDatabaseError, err)
+	}
+
+	return templates, nil
+}
+
+func (s *TemplateService) Update(ctx context.Context, inst *instance.Instance, id string, inp input.UpdateTemplateInput) (*template.Template, *app.AppError) {
+	l := app.GetTemplateServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("template service", err)
+	}
+
+	t, appErr := s.Get(ctx, inst, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	t.Update(inp.Name, inp.Body, inp.Media)
+	if err := t.Validate(); err != nil {
+		return nil, translateTemplateError(err)
+	}
+
+	if appErr := s.checkName(inst, t); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.templateRepo.Update(t); err != nil {
+		l.Error("Error updating template", "id", id, "error", err)
+		return nil, app.NewAppError("template service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Template updated", "instance", inst.ID, "template", t.ID)
+	return t, nil
+}
+
+func (s *TemplateService) Delete(ctx context.Context, inst *instance.Instance, id string) *app.AppError {
+	l := app.GetTemplateServiceLogger()
+
+	if _, appErr := s.Get(ctx, inst, id); appErr != nil {
+		return appErr
+	}
+
+	if err := s.templateRepo.Delete(template.WhereInstanceID(inst.ID), template.WhereID(id)); err != nil {
+		l.Error("Error deleting template", "id", id, "error", err)
+		return app.NewAppError("template service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Template deleted", "instance", inst.ID, "template", id)
+	return nil
+}
+
+// Render fills the template with the variables without sending it, to preview the result
+func (s *TemplateService) Render(ctx context.Context, inst *instance.Instance, id string, inp input.RenderTemplateInput) (*template.Rendered, *app.AppError) {
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("template service", err)
+	}
+
+	t, appErr := s.Get(ctx, inst, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	rendered, err := t.Render(inp.Variables)
+	if err != nil {
+		return nil, translateTemplateError(err)
+	}
+
+	return rendered, nil
+}
+
+// Send renders the template for the recipient and sends it as a message of the kind of the template
+func (s *TemplateService) Send(ctx context.Context, inst *instance.Instance, id string, inp input.SendFromTemplateInput) (*message.Message, *app.AppError) {
+	l := app.GetTemplateServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("template service", err)
+	}
+
+	t, appErr := s.Get(ctx, inst, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	rendered, err := t.Render(inp.Variables)
+	if err != nil {
+		return nil, translateTemplateError(err)
+	}
+
+	l.Debug("Sending template", "instance", inst.ID, "template", t.ID, "kind", t.Kind, "to", inp.To)
+
+	var caption *string
+	if rendered.Body != "" {
+		caption = &rendered.Body
+	}
+
+	var msg *message.Message
+
+	switch t.Kind {
+	case message.MessageKindText:
+		return s.messageService.SendTextMessage(ctx, inst, input.SendTextMessageInput{
+			To:         inp.To,
+			Text:       rendered.Body,
+			Mentions:   inp.Mentions,
+			Expiration: inp.Expiration,
+			ReplyTo:    inp.ReplyTo,
+		})
+	case message.MessageKindImage:
+		msg, err = s.messageService.SendImageMessage(ctx, inst, input.SendImageMessageInput{
+			To:         inp.To,
+			Image:      *rendered.Media,
+			Caption:    caption,
+			Mentions:   inp.Mentions,
+			Expiration: inp.Expiration,
+			ReplyTo:    inp.ReplyTo,
+		})
+	case message.MessageKindVideo:
+		msg, err = s.messageService.SendVideoMessage(ctx, inst, input.SendVideoMessageInput{
+			To:         inp.To,
+			Video:      *rendered.Media,
+			Caption:    caption,
+			Mentions:   inp.Mentions,
+			Expiration: inp.Expiration,
+			ReplyTo:    inp.ReplyTo,
+		})
+	case message.MessageKindDocument:
+		msg, err = s.messageService.SendDocumentMessage(ctx, inst, input.SendDocumentMessageInput{
+			To:         inp.To,
+			Document:   *rendered.Media,
+			Caption:    caption,
+			Mentions:   inp.Mentions,
+			Expiration: inp.Expiration,
+			ReplyTo:    inp.ReplyTo,
+		})
+	default:
+		return nil, app.TranslateError("template service", template.ErrUnsupportedKind)
+	}
+
+	if err != nil {
+		return nil, app.TranslateError("template service", err)
+	}
+
+	return msg, nil
+}
+
+// checkName makes sure no other template of the instance uses the name
+func (s *TemplateService) checkName(inst *instance.Instance, t *template.Template) *app.AppError {
+	other, err := s.templateRepo.Get(template.WhereInstanceID(inst.ID), template.WhereName(t.Name))
+	if err != nil {
+		app.GetTemplateServiceLogger().Error("Error getting template by name", "name", t.Name, "error", err)
+		return app.NewAppError("template service", app.CodeDatabaseError, err)
+	}
+
+	if other != nil && other.ID != t.ID {
+		return app.TranslateError("template service", template.ErrNameTaken)
+	}
+
+	return nil
+}
+
+// translateTemplateError keeps the detail of syntax and missing variable errors, which carry the place or the
+// name of the variable
+func translateTemplateError(err error) *app.AppError {
+	switch {
+	case errors.Is(err, template.ErrInvalidSyntax):
+		return app.NewAppError("template service", app.CodeInvalidTemplateSyntax, err)
+	case errors.Is(err, template.ErrMissingVariable):
+		return app.NewAppError("template service", app.CodeMissingTemplateVariable, err)
+	default:
+		return app.TranslateError("template service", err)
+	}
+}
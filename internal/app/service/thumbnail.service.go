@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/cache"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	c "github.com/mauriciorobertodev/whappy-go/internal/infra/cache"
+)
+
+// ThumbnailService generates the previews of media sent or uploaded without one, images are scaled down,
+// videos use a frame and pdfs their first page, the last two only when the transcoder is available
+type ThumbnailService struct {
+	transcodeService *TranscodeService
+	fileService      *FileService
+	fileRepo         file.FileRepository
+	storage          storage.Storage
+	cache            cache.Cache
+	cacheTTL         time.Duration
+}
+
+func NewThumbnailService(transcodeService *TranscodeService, fileService *FileService, fileRepo file.FileRepository, storage storage.Storage, cache cache.Cache, cacheTTL time.Duration) *ThumbnailService {
+	return &ThumbnailService{
+		transcodeService: transcodeService,
+		fileService:      fileService,
+		fileRepo:         fileRepo,
+		storage:          storage,
+		cache:            cache,
+		cacheTTL:         cacheTTL,
+	}
+}
+
+// Supports tells whether a thumbnail can be generated for the mime, so callers only read media that needs it
+func (s *ThumbnailService) Supports(mime string) bool {
+	if s == nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mime, "image/"):
+		return true
+	case strings.HasPrefix(mime, "video/"), strings.HasPrefix(mime, "application/pdf"):
+		return s.transcodeService.Enabled()
+	}
+
+	return false
+}
+
+// Generate returns the jpeg thumbnail of the media and its dimensions
+func (s *ThumbnailService) Generate(ctx context.Context, mime string, data []byte) ([]byte, *uint32, *uint32, error) {
+	if !s.Supports(mime) {
+		return nil, nil, nil, file.ErrThumbnailUnsupported
+	}
+
+	image := data
+
+	var err error
+	switch {
+	case strings.HasPrefix(mime, "video/"):
+		image, err = s.transcodeService.Frame(ctx, data)
+	case strings.HasPrefix(mime, "application/pdf"):
+		image, err = s.transcodeService.Page(ctx, data)
+	}
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return file.GenerateThumbnail(image, file.ThumbnailMaxSize)
+}
+
+// ForSource returns the base64 thumbnail of a media being sent, it is cached by the source like the
+// thumbnails given by the caller, so the media is only read again when the cache expires
+func (s *ThumbnailService) ForSource(ctx context.Context, source, mime string, data []byte, useCache bool) *string {
+	l := app.GetThumbnailServiceLogger()
+
+	cacheKey := s.cacheKey(source)
+	if useCache {
+		if thumbnail := s.Cached(source); thumbnail != nil {
+			return thumbnail
+		}
+	}
+
+	thumbnail, _, _, err := s.Generate(ctx, mime, data)
+	if err != nil {
+		l.Warn("Error generating thumbnail", "mime", mime, "error", err)
+		return nil
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(thumbnail)
+	if useCache && s.cache != nil {
+		l.Debug("Caching generated thumbnail", "cacheKey", cacheKey)
+		c.Set(s.cache, cacheKey, encoded, s.cacheTTL)
+	}
+
+	return &encoded
+}
+
+// Cached returns the thumbnail generated before for the source, nil when there is none
+func (s *ThumbnailService) Cached(source string) *string {
+	if s == nil || s.cache == nil {
+		return nil
+	}
+
+	thumbnail, err := c.Get[string](s.cache, s.cacheKey(source))
+	if err != nil || thumbnail == "" {
+		return nil
+	}
+
+	return &thumbnail
+}
+
+// Link stores the thumbnail of an upload as a file of its own and links it, the upload must be saved
+// afterwards for the link to persist
+func (s *ThumbnailService) Link(ctx context.Context, f *file.File, data []byte) error {
+	l := app.GetThumbnailServiceLogger()
+
+	if s.storage == nil {
+		return storage.ErrStorageNotConfigured
+	}
+
+	thumbnail, width, height, err := s.Generate(ctx, f.Mime, data)
+	if err != nil {
+		return err
+	}
+
+	mime := "image/jpeg"
+	thumbFile, err := s.fileService.SaveFromBytes(ctx, thumbnail, &mime)
+	if err != nil {
+		l.Error("Error saving thumbnail to storage", "file", f.ID, "error", err)
+		return err
+	}
+
+	thumbFile.Width = width
+	thumbFile.Height = height
+	thumbFile.InstanceID = f.InstanceID
+	thumbFile.IsThumbnail = true
+
+	if err := s.fileRepo.Insert(thumbFile); err != nil {
+		l.Error("Error saving thumbnail to database", "file", f.ID, "error", err)
+		if err := s.storage.Delete(ctx, thumbFile.Path); err != nil {
+			l.Error("Error deleting orphan thumbnail from storage", "path", thumbFile.Path, "error", err)
+		}
+		return err
+	}
+
+	f.Thumbnail, _ = thumbFile.ToImageFile()
+
+	l.Info("Thumbnail generated", "file", f.ID, "thumbnail", thumbFile.ID, "width", *width, "height", *height)
+
+	return nil
+}
+
+func (s *ThumbnailService) cacheKey(source string) string {
+	source256 := sha256.Sum256([]byte(source))
+	return cache.CacheKeyThumbnailPrefix + "generated:" + hex.EncodeToString(source256[:])
+}
@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func fakePNG(width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	Expect(png.Encode(&buf, img)).To(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("Thumbnail Service", func() {
+	It("should only need the transcoder for videos and pdfs", func() {
+		s := service.NewThumbnailService(service.NewTranscodeService(nil, nil, 0), nil, nil, nil, nil, 0)
+
+		Expect(s.Supports("image/png")).To(BeTrue())
+		Expect(s.Supports("video/mp4")).To(BeFalse())
+		Expect(s.Supports("application/pdf")).To(BeFalse())
+		Expect(s.Supports("text/plain")).To(BeFalse())
+
+		s = service.NewThumbnailService(service.NewTranscodeService(&countingTranscoder{}, nil, 0), nil, nil, nil, nil, 0)
+		Expect(s.Supports("video/mp4")).To(BeTrue())
+		Expect(s.Supports("application/pdf")).To(BeTrue())
+	})
+
+	It("should scale images down to a jpeg", func() {
+		s := service.NewThumbnailService(nil, nil, nil, nil, nil, 0)
+
+		thumbnail, width, height, err := s.Generate(context.Background(), "image/png", fakePNG(640, 320))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*width).To(Equal(uint32(file.ThumbnailMaxSize)))
+		Expect(*height).To(Equal(uint32(file.ThumbnailMaxSize / 2)))
+
+		_, err = jpeg.Decode(bytes.NewReader(thumbnail))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should refuse what it cannot preview", func() {
+		s := service.NewThumbnailService(nil, nil, nil, nil, nil, 0)
+
+		_, _, _, err := s.Generate(context.Background(), "application/zip", []byte("zip"))
+		Expect(err).To(Equal(file.ErrThumbnailUnsupported))
+		Expect(s.ForSource(context.Background(), "https://example.com/a.zip", "application/zip", []byte("zip"), true)).To(BeNil())
+	})
+
+	It("should cache the thumbnail by source", func() {
+		s := service.NewThumbnailService(nil, nil, nil, nil, fake.NewFakeCache(), time.Hour)
+		source := "https://example.com/a.png"
+
+		Expect(s.Cached(source)).To(BeNil())
+
+		thumbnail := s.ForSource(context.Background(), source, "image/png", fakePNG(32, 32), true)
+		Expect(thumbnail).ToNot(BeNil())
+		_, err := base64.StdEncoding.DecodeString(*thumbnail)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(s.Cached(source)).To(Equal(thumbnail))
+		Expect(s.Cached("https://example.com/b.png")).To(BeNil())
+	})
+
+	It("should not cache when the caller opts out", func() {
+		s := service.NewThumbnailService(nil, nil, nil, nil, fake.NewFakeCache(), time.Hour)
+		source := "https://example.com/a.png"
+
+		Expect(s.ForSource(context.Background(), source, "image/png", fakePNG(32, 32), false)).ToNot(BeNil())
+		Expect(s.Cached(source)).To(BeNil())
+	})
+})
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/cache"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/transcoder"
+	c "github.com/mauriciorobertodev/whappy-go/internal/infra/cache"
+)
+
+// TranscodeService prepares voice notes and videos before they are sent, results are cached by the hash of
+// the source so the same media is converted once
+type TranscodeService struct {
+	transcoder transcoder.Transcoder
+	cache      cache.Cache
+	cacheTTL   time.Duration
+}
+
+func NewTranscodeService(transcoder transcoder.Transcoder, cache cache.Cache, cacheTTL time.Duration) *TranscodeService {
+	return &TranscodeService{
+		transcoder: transcoder,
+		cache:      cache,
+		cacheTTL:   cacheTTL,
+	}
+}
+
+// Enabled tells whether media can be transcoded, without a transcoder media is sent as given
+func (s *TranscodeService) Enabled() bool {
+	return s != nil && s.transcoder != nil
+}
+
+func (s *TranscodeService) Voice(ctx context.Context, data []byte) (*transcoder.Result, error) {
+	return s.transcode(ctx, "voice", data)
+}
+
+func (s *TranscodeService) Video(ctx context.Context, data []byte) (*transcoder.Result, error) {
+	return s.transcode(ctx, "video", data)
+}
+
+func (s *TranscodeService) Probe(ctx context.Context, data []byte) (*transcoder.Probe, error) {
+	if !s.Enabled() {
+		return nil, transcoder.ErrTranscoderNotConfigured
+	}
+
+	return s.transcoder.Probe(ctx, data)
+}
+
+// Frame grabs a still of a video, it is not cached because only thumbnails are made from it
+func (s *TranscodeService) Frame(ctx context.Context, data []byte) ([]byte, error) {
+	if !s.Enabled() {
+		return nil, transcoder.ErrTranscoderNotConfigured
+	}
+
+	return s.transcoder.Frame(ctx, data)
+}
+
+// Page renders the first page of a pdf, it is not cached because only thumbnails are made from it
+func (s *TranscodeService) Page(ctx context.Context, data []byte) ([]byte, error) {
+	if !s.Enabled() {
+		return nil, transcoder.ErrTranscoderNotConfigured
+	}
+
+	return s.transcoder.Page(ctx, data)
+}
+
+func (s *TranscodeService) transcode(ctx context.Context, kind string, data []byte) (*transcoder.Result, error) {
+	l := app.GetTranscodeServiceLogger()
+
+	if !s.Enabled() {
+		return nil, transcoder.ErrTranscoderNotConfigured
+	}
+
+	sum := sha256.Sum256(data)
+	cacheKey := cache.CacheKeyTranscodePrefix + kind + ":" + hex.EncodeToString(sum[:])
+
+	if s.cache != nil {
+		if cached, err := c.Get[transcoder.Result](s.cache, cacheKey); err == nil {
+			l.Debug("Found cached transcoded media", "kind", kind, "cacheKey", cacheKey)
+			return &cached, nil
+		}
+	}
+
+	convert := s.transcoder.Voice
+	if kind == "video" {
+		convert = s.transcoder.Video
+	}
+
+	start := time.Now()
+	result, err := convert(ctx, data)
+	if err != nil {
+		l.Error("Error transcoding media", "kind", kind, "size", len(data), "error", err)
+		return nil, err
+	}
+
+	l.Info("Media transcoded", "kind", kind, "from", len(data), "to", len(result.Data), "took", time.Since(start))
+
+	if s.cache != nil {
+		c.Set(s.cache, cacheKey, result, s.cacheTTL)
+	}
+
+	return result, nil
+}
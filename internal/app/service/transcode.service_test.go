@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/transcoder"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type countingTranscoder struct {
+	calls int
+}
+
+func (t *countingTranscoder) Probe(ctx context.Context, data []byte) (*transcoder.Probe, error) {
+	return &transcoder.Probe{Format: "mp3", AudioCodec: "mp3"}, nil
+}
+
+func (t *countingTranscoder) Voice(ctx context.Context, data []byte) (*transcoder.Result, error) {
+	t.calls++
+	duration := uint32(3)
+	return &transcoder.Result{Data: []byte("opus"), Mime: transcoder.MimeVoice, Duration: &duration, Waveform: []byte{0, 50, 100}}, nil
+}
+
+func (t *countingTranscoder) Video(ctx context.Context, data []byte) (*transcoder.Result, error) {
+	t.calls++
+	return nil, transcoder.ErrNoVideoStream
+}
+
+func (t *countingTranscoder) Frame(ctx context.Context, data []byte) ([]byte, error) {
+	return nil, transcoder.ErrNoVideoStream
+}
+
+func (t *countingTranscoder) Page(ctx context.Context, data []byte) ([]byte, error) {
+	return nil, transcoder.ErrRendererNotConfigured
+}
+
+var _ = Describe("Transcode Service", func() {
+	It("should be disabled without a transcoder", func() {
+		s := service.NewTranscodeService(nil, nil, 0)
+		Expect(s.Enabled()).To(BeFalse())
+
+		_, err := s.Voice(context.Background(), []byte("mp3"))
+		Expect(err).To(Equal(transcoder.ErrTranscoderNotConfigured))
+	})
+
+	It("should transcode the same source once", func() {
+		t := &countingTranscoder{}
+		s := service.NewTranscodeService(t, fake.NewFakeCache(), time.Hour)
+
+		first, err := s.Voice(context.Background(), []byte("mp3"))
+		Expect(err).ToNot(HaveOccurred())
+
+		second, err := s.Voice(context.Background(), []byte("mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second).To(Equal(first))
+		Expect(t.calls).To(Equal(1))
+
+		_, err = s.Voice(context.Background(), []byte("another mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.calls).To(Equal(2))
+	})
+
+	It("should not cache failures", func() {
+		t := &countingTranscoder{}
+		s := service.NewTranscodeService(t, fake.NewFakeCache(), time.Hour)
+
+		for range 2 {
+			_, err := s.Video(context.Background(), []byte("mp3"))
+			Expect(err).To(Equal(transcoder.ErrNoVideoStream))
+		}
+		Expect(t.calls).To(Equal(2))
+	})
+})
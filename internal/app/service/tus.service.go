@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+)
+
+const (
+	// tusSweepInterval is how often expired uploads are discarded, an expired upload is already refused, the
+	// sweep frees what it left in the storage
+	tusSweepInterval = time.Hour
+	tusSweepBatch    = 100
+
+	// tusClaimStaleAfter is how long a request may hold an upload, a claim older than it belongs to a request
+	// that died and is taken over
+	tusClaimStaleAfter = 10 * time.Minute
+)
+
+// TusService takes uploads in chunks over the tus protocol, so big files survive flaky connections. Chunks are
+// written to the storage as parts as they arrive and the completed upload is registered like any other upload
+type TusService struct {
+	tusRepo       tus.UploadRepository
+	storage       storage.Storage
+	uploadService *UploadService
+	maxSize       uint64
+	ttl           time.Duration
+}
+
+func NewTusService(tusRepo tus.UploadRepository, storage storage.Storage, uploadService *UploadService, maxSize uint64, ttl time.Duration) *TusService {
+	return &TusService{
+		tusRepo:       tusRepo,
+		storage:       storage,
+		uploadService: uploadService,
+		maxSize:       maxSize,
+		ttl:           ttl,
+	}
+}
+
+func (s *TusService) MaxSize() uint64 {
+	return s.maxSize
+}
+
+func (s *TusService) Create(ctx context.Context, inst *instance.Instance, inp input.CreateTusUpload) (*tus.Upload, *app.AppError) {
+	l := app.GetTusServiceLogger()
+
+	if s.storage == nil {
+		l.Error("Global storage is not configured")
+		return nil, app.NewAppError("tus service", app.GLOBAL_STORAGE_UNAVAILABLE, storage.ErrStorageNotConfigured)
+	}
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("tus service", err)
+	}
+
+	metadata, _ := tus.ParseMetadata(inp.Metadata)
+	u := tus.New(inst.ID, inp.Length, metadata, s.ttl)
+
+	if err := u.Validate(s.maxSize); err != nil {
+		return nil, app.TranslateError("tus service", err)
+	}
+
+	// checked again when the upload completes, other uploads may land in between
+	if appErr := s.uploadService.CheckQuota(ctx, inst, u.Length); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.tusRepo.Insert(u); err != nil {
+		l.Error("Error storing upload", "instance", inst.ID, "error", err)
+		return nil, app.NewAppError("tus service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Upload created", "instance", inst.ID, "upload", u.ID, "length", u.Length)
+	return u, nil
+}
+
+func (s *TusService) Get(ctx context.Context, inst *instance.Instance, id string) (*tus.Upload, *app.AppError) {
+	l := app.GetTusServiceLogger()
+
+	inp := input.AppendTusUpload{ID: id}
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("tus service", err)
+	}
+
+	u, err := s.tusRepo.Get(tus.WhereID(id), tus.WhereInstanceID(inst.ID))
+	if err != nil {
+		l.Error("Error getting upload", "upload", id, "error", err)
+		return nil, app.NewAppError("tus service", app.CodeDatabaseError, err)
+	}
+
+	if u == nil {
+		return nil, app.TranslateError("tus service", tus.ErrUploadNotFound)
+	}
+
+	if !u.IsComplete() && u.IsExpired(time.Now()) {
+		return nil, app.TranslateError("tus service", tus.ErrUploadExpired)
+	}
+
+	return u, nil
+}
+
+// Append writes a chunk at the offset the client believes the upload is, the last chunk completes the upload
+// and registers its file. The upload is claimed at its offset before anything is written, so of requests racing
+// on any replica only one writes
+func (s *TusService) Append(ctx context.Context, inst *instance.Instance, inp input.AppendTusUpload) (*tus.Upload, *app.AppError) {
+	l := app.GetTusServiceLogger()
+
+	if s.storage == nil {
+		l.Error("Global storage is not configured")
+		return nil, app.NewAppError("tus service", app.GLOBAL_STORAGE_UNAVAILABLE, storage.ErrStorageNotConfigured)
+	}
+
+	u, appErr := s.Get(ctx, inst, inp.ID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if err := u.Accepts(inp.Offset, len(inp.Chunk)); err != nil {
+		l.Debug("Chunk refused", "upload", u.ID, "offset", u.Offset, "sent", inp.Offset, "size", len(inp.Chunk), "error", err)
+		return nil, app.TranslateError("tus service", err)
+	}
+
+	if len(inp.Chunk) == 0 {
+		// an upload whose file could not be registered is completed again by the next request
+		if u.IsComplete() && !u.IsRegistered() {
+			return s.complete(ctx, inst, u)
+		}
+		return u, nil
+	}
+
+	if appErr := s.claim(u); appErr != nil {
+		return nil, appErr
+	}
+
+	from, pending := u.Offset, u.Pending
+
+	if err := s.hash(u, inp.Chunk); err != nil {
+		l.Error("Error restoring upload hash", "upload", u.ID, "error", err)
+		s.release(u)
+		return nil, app.NewAppError("tus service", app.CodeFileUnreachable, err)
+	}
+
+	if u.Mime == "" {
+		u.Mime = http.DetectContentType(inp.Chunk)
+	}
+
+	if err := s.write(ctx, u, inp.Chunk); err != nil {
+		l.Error("Error writing chunk to storage", "upload", u.ID, "offset", u.Offset, "error", err)
+		s.release(u)
+		return nil, app.TranslateError("tus service", file.ErrStorageFailed)
+	}
+
+	u.Offset += uint64(len(inp.Chunk))
+	u.UpdatedAt = time.Now().UTC()
+
+	stored, err := s.tusRepo.UpdateFrom(u, from)
+	if err != nil {
+		l.Error("Error storing upload", "upload", u.ID, "error", err)
+		return nil, app.NewAppError("tus service", app.CodeDatabaseError, err)
+	}
+
+	// the claim went stale and another request took the upload over
+	if !stored {
+		return nil, app.TranslateError("tus service", tus.ErrOffsetMismatch)
+	}
+	u.Claim, u.ClaimedAt = "", nil
+
+	if pending > 0 && u.Pending == 0 {
+		if err := s.storage.Delete(ctx, pendingKey(u)); err != nil {
+			l.Error("Error deleting pending chunk", "upload", u.ID, "error", err)
+		}
+	}
+
+	l.Debug("Chunk written", "upload", u.ID, "offset", u.Offset, "length", u.Length)
+
+	if u.IsComplete() {
+		return s.complete(ctx, inst, u)
+	}
+
+	return u, nil
+}
+
+// Terminate discards the upload and what it wrote, a completed upload keeps its file
+func (s *TusService) Terminate(ctx context.Context, inst *instance.Instance, id string) *app.AppError {
+	l := app.GetTusServiceLogger()
+
+	inp := input.AppendTusUpload{ID: id}
+	if err := inp.Validate(); err != nil {
+		return app.TranslateError("tus service", err)
+	}
+
+	u, err := s.tusRepo.Get(tus.WhereID(id), tus.WhereInstanceID(inst.ID))
+	if err != nil {
+		l.Error("Error getting upload", "upload", id, "error", err)
+		return app.NewAppError("tus service", app.CodeDatabaseError, err)
+	}
+
+	if u == nil {
+		return app.TranslateError("tus service", tus.ErrUploadNotFound)
+	}
+
+	// a chunk being written would land in a discarded upload
+	if appErr := s.claim(u); appErr != nil {
+		return appErr
+	}
+
+	s.discard(ctx, u)
+
+	if err := s.tusRepo.Delete(tus.WhereID(u.ID)); err != nil {
+		l.Error("Error deleting upload", "upload", id, "error", err)
+		return app.NewAppError("tus service", app.CodeDatabaseError, err)
+	}
+
+	l.Info("Upload terminated", "instance", inst.ID, "upload", u.ID)
+	return nil
+}
+
+func (s *TusService) Run(ctx context.Context) {
+	ticker := time.NewTicker(tusSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *TusService) sweep(ctx context.Context) {
+	l := app.GetTusServiceLogger()
+
+	uploads, err := s.tusRepo.Expired(time.Now(), tusSweepBatch)
+	if err != nil {
+		l.Error("Error listing expired uploads", "error", err)
+		return
+	}
+
+	for _, u := range uploads {
+		// a request still writing the upload leaves it to the next sweep
+		if appErr := s.claim(u); appErr != nil {
+			continue
+		}
+
+		if s.storage != nil {
+			s.discard(ctx, u)
+		}
+
+		if err := s.tusRepo.Delete(tus.WhereID(u.ID)); err != nil {
+			l.Error("Error deleting expired upload", "upload", u.ID, "error", err)
+		}
+	}
+
+	if len(uploads) > 0 {
+		l.Debug("Expired uploads discarded", "count", len(uploads))
+	}
+}
+
+// write adds the chunk to the upload, chunks too small to be a part wait in a pending object until enough
+// arrives or the upload ends
+func (s *TusService) write(ctx context.Context, u *tus.Upload, chunk []byte) error {
+	mp := storage.Multipart(s.storage)
+
+	data := chunk
+	if u.Pending > 0 {
+		pending, err := s.storage.Get(ctx, pendingKey(u))
+		if err != nil {
+			return err
+		}
+		if uint64(len(pending)) < u.Pending {
+			return tus.ErrOffsetMismatch
+		}
+		data = append(pending[:u.Pending:u.Pending], chunk...)
+	}
+
+	last := u.Offset+uint64(len(chunk)) == u.Length
+	if len(data) < mp.MinPartSize() && !last {
+		if err := s.storage.Put(ctx, pendingKey(u), data); err != nil {
+			return err
+		}
+		u.Pending = uint64(len(data))
+		return nil
+	}
+
+	if u.MultipartID == "" {
+		u.Path = u.ID + "." + file.DetectExtension(u.Mime)
+
+		id, err := mp.StartMultipart(ctx, u.Path)
+		if err != nil {
+			return err
+		}
+		u.MultipartID = id
+	}
+
+	number := int32(len(u.Parts) + 1)
+	etag, err := mp.PutPart(ctx, u.Path, u.MultipartID, number, data)
+	if err != nil {
+		return err
+	}
+
+	u.Parts = append(u.Parts, tus.Part{Number: number, ETag: etag, Size: uint64(len(data))})
+	u.Pending = 0
+
+	return nil
+}
+
+// complete joins the parts of a fully received upload and registers its file, under its own claim so a file is
+// registered once however many requests find the upload complete
+func (s *TusService) complete(ctx context.Context, inst *instance.Instance, u *tus.Upload) (*tus.Upload, *app.AppError) {
+	l := app.GetTusServiceLogger()
+
+	if appErr := s.claim(u); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := storage.Multipart(s.storage).CompleteMultipart(ctx, u.Path, u.MultipartID, storageParts(u)); err != nil {
+		l.Error("Error completing upload in storage", "upload", u.ID, "error", err)
+		s.release(u)
+		return nil, app.TranslateError("tus service", file.ErrStorageFailed)
+	}
+
+	sum, err := restoreHash(u.HashState)
+	if err != nil {
+		l.Error("Error restoring upload hash", "upload", u.ID, "error", err)
+		s.release(u)
+		return nil, app.NewAppError("tus service", app.CodeFileUnreachable, err)
+	}
+
+	f := file.NewFromMime(u.Mime)
+	f.ID = u.ID
+	f.Path = u.Path
+	f.Name = u.Path
+	f.Size = u.Length
+	f.Sha256 = hex.EncodeToString(sum.Sum(nil))
+
+	if url, err := s.storage.URL(ctx, f.Path); err == nil {
+		f.URL = url
+	}
+
+	registered, appErr := s.uploadService.Register(ctx, inst, f, u.FileMetadata(), u.ThumbnailID())
+	if appErr != nil {
+		s.release(u)
+		return nil, appErr
+	}
+
+	u.FileID = &registered.ID
+	u.UpdatedAt = time.Now().UTC()
+
+	stored, err := s.tusRepo.UpdateFrom(u, u.Offset)
+	if err != nil || !stored {
+		l.Error("Error storing completed upload", "upload", u.ID, "file", registered.ID, "error", err)
+		return nil, app.NewAppError("tus service", app.CodeDatabaseError, err)
+	}
+	u.Claim, u.ClaimedAt = "", nil
+
+	l.Info("Upload completed", "instance", inst.ID, "upload", u.ID, "file", registered.ID, "size", registered.Size)
+	return u, nil
+}
+
+// claim takes the upload at its current offset for the request, ErrUploadLocked while another request holds it
+func (s *TusService) claim(u *tus.Upload) *app.AppError {
+	now := time.Now()
+	u.NewClaim(now)
+
+	claimed, err := s.tusRepo.Claim(u, now.Add(-tusClaimStaleAfter))
+	if err != nil {
+		app.GetTusServiceLogger().Error("Error claiming upload", "upload", u.ID, "error", err)
+		return app.NewAppError("tus service", app.CodeDatabaseError, err)
+	}
+
+	if !claimed {
+		return app.TranslateError("tus service", tus.ErrUploadLocked)
+	}
+
+	return nil
+}
+
+// release gives up the claim of a request that failed, the upload is stored back as it was before the request
+func (s *TusService) release(u *tus.Upload) {
+	stored, err := s.tusRepo.Get(tus.WhereID(u.ID))
+	if err != nil || stored == nil {
+		return
+	}
+
+	stored.Claim = u.Claim
+	if _, err := s.tusRepo.UpdateFrom(stored, stored.Offset); err != nil {
+		app.GetTusServiceLogger().Error("Error releasing upload", "upload", u.ID, "error", err)
+	}
+}
+
+// discard frees what an unfinished upload wrote to the storage, failures only leave garbage behind
+func (s *TusService) discard(ctx context.Context, u *tus.Upload) {
+	l := app.GetTusServiceLogger()
+
+	if u.FileID != nil {
+		return
+	}
+
+	if u.MultipartID != "" {
+		if err := storage.Multipart(s.storage).AbortMultipart(ctx, u.Path, u.MultipartID, storageParts(u)); err != nil {
+			l.Error("Error aborting upload in storage", "upload", u.ID, "error", err)
+		}
+	}
+
+	if u.Pending > 0 {
+		if err := s.storage.Delete(ctx, pendingKey(u)); err != nil {
+			l.Error("Error deleting pending chunk", "upload", u.ID, "error", err)
+		}
+	}
+}
+
+// hash carries the sha256 of the received bytes from chunk to chunk, so the upload is never read back
+func (s *TusService) hash(u *tus.Upload, chunk []byte) error {
+	sum, err := restoreHash(u.HashState)
+	if err != nil {
+		return err
+	}
+
+	sum.Write(chunk)
+
+	state, err := sum.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	u.HashState = state
+	return nil
+}
+
+func restoreHash(state []byte) (interface {
+	Write([]byte) (int, error)
+	Sum([]byte) []byte
+}, error) {
+	sum := sha256.New()
+	if len(state) == 0 {
+		return sum, nil
+	}
+
+	if err := sum.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, err
+	}
+
+	return sum, nil
+}
+
+func storageParts(u *tus.Upload) []storage.Part {
+	parts := make([]storage.Part, 0, len(u.Parts))
+	for _, part := range u.Parts {
+		parts = append(parts, storage.Part{Number: part.Number, ETag: part.ETag})
+	}
+	return parts
+}
+
+func pendingKey(u *tus.Upload) string {
+	return ".tus/" + u.ID + ".pending"
+}
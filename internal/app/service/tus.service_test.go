@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	infrastorage "github.com/mauriciorobertodev/whappy-go/internal/infra/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tus Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	fileRepo := repository.NewFileRepository(db)
+	tusRepo := repository.NewTusUploadRepository(db)
+	bus := fake.NewFakeEventBus()
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	var (
+		inst    *instance.Instance
+		store   *infrastorage.LocalStorage
+		uploads *service.TusService
+		ctx     = context.Background()
+	)
+
+	create := func(length uint64) *tus.Upload {
+		u, appErr := uploads.Create(ctx, inst, input.CreateTusUpload{Length: length})
+		Expect(appErr).To(BeNil())
+		return u
+	}
+
+	appendChunk := func(u *tus.Upload, offset uint64, chunk string) (*tus.Upload, *app.AppError) {
+		return uploads.Append(ctx, inst, input.AppendTusUpload{ID: u.ID, Offset: offset, Chunk: []byte(chunk)})
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+
+		inst = fake.InstanceFactory().Connected().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		store = infrastorage.NewLocalStorage(&config.StorageConfig{Driver: config.StorageDriverLocal, Path: GinkgoT().TempDir(), URL: "http://localhost/storage"})
+		fileService := service.NewFileService(store, fileRepo)
+		quotaService := service.NewQuotaService(repository.NewQuotaRepository(db), fileRepo, instRepo, quota.Quota{})
+		uploadService := service.NewUploadService(fileService, nil, fileRepo, store, quotaService, bus)
+		uploads = service.NewTusService(tusRepo, store, uploadService, 0, time.Hour)
+	})
+
+	It("should write the chunks and register the file once the last one lands", func() {
+		u := create(11)
+
+		u, appErr := appendChunk(u, 0, "hello ")
+		Expect(appErr).To(BeNil())
+		Expect(u.Offset).To(Equal(uint64(6)))
+		Expect(u.FileID).To(BeNil())
+
+		u, appErr = appendChunk(u, 6, "world")
+		Expect(appErr).To(BeNil())
+		Expect(u.Offset).To(Equal(uint64(11)))
+		Expect(u.FileID).ToNot(BeNil())
+
+		f, err := fileRepo.Get(file.WhereID(*u.FileID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Size).To(Equal(uint64(11)))
+
+		data, err := store.Get(ctx, f.Path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("hello world"))
+
+		stored, err := tusRepo.Get(tus.WhereID(u.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored.FileID).To(Equal(u.FileID))
+		Expect(stored.Claim).To(BeEmpty())
+	})
+
+	It("should refuse a chunk sent again at an offset already written", func() {
+		u := create(11)
+
+		_, appErr := appendChunk(u, 0, "hello ")
+		Expect(appErr).To(BeNil())
+
+		_, appErr = appendChunk(u, 0, "hello ")
+		Expect(appErr).ToNot(BeNil())
+		Expect(appErr.Code).To(Equal(app.CodeUploadOffsetMismatch))
+	})
+
+	It("should refuse a chunk while another request holds the upload until its claim goes stale", func() {
+		u := create(11)
+
+		held, err := tusRepo.Get(tus.WhereID(u.ID))
+		Expect(err).ToNot(HaveOccurred())
+		held.NewClaim(time.Now())
+		Expect(tusRepo.Claim(held, time.Now().Add(-time.Minute))).To(BeTrue())
+
+		_, appErr := appendChunk(u, 0, "hello ")
+		Expect(appErr).ToNot(BeNil())
+		Expect(appErr.Code).To(Equal(app.CodeUploadLocked))
+
+		// a request that died long ago does not hold the upload forever
+		stale, err := tusRepo.Get(tus.WhereID(u.ID))
+		Expect(err).ToNot(HaveOccurred())
+		stale.NewClaim(time.Now().Add(-time.Hour))
+		Expect(tusRepo.Claim(stale, time.Now())).To(BeTrue())
+
+		u, appErr = appendChunk(u, 0, "hello ")
+		Expect(appErr).To(BeNil())
+		Expect(u.Offset).To(Equal(uint64(6)))
+	})
+})
@@ -15,21 +15,31 @@ import (
 )
 
 type UploadService struct {
-	fileService *FileService
-	fileRepo    file.FileRepository
-	storage     storage.Storage
-	bus         events.EventBus
+	fileService      *FileService
+	thumbnailService *ThumbnailService
+	fileRepo         file.FileRepository
+	storage          storage.Storage
+	quotaService     *QuotaService
+	bus              events.EventBus
 }
 
-func NewUploadService(fileService *FileService, fileRepo file.FileRepository, storage storage.Storage, bus events.EventBus) *UploadService {
+func NewUploadService(fileService *FileService, thumbnailService *ThumbnailService, fileRepo file.FileRepository, storage storage.Storage, quotaService *QuotaService, bus events.EventBus) *UploadService {
 	return &UploadService{
-		fileService: fileService,
-		fileRepo:    fileRepo,
-		storage:     storage,
-		bus:         bus,
+		fileService:      fileService,
+		thumbnailService: thumbnailService,
+		fileRepo:         fileRepo,
+		storage:          storage,
+		quotaService:     quotaService,
+		bus:              bus,
 	}
 }
 
+// CheckQuota tells whether the instance can store an upload of the size before it is written, zero when the size
+// is not known yet
+func (s *UploadService) CheckQuota(ctx context.Context, inst *instance.Instance, size uint64) *app.AppError {
+	return s.quotaService.Check(ctx, inst.ID, size)
+}
+
 func (s *UploadService) UploadWithStream(ctx context.Context, inst *instance.Instance, inp input.UploadFile) (*file.File, *app.AppError) {
 	l := app.GetUploadServiceLogger()
 
@@ -38,22 +48,98 @@ func (s *UploadService) UploadWithStream(ctx context.Context, inst *instance.Ins
 		return nil, app.NewAppError("upload service", app.GLOBAL_STORAGE_UNAVAILABLE, storage.ErrStorageNotConfigured)
 	}
 
-	l.Debug("Uploading file to storage")
+	// an instance already at its quota is refused before its content is read
+	if appErr := s.CheckQuota(ctx, inst, 0); appErr != nil {
+		return nil, appErr
+	}
+
+	// the content is hashed before it is stored, a content the instance already has is never written again
+	content, err := spool(inp.Stream)
+	if err != nil {
+		l.Error("Error reading uploaded file", "error", err.Error())
+		return nil, app.TranslateError("upload service", err)
+	}
+	defer content.Close()
 
-	f, err := s.fileService.SaveStream(ctx, inp.Stream, inp.Metadata.Mime)
+	existing, err := s.fileService.Reuse(inst.ID, content.sha256)
 	if err != nil {
+		l.Error("Error looking for the same content in database", "error", err.Error())
+		return nil, app.NewDatabaseError("upload service", err)
+	}
+
+	if existing != nil {
+		l.Info("File already uploaded, reusing it", "file", existing.ID, "references", existing.References)
+		s.fileService.Sign(ctx, existing)
+		return existing, nil
+	}
+
+	if appErr := s.CheckQuota(ctx, inst, uint64(content.size)); appErr != nil {
+		return nil, appErr
+	}
+
+	mime := content.mime()
+	if inp.Metadata.Mime != nil && *inp.Metadata.Mime != "" {
+		mime = *inp.Metadata.Mime
+	}
+
+	f := file.NewFromMime(mime)
+	f.Size = uint64(content.size)
+	f.Sha256 = content.sha256
+
+	l.Debug("Uploading file to storage")
+
+	if err := s.fileService.Write(ctx, f, content); err != nil {
 		l.Error("Error uploading file to storage", "error", err.Error())
 		return nil, app.TranslateError("upload service", err)
 	}
 
-	f.UpdateMeta(inp.Metadata)
+	return s.record(ctx, inst, f, inp.Metadata, inp.ThumbnailID)
+}
+
+// Register records a file the client already wrote to the storage as an upload of the instance, so resumable and
+// presigned uploads end up as the ones uploaded in a single request. The object is removed when the instance
+// already has its content
+func (s *UploadService) Register(ctx context.Context, inst *instance.Instance, f *file.File, metadata file.Metadata, thumbnailID *string) (*file.File, *app.AppError) {
+	l := app.GetUploadServiceLogger()
+
+	existing, err := s.fileService.Reuse(inst.ID, f.Sha256)
+	if err != nil {
+		l.Error("Error looking for the same content in database", "error", err.Error())
+		return nil, app.NewDatabaseError("upload service", err)
+	}
+
+	if existing != nil {
+		if err := s.storage.Delete(ctx, f.Path); err != nil {
+			l.Error("Error removing duplicated file from storage", "path", f.Path, "error", err)
+		}
+
+		l.Info("File already uploaded, reusing it", "file", existing.ID, "references", existing.References)
+		s.fileService.Sign(ctx, existing)
+		return existing, nil
+	}
+
+	if appErr := s.CheckQuota(ctx, inst, f.Size); appErr != nil {
+		if err := s.storage.Delete(ctx, f.Path); err != nil {
+			l.Error("Error removing file over the quota from storage", "path", f.Path, "error", err)
+		}
+		return nil, appErr
+	}
+
+	return s.record(ctx, inst, f, metadata, thumbnailID)
+}
+
+// record saves a new content written to the storage as an upload of the instance
+func (s *UploadService) record(ctx context.Context, inst *instance.Instance, f *file.File, metadata file.Metadata, thumbnailID *string) (*file.File, *app.AppError) {
+	l := app.GetUploadServiceLogger()
+
+	f.UpdateMeta(metadata)
 	f.InstanceID = &inst.ID
 
-	if inp.ThumbnailID != nil && *inp.ThumbnailID != "" {
-		thumbFile, err := s.fileRepo.Get(file.WhereID(*inp.ThumbnailID))
+	if thumbnailID != nil && *thumbnailID != "" {
+		thumbFile, err := s.fileRepo.Get(file.WhereID(*thumbnailID))
 		if err != nil {
 			if errors.Is(err, file.ErrFileNotFound) {
-				l.Error("Thumbnail file not found", "thumbnail_id", *inp.ThumbnailID)
+				l.Error("Thumbnail file not found", "thumbnail_id", *thumbnailID)
 				return nil, app.NewAppError("upload service", app.CodeFileNotFound, file.ErrFileNotFound)
 			}
 
@@ -70,18 +156,31 @@ func (s *UploadService) UploadWithStream(ctx context.Context, inst *instance.Ins
 		f.Thumbnail = thumb
 	}
 
+	if !f.HasThumbnail() && s.thumbnailService.Supports(f.Mime) {
+		s.generateThumbnail(ctx, f)
+	}
+
 	if f.HasThumbnail() {
 		l.Debug("File has thumbnail", "thumbnail_id", f.Thumbnail.ID)
 	}
 
 	l.Info("File uploaded to storage successfully", "name", f.Name)
 
-	err = s.fileRepo.Insert(f)
+	stored, reused, err := s.fileService.Keep(ctx, f)
 	if err != nil {
 		l.Error("Error saving file to database", "error", err.Error())
+		if err := s.storage.Delete(ctx, f.Path); err != nil {
+			l.Error("Error removing unsaved file from storage", "path", f.Path, "error", err)
+		}
 		return nil, app.TranslateError("upload service", err)
 	}
 
+	if reused {
+		l.Info("Same file uploaded meanwhile, reusing it", "file", stored.ID, "references", stored.References)
+		s.fileService.Sign(ctx, stored)
+		return stored, nil
+	}
+
 	go s.bus.Publish(f.EventUploaded(&inst.ID))
 
 	l.Info("File saved to database successfully", "name", f.Name)
@@ -132,6 +231,10 @@ func (s *UploadService) ListUploads(ctx context.Context, inst *instance.Instance
 		files = files[:len(files)-1]
 	}
 
+	for _, f := range files {
+		s.fileService.Sign(ctx, f)
+	}
+
 	return files, nextCursorEncoded, nil
 }
 
@@ -157,6 +260,8 @@ func (s *UploadService) GetUpload(ctx context.Context, inst *instance.Instance,
 
 	l.Info("File retrieved from database successfully", "file", inp.FileID)
 
+	s.fileService.Sign(ctx, f)
+
 	return f, nil
 }
 
@@ -190,12 +295,29 @@ func (s *UploadService) DeleteUpload(ctx context.Context, inst *instance.Instanc
 		return app.NewAppError("upload service", app.CodeFileNotFound, file.ErrFileNotFound)
 	}
 
-	err = s.fileRepo.Delete(file.WhereID(inp.FileID))
+	references, err := s.fileRepo.Release(f.ID)
+	if err != nil {
+		l.Error("Error releasing file reference in database", "error", err.Error())
+		return app.TranslateError("upload service", err)
+	}
+
+	if references > 0 {
+		l.Info("File is still referenced, keeping it", "file", inp.FileID, "references", references)
+		return nil
+	}
+
+	// an upload of the same content may have referenced it again since it was released
+	deleted, err := s.fileRepo.DeleteReleased(f.ID)
 	if err != nil {
 		l.Error("Error deleting file from database", "error", err.Error())
 		return app.TranslateError("upload service", err)
 	}
 
+	if !deleted {
+		l.Info("File was referenced again, keeping it", "file", inp.FileID)
+		return nil
+	}
+
 	l.Info("File deleted from database successfully", "file", inp.FileID)
 
 	err = s.storage.Delete(ctx, f.Path)
@@ -211,6 +333,47 @@ func (s *UploadService) DeleteUpload(ctx context.Context, inst *instance.Instanc
 	return nil
 }
 
+func (s *UploadService) PinUpload(ctx context.Context, inst *instance.Instance, inp input.PinUpload) (*file.File, *app.AppError) {
+	l := app.GetUploadServiceLogger()
+
+	if err := inp.Validate(); err != nil {
+		return nil, app.TranslateError("upload service", err)
+	}
+
+	f, err := s.fileRepo.Get(file.WhereID(inp.FileID))
+	if err != nil {
+		if errors.Is(err, file.ErrFileNotFound) {
+			return nil, app.NewAppError("upload service", app.CodeFileNotFound, file.ErrFileNotFound)
+		}
+
+		l.Error("Error getting file from database", "error", err.Error())
+		return nil, app.TranslateError("upload service", err)
+	}
+
+	if f.InstanceID == nil || *f.InstanceID != inst.ID {
+		l.Error("File does not belong to this instance", "file", inp.FileID, "instance", inst.ID)
+		return nil, app.NewAppError("upload service", app.CodeFileNotFound, file.ErrFileNotFound)
+	}
+
+	if f.Pinned != inp.Pinned {
+		f.Pinned = inp.Pinned
+		now := time.Now().UTC()
+		f.UpdatedAt = &now
+
+		if err := s.fileRepo.Update(f); err != nil {
+			l.Error("Error updating file in database", "error", err.Error())
+			return nil, app.TranslateError("upload service", err)
+		}
+
+		go s.bus.Publish(f.EventUpdated(&inst.ID))
+		l.Info("File pin changed", "file", inp.FileID, "pinned", inp.Pinned)
+	}
+
+	s.fileService.Sign(ctx, f)
+
+	return f, nil
+}
+
 func (s *UploadService) UpdateFileMetadata(ctx context.Context, inst *instance.Instance, inp input.UpdateUploadMetadata) (*file.File, *app.AppError) {
 	l := app.GetUploadServiceLogger()
 
@@ -247,7 +410,22 @@ func (s *UploadService) UpdateFileMetadata(ctx context.Context, inst *instance.I
 	go s.bus.Publish(f.EventUpdated(&inst.ID))
 	l.Info("File metadata updated in database successfully", "file", inp.FileID)
 
+	s.fileService.Sign(ctx, f)
+
 	return f, nil
 }
 
-// TODO: download
+// generateThumbnail links a generated thumbnail to an upload sent without one, the upload works without it
+func (s *UploadService) generateThumbnail(ctx context.Context, f *file.File) {
+	l := app.GetUploadServiceLogger()
+
+	data, err := s.storage.Get(ctx, f.Path)
+	if err != nil {
+		l.Error("Error reading file from storage to generate its thumbnail", "error", err.Error())
+		return
+	}
+
+	if err := s.thumbnailService.Link(ctx, f, data); err != nil {
+		l.Warn("File uploaded without thumbnail", "name", f.Name, "error", err.Error())
+	}
+}
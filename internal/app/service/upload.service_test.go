@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	infrastorage "github.com/mauriciorobertodev/whappy-go/internal/infra/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Upload Service", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	fileRepo := repository.NewFileRepository(db)
+	bus := fake.NewFakeEventBus()
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	var (
+		inst    *instance.Instance
+		store   *infrastorage.LocalStorage
+		uploads *service.UploadService
+	)
+
+	upload := func(content string) *file.File {
+		f, appErr := uploads.UploadWithStream(context.Background(), inst, input.UploadFile{Stream: strings.NewReader(content)})
+		Expect(appErr).To(BeNil())
+		return f
+	}
+
+	objects := func() int {
+		count := 0
+		Expect(store.Walk(context.Background(), func(storage.Object) error {
+			count++
+			return nil
+		})).To(Succeed())
+		return count
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		bus.Clear()
+		bus.ClearPublished()
+
+		inst = fake.InstanceFactory().Connected().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		store = infrastorage.NewLocalStorage(&config.StorageConfig{Driver: config.StorageDriverLocal, Path: GinkgoT().TempDir(), URL: "http://localhost/storage"})
+		fileService := service.NewFileService(store, fileRepo)
+		quotaService := service.NewQuotaService(repository.NewQuotaRepository(db), fileRepo, instRepo, quota.Quota{})
+		uploads = service.NewUploadService(fileService, nil, fileRepo, store, quotaService, bus)
+	})
+
+	It("should store the same content once and count its references", func() {
+		first := upload("the same content")
+		second := upload("the same content")
+
+		Expect(second.ID).To(Equal(first.ID))
+		Expect(second.References).To(Equal(uint32(2)))
+		Expect(objects()).To(Equal(1))
+
+		upload("another content")
+		Expect(objects()).To(Equal(2))
+	})
+
+	It("should only remove the content once its last reference is deleted", func() {
+		f := upload("the same content")
+		upload("the same content")
+
+		Expect(uploads.DeleteUpload(context.Background(), inst, input.DeleteUpload{FileID: f.ID})).To(BeNil())
+		Expect(objects()).To(Equal(1))
+
+		Expect(uploads.DeleteUpload(context.Background(), inst, input.DeleteUpload{FileID: f.ID})).To(BeNil())
+		Expect(objects()).To(Equal(0))
+
+		_, err := fileRepo.Get(file.WhereID(f.ID))
+		Expect(err).To(MatchError(file.ErrFileNotFound))
+	})
+})
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Object is a stored object as seen when walking the storage
+type Object struct {
+	Key        string
+	Size       uint64
+	ModifiedAt time.Time
+}
+
+// ListableStorage walks every object it holds, an error from fn stops the walk and is returned
+type ListableStorage interface {
+	Walk(ctx context.Context, fn func(Object) error) error
+}
+
+// Listable returns the storage as a ListableStorage, nil when it cannot list its objects
+func Listable(s Storage) ListableStorage {
+	if l, ok := s.(ListableStorage); ok {
+		return l
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// Part is a piece of an object written with MultipartStorage
+type Part struct {
+	Number int32
+	ETag   string
+}
+
+// MultipartStorage writes an object in parts, so big uploads never sit whole in memory or in a single request
+type MultipartStorage interface {
+	StartMultipart(ctx context.Context, key string) (string, error)
+	// PutPart writes a part, parts are numbered from 1 and every part but the last must reach MinPartSize
+	PutPart(ctx context.Context, key string, uploadID string, number int32, data []byte) (string, error)
+	CompleteMultipart(ctx context.Context, key string, uploadID string, parts []Part) error
+	AbortMultipart(ctx context.Context, key string, uploadID string, parts []Part) error
+	MinPartSize() int
+}
+
+// Multipart returns the storage itself when it writes parts natively, any other storage keeps each part as an
+// object of its own and joins them when the upload completes
+func Multipart(s Storage) MultipartStorage {
+	if mp, ok := s.(MultipartStorage); ok {
+		return mp
+	}
+
+	return &objectParts{storage: s}
+}
+
+type objectParts struct {
+	storage Storage
+}
+
+func (p *objectParts) StartMultipart(ctx context.Context, key string) (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (p *objectParts) PutPart(ctx context.Context, key string, uploadID string, number int32, data []byte) (string, error) {
+	if err := p.storage.Put(ctx, partKey(uploadID, number), data); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+func (p *objectParts) CompleteMultipart(ctx context.Context, key string, uploadID string, parts []Part) error {
+	pr, pw := io.Pipe()
+
+	go func() {
+		for _, part := range parts {
+			stream, err := p.storage.Load(ctx, partKey(uploadID, part.Number))
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+
+			_, err = io.Copy(pw, stream)
+			stream.Close()
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		pw.Close()
+	}()
+
+	if err := p.storage.Save(ctx, key, pr); err != nil {
+		pr.CloseWithError(err)
+		return err
+	}
+
+	return p.AbortMultipart(ctx, key, uploadID, parts)
+}
+
+// AbortMultipart removes the parts, a part that cannot be removed is left behind rather than failing the upload
+func (p *objectParts) AbortMultipart(ctx context.Context, key string, uploadID string, parts []Part) error {
+	for _, part := range parts {
+		_ = p.storage.Delete(ctx, partKey(uploadID, part.Number))
+	}
+
+	return nil
+}
+
+func (p *objectParts) MinPartSize() int {
+	return 0
+}
+
+func partKey(uploadID string, number int32) string {
+	return fmt.Sprintf(".parts/%s/%05d", uploadID, number)
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrPresignUnsupported = errors.New("storage cannot presign uploads")
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrSignatureExpired   = errors.New("signature expired")
+)
+
+// PresignedStorage hands out time limited URLs to write a key straight to the storage, so uploads do not go
+// through the API
+type PresignedStorage interface {
+	// PresignPut returns the URL to PUT the key to and the headers the client must send besides Content-Type, mime
+	// is part of the signature when given and the client must send it as Content-Type
+	PresignPut(ctx context.Context, key, mime string, ttl time.Duration) (string, map[string]string, error)
+}
+
+// SignedStorage serves its own signed URLs through the API, Verify checks a request to a key before it is served
+type SignedStorage interface {
+	Verify(method, key, expires, signature string) error
+}
+
+// Presigned returns the storage as a PresignedStorage, nil when it cannot presign uploads
+func Presigned(s Storage) PresignedStorage {
+	if p, ok := s.(PresignedStorage); ok {
+		return p
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package transcoder
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrTranscoderNotConfigured = errors.New("transcoder not configured")
+	ErrTranscodeFailed         = errors.New("failed to transcode media")
+	ErrMediaTooLarge           = errors.New("media is larger than the transcoder accepts")
+	ErrMediaTooLong            = errors.New("media is longer than the transcoder accepts")
+	ErrNoAudioStream           = errors.New("media has no audio")
+	ErrNoVideoStream           = errors.New("media has no video")
+	ErrRendererNotConfigured   = errors.New("pdf renderer not configured")
+)
+
+const (
+	MimeVoice = "audio/ogg; codecs=opus"
+	MimeVideo = "video/mp4"
+)
+
+// Probe is what was found about a media without decoding it, a missing value is nil or empty
+type Probe struct {
+	Format     string  `json:"format"`
+	AudioCodec string  `json:"audio_codec,omitempty"`
+	VideoCodec string  `json:"video_codec,omitempty"`
+	Duration   *uint32 `json:"duration,omitempty"` // in seconds, rounded up
+	Width      *uint32 `json:"width,omitempty"`
+	Height     *uint32 `json:"height,omitempty"`
+}
+
+func (p *Probe) HasAudio() bool {
+	return p.AudioCodec != ""
+}
+
+func (p *Probe) HasVideo() bool {
+	return p.VideoCodec != ""
+}
+
+// Result is a media ready to be sent to WhatsApp and what was probed from it
+type Result struct {
+	Data     []byte  `json:"data"`
+	Mime     string  `json:"mime"`
+	Duration *uint32 `json:"duration,omitempty"`
+	Width    *uint32 `json:"width,omitempty"`
+	Height   *uint32 `json:"height,omitempty"`
+	Waveform []byte  `json:"waveform,omitempty"`
+}
+
+type Transcoder interface {
+	Probe(ctx context.Context, data []byte) (*Probe, error)
+	// Voice converts to mono OGG/Opus, the only format WhatsApp plays as a voice note, with its waveform
+	Voice(ctx context.Context, data []byte) (*Result, error)
+	// Video converts to H.264/AAC MP4 with the index at the start, so it plays while it downloads
+	Video(ctx context.Context, data []byte) (*Result, error)
+	// Frame picks a representative frame near the start of a video as a JPEG
+	Frame(ctx context.Context, data []byte) ([]byte, error)
+	// Page renders the first page of a PDF as a JPEG
+	Page(ctx context.Context, data []byte) ([]byte, error)
+}
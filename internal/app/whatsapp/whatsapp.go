@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"os"
 
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/contact"
@@ -57,6 +58,7 @@ const (
 	MediaVideo
 	MediaAudio
 	MediaDocument
+	MediaLinkThumbnail
 )
 
 type PhoneStatus struct {
@@ -68,6 +70,46 @@ type PhoneStatus struct {
 	VerifiedName *string `json:"verified_name"`
 }
 
+type HistorySyncType string
+
+const (
+	HistorySyncInitial  HistorySyncType = "initial"
+	HistorySyncRecent   HistorySyncType = "recent"
+	HistorySyncFull     HistorySyncType = "full"
+	HistorySyncOnDemand HistorySyncType = "on_demand"
+)
+
+// HistorySync is one chunk of the conversations the phone shares with the linked device, progress goes
+// from 0 to 100 and is nil when the phone does not report it
+type HistorySync struct {
+	Type     HistorySyncType
+	Chunk    uint32
+	Progress *uint32
+	Chats    []*chat.Chat
+	Messages []*message.Message
+}
+
+func (h HistorySync) IsComplete() bool {
+	return h.Type == HistorySyncOnDemand || (h.Progress != nil && *h.Progress >= 100)
+}
+
+type HistorySyncHandler func(inst *instance.Instance, history HistorySync)
+
+// MediaHandler gets every received message carrying a media once its event is published, it runs in the
+// event handler of the connection and must not block
+type MediaHandler func(inst *instance.Instance, msg *message.Message)
+
+// DownloadTarget is where a media is downloaded and decrypted in place, an *os.File or a wrapper around it
+type DownloadTarget interface {
+	io.Reader
+	io.Writer
+	io.Seeker
+	io.ReaderAt
+	io.WriterAt
+	Truncate(size int64) error
+	Stat() (os.FileInfo, error)
+}
+
 type BlocklistAction string
 
 const (
@@ -83,8 +125,13 @@ type WhatsAppGateway interface {
 	Connect(ctx context.Context, instance *instance.Instance) error
 	Disconnect(ctx context.Context, instance *instance.Instance) error
 	Ping(ctx context.Context, inst *instance.Instance) (Ping, error)
+	OnHistorySync(handler HistorySyncHandler)
+	OnMedia(handler MediaHandler)
 
 	UploadFile(ctx context.Context, inst *instance.Instance, file io.ReadCloser, kind MediaKind, mime string) (*file.File, error)
+	// DownloadFile downloads and decrypts a media received from WhatsApp into dst, checking the hashes of the file,
+	// the media is decrypted in place so dst is rewritten from its start
+	DownloadFile(ctx context.Context, inst *instance.Instance, file *file.File, kind MediaKind, dst DownloadTarget) error
 
 	// Messages
 	GenerateMessageID(ctx context.Context, inst *instance.Instance) (string, error)
@@ -94,10 +141,19 @@ type WhatsAppGateway interface {
 	SendAudioMessage(ctx context.Context, inst *instance.Instance, message *message.Message) (*message.Message, error)
 	SendVoiceMessage(ctx context.Context, inst *instance.Instance, message *message.Message) (*message.Message, error)
 	SendDocumentMessage(ctx context.Context, inst *instance.Instance, message *message.Message) (*message.Message, error)
+	SendButtonsMessage(ctx context.Context, inst *instance.Instance, message *message.Message) (*message.Message, error)
+	SendListMessage(ctx context.Context, inst *instance.Instance, message *message.Message) (*message.Message, error)
+	SendTemplateMessage(ctx context.Context, inst *instance.Instance, message *message.Message) (*message.Message, error)
 	SendReaction(ctx context.Context, inst *instance.Instance, message *message.Message) (*message.Message, error)
+	ForwardMessage(ctx context.Context, inst *instance.Instance, message *message.Message) (*message.Message, error)
 	ReadMessages(ctx context.Context, inst *instance.Instance, chat string, ids []string, sender string) error
+	EditMessage(ctx context.Context, inst *instance.Instance, chat string, id string, kind message.MessageKind, text string) error
+	RevokeMessage(ctx context.Context, inst *instance.Instance, chat string, id string, sender *string) error
+	// RequestHistory asks the phone for count messages sent before the anchor, they arrive later as an on demand history sync
+	RequestHistory(ctx context.Context, inst *instance.Instance, anchor *message.Message, count int) error
 	// Chat
 	SendChatPresence(ctx context.Context, inst *instance.Instance, presence chat.Presence) error
+	SetChatDisappearingTimer(ctx context.Context, inst *instance.Instance, chatJID string, timer chat.DisappearingTimer) error
 	// Contacts
 	CheckPhones(ctx context.Context, inst *instance.Instance, phones []string) ([]PhoneStatus, error)
 	GetContact(ctx context.Context, inst *instance.Instance, phoneOrJID string) (*contact.Contact, error)
@@ -0,0 +1,205 @@
+package campaign
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+const (
+	MaxNameLength   = 255
+	MaxRecipients   = 10000
+	CheckBatchSize  = 100 // phones checked on whatsapp per request
+	MaxDelay        = 10 * time.Minute
+	DefaultMinDelay = 3 * time.Second
+	DefaultMaxDelay = 8 * time.Second
+)
+
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusPaused    Status = "paused"
+	StatusCompleted Status = "completed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Pacing is how the recipients of a campaign are spread over time, on top of the rate limit of the instance
+type Pacing struct {
+	MinDelayMs   int `json:"min_delay_ms"` // wait between two recipients, randomised up to max
+	MaxDelayMs   int `json:"max_delay_ms"`
+	BatchSize    int `json:"batch_size"`     // recipients sent before a longer pause, 0 disables
+	BatchPauseMs int `json:"batch_pause_ms"` // pause after each batch
+}
+
+func DefaultPacing() Pacing {
+	return Pacing{
+		MinDelayMs: int(DefaultMinDelay / time.Millisecond),
+		MaxDelayMs: int(DefaultMaxDelay / time.Millisecond),
+	}
+}
+
+func (p Pacing) Validate() error {
+	maxDelayMs := int(MaxDelay / time.Millisecond)
+
+	if p.MinDelayMs < 0 || p.MaxDelayMs < 0 || p.MinDelayMs > p.MaxDelayMs || p.MaxDelayMs > maxDelayMs {
+		return ErrInvalidPacing
+	}
+
+	if p.BatchSize < 0 || p.BatchPauseMs < 0 || p.BatchPauseMs > maxDelayMs {
+		return ErrInvalidPacing
+	}
+
+	return nil
+}
+
+// Delay is the wait after the given number of recipients were sent
+func (p Pacing) Delay(dispatched int) time.Duration {
+	delay := time.Duration(p.MinDelayMs) * time.Millisecond
+	if p.MaxDelayMs > p.MinDelayMs {
+		delay += rand.N(time.Duration(p.MaxDelayMs-p.MinDelayMs) * time.Millisecond)
+	}
+
+	if p.BatchSize > 0 && dispatched > 0 && dispatched%p.BatchSize == 0 {
+		delay += time.Duration(p.BatchPauseMs) * time.Millisecond
+	}
+
+	return delay
+}
+
+// Campaign sends the same message to many recipients, the payload is the body of the send endpoint of the
+// kind without the "to", that is filled for each recipient
+type Campaign struct {
+	ID         string              `json:"id"`
+	InstanceID string              `json:"instance_id"`
+	Name       string              `json:"name"`
+	Kind       message.MessageKind `json:"kind"`
+	Payload    json.RawMessage     `json:"payload"`
+	Pacing     Pacing              `json:"pacing"`
+
+	Status     Status    `json:"status"`
+	Dispatched int       `json:"dispatched"` // recipients handed to be sent, drives the batch pauses
+	NextSendAt time.Time `json:"next_send_at"`
+	Version    int       `json:"-"` // changes on every update, so two workers never take the same turn
+
+	Progress *Progress `json:"progress,omitempty"`
+
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Progress counts the recipients of a campaign by status
+type Progress struct {
+	Total   int `json:"total"`
+	Pending int `json:"pending"`
+	Sending int `json:"sending"`
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+	Invalid int `json:"invalid"`
+	Skipped int `json:"skipped"`
+}
+
+func (p *Progress) Add(status RecipientStatus, count int) {
+	p.Total += count
+
+	switch status {
+	case RecipientPending:
+		p.Pending += count
+	case RecipientSending:
+		p.Sending += count
+	case RecipientSent:
+		p.Sent += count
+	case RecipientFailed:
+		p.Failed += count
+	case RecipientInvalid:
+		p.Invalid += count
+	case RecipientSkipped:
+		p.Skipped += count
+	}
+}
+
+// New creates a campaign that starts running right away
+func New(instanceID string, name string, kind message.MessageKind, payload json.RawMessage, pacing Pacing) *Campaign {
+	id, _ := uuid.NewV7()
+	now := time.Now().UTC()
+
+	return &Campaign{
+		ID:         id.String(),
+		InstanceID: instanceID,
+		Name:       name,
+		Kind:       kind,
+		Payload:    payload,
+		Pacing:     pacing,
+		Status:     StatusRunning,
+		NextSendAt: now,
+		StartedAt:  now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func (c *Campaign) IsFinished() bool {
+	return c.Status == StatusCompleted || c.Status == StatusCanceled
+}
+
+// Advance takes the turn of the next recipient and schedules the one after it following the pacing
+func (c *Campaign) Advance() {
+	now := time.Now().UTC()
+	c.Dispatched++
+	c.NextSendAt = now.Add(c.Pacing.Delay(c.Dispatched))
+	c.UpdatedAt = now
+}
+
+// Postpone holds the campaign, used while the instance is offline or rate limited
+func (c *Campaign) Postpone(delay time.Duration) {
+	now := time.Now().UTC()
+	c.NextSendAt = now.Add(delay)
+	c.UpdatedAt = now
+}
+
+func (c *Campaign) Pause() error {
+	if c.Status != StatusRunning {
+		return ErrNotRunning
+	}
+
+	c.Status = StatusPaused
+	c.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (c *Campaign) Resume() error {
+	if c.Status != StatusPaused {
+		return ErrNotPaused
+	}
+
+	now := time.Now().UTC()
+	c.Status = StatusRunning
+	c.NextSendAt = now
+	c.UpdatedAt = now
+	return nil
+}
+
+func (c *Campaign) Cancel() error {
+	if c.IsFinished() {
+		return ErrFinished
+	}
+
+	c.finish(StatusCanceled)
+	return nil
+}
+
+func (c *Campaign) Complete() {
+	c.finish(StatusCompleted)
+}
+
+func (c *Campaign) finish(status Status) {
+	now := time.Now().UTC()
+	c.Status = status
+	c.FinishedAt = &now
+	c.UpdatedAt = now
+}
@@ -0,0 +1,104 @@
+package campaign_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCampaign(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Campaign Entity Suite")
+}
+
+var _ = Describe("Campaign entity", func() {
+	newCampaign := func(pacing campaign.Pacing) *campaign.Campaign {
+		return campaign.New("instance-1", "promo", message.MessageKindText, []byte(`{"text":"hi"}`), pacing)
+	}
+
+	It("should validate the pacing", func() {
+		Expect(campaign.DefaultPacing().Validate()).To(Succeed())
+		Expect(campaign.Pacing{MinDelayMs: 2000, MaxDelayMs: 1000}.Validate()).To(Equal(campaign.ErrInvalidPacing))
+		Expect(campaign.Pacing{MaxDelayMs: int(campaign.MaxDelay/time.Millisecond) + 1}.Validate()).To(Equal(campaign.ErrInvalidPacing))
+		Expect(campaign.Pacing{BatchSize: -1}.Validate()).To(Equal(campaign.ErrInvalidPacing))
+	})
+
+	It("should wait between recipients and pause after each batch", func() {
+		pacing := campaign.Pacing{MinDelayMs: 1000, MaxDelayMs: 2000, BatchSize: 3, BatchPauseMs: 60000}
+
+		for dispatched := 1; dispatched <= 6; dispatched++ {
+			delay := pacing.Delay(dispatched)
+			if dispatched%3 == 0 {
+				Expect(delay).To(BeNumerically(">=", 61*time.Second))
+				Expect(delay).To(BeNumerically("<=", 62*time.Second))
+				continue
+			}
+			Expect(delay).To(BeNumerically(">=", time.Second))
+			Expect(delay).To(BeNumerically("<=", 2*time.Second))
+		}
+	})
+
+	It("should advance to the next turn following the pacing", func() {
+		c := newCampaign(campaign.Pacing{MinDelayMs: 5000, MaxDelayMs: 5000})
+		Expect(c.Status).To(Equal(campaign.StatusRunning))
+
+		before := time.Now()
+		c.Advance()
+		Expect(c.Dispatched).To(Equal(1))
+		Expect(c.NextSendAt).To(BeTemporally("~", before.Add(5*time.Second), time.Second))
+	})
+
+	It("should pause, resume and cancel", func() {
+		c := newCampaign(campaign.DefaultPacing())
+
+		Expect(c.Resume()).To(Equal(campaign.ErrNotPaused))
+		Expect(c.Pause()).To(Succeed())
+		Expect(c.Pause()).To(Equal(campaign.ErrNotRunning))
+		Expect(c.Resume()).To(Succeed())
+		Expect(c.Status).To(Equal(campaign.StatusRunning))
+
+		Expect(c.Cancel()).To(Succeed())
+		Expect(c.Status).To(Equal(campaign.StatusCanceled))
+		Expect(c.FinishedAt).ToNot(BeNil())
+		Expect(c.Cancel()).To(Equal(campaign.ErrFinished))
+	})
+
+	It("should count the recipients by status", func() {
+		progress := campaign.Progress{}
+		progress.Add(campaign.RecipientSent, 2)
+		progress.Add(campaign.RecipientPending, 3)
+		progress.Add(campaign.RecipientInvalid, 1)
+
+		Expect(progress).To(Equal(campaign.Progress{Total: 6, Pending: 3, Sent: 2, Invalid: 1}))
+	})
+
+	It("should track the result of a recipient", func() {
+		r := campaign.NewRecipient("campaign-1", "5511999999999", "5511999999999@s.whatsapp.net")
+		Expect(r.Status).To(Equal(campaign.RecipientPending))
+
+		r.MarkSending()
+		r.Fail(errors.New("boom"))
+		Expect(r.Status).To(Equal(campaign.RecipientFailed))
+		Expect(*r.Error).To(Equal("boom"))
+
+		externalID := "3EB0000000000001"
+		msg := message.NewMessage(&externalID, "me@s.whatsapp.net", r.To, message.NewTextContent("hi", nil), nil, nil, true)
+		msg.MarkAsSent(time.Now())
+
+		r.MarkSent(msg)
+		Expect(r.Status).To(Equal(campaign.RecipientSent))
+		Expect(*r.MessageID).To(Equal(msg.ID))
+		Expect(*r.ExternalID).To(Equal(externalID))
+		Expect(r.Error).To(BeNil())
+
+		invalid := campaign.NewInvalidRecipient("campaign-1", "5500000000000")
+		Expect(invalid.Status).To(Equal(campaign.RecipientInvalid))
+		Expect(invalid.To).To(BeEmpty())
+		Expect(*invalid.Error).To(Equal(campaign.ErrNotOnWhatsApp.Error()))
+	})
+})
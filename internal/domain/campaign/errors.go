@@ -0,0 +1,19 @@
+package campaign
+
+import "errors"
+
+var (
+	ErrCampaignNotFound  = errors.New("campaign not found")
+	ErrInvalidName       = errors.New("invalid campaign name")
+	ErrNoRecipients      = errors.New("campaign has no recipients")
+	ErrTooManyRecipients = errors.New("too many campaign recipients")
+	ErrInvalidRecipient  = errors.New("invalid campaign recipient")
+	ErrInvalidPacing     = errors.New("invalid campaign pacing")
+	ErrNotRunning        = errors.New("campaign is not running")
+	ErrNotPaused         = errors.New("campaign is not paused")
+	ErrFinished          = errors.New("campaign is already finished")
+	ErrNotOnWhatsApp     = errors.New("phone is not on whatsapp")
+	ErrInvalidStatus     = errors.New("invalid recipient status")
+	ErrInvalidLimit      = errors.New("invalid recipients limit")
+	ErrConflict          = errors.New("campaign was changed at the same time, try again")
+)
@@ -0,0 +1,50 @@
+package campaign
+
+import "github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+
+const (
+	// To listen all campaign events, use "campaign:*"
+
+	// Published when a campaign is created and starts sending
+	EventStarted events.EventName = "campaign:started"
+	// Published after each recipient is sent or failed
+	EventProgress events.EventName = "campaign:progress"
+	// Published when a campaign is paused
+	EventPaused events.EventName = "campaign:paused"
+	// Published when a paused campaign is resumed
+	EventResumed events.EventName = "campaign:resumed"
+	// Published when every recipient of a campaign was handled
+	EventCompleted events.EventName = "campaign:completed"
+	// Published when a campaign is canceled
+	EventCanceled events.EventName = "campaign:canceled"
+)
+
+// Event builds the event of a change of status of the campaign
+func (c *Campaign) Event(name events.EventName) events.Event {
+	return events.New(
+		name,
+		PayloadCampaign{
+			ID:       c.ID,
+			Name:     c.Name,
+			Status:   c.Status,
+			Progress: c.Progress,
+		},
+		&c.InstanceID,
+	)
+}
+
+func (c *Campaign) EventProgress(r *Recipient) events.Event {
+	return events.New(
+		EventProgress,
+		PayloadCampaignProgress{
+			ID:              c.ID,
+			RecipientID:     r.ID,
+			To:              r.To,
+			RecipientStatus: r.Status,
+			MessageID:       r.MessageID,
+			Error:           r.Error,
+			Progress:        c.Progress,
+		},
+		&c.InstanceID,
+	)
+}
@@ -0,0 +1,18 @@
+package campaign
+
+type PayloadCampaign struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Status   Status    `json:"status"`
+	Progress *Progress `json:"progress"`
+}
+
+type PayloadCampaignProgress struct {
+	ID              string          `json:"id"`
+	RecipientID     string          `json:"recipient_id"`
+	To              string          `json:"to"`
+	RecipientStatus RecipientStatus `json:"recipient_status"`
+	MessageID       *string         `json:"message_id"`
+	Error           *string         `json:"error"`
+	Progress        *Progress       `json:"progress"`
+}
@@ -0,0 +1,96 @@
+package campaign
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+type RecipientStatus string
+
+const (
+	RecipientPending RecipientStatus = "pending"
+	RecipientSending RecipientStatus = "sending"
+	RecipientSent    RecipientStatus = "sent"
+	RecipientFailed  RecipientStatus = "failed"
+	RecipientInvalid RecipientStatus = "invalid" // not on whatsapp, never sent
+	RecipientSkipped RecipientStatus = "skipped" // the campaign was canceled before its turn
+)
+
+func (s RecipientStatus) IsValid() bool {
+	switch s {
+	case RecipientPending, RecipientSending, RecipientSent, RecipientFailed, RecipientInvalid, RecipientSkipped:
+		return true
+	default:
+		return false
+	}
+}
+
+// Recipient is a chat of a campaign with the result of its send
+type Recipient struct {
+	ID         string          `json:"id"`
+	CampaignID string          `json:"campaign_id"`
+	Original   string          `json:"original"` // phone or jid as given
+	To         string          `json:"to"`       // jid the message is sent to, empty when invalid
+	Status     RecipientStatus `json:"status"`
+
+	MessageID  *string    `json:"message_id"`
+	ExternalID *string    `json:"external_id"`
+	Error      *string    `json:"error"`
+	SentAt     *time.Time `json:"sent_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewRecipient(campaignID string, original string, to string) *Recipient {
+	id, _ := uuid.NewV7()
+	now := time.Now().UTC()
+
+	return &Recipient{
+		ID:         id.String(),
+		CampaignID: campaignID,
+		Original:   original,
+		To:         to,
+		Status:     RecipientPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// NewInvalidRecipient records a phone that is not on whatsapp, so it shows in the results
+func NewInvalidRecipient(campaignID string, original string) *Recipient {
+	r := NewRecipient(campaignID, original, "")
+	reason := ErrNotOnWhatsApp.Error()
+	r.Status = RecipientInvalid
+	r.Error = &reason
+	return r
+}
+
+func (r *Recipient) MarkSending() {
+	r.Status = RecipientSending
+	r.UpdatedAt = time.Now().UTC()
+}
+
+func (r *Recipient) MarkSent(msg *message.Message) {
+	r.Status = RecipientSent
+	r.MessageID = &msg.ID
+	r.ExternalID = msg.ExternalID
+	r.SentAt = msg.SentAt
+	r.Error = nil
+	r.UpdatedAt = time.Now().UTC()
+}
+
+func (r *Recipient) Fail(err error) {
+	reason := err.Error()
+	r.Status = RecipientFailed
+	r.Error = &reason
+	r.UpdatedAt = time.Now().UTC()
+}
+
+// Release gives the turn back, the recipient is sent again later
+func (r *Recipient) Release() {
+	r.Status = RecipientPending
+	r.UpdatedAt = time.Now().UTC()
+}
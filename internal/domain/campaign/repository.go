@@ -0,0 +1,114 @@
+package campaign
+
+import "time"
+
+const (
+	DefaultRecipientsLimit = 100
+	MaxRecipientsLimit     = 1000
+)
+
+type CampaignQueryOptions struct {
+	ID         *string `db:"id"`
+	InstanceID *string `db:"instance_id"`
+	Status     *Status `db:"status"`
+
+	Limit *int `db:"limit"`
+}
+
+type CampaignQueryOption func(*CampaignQueryOptions)
+
+type CampaignRepository interface {
+	Insert(c *Campaign) error
+
+	// Update stores the campaign only when the stored version is the one it was loaded with, it reports whether
+	// it was stored, so a worker and a pause never overwrite each other
+	Update(c *Campaign) (bool, error)
+
+	// Due returns the running campaigns whose next recipient may be sent
+	Due(now time.Time, limit int) ([]*Campaign, error)
+
+	Get(opts ...CampaignQueryOption) (*Campaign, error)
+	List(opts ...CampaignQueryOption) ([]*Campaign, error)
+}
+
+type RecipientQueryOptions struct {
+	CampaignID *string          `db:"campaign_id"`
+	Status     *RecipientStatus `db:"status"`
+	After      *string          `db:"after"` // ids are time ordered, so they work as cursor
+
+	Limit *int `db:"limit"`
+}
+
+type RecipientQueryOption func(*RecipientQueryOptions)
+
+type RecipientRepository interface {
+	InsertMany(recipients []*Recipient) error
+	Update(r *Recipient) error
+
+	// UpdateFrom stores the recipient only when the stored status is still from
+	UpdateFrom(r *Recipient, from RecipientStatus) (bool, error)
+
+	// Next returns the oldest pending recipient of the campaign
+	Next(campaignID string) (*Recipient, error)
+
+	// Release puts back the recipients left as sending since before the given time
+	Release(before time.Time) (int64, error)
+
+	// Renew keeps the claim of a recipient being sent, so a send taking long is not released, it reports false
+	// when the recipient is no longer sending
+	Renew(id string, now time.Time) (bool, error)
+
+	// Skip marks the pending recipients of the campaign as skipped
+	Skip(campaignID string) (int64, error)
+
+	Progress(campaignID string) (*Progress, error)
+	List(opts ...RecipientQueryOption) ([]*Recipient, error)
+}
+
+func WhereID(id string) CampaignQueryOption {
+	return func(o *CampaignQueryOptions) {
+		o.ID = &id
+	}
+}
+
+func WhereInstanceID(instanceID string) CampaignQueryOption {
+	return func(o *CampaignQueryOptions) {
+		o.InstanceID = &instanceID
+	}
+}
+
+func WhereStatus(status Status) CampaignQueryOption {
+	return func(o *CampaignQueryOptions) {
+		o.Status = &status
+	}
+}
+
+func Limit(limit int) CampaignQueryOption {
+	return func(o *CampaignQueryOptions) {
+		o.Limit = &limit
+	}
+}
+
+func WhereCampaignID(campaignID string) RecipientQueryOption {
+	return func(o *RecipientQueryOptions) {
+		o.CampaignID = &campaignID
+	}
+}
+
+func WhereRecipientStatus(status RecipientStatus) RecipientQueryOption {
+	return func(o *RecipientQueryOptions) {
+		o.Status = &status
+	}
+}
+
+func WhereAfter(id string) RecipientQueryOption {
+	return func(o *RecipientQueryOptions) {
+		o.After = &id
+	}
+}
+
+func RecipientsLimit(limit int) RecipientQueryOption {
+	return func(o *RecipientQueryOptions) {
+		o.Limit = &limit
+	}
+}
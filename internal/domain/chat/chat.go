@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Chat struct {
+	ID            string     `json:"id"`
+	JID           string     `json:"jid"`
+	Name          *string    `json:"name"`
+	UnreadCount   uint32     `json:"unread_count"`
+	Archived      bool       `json:"archived"`
+	Pinned        bool       `json:"pinned"`
+	MutedUntil    *time.Time `json:"muted_until"`
+	Expiration    *uint32    `json:"expiration"` // disappearing messages timer in seconds, nil when disabled
+	LastMessageAt *time.Time `json:"last_message_at"`
+
+	InstanceID string    `json:"instance_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func New(jid string, instanceID string) *Chat {
+	uuid, _ := uuid.NewV7()
+	return &Chat{
+		ID:         uuid.String(),
+		JID:        jid,
+		InstanceID: instanceID,
+		CreatedAt:  time.Now().UTC(),
+		UpdatedAt:  time.Now().UTC(),
+	}
+}
+
+func (c *Chat) IsMuted() bool {
+	return c.MutedUntil != nil && c.MutedUntil.After(time.Now())
+}
+
+func (c *Chat) HasExpiration() bool {
+	return c.Expiration != nil && *c.Expiration > 0
+}
+
+// SetExpiration changes the disappearing messages timer, 0 disables it, it reports whether the timer changed
+func (c *Chat) SetExpiration(seconds uint32) bool {
+	current := uint32(0)
+	if c.Expiration != nil {
+		current = *c.Expiration
+	}
+
+	if current == seconds {
+		return false
+	}
+
+	c.Expiration = nil
+	if seconds > 0 {
+		c.Expiration = &seconds
+	}
+	c.UpdatedAt = time.Now().UTC()
+
+	return true
+}
+
+// IsDirectJID reports whether the jid is a one-to-one chat, groups, newsletters and broadcasts are not
+func IsDirectJID(jid string) bool {
+	return strings.HasSuffix(jid, "@s.whatsapp.net") || strings.HasSuffix(jid, "@lid")
+}
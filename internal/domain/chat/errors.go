@@ -5,4 +5,6 @@ import "errors"
 var (
 	ErrInvalidJID              = errors.New("invalid jid")
 	ErrInvalidChatPresenceType = errors.New("invalid chat presence type")
+	ErrInvalidDisappearing     = errors.New("invalid disappearing timer")
+	ErrNotDirectChat           = errors.New("chat is not a one-to-one chat")
 )
@@ -11,4 +11,6 @@ const (
 	ChatChangedMute     = "chat:changed/mute"     // Dispatched when a chat is muted or unmuted
 	ChatChangedPin      = "chat:changed/pin"      // Dispatched when a chat is pinned or unpinned
 	ChatChangedArchive  = "chat:changed/archive"  // Dispatched when a chat is archived or unarchived
+	// Dispatched when the disappearing messages timer of a one-to-one chat is changed, by us or the other side
+	ChatChangedDisappearing = "chat:changed/disappearing"
 )
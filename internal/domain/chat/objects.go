@@ -1,5 +1,7 @@
 package chat
 
+import "time"
+
 type ChatPresenceType string
 
 const (
@@ -21,3 +23,39 @@ type Presence struct {
 	To   string
 	Type ChatPresenceType
 }
+
+// DisappearingTimer is the default expiration of the messages of a chat, one-to-one chats only accept these fixed values
+type DisappearingTimer string
+
+const (
+	DisappearingTimerOff     DisappearingTimer = "off"
+	DisappearingTimer24Hours DisappearingTimer = "24h"
+	DisappearingTimer7Days   DisappearingTimer = "7d"
+	DisappearingTimer90Days  DisappearingTimer = "90d"
+)
+
+func (t DisappearingTimer) IsValid() bool {
+	switch t {
+	case DisappearingTimerOff, DisappearingTimer24Hours, DisappearingTimer7Days, DisappearingTimer90Days:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t DisappearingTimer) ToExpiration() uint32 {
+	switch t {
+	case DisappearingTimer24Hours:
+		return 24 * 60 * 60
+	case DisappearingTimer7Days:
+		return 7 * 24 * 60 * 60
+	case DisappearingTimer90Days:
+		return 90 * 24 * 60 * 60
+	default:
+		return 0
+	}
+}
+
+func (t DisappearingTimer) ToDuration() time.Duration {
+	return time.Duration(t.ToExpiration()) * time.Second
+}
@@ -45,3 +45,10 @@ type PayloadChatChangedArchive struct {
 	Archived  bool      `json:"archived"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+type PayloadChatChangedDisappearing struct {
+	Chat       string    `json:"chat"`
+	Sender     string    `json:"sender"`
+	Expiration uint32    `json:"expiration"` // timer in seconds, 0 when disabled
+	Timestamp  time.Time `json:"timestamp"`
+}
@@ -0,0 +1,47 @@
+package chat
+
+type ChatQueryOptions struct {
+	ID         *string `db:"id"`
+	JID        *string `db:"jid"`
+	InstanceID *string `db:"instance_id"`
+
+	Limit *int `db:"limit"`
+}
+
+type ChatQueryOption func(*ChatQueryOptions)
+
+type ChatRepository interface {
+	// Save stores the chat, a chat already stored for the same instance and jid is updated instead
+	Save(chat *Chat) error
+	// SaveMany saves the chats in a single transaction
+	SaveMany(chats []*Chat) error
+
+	Update(chat *Chat) error
+
+	Get(opts ...ChatQueryOption) (*Chat, error)
+	List(opts ...ChatQueryOption) ([]*Chat, error)
+}
+
+func WhereID(id string) ChatQueryOption {
+	return func(o *ChatQueryOptions) {
+		o.ID = &id
+	}
+}
+
+func WhereJID(jid string) ChatQueryOption {
+	return func(o *ChatQueryOptions) {
+		o.JID = &jid
+	}
+}
+
+func WhereInstanceID(instanceID string) ChatQueryOption {
+	return func(o *ChatQueryOptions) {
+		o.InstanceID = &instanceID
+	}
+}
+
+func Limit(limit int) ChatQueryOption {
+	return func(o *ChatQueryOptions) {
+		o.Limit = &limit
+	}
+}
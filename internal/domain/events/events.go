@@ -13,6 +13,7 @@ const (
 	// Eventos de mensagem
 	EventMessageQueued EventName = "message.queued"
 	EventMessageSent   EventName = "message.sent"
+	EventMessageFailed EventName = "message.failed"
 )
 
 type Event struct {
@@ -1,7 +1,29 @@
 package events
 
+import "time"
+
 type PayloadMessageQueued struct {
-	ID         string `json:"id"`
-	To         string `json:"to"`
-	InstanceID string `json:"instance_id"`
+	ID          string     `json:"id"`
+	To          string     `json:"to"`
+	InstanceID  string     `json:"instance_id"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+type PayloadMessageSent struct {
+	ID          string     `json:"id"`
+	To          string     `json:"to"`
+	InstanceID  string     `json:"instance_id"`
+	MessageID   *string    `json:"message_id"`
+	ExternalID  *string    `json:"external_id"`
+	SentAt      *time.Time `json:"sent_at"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+type PayloadMessageFailed struct {
+	ID          string     `json:"id"`
+	To          string     `json:"to"`
+	InstanceID  string     `json:"instance_id"`
+	Attempts    int        `json:"attempts"`
+	Error       *string    `json:"error"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
 }
@@ -8,6 +8,7 @@ var (
 	ErrFileTooLarge    = errors.New("file too large")
 	ErrUnsupported     = errors.New("unsupported file type")
 	ErrCorruptedFile   = errors.New("corrupted file")
+	ErrImageTooLarge   = errors.New("image has too many pixels")
 	ErrUploadFailed    = errors.New("file upload failed")
 	ErrDownloadFailed  = errors.New("file download failed")
 	ErrStorageFailed   = errors.New("file storage failed")
@@ -21,4 +22,17 @@ var (
 
 	ErrFileSourceEmpty = errors.New("file source is empty")
 	ErrInvalidFileID   = errors.New("invalid file ID")
+
+	ErrInvalidMediaType     = errors.New("invalid media type")
+	ErrMediaNotDownloadable = errors.New("media url or direct path, media key and encrypted sha256 are required")
+	ErrMediaTypeMismatch    = errors.New("media type does not match the message")
+	ErrInvalidMediaURL      = errors.New("media url must point to a whatsapp media host")
+	ErrMediaHashMismatch    = errors.New("media hash does not match")
+	ErrMediaExpired         = errors.New("media is no longer available on whatsapp")
+
+	ErrThumbnailUnsupported = errors.New("no thumbnail can be generated for this file")
+
+	ErrMimeRequired            = errors.New("mime type is required")
+	ErrPresignedUploadNotFound = errors.New("presigned upload not found or expired")
+	ErrPresignedUploadMissing  = errors.New("nothing was uploaded to the presigned url")
 )
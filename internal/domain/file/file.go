@@ -27,9 +27,16 @@ type File struct {
 	Height   *uint32 `json:"height,omitempty"`
 	Duration *uint32 `json:"duration,omitempty"`
 	Pages    *uint32 `json:"pages,omitempty"`
+	Waveform []byte  `json:"waveform,omitempty"` // just for voice notes, see GenerateWaveform
 
 	Thumbnail *ImageFile `json:"thumbnail,omitempty"`
 
+	// uploads of the same content by an instance share the file, the stored object goes with the last reference
+	References uint32 `json:"references,omitempty"`
+
+	Pinned      bool `json:"pinned"`                 // retention never deletes pinned files when the rule keeps them
+	IsThumbnail bool `json:"is_thumbnail,omitempty"` // generated for another file, goes once no file uses it
+
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 
@@ -97,6 +104,9 @@ func NewFile(name, mime, extension, path, sha256 string, size uint64, width, hei
 		Height:    height,
 		Duration:  duration,
 		Pages:     pages,
+
+		References: 1,
+
 		CreatedAt: &createdAt,
 		UpdatedAt: &updatedAt,
 	}
@@ -128,6 +138,8 @@ func NewFromMime(mime string) *File {
 		Duration: nil,
 		Pages:    nil,
 
+		References: 1,
+
 		CreatedAt: &createdAt,
 		UpdatedAt: &updatedAt,
 
@@ -135,6 +147,18 @@ func NewFromMime(mime string) *File {
 	}
 }
 
+// IsStoragePath tells whether the key is named the way the files of this service are stored, a uuid v7 and an
+// extension at the root, so objects other software keeps in the same storage are never taken for them
+func IsStoragePath(key string) bool {
+	id, extension, _ := strings.Cut(key, ".")
+	if strings.Contains(extension, "/") {
+		return false
+	}
+
+	parsed, err := uuid.Parse(id)
+	return err == nil && parsed.Version() == 7 && parsed.String() == id
+}
+
 func (f *File) HasThumbnail() bool {
 	return f.Thumbnail != nil
 }
@@ -0,0 +1,102 @@
+package file_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "File Suite")
+}
+
+var _ = Describe("File", func() {
+	Describe("GenerateWaveform", func() {
+		It("should scale the bars against the loudest one", func() {
+			samples := make([]int16, file.WaveformSamples*10)
+			for i := range samples {
+				if i >= len(samples)/2 {
+					samples[i] = 1000
+				} else {
+					samples[i] = -500
+				}
+			}
+
+			waveform := file.GenerateWaveform(samples)
+			Expect(waveform).To(HaveLen(file.WaveformSamples))
+			Expect(waveform[0]).To(Equal(byte(50)))
+			Expect(waveform[file.WaveformSamples-1]).To(Equal(byte(100)))
+		})
+
+		It("should be flat for silence and short audio", func() {
+			Expect(file.GenerateWaveform(nil)).To(Equal(make([]byte, file.WaveformSamples)))
+			Expect(file.GenerateWaveform(make([]int16, 10))).To(Equal(make([]byte, file.WaveformSamples)))
+			Expect(file.GenerateWaveform([]int16{100, 200})).To(HaveLen(file.WaveformSamples))
+		})
+	})
+
+	Describe("GenerateThumbnail", func() {
+		It("should refuse an image declaring too many pixels before decoding it", func() {
+			var buf bytes.Buffer
+			Expect(png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))).To(Succeed())
+			data := buf.Bytes()
+
+			// the IHDR chunk right after the signature declares 50000x50000, its crc is computed again
+			binary.BigEndian.PutUint32(data[16:], 50000)
+			binary.BigEndian.PutUint32(data[20:], 50000)
+			binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+			_, _, _, err := file.GenerateThumbnail(data, file.ThumbnailMaxSize)
+			Expect(err).To(MatchError(file.ErrImageTooLarge))
+		})
+	})
+
+	Describe("Media", func() {
+		It("should tell whether a file can be downloaded from WhatsApp", func() {
+			f := &file.File{MediaKey: "aa", Sha256Enc: "bb"}
+			Expect(f.IsDownloadable()).To(BeFalse())
+
+			f.DirectPath = "/v/t62.7118-24/12345"
+			Expect(f.IsDownloadable()).To(BeTrue())
+		})
+
+		It("should match the content against its hash", func() {
+			f := &file.File{Sha256: "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"}
+			Expect(f.MatchesSha256([]byte("hello"))).To(BeTrue())
+			Expect(f.MatchesSha256([]byte("world"))).To(BeFalse())
+		})
+	})
+
+	Describe("IsStoragePath", func() {
+		It("should only take the keys the files are stored at", func() {
+			Expect(file.IsStoragePath(file.NewFromMime("image/png").Path)).To(BeTrue())
+			Expect(file.IsStoragePath(file.NewPresignedUpload("video/mp4", time.Minute).Path)).To(BeTrue())
+
+			Expect(file.IsStoragePath("backup.tar.gz")).To(BeFalse())
+			Expect(file.IsStoragePath(".tus/0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b.pending")).To(BeFalse())
+			Expect(file.IsStoragePath("other/0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b.png")).To(BeFalse())
+			Expect(file.IsStoragePath("0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b.png/other")).To(BeFalse())
+			Expect(file.IsStoragePath("6ba7b810-9dad-11d1-80b4-00c04fd430c8.png")).To(BeFalse())
+		})
+	})
+
+	Describe("PresignedUpload", func() {
+		It("should be written to a key named after its id", func() {
+			u := file.NewPresignedUpload("video/mp4", time.Minute)
+
+			Expect(u.Path).To(Equal(u.ID + ".mp4"))
+			Expect(u.Method).To(Equal("PUT"))
+			Expect(u.Headers).To(HaveKeyWithValue("Content-Type", "video/mp4"))
+			Expect(u.ExpiresAt).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+		})
+	})
+})
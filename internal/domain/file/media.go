@@ -0,0 +1,74 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// MediaType is the kind of an encrypted WhatsApp media, it picks the keys used to decrypt it
+type MediaType string
+
+const (
+	MediaTypeImage    MediaType = "image"
+	MediaTypeVideo    MediaType = "video"
+	MediaTypeAudio    MediaType = "audio"
+	MediaTypeSticker  MediaType = "sticker"
+	MediaTypeDocument MediaType = "document"
+)
+
+func (t MediaType) IsValid() bool {
+	switch t {
+	case MediaTypeImage, MediaTypeVideo, MediaTypeAudio, MediaTypeSticker, MediaTypeDocument:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDownloadable reports whether the file carries what is needed to download and decrypt it from WhatsApp
+func (f *File) IsDownloadable() bool {
+	return f.MediaKey != "" && f.Sha256Enc != "" && (f.URL != "" || f.DirectPath != "")
+}
+
+// MatchesSha256 reports whether the data is the content the file describes, a file without hash matches anything
+func (f *File) MatchesSha256(data []byte) bool {
+	if f.Sha256 == "" {
+		return true
+	}
+
+	sum := sha256.Sum256(data)
+	return f.MatchesSha256Sum(hex.EncodeToString(sum[:]))
+}
+
+// MatchesSha256Sum reports whether the hex sha256 is the hash of the content the file describes
+func (f *File) MatchesSha256Sum(sum string) bool {
+	return f.Sha256 == "" || strings.EqualFold(sum, f.Sha256)
+}
+
+// IsWhatsAppMediaURL reports whether the url points to a WhatsApp media host, media are never fetched from
+// anywhere else
+func IsWhatsAppMediaURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	return host == "whatsapp.net" || strings.HasSuffix(host, ".whatsapp.net")
+}
+
+// DownloadName is the name the file is served with, documents keep their own name
+func (f *File) DownloadName(fallback string) string {
+	if f.Name != "" {
+		return f.Name
+	}
+
+	extension := f.Extension
+	if extension == "" {
+		extension = DetectExtension(f.Mime)
+	}
+
+	return fallback + "." + extension
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PresignedUpload is an upload the client writes straight to the storage with a signed URL, completing it
+// registers the file with the same ID
+type PresignedUpload struct {
+	ID        string            `json:"id"`
+	Path      string            `json:"-"`
+	Mime      string            `json:"mime"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+func NewPresignedUpload(mime string, ttl time.Duration) *PresignedUpload {
+	id, _ := uuid.NewV7()
+
+	return &PresignedUpload{
+		ID:        id.String(),
+		Path:      fmt.Sprintf("%s.%s", id, DetectExtension(mime)),
+		Mime:      mime,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": mime},
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+}
@@ -7,15 +7,32 @@ import (
 type FileRepository interface {
 	Insert(file *File) error
 	InsertMany(files []*File) error
+	// InsertOrReference inserts the file, or counts one more reference of the file of the instance with the same
+	// content when there is one, the stored file is returned
+	InsertOrReference(file *File) (*File, error)
 
 	Update(file *File) error
 
+	// Reference counts one more upload of the file, ErrFileNotFound when it was deleted
+	Reference(id string) error
+	// Release drops one reference and returns how many are left, the file row itself is not deleted
+	Release(id string) (uint32, error)
+	// DeleteReleased deletes the file when no reference is left, false when it was referenced again meanwhile
+	DeleteReleased(id string) (bool, error)
+
 	Get(opts ...FileQueryOption) (*File, error)
 	List(opts ...FileQueryOption) ([]*File, error)
 
 	Delete(opts ...FileQueryOption) error
 
 	Count(opts ...FileQueryOption) (uint64, error)
+
+	// OrphanThumbnails lists generated thumbnails created before the time that no file uses anymore
+	OrphanThumbnails(before time.Time, limit int) ([]*File, error)
+
+	Usage(instanceID string) (*Usage, error)
+	// UsageByInstance returns the usage of every instance with files
+	UsageByInstance() ([]*Usage, error)
 }
 
 type FileQueryOptions struct {
@@ -28,6 +45,8 @@ type FileQueryOptions struct {
 	SortBy        string     `db:"sort_by"`
 	HasThumbnail  *bool      `db:"has_thumbnail"`
 	WithThumbnail bool       `db:"with_thumbnail"`
+	Path          *string    `db:"path"`
+	IsThumbnail   *bool      `db:"is_thumbnail"`
 }
 
 type FileQueryOption func(*FileQueryOptions)
@@ -58,6 +77,19 @@ func WhereSha256(sha256 string) FileQueryOption {
 	}
 }
 
+func WherePath(path string) FileQueryOption {
+	return func(o *FileQueryOptions) {
+		o.Path = &path
+	}
+}
+
+// WhereIsThumbnail filters generated thumbnails in or out
+func WhereIsThumbnail(is bool) FileQueryOption {
+	return func(o *FileQueryOptions) {
+		o.IsThumbnail = &is
+	}
+}
+
 func WhereID(id string) FileQueryOption {
 	return func(o *FileQueryOptions) {
 		o.ID = &id
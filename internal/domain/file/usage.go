@@ -0,0 +1,8 @@
+package file
+
+// Usage is how much of the storage the files of an instance take, thumbnails count towards bytes but not files
+type Usage struct {
+	InstanceID string `json:"instance_id" db:"instance_id"`
+	Files      uint64 `json:"files" db:"files"`
+	Bytes      uint64 `json:"bytes" db:"bytes"`
+}
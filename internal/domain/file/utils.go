@@ -1,13 +1,23 @@
 package file
 
 import (
+	"bytes"
 	"image"
-	_ "image/jpeg"
+	_ "image/gif"
+	"image/jpeg"
 	_ "image/png"
+	"math"
 	"mime"
 	"strings"
 )
 
+const (
+	ThumbnailMaxSize   = 320
+	ThumbnailQuality   = 75
+	ThumbnailMaxPixels = 40_000_000 // bigger images are refused before decoding, a few KB can declare gigabytes
+	WaveformSamples    = 64         // bars of the voice note player
+)
+
 // I created a map of preferred extensions for certain MIME types,
 // because mime.ExtensionsByType can return multiple options,
 // and sometimes the defaults are uncommon or not ideal.
@@ -84,3 +94,94 @@ func DecodeImageConfig(data *[]byte) (*uint32, *uint32, error) {
 
 	return &width, &height, nil
 }
+
+// GenerateThumbnail decodes the image and scales it down with nearest neighbour so the biggest side
+// fits in maxSize, the result is always a jpeg because that is what whatsapp expects on thumbnails. Images
+// over ThumbnailMaxPixels are refused from their header, before anything is decoded
+func GenerateThumbnail(data []byte, maxSize int) ([]byte, *uint32, *uint32, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, nil, ErrCorruptedFile
+	}
+
+	if uint64(cfg.Width)*uint64(cfg.Height) > ThumbnailMaxPixels {
+		return nil, nil, nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, nil, ErrCorruptedFile
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, nil, nil, ErrCorruptedFile
+	}
+
+	newWidth, newHeight := width, height
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			newWidth = maxSize
+			newHeight = max(1, height*maxSize/width)
+		} else {
+			newHeight = maxSize
+			newWidth = max(1, width*maxSize/height)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		srcY := bounds.Min.Y + y*height/newHeight
+		for x := 0; x < newWidth; x++ {
+			srcX := bounds.Min.X + x*width/newWidth
+			dst.Set(x, y, src.At(srcX, srcY))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: ThumbnailQuality}); err != nil {
+		return nil, nil, nil, ErrCorruptedFile
+	}
+
+	w := uint32(newWidth)
+	h := uint32(newHeight)
+
+	return buf.Bytes(), &w, &h, nil
+}
+
+// GenerateWaveform reduces mono pcm samples to the WaveformSamples bars drawn in the voice note player,
+// each bar is the mean loudness of its slice scaled from 0 to 100 against the loudest one
+func GenerateWaveform(samples []int16) []byte {
+	waveform := make([]byte, WaveformSamples)
+	if len(samples) == 0 {
+		return waveform
+	}
+
+	bars := make([]float64, WaveformSamples)
+	loudest := 0.0
+
+	for i := range bars {
+		start := i * len(samples) / WaveformSamples
+		end := max((i+1)*len(samples)/WaveformSamples, start+1)
+		end = min(end, len(samples))
+
+		sum := 0.0
+		for _, sample := range samples[start:end] {
+			sum += math.Abs(float64(sample))
+		}
+
+		bars[i] = sum / float64(end-start)
+		loudest = max(loudest, bars[i])
+	}
+
+	if loudest == 0 {
+		return waveform
+	}
+
+	for i, bar := range bars {
+		waveform[i] = byte(math.Round(bar / loudest * 100))
+	}
+
+	return waveform
+}
@@ -21,10 +21,12 @@ const (
 	EventParticipantsLeft     = "group:participants/left"     // Dispatched when an user leaves a group
 
 	// To listen all new message events, use the prefix "group:new/*"
-	EventNewTextMessage     events.EventName = "group:new/text"     // Dispatched when a new text message is received from a group
-	EventNewImageMessage    events.EventName = "group:new/image"    // Dispatched when a new image message is received from a group
-	EventNewVideoMessage    events.EventName = "group:new/video"    // Dispatched when a new video message is received from a group
-	EventNewAudioMessage    events.EventName = "group:new/audio"    // Dispatched when a new audio message is received from a group
-	EventNewVoiceMessage    events.EventName = "group:new/voice"    // Dispatched when a new voice message is received from a group
-	EventNewDocumentMessage events.EventName = "group:new/document" // Dispatched when a new document message is received from a group
+	EventNewTextMessage     events.EventName = "group:new/text"         // Dispatched when a new text message is received from a group
+	EventNewImageMessage    events.EventName = "group:new/image"        // Dispatched when a new image message is received from a group
+	EventNewVideoMessage    events.EventName = "group:new/video"        // Dispatched when a new video message is received from a group
+	EventNewAudioMessage    events.EventName = "group:new/audio"        // Dispatched when a new audio message is received from a group
+	EventNewVoiceMessage    events.EventName = "group:new/voice"        // Dispatched when a new voice message is received from a group
+	EventNewDocumentMessage events.EventName = "group:new/document"     // Dispatched when a new document message is received from a group
+	EventNewButtonReply     events.EventName = "group:new/button_reply" // Dispatched when a participant taps a button of a buttons or template message
+	EventNewListReply       events.EventName = "group:new/list_reply"   // Dispatched when a participant picks a row of a list message
 )
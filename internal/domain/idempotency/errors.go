@@ -0,0 +1,9 @@
+package idempotency
+
+import "errors"
+
+var (
+	ErrInvalidKey = errors.New("invalid idempotency key")
+	ErrKeyInUse   = errors.New("a request with this idempotency key is still being processed")
+	ErrKeyReused  = errors.New("idempotency key already used for a different request")
+)
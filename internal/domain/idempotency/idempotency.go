@@ -0,0 +1,94 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxKeyLength = 255
+
+	// LockTimeout is how long a request may hold its key, a key still processing after it belongs to a request
+	// that never finished, the process stopped in the middle of it, and is taken over by the next retry
+	LockTimeout = 5 * time.Minute
+)
+
+type Status string
+
+const (
+	StatusProcessing Status = "processing"
+	StatusCompleted  Status = "completed"
+)
+
+// Record keeps the response of a request sent with an idempotency key, so a retry with the same key gets the
+// original response instead of running the request again
+type Record struct {
+	ID             string
+	InstanceID     string
+	Key            string
+	Fingerprint    string // hash of the method, path and body, a key is bound to a single request
+	Status         Status
+	ResponseStatus int
+	ResponseBody   []byte
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+func New(instanceID string, key string, fingerprint string, ttl time.Duration) *Record {
+	uuid, _ := uuid.NewV7()
+	now := time.Now().UTC()
+
+	return &Record{
+		ID:          uuid.String(),
+		InstanceID:  instanceID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      StatusProcessing,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+func ValidateKey(key string) error {
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > MaxKeyLength {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
+
+func Fingerprint(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (r *Record) Complete(status int, body []byte) {
+	r.Status = StatusCompleted
+	r.ResponseStatus = status
+	r.ResponseBody = body
+}
+
+func (r *Record) IsCompleted() bool {
+	return r.Status == StatusCompleted
+}
+
+func (r *Record) Matches(fingerprint string) bool {
+	return r.Fingerprint == fingerprint
+}
+
+// IsStale reports whether the key can be taken by a new request, it expired or the request holding it was abandoned
+func (r *Record) IsStale(now time.Time) bool {
+	if !now.Before(r.ExpiresAt) {
+		return true
+	}
+
+	return r.Status == StatusProcessing && now.Sub(r.CreatedAt) > LockTimeout
+}
@@ -0,0 +1,60 @@
+package idempotency_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/idempotency"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestIdempotency(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Idempotency Suite")
+}
+
+var _ = Describe("Idempotency record", func() {
+	It("should validate the key", func() {
+		Expect(idempotency.ValidateKey("a3f1c2d4-order-42")).To(Succeed())
+		Expect(idempotency.ValidateKey("  ")).To(Equal(idempotency.ErrInvalidKey))
+
+		long := make([]byte, idempotency.MaxKeyLength+1)
+		for i := range long {
+			long[i] = 'k'
+		}
+		Expect(idempotency.ValidateKey(string(long))).To(Equal(idempotency.ErrInvalidKey))
+	})
+
+	It("should bind the fingerprint to the method, path and body", func() {
+		fingerprint := idempotency.Fingerprint("POST", "/messages/text", []byte(`{"text":"hi"}`))
+
+		Expect(idempotency.Fingerprint("POST", "/messages/text", []byte(`{"text":"hi"}`))).To(Equal(fingerprint))
+		Expect(idempotency.Fingerprint("POST", "/messages/text", []byte(`{"text":"hello"}`))).ToNot(Equal(fingerprint))
+		Expect(idempotency.Fingerprint("POST", "/messages/text?async=true", []byte(`{"text":"hi"}`))).ToNot(Equal(fingerprint))
+	})
+
+	It("should complete with the response", func() {
+		rec := idempotency.New("instance-1", "key", "fingerprint", time.Hour)
+		Expect(rec.Status).To(Equal(idempotency.StatusProcessing))
+		Expect(rec.IsCompleted()).To(BeFalse())
+
+		rec.Complete(200, []byte(`{"success":true}`))
+		Expect(rec.IsCompleted()).To(BeTrue())
+		Expect(rec.ResponseStatus).To(Equal(200))
+	})
+
+	It("should be stale when expired or abandoned", func() {
+		now := time.Now()
+		rec := idempotency.New("instance-1", "key", "fingerprint", time.Hour)
+		Expect(rec.IsStale(now)).To(BeFalse())
+		Expect(rec.IsStale(now.Add(time.Hour + time.Second))).To(BeTrue())
+
+		// a request processing for longer than the lock timeout was abandoned
+		Expect(rec.IsStale(now.Add(idempotency.LockTimeout + time.Second))).To(BeTrue())
+
+		// a completed response is kept until it expires
+		rec.Complete(200, nil)
+		Expect(rec.IsStale(now.Add(idempotency.LockTimeout + time.Second))).To(BeFalse())
+	})
+})
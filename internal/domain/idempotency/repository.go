@@ -0,0 +1,42 @@
+package idempotency
+
+import "time"
+
+type IdempotencyQueryOptions struct {
+	ID         *string `db:"id"`
+	InstanceID *string `db:"instance_id"`
+	Key        *string `db:"idempotency_key"`
+}
+
+type IdempotencyQueryOption func(*IdempotencyQueryOptions)
+
+type IdempotencyRepository interface {
+	// Insert stores the record unless the instance already has one with the same key, it reports whether it
+	// was stored, so only the first of concurrent requests with the same key goes on
+	Insert(r *Record) (bool, error)
+	Update(r *Record) error
+
+	Get(opts ...IdempotencyQueryOption) (*Record, error)
+	Delete(opts ...IdempotencyQueryOption) error
+
+	// DeleteExpired removes the records expired before the given time, it returns how many were removed
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+func WhereID(id string) IdempotencyQueryOption {
+	return func(o *IdempotencyQueryOptions) {
+		o.ID = &id
+	}
+}
+
+func WhereInstanceID(instanceID string) IdempotencyQueryOption {
+	return func(o *IdempotencyQueryOptions) {
+		o.InstanceID = &instanceID
+	}
+}
+
+func WhereKey(key string) IdempotencyQueryOption {
+	return func(o *IdempotencyQueryOptions) {
+		o.Key = &key
+	}
+}
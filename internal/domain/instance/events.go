@@ -42,6 +42,13 @@ const (
 	EventPairingSuccess events.EventName = "instance:pairing/success"
 	// Published when the instance pairing fails
 	EventPairingFailed events.EventName = "instance:pairing/failed"
+
+	// To listen all history events, use "instance:history/*"
+
+	// Published for each history chunk the phone shares and that was stored
+	EventHistoryProgress events.EventName = "instance:history/progress"
+	// Published when the phone finished sharing the history, or when an on demand request was answered
+	EventHistoryComplete events.EventName = "instance:history/complete"
 )
 
 // #region Pairing Events
@@ -166,3 +173,32 @@ func (i *Instance) EventLoggedOut() events.Event {
 		&i.ID,
 	)
 }
+
+// #region History Events
+func (i *Instance) EventHistoryProgress(syncType string, chunk uint32, progress *uint32, chats int, messages int) events.Event {
+	return events.New(
+		EventHistoryProgress,
+		PayloadInstanceHistoryProgress{
+			ID:       i.ID,
+			Type:     syncType,
+			Chunk:    chunk,
+			Progress: progress,
+			Chats:    chats,
+			Messages: messages,
+		},
+		&i.ID,
+	)
+}
+
+func (i *Instance) EventHistoryComplete(syncType string, chats int, messages int) events.Event {
+	return events.New(
+		EventHistoryComplete,
+		PayloadInstanceHistoryComplete{
+			ID:       i.ID,
+			Type:     syncType,
+			Chats:    chats,
+			Messages: messages,
+		},
+		&i.ID,
+	)
+}
@@ -99,3 +99,19 @@ type PayloadInstanceToken struct {
 	Token  string `json:"token"`
 	Masked bool   `json:"masked"`
 }
+
+type PayloadInstanceHistoryProgress struct {
+	ID       string  `json:"id"`
+	Type     string  `json:"type"`
+	Chunk    uint32  `json:"chunk"`
+	Progress *uint32 `json:"progress"`
+	Chats    int     `json:"chats"`    // chats stored from this chunk
+	Messages int     `json:"messages"` // messages stored from this chunk
+}
+
+type PayloadInstanceHistoryComplete struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Chats    int    `json:"chats"`    // chats stored during the whole sync
+	Messages int    `json:"messages"` // messages stored during the whole sync
+}
@@ -0,0 +1,9 @@
+package media
+
+import "errors"
+
+var (
+	ErrInvalidMaxSize = errors.New("invalid max_size, it must be between 0 and 2GB")
+	ErrInvalidChat    = errors.New("invalid chat in chats, use the chat jid")
+	ErrTooManyChats   = errors.New("too many chats in the media policy")
+)
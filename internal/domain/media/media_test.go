@@ -0,0 +1,59 @@
+package media_test
+
+import (
+	"testing"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMedia(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Media Suite")
+}
+
+var _ = Describe("Media policy", func() {
+	It("should save nothing while disabled", func() {
+		p := media.NewPolicy("instance-1", media.Policy{})
+		Expect(p.Allows("5511999999999@s.whatsapp.net", file.MediaTypeImage, 10)).To(BeFalse())
+	})
+
+	It("should filter by type, size and chat", func() {
+		p := media.NewPolicy("instance-1", media.Policy{
+			Enabled: true,
+			Types:   []file.MediaType{file.MediaTypeImage, file.MediaTypeDocument},
+			MaxSize: 1024,
+			Chats:   []string{"5511999999999@s.whatsapp.net"},
+		})
+
+		Expect(p.Allows("5511999999999@s.whatsapp.net", file.MediaTypeImage, 1024)).To(BeTrue())
+		Expect(p.Allows("5511999999999@s.whatsapp.net", file.MediaTypeVideo, 10)).To(BeFalse())
+		Expect(p.Allows("5511999999999@s.whatsapp.net", file.MediaTypeImage, 1025)).To(BeFalse())
+		Expect(p.Allows("5511888888888@s.whatsapp.net", file.MediaTypeImage, 10)).To(BeFalse())
+	})
+
+	It("should leave groups out when asked", func() {
+		p := media.NewPolicy("instance-1", media.Policy{Enabled: true, IgnoreGroups: true})
+
+		Expect(p.Allows("5511999999999@s.whatsapp.net", file.MediaTypeAudio, 0)).To(BeTrue())
+		Expect(p.Allows("120363025246125888@g.us", file.MediaTypeAudio, 0)).To(BeFalse())
+	})
+
+	It("should validate types, size and chats", func() {
+		p := media.NewPolicy("instance-1", media.Policy{})
+		Expect(p.Validate()).To(Succeed())
+
+		p.Types = []file.MediaType{"gif"}
+		Expect(p.Validate()).To(Equal(file.ErrInvalidMediaType))
+
+		p.Types = nil
+		p.MaxSize = media.MaxSizeLimit + 1
+		Expect(p.Validate()).To(Equal(media.ErrInvalidMaxSize))
+
+		p.MaxSize = 0
+		p.Chats = []string{"5511999999999"}
+		Expect(p.Validate()).To(Equal(media.ErrInvalidChat))
+	})
+})
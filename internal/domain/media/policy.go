@@ -0,0 +1,100 @@
+package media
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+)
+
+const (
+	MaxSizeLimit = 2 << 30 // WhatsApp does not deliver media bigger than 2GB
+	MaxChats     = 256
+)
+
+// Policy is which incoming media an instance saves to the storage on its own
+type Policy struct {
+	InstanceID string `json:"instance_id"`
+
+	Enabled      bool             `json:"enabled"`
+	Types        []file.MediaType `json:"types"`         // the kinds saved, empty saves every kind
+	MaxSize      uint64           `json:"max_size"`      // in bytes, zero saves any size
+	Chats        []string         `json:"chats"`         // only the media of these chats is saved, empty saves every chat
+	IgnoreGroups bool             `json:"ignore_groups"` // groups and communities are left out
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewPolicy creates the policy of an instance from the defaults
+func NewPolicy(instanceID string, defaults Policy) *Policy {
+	now := time.Now().UTC()
+
+	policy := defaults
+	policy.InstanceID = instanceID
+	policy.Types = slices.Clone(defaults.Types)
+	policy.Chats = slices.Clone(defaults.Chats)
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+
+	if policy.Types == nil {
+		policy.Types = []file.MediaType{}
+	}
+	if policy.Chats == nil {
+		policy.Chats = []string{}
+	}
+
+	return &policy
+}
+
+func (p *Policy) Validate() error {
+	for _, t := range p.Types {
+		if !t.IsValid() {
+			return file.ErrInvalidMediaType
+		}
+	}
+
+	if p.MaxSize > MaxSizeLimit {
+		return ErrInvalidMaxSize
+	}
+
+	if len(p.Chats) > MaxChats {
+		return ErrTooManyChats
+	}
+
+	for _, chat := range p.Chats {
+		if !strings.Contains(chat, "@") {
+			return ErrInvalidChat
+		}
+	}
+
+	return nil
+}
+
+// Allows tells whether a media of the type and size received in the chat has to be saved, a size of zero
+// means the sender did not tell it and is left for the download to find out
+func (p *Policy) Allows(chat string, mediaType file.MediaType, size uint64) bool {
+	if !p.Enabled {
+		return false
+	}
+
+	if len(p.Types) > 0 && !slices.Contains(p.Types, mediaType) {
+		return false
+	}
+
+	if p.MaxSize > 0 && size > p.MaxSize {
+		return false
+	}
+
+	if p.IgnoreGroups && strings.HasSuffix(chat, "@g.us") {
+		return false
+	}
+
+	return len(p.Chats) == 0 || slices.Contains(p.Chats, chat)
+}
+
+// Fits tells whether the downloaded content is within the size cap, the size the sender tells can be missing
+func (p *Policy) Fits(size uint64) bool {
+	return p.MaxSize == 0 || size <= p.MaxSize
+}
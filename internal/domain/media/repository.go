@@ -0,0 +1,7 @@
+package media
+
+type PolicyRepository interface {
+	// Save inserts or replaces the policy of the instance
+	Save(policy *Policy) error
+	Get(instanceID string) (*Policy, error)
+}
@@ -0,0 +1,94 @@
+package message
+
+import "encoding/json"
+
+// DecodeContent decodes a JSON encoded content of the given kind, the inverse of marshalling Message.Content
+func DecodeContent(kind MessageKind, data []byte) (Content, error) {
+	var content Content
+	var err error
+
+	switch kind {
+	case MessageKindText:
+		var c TextContent
+		err = json.Unmarshal(data, &c)
+		content = c
+	case MessageKindImage:
+		var c ImageContent
+		err = json.Unmarshal(data, &c)
+		content = c
+	case MessageKindReaction:
+		var c ReactionContent
+		err = json.Unmarshal(data, &c)
+		content = c
+	case MessageKindButtons:
+		var c ButtonsContent
+		err = json.Unmarshal(data, &c)
+		content = c
+	case MessageKindList:
+		var c ListContent
+		err = json.Unmarshal(data, &c)
+		content = c
+	case MessageKindTemplate:
+		var c TemplateContent
+		err = json.Unmarshal(data, &c)
+		content = c
+	case MessageKindButtonReply:
+		var c ButtonReplyContent
+		err = json.Unmarshal(data, &c)
+		content = c
+	case MessageKindListReply:
+		var c ListReplyContent
+		err = json.Unmarshal(data, &c)
+		content = c
+	case MessageKindVideo:
+		c := &VideoContent{}
+		err = json.Unmarshal(data, c)
+		content = c
+	case MessageKindAudio:
+		c := &AudioContent{}
+		err = json.Unmarshal(data, c)
+		content = c
+	case MessageKindVoice:
+		c := &VoiceContent{}
+		err = json.Unmarshal(data, c)
+		content = c
+	case MessageKindDocument:
+		c := &DocumentContent{}
+		err = json.Unmarshal(data, c)
+		content = c
+	default:
+		return nil, ErrInvalidContent
+	}
+
+	if err != nil {
+		return nil, ErrInvalidContent
+	}
+
+	return content, nil
+}
+
+// UnmarshalJSON restores the concrete content type based on the message type
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type alias Message
+	raw := struct {
+		*alias
+		Content json.RawMessage `json:"content"`
+	}{alias: (*alias)(m)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if len(raw.Content) == 0 || string(raw.Content) == "null" {
+		m.Content = nil
+		return nil
+	}
+
+	content, err := DecodeContent(m.Type, raw.Content)
+	if err != nil {
+		return err
+	}
+
+	m.Content = content
+	return nil
+}
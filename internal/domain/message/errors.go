@@ -16,4 +16,38 @@ var (
 	ErrVoiceRequired    = errors.New("voice is required")
 	ErrDocumentRequired = errors.New("document is required")
 	ErrEmptyMessageIDs  = errors.New("message ids cannot be empty")
+
+	ErrInvalidReplyID     = errors.New("reply message id is required")
+	ErrInvalidReplySender = errors.New("reply sender is required")
+
+	ErrNotEditable       = errors.New("only text, image, video and document messages can be edited")
+	ErrEditWindowExpired = errors.New("the message is older than the edit window")
+
+	ErrNotForwardable    = errors.New("only text and media messages can be forwarded")
+	ErrEmptyForwardChats = errors.New("at least one destination chat is required")
+	ErrTooManyChats      = errors.New("too many destination chats")
+	ErrInvalidContent    = errors.New("invalid message content")
+
+	ErrButtonsRequired       = errors.New("at least one button is required")
+	ErrTooManyButtons        = errors.New("too many buttons")
+	ErrInvalidButton         = errors.New("button id and text are required")
+	ErrDuplicatedButtonID    = errors.New("button ids must be unique")
+	ErrSectionsRequired      = errors.New("at least one section with rows is required")
+	ErrTooManyRows           = errors.New("too many list rows")
+	ErrInvalidListRow        = errors.New("list row id and title are required")
+	ErrButtonTextRequired    = errors.New("button text is required")
+	ErrTitleRequired         = errors.New("title is required")
+	ErrInvalidTemplateButton = errors.New("invalid template button")
+
+	ErrPreviewTitleTooLong       = errors.New("preview title is too long")
+	ErrPreviewDescriptionTooLong = errors.New("preview description is too long")
+	ErrPreviewURLNotInText       = errors.New("preview url must be present in the text")
+	ErrPreviewUnavailable        = errors.New("link preview unavailable")
+
+	ErrMessageNotFound = errors.New("message not found")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrInvalidLimit    = errors.New("invalid limit")
+
+	ErrInvalidHistoryCount = errors.New("invalid history count")
+	ErrNoHistoryAnchor     = errors.New("no stored message to anchor the history request")
 )
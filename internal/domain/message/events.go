@@ -14,4 +14,7 @@ const (
 
 	EventMessageReactionNew     events.EventName = "message:reaction/new"
 	EventMessageReactionRemoved events.EventName = "message:reaction/removed"
+
+	// Published after the new message event, once its media is saved to the storage
+	EventMessageMediaSaved events.EventName = "message:media/saved"
 )
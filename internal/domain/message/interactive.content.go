@@ -0,0 +1,166 @@
+package message
+
+const (
+	MaxButtons         = 3
+	MaxTemplateButtons = 3
+	MaxListRows        = 10
+)
+
+type Button struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+type ButtonsContent struct {
+	Header  *string  `json:"header"`
+	Text    string   `json:"text"`
+	Footer  *string  `json:"footer"`
+	Buttons []Button `json:"buttons"`
+}
+
+func NewButtonsContent(header *string, text string, footer *string, buttons []Button) ButtonsContent {
+	return ButtonsContent{
+		Header:  header,
+		Text:    text,
+		Footer:  footer,
+		Buttons: buttons,
+	}
+}
+
+func (b ButtonsContent) Kind() MessageKind {
+	return MessageKindButtons
+}
+
+type ListRow struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+}
+
+type ListSection struct {
+	Title string    `json:"title"`
+	Rows  []ListRow `json:"rows"`
+}
+
+type ListContent struct {
+	Title      string        `json:"title"`
+	Text       string        `json:"text"`
+	ButtonText string        `json:"button_text"` // label of the button that opens the list
+	Footer     *string       `json:"footer"`
+	Sections   []ListSection `json:"sections"`
+}
+
+func NewListContent(title string, text string, buttonText string, footer *string, sections []ListSection) ListContent {
+	return ListContent{
+		Title:      title,
+		Text:       text,
+		ButtonText: buttonText,
+		Footer:     footer,
+		Sections:   sections,
+	}
+}
+
+func (l ListContent) Kind() MessageKind {
+	return MessageKindList
+}
+
+func (l ListContent) CountRows() int {
+	count := 0
+	for _, section := range l.Sections {
+		count += len(section.Rows)
+	}
+	return count
+}
+
+type TemplateButtonKind string
+
+const (
+	TemplateButtonReply TemplateButtonKind = "reply"
+	TemplateButtonURL   TemplateButtonKind = "url"
+	TemplateButtonCall  TemplateButtonKind = "call"
+)
+
+type TemplateButton struct {
+	Type  TemplateButtonKind `json:"type"`
+	Text  string             `json:"text"`
+	ID    *string            `json:"id"`    // required for reply buttons
+	URL   *string            `json:"url"`   // required for url buttons
+	Phone *string            `json:"phone"` // required for call buttons
+}
+
+func (b TemplateButton) IsValid() bool {
+	if b.Text == "" {
+		return false
+	}
+
+	switch b.Type {
+	case TemplateButtonReply:
+		return b.ID != nil && *b.ID != ""
+	case TemplateButtonURL:
+		return b.URL != nil && *b.URL != ""
+	case TemplateButtonCall:
+		return b.Phone != nil && *b.Phone != ""
+	default:
+		return false
+	}
+}
+
+type TemplateContent struct {
+	Title   *string          `json:"title"`
+	Text    string           `json:"text"`
+	Footer  *string          `json:"footer"`
+	Buttons []TemplateButton `json:"buttons"`
+}
+
+func NewTemplateContent(title *string, text string, footer *string, buttons []TemplateButton) TemplateContent {
+	return TemplateContent{
+		Title:   title,
+		Text:    text,
+		Footer:  footer,
+		Buttons: buttons,
+	}
+}
+
+func (t TemplateContent) Kind() MessageKind {
+	return MessageKindTemplate
+}
+
+// ButtonReplyContent is the answer to a buttons or template message, message is the id of the answered message
+type ButtonReplyContent struct {
+	ID      string `json:"id"`
+	Text    string `json:"text"`
+	Message string `json:"message"`
+}
+
+func NewButtonReplyContent(id string, text string, message string) ButtonReplyContent {
+	return ButtonReplyContent{
+		ID:      id,
+		Text:    text,
+		Message: message,
+	}
+}
+
+func (b ButtonReplyContent) Kind() MessageKind {
+	return MessageKindButtonReply
+}
+
+// ListReplyContent is the answer to a list message, message is the id of the answered message
+type ListReplyContent struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+	Message     string  `json:"message"`
+}
+
+func NewListReplyContent(id string, title string, description *string, message string) ListReplyContent {
+	return ListReplyContent{
+		ID:          id,
+		Title:       title,
+		Description: description,
+		Message:     message,
+	}
+}
+
+func (l ListReplyContent) Kind() MessageKind {
+	return MessageKindListReply
+}
@@ -2,12 +2,17 @@ package message
 
 import (
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
 )
 
 const (
 	MaxGenerateMessageIDs = 2000
 	MaxMessageTextLength  = 65_535
 	MaxCaptionLength      = 1024
+	EditWindow            = 20 * time.Minute // WhatsApp rejects edits sent after this window
+	MaxForwardChats       = 50
 )
 
 type Content interface {
@@ -24,6 +29,12 @@ const (
 	MessageKindVoice    MessageKind = "voice"
 	MessageKindDocument MessageKind = "document"
 	MessageKindReaction MessageKind = "reaction"
+
+	MessageKindButtons     MessageKind = "buttons"
+	MessageKindList        MessageKind = "list"
+	MessageKindTemplate    MessageKind = "template"
+	MessageKindButtonReply MessageKind = "button_reply"
+	MessageKindListReply   MessageKind = "list_reply"
 )
 
 type MessageStatus string
@@ -38,6 +49,21 @@ const (
 	MessageStatusDeleted   MessageStatus = "deleted"
 )
 
+// statusRank orders the statuses a sent message goes through, deleted is handled apart as it is final
+var statusRank = map[MessageStatus]int{
+	MessageStatusFailed:    0,
+	MessageStatusPending:   1,
+	MessageStatusSent:      2,
+	MessageStatusDelivered: 3,
+	MessageStatusRead:      4,
+	MessageStatusPlayed:    5,
+}
+
+// IsAfter reports whether the status comes later than the other in the lifecycle of a sent message
+func (s MessageStatus) IsAfter(other MessageStatus) bool {
+	return statusRank[s] > statusRank[other]
+}
+
 type Sender struct {
 	JID   string `json:"jid"`
 	LID   string `json:"lid"`
@@ -45,13 +71,46 @@ type Sender struct {
 	Name  string `json:"name"`
 }
 
+// ReplyTo references an earlier message being quoted, content is an optional text snapshot of it
+type ReplyTo struct {
+	ID      string  `json:"id"`
+	Sender  string  `json:"sender"`
+	Content *string `json:"content"`
+}
+
+func (r *ReplyTo) Validate() error {
+	if r.ID == "" {
+		return ErrInvalidReplyID
+	}
+
+	if r.Sender == "" {
+		return ErrInvalidReplySender
+	}
+
+	return nil
+}
+
+type ForwardResult struct {
+	Chat    string   `json:"chat"`
+	Message *Message `json:"message"`
+	Error   *string  `json:"error"`
+}
+
+// HistoryPage is a page of a chat history, next cursor is nil on the last page
+type HistoryPage struct {
+	Messages   []*Message `json:"messages"`
+	NextCursor *string    `json:"next_cursor"`
+}
+
 type Message struct {
 	ID     string      `json:"id"`
 	Type   MessageKind `json:"type"`
 	Sender string      `json:"sender"`
 	Chat   string      `json:"chat"`
 
-	Content Content `json:"content"`
+	Content Content       `json:"content"`
+	ReplyTo *ReplyTo      `json:"reply_to"`
+	Status  MessageStatus `json:"status"`
 
 	Expiration *uint32 `json:"expiration"`
 
@@ -60,7 +119,9 @@ type Message struct {
 	ReadAt      *time.Time `json:"read_at"`
 	ExpiresAt   *time.Time `json:"expires_at"`
 
-	IsFromMe bool `json:"is_from_me"`
+	IsFromMe        bool   `json:"is_from_me"`
+	IsForwarded     bool   `json:"is_forwarded"`
+	ForwardingScore uint32 `json:"forwarding_score"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -77,8 +138,23 @@ func NewMessage(id *string, sender string, chat string, content Content, instanc
 		expiresAt = &t
 	}
 
+	// unsupported incoming messages have no content, callers check it before using the message
+	var kind MessageKind
+	if content != nil {
+		kind = content.Kind()
+	}
+
+	status := MessageStatusDelivered
+	if me {
+		status = MessageStatusPending
+	}
+
+	uuid, _ := uuid.NewV7()
+
 	return &Message{
-		Type:       content.Kind(),
+		ID:         uuid.String(),
+		Type:       kind,
+		Status:     status,
 		Sender:     sender,
 		Chat:       chat,
 		Content:    content,
@@ -92,6 +168,105 @@ func NewMessage(id *string, sender string, chat string, content Content, instanc
 	}
 }
 
+// Forward returns a copy of the message addressed to another chat, flagged as forwarded, the content is
+// shared so already uploaded media is reused
+func (m *Message) Forward(sender string, chat string, instanceID *string) *Message {
+	fwd := NewMessage(nil, sender, chat, m.Content, instanceID, nil, true)
+	fwd.IsForwarded = true
+	fwd.ForwardingScore = m.ForwardingScore + 1
+	return fwd
+}
+
+func (m *Message) MarkAsSent(at time.Time) {
+	m.Status = MessageStatusSent
+	m.SentAt = &at
+	m.UpdatedAt = time.Now()
+}
+
+func (m *Message) MarkAsDeleted() {
+	m.Status = MessageStatusDeleted
+	m.UpdatedAt = time.Now()
+}
+
+// ApplyReceipt moves the message forward with a delivered, read or played receipt, receipts arriving out of
+// order never move it back. In groups the first participant to acknowledge sets the timestamps. It returns
+// false when nothing changed
+func (m *Message) ApplyReceipt(status MessageStatus, at time.Time) bool {
+	changed := false
+
+	if status == MessageStatusDelivered || status == MessageStatusRead || status == MessageStatusPlayed {
+		if m.DeliveredAt == nil {
+			m.DeliveredAt = &at
+			changed = true
+		}
+	}
+
+	if status == MessageStatusRead || status == MessageStatusPlayed {
+		if m.ReadAt == nil {
+			m.ReadAt = &at
+			changed = true
+		}
+	}
+
+	if m.Status != MessageStatusDeleted && status.IsAfter(m.Status) {
+		m.Status = status
+		changed = true
+	}
+
+	if changed {
+		m.UpdatedAt = time.Now()
+	}
+
+	return changed
+}
+
+// SetText replaces the text of a text message or the caption of a media message, it returns false when
+// the content has no text to replace
+func (m *Message) SetText(text string) bool {
+	switch content := m.Content.(type) {
+	case TextContent:
+		content.Text = text
+		m.Content = content
+	case ImageContent:
+		content.Caption = &text
+		m.Content = content
+	case *VideoContent:
+		content.Caption = &text
+	case *DocumentContent:
+		content.Caption = &text
+	default:
+		return false
+	}
+
+	m.UpdatedAt = time.Now()
+	return true
+}
+
+// Timestamp is when the message was sent, or when it was created while it is not sent yet
+func (m *Message) Timestamp() time.Time {
+	if m.SentAt != nil {
+		return *m.SentAt
+	}
+	return m.CreatedAt
+}
+
+// CanEdit fails when the message was sent longer than the edit window ago
+func (m *Message) CanEdit(now time.Time) error {
+	if now.Sub(m.Timestamp()) > EditWindow {
+		return ErrEditWindowExpired
+	}
+	return nil
+}
+
+func (m *Message) IsReply() bool {
+	return m.ReplyTo != nil
+}
+
+// IsEditable reports whether a message of the given kind can have its text or caption edited
+func IsEditable(kind MessageKind) bool {
+	return kind == MessageKindText || kind == MessageKindImage || kind == MessageKindVideo || kind == MessageKindDocument
+}
+
 func (m *Message) IsSent() bool {
 	return m.SentAt != nil
 }
@@ -115,6 +290,26 @@ func (m *Message) HasMedia() bool {
 	return m.Type == MessageKindImage || m.Type == MessageKindVideo || m.Type == MessageKindAudio || m.Type == MessageKindVoice || m.Type == MessageKindDocument
 }
 
+// MediaFile returns the file of a media message with the type of media it is, nil for the other kinds
+func (m *Message) MediaFile() (*file.File, file.MediaType) {
+	switch content := m.Content.(type) {
+	case ImageContent:
+		if content.Image != nil {
+			return &content.Image.File, file.MediaTypeImage
+		}
+	case *VideoContent:
+		return &content.Video.File, file.MediaTypeVideo
+	case *AudioContent:
+		return &content.Audio.File, file.MediaTypeAudio
+	case *VoiceContent:
+		return &content.Voice.File, file.MediaTypeAudio
+	case *DocumentContent:
+		return &content.Document, file.MediaTypeDocument
+	}
+
+	return nil, ""
+}
+
 func (m *Message) IsText() bool {
 	return m.Type == MessageKindText
 }
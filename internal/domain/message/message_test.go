@@ -0,0 +1,125 @@
+package message_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMessage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Message Entity Suite")
+}
+
+var _ = Describe("Message entity", func() {
+	It("should decode the content back to its concrete type", func() {
+		duration := uint32(12)
+		video := file.VideoFile{
+			File: file.File{
+				Mime:       "video/mp4",
+				DirectPath: "/v/t62.7161-24/abc",
+				MediaKey:   "0a0b0c",
+				Sha256:     "0d0e0f",
+			},
+			Duration: &duration,
+		}
+		caption := "look at this"
+
+		original := message.NewMessage(nil, "me@s.whatsapp.net", "123@g.us", message.NewVideoContent(video, nil, &caption, nil, nil), nil, nil, true)
+
+		data, err := json.Marshal(original)
+		Expect(err).To(BeNil())
+
+		var decoded message.Message
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded.Type).To(Equal(message.MessageKindVideo))
+
+		content, ok := decoded.Content.(*message.VideoContent)
+		Expect(ok).To(BeTrue())
+		Expect(content.Video.DirectPath).To(Equal("/v/t62.7161-24/abc"))
+		Expect(content.Video.MediaKey).To(Equal("0a0b0c"))
+		Expect(*content.Caption).To(Equal("look at this"))
+	})
+
+	It("should decode value contents", func() {
+		data := []byte(`{"type":"text","content":{"text":"hello","mentions":null}}`)
+
+		var decoded message.Message
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+
+		content, ok := decoded.Content.(message.TextContent)
+		Expect(ok).To(BeTrue())
+		Expect(content.Text).To(Equal("hello"))
+	})
+
+	It("should fail to decode an unknown content type", func() {
+		data := []byte(`{"type":"unknown","content":{"text":"hello"}}`)
+
+		var decoded message.Message
+		Expect(json.Unmarshal(data, &decoded)).To(Equal(message.ErrInvalidContent))
+	})
+
+	It("should forward a message to another chat", func() {
+		original := message.NewMessage(nil, "other@s.whatsapp.net", "other@s.whatsapp.net", message.NewTextContent("hello", nil), nil, nil, false)
+		original.ForwardingScore = 2
+
+		instanceID := "instance-id"
+		fwd := original.Forward("me@s.whatsapp.net", "123@g.us", &instanceID)
+
+		Expect(fwd.Chat).To(Equal("123@g.us"))
+		Expect(fwd.Sender).To(Equal("me@s.whatsapp.net"))
+		Expect(fwd.IsFromMe).To(BeTrue())
+		Expect(fwd.IsForwarded).To(BeTrue())
+		Expect(fwd.ForwardingScore).To(Equal(uint32(3)))
+		Expect(fwd.Content).To(Equal(original.Content))
+		Expect(*fwd.InstanceID).To(Equal("instance-id"))
+	})
+
+	It("should find the first url in the text", func() {
+		link, found := message.FindFirstURL("see https://example.com/a?b=1, and http://other.com")
+		Expect(found).To(BeTrue())
+		Expect(link).To(Equal("https://example.com/a?b=1"))
+
+		_, found = message.FindFirstURL("no links here")
+		Expect(found).To(BeFalse())
+	})
+
+	It("should apply receipts without moving the status back", func() {
+		m := message.NewMessage(nil, "me@s.whatsapp.net", "123@s.whatsapp.net", message.NewTextContent("hi", nil), nil, nil, true)
+		m.MarkAsSent(time.Now())
+
+		readAt := time.Now()
+		Expect(m.ApplyReceipt(message.MessageStatusRead, readAt)).To(BeTrue())
+		Expect(m.Status).To(Equal(message.MessageStatusRead))
+		Expect(*m.ReadAt).To(Equal(readAt))
+		Expect(*m.DeliveredAt).To(Equal(readAt))
+
+		// a delivery receipt arriving late changes nothing
+		Expect(m.ApplyReceipt(message.MessageStatusDelivered, readAt.Add(time.Second))).To(BeFalse())
+		Expect(m.Status).To(Equal(message.MessageStatusRead))
+		Expect(*m.DeliveredAt).To(Equal(readAt))
+	})
+
+	It("should only be edited within the edit window", func() {
+		m := message.NewMessage(nil, "me@s.whatsapp.net", "123@s.whatsapp.net", message.NewTextContent("hi", nil), nil, nil, true)
+		m.MarkAsSent(time.Now().Add(-time.Minute))
+		Expect(m.CanEdit(time.Now())).To(Succeed())
+
+		m.MarkAsSent(time.Now().Add(-message.EditWindow - time.Minute))
+		Expect(m.CanEdit(time.Now())).To(MatchError(message.ErrEditWindowExpired))
+	})
+
+	It("should keep a deleted message deleted when a receipt arrives", func() {
+		m := message.NewMessage(nil, "me@s.whatsapp.net", "123@s.whatsapp.net", message.NewTextContent("hi", nil), nil, nil, true)
+		m.MarkAsDeleted()
+
+		Expect(m.ApplyReceipt(message.MessageStatusDelivered, time.Now())).To(BeTrue())
+		Expect(m.Status).To(Equal(message.MessageStatusDeleted))
+		Expect(m.DeliveredAt).ToNot(BeNil())
+	})
+})
@@ -30,6 +30,19 @@ type PayloadNewMessage struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+type PayloadMessageMediaSaved struct {
+	Message   string      `json:"message"` // ID da mensagem
+	Chat      string      `json:"chat"`
+	Media     StoredMedia `json:"media"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// StoredMedia is the upload created from the media of a received message
+type StoredMedia struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
 type PayloadMessageReaction struct {
 	Chat      string    `json:"chat"`    // JID do chat
 	Message   string    `json:"message"` // ID da mensagem
@@ -38,3 +51,18 @@ type PayloadMessageReaction struct {
 	Removed   bool      `json:"removed"` // Se a reação foi removida
 	Timestamp time.Time `json:"timestamp"`
 }
+
+type PayloadMessageEdited struct {
+	Message   string    `json:"message"`
+	Chat      string    `json:"chat"`
+	Sender    string    `json:"sender"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type PayloadMessageDeleted struct {
+	Message   string    `json:"message"`
+	Chat      string    `json:"chat"`
+	Sender    string    `json:"sender"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,59 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+)
+
+const (
+	MaxPreviewTitleLength       = 256
+	MaxPreviewDescriptionLength = 1024
+)
+
+var urlRegex = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"']+`)
+
+// LinkPreview is the card shown under a text message, thumbnail is a small base64 jpeg and image is the
+// same thumbnail uploaded to whatsapp servers, which is what makes the preview render in high quality
+type LinkPreview struct {
+	URL         string          `json:"url"`
+	Title       *string         `json:"title"`
+	Description *string         `json:"description"`
+	Thumbnail   *string         `json:"thumbnail"`
+	Image       *file.ImageFile `json:"image"`
+}
+
+func (p *LinkPreview) Validate() error {
+	if p.URL == "" {
+		return ErrInvalidURL
+	}
+
+	if p.Title != nil && len(*p.Title) > MaxPreviewTitleLength {
+		return ErrPreviewTitleTooLong
+	}
+
+	if p.Description != nil && len(*p.Description) > MaxPreviewDescriptionLength {
+		return ErrPreviewDescriptionTooLong
+	}
+
+	return nil
+}
+
+func (p *LinkPreview) HasThumbnail() bool {
+	return p.Thumbnail != nil && *p.Thumbnail != ""
+}
+
+func (p *LinkPreview) HasImage() bool {
+	return p.Image != nil && p.Image.DirectPath != ""
+}
+
+// FindFirstURL returns the first http(s) url found in the text, trailing punctuation is not part of the url
+func FindFirstURL(text string) (string, bool) {
+	match := urlRegex.FindString(text)
+	if match == "" {
+		return "", false
+	}
+
+	return strings.TrimRight(match, ".,;:!?)]}"), true
+}
@@ -0,0 +1,61 @@
+package message
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Receipt is an acknowledgement of a message by one participant, a private chat has a single participant
+// while groups have one per member
+type Receipt struct {
+	ID          string        `json:"id"`
+	MessageID   string        `json:"message_id"`
+	Participant string        `json:"participant"`
+	Status      MessageStatus `json:"status"`
+	Timestamp   time.Time     `json:"timestamp"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+func NewReceipt(messageID string, participant string, status MessageStatus, timestamp time.Time) *Receipt {
+	uuid, _ := uuid.NewV7()
+	return &Receipt{
+		ID:          uuid.String(),
+		MessageID:   messageID,
+		Participant: participant,
+		Status:      status,
+		Timestamp:   timestamp,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// StatusTimeline is the delivery state of a message along with every receipt received for it, oldest first
+type StatusTimeline struct {
+	ID          string        `json:"id"`
+	ExternalID  *string       `json:"external_id"`
+	Chat        string        `json:"chat"`
+	Status      MessageStatus `json:"status"`
+	SentAt      *time.Time    `json:"sent_at"`
+	DeliveredAt *time.Time    `json:"delivered_at"`
+	ReadAt      *time.Time    `json:"read_at"`
+	Receipts    []*Receipt    `json:"receipts"`
+}
+
+func NewStatusTimeline(m *Message, receipts []*Receipt) *StatusTimeline {
+	return &StatusTimeline{
+		ID:          m.ID,
+		ExternalID:  m.ExternalID,
+		Chat:        m.Chat,
+		Status:      m.Status,
+		SentAt:      m.SentAt,
+		DeliveredAt: m.DeliveredAt,
+		ReadAt:      m.ReadAt,
+		Receipts:    receipts,
+	}
+}
+
+type ReceiptRepository interface {
+	// Insert stores the receipt, a receipt already stored for the same message, participant and status is ignored
+	Insert(receipt *Receipt) error
+	ListByMessage(messageID string) ([]*Receipt, error)
+}
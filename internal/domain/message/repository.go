@@ -0,0 +1,131 @@
+package message
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 200
+
+	DefaultHistoryRequestCount = 50 // the amount the official clients ask for
+	MaxHistoryRequestCount     = 500
+)
+
+type SortBy string
+
+const (
+	SortByAsc  SortBy = "ASC"
+	SortByDesc SortBy = "DESC"
+)
+
+// Cursor points to the last message of a page, the next page starts right after it
+type Cursor struct {
+	Timestamp int64  // unix milliseconds
+	ID        string // breaks ties between messages with the same timestamp
+}
+
+func NewCursor(m *Message) Cursor {
+	return Cursor{Timestamp: m.Timestamp().UnixMilli(), ID: m.ID}
+}
+
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%s", c.Timestamp, c.ID))
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	timestamp, id, found := strings.Cut(string(data), ":")
+	if !found || id == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{Timestamp: ms, ID: id}, nil
+}
+
+type MessageQueryOptions struct {
+	ID         *string `db:"id"`
+	ExternalID *string `db:"external_id"`
+	Chat       *string `db:"chat"`
+	InstanceID *string `db:"instance_id"`
+	Before     *Cursor `db:"-"`
+
+	Limit   *int   `db:"limit"`
+	OrderBy string `db:"order_by"`
+	SortBy  SortBy `db:"sort_by"`
+}
+
+type MessageQueryOption func(*MessageQueryOptions)
+
+type MessageRepository interface {
+	// Insert stores the message, a message already stored with the same instance, chat and external id
+	// is updated instead, so redelivered messages are not duplicated
+	Insert(message *Message) error
+	// InsertMany inserts the messages in a single transaction, the same way as Insert
+	InsertMany(messages []*Message) error
+
+	Update(message *Message) error
+
+	Get(opts ...MessageQueryOption) (*Message, error)
+	List(opts ...MessageQueryOption) ([]*Message, error)
+
+	Delete(opts ...MessageQueryOption) error
+
+	Count(opts ...MessageQueryOption) (uint64, error)
+}
+
+func WhereID(id string) MessageQueryOption {
+	return func(o *MessageQueryOptions) {
+		o.ID = &id
+	}
+}
+
+func WhereExternalID(externalID string) MessageQueryOption {
+	return func(o *MessageQueryOptions) {
+		o.ExternalID = &externalID
+	}
+}
+
+func WhereChat(chat string) MessageQueryOption {
+	return func(o *MessageQueryOptions) {
+		o.Chat = &chat
+	}
+}
+
+func WhereInstanceID(instanceID string) MessageQueryOption {
+	return func(o *MessageQueryOptions) {
+		o.InstanceID = &instanceID
+	}
+}
+
+// WhereBefore returns only messages older than the cursor, newest first
+func WhereBefore(cursor Cursor) MessageQueryOption {
+	return func(o *MessageQueryOptions) {
+		o.Before = &cursor
+	}
+}
+
+func Limit(limit int) MessageQueryOption {
+	return func(o *MessageQueryOptions) {
+		o.Limit = &limit
+	}
+}
+
+func OrderBy(orderBy string, sortBy SortBy) MessageQueryOption {
+	return func(o *MessageQueryOptions) {
+		o.OrderBy = orderBy
+		o.SortBy = sortBy
+	}
+}
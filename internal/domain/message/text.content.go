@@ -1,8 +1,9 @@
 package message
 
 type TextContent struct {
-	Text     string    `json:"text"`
-	Mentions *[]string `json:"mentions"`
+	Text     string       `json:"text"`
+	Mentions *[]string    `json:"mentions"`
+	Preview  *LinkPreview `json:"preview"`
 }
 
 func NewTextContent(text string, mentions *[]string) TextContent {
@@ -23,3 +24,7 @@ func (t *TextContent) TextForMentions() string {
 func (t *TextContent) HasMentions() bool {
 	return t.Mentions != nil && len(*t.Mentions) > 0
 }
+
+func (t *TextContent) HasPreview() bool {
+	return t.Preview != nil
+}
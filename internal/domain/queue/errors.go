@@ -0,0 +1,17 @@
+package queue
+
+import "errors"
+
+var (
+	ErrQueuedMessageNotFound = errors.New("queued message not found")
+	ErrNotCancelable         = errors.New("queued message is already being sent or finished")
+	ErrUnsupportedKind       = errors.New("this kind of message can't be queued")
+	ErrInvalidPayload        = errors.New("invalid queued message payload")
+
+	ErrScheduledMessageNotFound = errors.New("scheduled message not found")
+	ErrNotEditable              = errors.New("scheduled message is already being sent or finished")
+	ErrInvalidSendAt            = errors.New("invalid send_at, use RFC 3339 or YYYY-MM-DDTHH:MM:SS")
+	ErrInvalidTimezone          = errors.New("invalid timezone, use an IANA name like America/Sao_Paulo")
+	ErrSendAtInPast             = errors.New("send_at must be in the future")
+	ErrSendAtTooFar             = errors.New("send_at is too far in the future")
+)
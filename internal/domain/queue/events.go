@@ -0,0 +1,47 @@
+package queue
+
+import "github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+
+func (q *QueuedMessage) EventQueued() events.Event {
+	return events.New(
+		events.EventMessageQueued,
+		events.PayloadMessageQueued{
+			ID:          q.ID,
+			To:          q.Chat,
+			InstanceID:  q.InstanceID,
+			ScheduledAt: q.ScheduledAt,
+		},
+		&q.InstanceID,
+	)
+}
+
+func (q *QueuedMessage) EventSent() events.Event {
+	return events.New(
+		events.EventMessageSent,
+		events.PayloadMessageSent{
+			ID:          q.ID,
+			To:          q.Chat,
+			InstanceID:  q.InstanceID,
+			MessageID:   q.MessageID,
+			ExternalID:  q.ExternalID,
+			SentAt:      q.SentAt,
+			ScheduledAt: q.ScheduledAt,
+		},
+		&q.InstanceID,
+	)
+}
+
+func (q *QueuedMessage) EventFailed() events.Event {
+	return events.New(
+		events.EventMessageFailed,
+		events.PayloadMessageFailed{
+			ID:          q.ID,
+			To:          q.Chat,
+			InstanceID:  q.InstanceID,
+			Attempts:    q.Attempts,
+			Error:       q.LastError,
+			ScheduledAt: q.ScheduledAt,
+		},
+		&q.InstanceID,
+	)
+}
@@ -0,0 +1,142 @@
+package queue
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+const (
+	DefaultMaxAttempts = 10
+	RetryBaseDelay     = 2 * time.Second
+	RetryMaxDelay      = 5 * time.Minute
+	ReconnectDelay     = 10 * time.Second // wait while the instance is offline, it does not count as an attempt
+)
+
+type Status string
+
+const (
+	StatusQueued   Status = "queued"
+	StatusSending  Status = "sending"
+	StatusSent     Status = "sent"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+// QueuedMessage is a send request waiting to be delivered, the payload is the body of the send endpoint
+// for the kind of message
+type QueuedMessage struct {
+	ID         string              `json:"id"`
+	InstanceID string              `json:"instance_id"`
+	Chat       string              `json:"chat"`
+	Kind       message.MessageKind `json:"kind"`
+	Payload    json.RawMessage     `json:"payload"`
+
+	Status        Status    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	LastError     *string   `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+
+	MessageID  *string    `json:"message_id"`  // id of the stored message once sent
+	ExternalID *string    `json:"external_id"` // whatsapp id once sent
+	SentAt     *time.Time `json:"sent_at"`
+
+	ScheduledAt *time.Time `json:"scheduled_at"` // only set on scheduled messages
+	Timezone    *string    `json:"timezone"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func New(instanceID string, chat string, kind message.MessageKind, payload json.RawMessage) *QueuedMessage {
+	uuid, _ := uuid.NewV7()
+	now := time.Now().UTC()
+
+	return &QueuedMessage{
+		ID:            uuid.String(),
+		InstanceID:    instanceID,
+		Chat:          chat,
+		Kind:          kind,
+		Payload:       payload,
+		Status:        StatusQueued,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// RetryDelay doubles the wait after each failed attempt, up to RetryMaxDelay
+func RetryDelay(attempts int) time.Duration {
+	delay := RetryBaseDelay
+	for i := 1; i < attempts && delay < RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, RetryMaxDelay)
+}
+
+func (q *QueuedMessage) IsPending() bool {
+	return q.Status == StatusQueued || q.Status == StatusSending
+}
+
+func (q *QueuedMessage) MarkSending() {
+	q.Status = StatusSending
+	q.UpdatedAt = time.Now().UTC()
+}
+
+func (q *QueuedMessage) MarkSent(msg *message.Message) {
+	q.Attempts++
+	q.Status = StatusSent
+	q.MessageID = &msg.ID
+	q.ExternalID = msg.ExternalID
+	q.SentAt = msg.SentAt
+	q.LastError = nil
+	q.UpdatedAt = time.Now().UTC()
+}
+
+// Retry puts the message back in the queue after a backoff, once the attempts are exhausted it is marked as
+// failed and false is returned
+func (q *QueuedMessage) Retry(err error, maxAttempts int) bool {
+	now := time.Now().UTC()
+	reason := err.Error()
+
+	q.Attempts++
+	q.LastError = &reason
+	q.UpdatedAt = now
+
+	if q.Attempts >= maxAttempts {
+		q.Status = StatusFailed
+		return false
+	}
+
+	q.Status = StatusQueued
+	q.NextAttemptAt = now.Add(RetryDelay(q.Attempts))
+	return true
+}
+
+// Postpone puts the message back in the queue without spending an attempt
+func (q *QueuedMessage) Postpone(delay time.Duration) {
+	now := time.Now().UTC()
+	q.Status = StatusQueued
+	q.NextAttemptAt = now.Add(delay)
+	q.UpdatedAt = now
+}
+
+func (q *QueuedMessage) Fail(err error) {
+	reason := err.Error()
+	q.Status = StatusFailed
+	q.LastError = &reason
+	q.UpdatedAt = time.Now().UTC()
+}
+
+// Cancel stops a message that is still waiting, a message being sent or already finished can't be canceled
+func (q *QueuedMessage) Cancel() error {
+	if q.Status != StatusQueued {
+		return ErrNotCancelable
+	}
+
+	q.Status = StatusCanceled
+	q.UpdatedAt = time.Now().UTC()
+	return nil
+}
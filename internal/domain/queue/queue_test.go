@@ -0,0 +1,126 @@
+package queue_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestQueue(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Queue Entity Suite")
+}
+
+var _ = Describe("Queued message entity", func() {
+	newQueued := func() *queue.QueuedMessage {
+		return queue.New("instance-1", "123@s.whatsapp.net", message.MessageKindText, []byte(`{"to":"123@s.whatsapp.net","text":"hi"}`))
+	}
+
+	It("should double the retry delay up to the maximum", func() {
+		Expect(queue.RetryDelay(1)).To(Equal(queue.RetryBaseDelay))
+		Expect(queue.RetryDelay(2)).To(Equal(2 * queue.RetryBaseDelay))
+		Expect(queue.RetryDelay(3)).To(Equal(4 * queue.RetryBaseDelay))
+		Expect(queue.RetryDelay(100)).To(Equal(queue.RetryMaxDelay))
+	})
+
+	It("should retry until the attempts are exhausted", func() {
+		q := newQueued()
+		q.MarkSending()
+
+		Expect(q.Retry(errors.New("timeout"), 2)).To(BeTrue())
+		Expect(q.Status).To(Equal(queue.StatusQueued))
+		Expect(q.Attempts).To(Equal(1))
+		Expect(q.NextAttemptAt).To(BeTemporally(">", time.Now()))
+		Expect(*q.LastError).To(Equal("timeout"))
+
+		q.MarkSending()
+		Expect(q.Retry(errors.New("timeout"), 2)).To(BeFalse())
+		Expect(q.Status).To(Equal(queue.StatusFailed))
+		Expect(q.Attempts).To(Equal(2))
+	})
+
+	It("should postpone without spending an attempt", func() {
+		q := newQueued()
+		q.MarkSending()
+		q.Postpone(queue.ReconnectDelay)
+
+		Expect(q.Status).To(Equal(queue.StatusQueued))
+		Expect(q.Attempts).To(Equal(0))
+	})
+
+	It("should keep the sent message references", func() {
+		externalID := "3EB0000000000001"
+		msg := message.NewMessage(&externalID, "me@s.whatsapp.net", "123@s.whatsapp.net", message.NewTextContent("hi", nil), nil, nil, true)
+		msg.MarkAsSent(time.Now())
+
+		q := newQueued()
+		q.MarkSending()
+		q.MarkSent(msg)
+
+		Expect(q.Status).To(Equal(queue.StatusSent))
+		Expect(*q.MessageID).To(Equal(msg.ID))
+		Expect(*q.ExternalID).To(Equal(externalID))
+		Expect(q.SentAt).ToNot(BeNil())
+	})
+
+	It("should only cancel a message still waiting", func() {
+		q := newQueued()
+		Expect(q.Cancel()).To(Succeed())
+		Expect(q.Status).To(Equal(queue.StatusCanceled))
+
+		sending := newQueued()
+		sending.MarkSending()
+		Expect(sending.Cancel()).To(Equal(queue.ErrNotCancelable))
+	})
+
+	It("should read send_at in the timezone when it has no offset", func() {
+		at, err := queue.ParseSendAt("2030-01-02T09:30:00", "America/Sao_Paulo")
+		Expect(err).To(BeNil())
+		Expect(at).To(Equal(time.Date(2030, 1, 2, 12, 30, 0, 0, time.UTC)))
+
+		at, err = queue.ParseSendAt("2030-01-02T09:30:00+01:00", "America/Sao_Paulo")
+		Expect(err).To(BeNil())
+		Expect(at).To(Equal(time.Date(2030, 1, 2, 8, 30, 0, 0, time.UTC)))
+
+		at, err = queue.ParseSendAt("2030-01-02 09:30", "")
+		Expect(err).To(BeNil())
+		Expect(at).To(Equal(time.Date(2030, 1, 2, 9, 30, 0, 0, time.UTC)))
+
+		_, err = queue.ParseSendAt("tomorrow", "UTC")
+		Expect(err).To(Equal(queue.ErrInvalidSendAt))
+
+		_, err = queue.ParseSendAt("2030-01-02T09:30:00", "Mars/Olympus")
+		Expect(err).To(Equal(queue.ErrInvalidTimezone))
+	})
+
+	It("should only accept a send_at in the future and not too far", func() {
+		Expect(queue.ValidateSendAt(time.Now().Add(time.Hour))).To(Succeed())
+		Expect(queue.ValidateSendAt(time.Now().Add(-time.Minute))).To(Equal(queue.ErrSendAtInPast))
+		Expect(queue.ValidateSendAt(time.Now().Add(queue.MaxScheduleAhead + time.Hour))).To(Equal(queue.ErrSendAtTooFar))
+	})
+
+	It("should be due at the scheduled time and reschedule while waiting", func() {
+		at := time.Now().Add(time.Hour)
+		q := queue.NewScheduled("instance-1", "123@s.whatsapp.net", message.MessageKindText, []byte(`{"to":"123@s.whatsapp.net","text":"hi"}`), at, "UTC")
+
+		Expect(q.IsScheduled()).To(BeTrue())
+		Expect(q.NextAttemptAt).To(Equal(at.UTC()))
+
+		later := at.Add(time.Hour)
+		Expect(q.Reschedule(later, "Europe/Lisbon")).To(Succeed())
+		Expect(*q.ScheduledAt).To(Equal(later.UTC()))
+		Expect(*q.Timezone).To(Equal("Europe/Lisbon"))
+		Expect(q.NextAttemptAt).To(Equal(later.UTC()))
+
+		q.MarkSending()
+		Expect(q.Reschedule(at, "UTC")).To(Equal(queue.ErrNotEditable))
+		Expect(q.Replace("456@s.whatsapp.net", []byte(`{"to":"456@s.whatsapp.net","text":"hi"}`))).To(Equal(queue.ErrNotEditable))
+
+		Expect(newQueued().Reschedule(at, "UTC")).To(Equal(queue.ErrNotEditable))
+	})
+})
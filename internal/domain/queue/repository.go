@@ -0,0 +1,69 @@
+package queue
+
+import "time"
+
+type QueueQueryOptions struct {
+	ID         *string `db:"id"`
+	InstanceID *string `db:"instance_id"`
+	Status     *Status `db:"status"`
+	Scheduled  *bool   `db:"scheduled"`
+
+	Limit *int `db:"limit"`
+}
+
+type QueueQueryOption func(*QueueQueryOptions)
+
+type QueueRepository interface {
+	Insert(q *QueuedMessage) error
+	Update(q *QueuedMessage) error
+
+	// UpdateFrom stores the message only when the stored status is still from, it reports whether it was
+	// stored, so two workers or a worker and a cancellation never act on the same message
+	UpdateFrom(q *QueuedMessage, from Status) (bool, error)
+
+	// Ready returns the oldest pending message of each chat when it is due, later messages of a chat wait
+	// for it to be sent, keeping the chat order, a scheduled message takes its place in the chat at its
+	// scheduled time
+	Ready(now time.Time, limit int) ([]*QueuedMessage, error)
+
+	// Release puts back in the queue the messages left as sending since before the given time, they belong
+	// to a worker that stopped in the middle of a send
+	Release(before time.Time) (int64, error)
+
+	// Renew keeps the claim of a message being sent, so a send taking long is not released, it reports false
+	// when the message is no longer sending
+	Renew(id string, now time.Time) (bool, error)
+
+	Get(opts ...QueueQueryOption) (*QueuedMessage, error)
+	List(opts ...QueueQueryOption) ([]*QueuedMessage, error)
+}
+
+func WhereID(id string) QueueQueryOption {
+	return func(o *QueueQueryOptions) {
+		o.ID = &id
+	}
+}
+
+func WhereInstanceID(instanceID string) QueueQueryOption {
+	return func(o *QueueQueryOptions) {
+		o.InstanceID = &instanceID
+	}
+}
+
+func WhereStatus(status Status) QueueQueryOption {
+	return func(o *QueueQueryOptions) {
+		o.Status = &status
+	}
+}
+
+func WhereScheduled(scheduled bool) QueueQueryOption {
+	return func(o *QueueQueryOptions) {
+		o.Scheduled = &scheduled
+	}
+}
+
+func Limit(limit int) QueueQueryOption {
+	return func(o *QueueQueryOptions) {
+		o.Limit = &limit
+	}
+}
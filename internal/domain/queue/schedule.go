@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+const (
+	MaxScheduleAhead = 365 * 24 * time.Hour
+	DefaultTimezone  = "UTC"
+)
+
+// layouts accepted for a send_at without offset, it is read in the given timezone
+var localSendAtLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// NewScheduled creates a queued message that is only sent once at is reached, the timezone is kept to show
+// and edit the schedule the way it was given
+func NewScheduled(instanceID string, chat string, kind message.MessageKind, payload json.RawMessage, at time.Time, timezone string) *QueuedMessage {
+	q := New(instanceID, chat, kind, payload)
+
+	at = at.UTC()
+	q.ScheduledAt = &at
+	q.Timezone = &timezone
+	q.NextAttemptAt = at
+
+	return q
+}
+
+// ParseSendAt reads a send_at value, a timestamp with offset is taken as is, one without offset is read in the
+// timezone, an empty timezone is UTC
+func ParseSendAt(value string, timezone string) (time.Time, error) {
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, ErrInvalidTimezone
+	}
+
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at.UTC(), nil
+	}
+
+	for _, layout := range localSendAtLayouts {
+		if at, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return at.UTC(), nil
+		}
+	}
+
+	return time.Time{}, ErrInvalidSendAt
+}
+
+// ValidateSendAt checks that the moment is in the future and not too far away
+func ValidateSendAt(at time.Time) error {
+	now := time.Now()
+
+	if !at.After(now) {
+		return ErrSendAtInPast
+	}
+
+	if at.After(now.Add(MaxScheduleAhead)) {
+		return ErrSendAtTooFar
+	}
+
+	return nil
+}
+
+func (q *QueuedMessage) IsScheduled() bool {
+	return q.ScheduledAt != nil
+}
+
+// Reschedule moves a scheduled message that was not sent yet to another moment
+func (q *QueuedMessage) Reschedule(at time.Time, timezone string) error {
+	if !q.IsScheduled() || q.Status != StatusQueued {
+		return ErrNotEditable
+	}
+
+	at = at.UTC()
+	q.ScheduledAt = &at
+	q.Timezone = &timezone
+	q.NextAttemptAt = at
+	q.Attempts = 0
+	q.LastError = nil
+	q.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Replace swaps the body of a scheduled message that was not sent yet, the kind stays the same
+func (q *QueuedMessage) Replace(chat string, payload json.RawMessage) error {
+	if !q.IsScheduled() || q.Status != StatusQueued {
+		return ErrNotEditable
+	}
+
+	q.Chat = chat
+	q.Payload = payload
+	q.UpdatedAt = time.Now().UTC()
+	return nil
+}
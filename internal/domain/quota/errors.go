@@ -0,0 +1,8 @@
+package quota
+
+import "errors"
+
+var (
+	ErrBytesExceeded = errors.New("storage quota exceeded, the instance has no bytes left")
+	ErrFilesExceeded = errors.New("storage quota exceeded, the instance cannot store more files")
+)
@@ -0,0 +1,49 @@
+package quota
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+)
+
+// Quota caps how much of the shared storage an instance takes, zero leaves a limit off
+type Quota struct {
+	InstanceID string `json:"instance_id"`
+
+	MaxBytes uint64 `json:"max_bytes"`
+	MaxFiles uint64 `json:"max_files"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewQuota creates the quota of an instance from the defaults
+func NewQuota(instanceID string, defaults Quota) *Quota {
+	now := time.Now().UTC()
+
+	quota := defaults
+	quota.InstanceID = instanceID
+	quota.CreatedAt = now
+	quota.UpdatedAt = now
+
+	return &quota
+}
+
+// Allows tells whether a new file of the size fits next to the usage
+func (q *Quota) Allows(usage *file.Usage, size uint64) error {
+	if q.MaxFiles > 0 && usage.Files+1 > q.MaxFiles {
+		return ErrFilesExceeded
+	}
+
+	if q.MaxBytes > 0 && usage.Bytes+size > q.MaxBytes {
+		return ErrBytesExceeded
+	}
+
+	return nil
+}
+
+// Summary is the usage of an instance next to its quota
+type Summary struct {
+	Usage *file.Usage `json:"usage"`
+	Quota *Quota      `json:"quota"`
+}
@@ -0,0 +1,36 @@
+package quota_test
+
+import (
+	"testing"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestQuota(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Quota Suite")
+}
+
+var _ = Describe("Storage quota", func() {
+	It("should allow anything without limits", func() {
+		q := quota.NewQuota("instance-1", quota.Quota{})
+		Expect(q.Allows(&file.Usage{Files: 1000, Bytes: 1 << 40}, 1<<30)).To(Succeed())
+	})
+
+	It("should refuse files past the count", func() {
+		q := quota.NewQuota("instance-1", quota.Quota{MaxFiles: 2})
+
+		Expect(q.Allows(&file.Usage{Files: 1}, 10)).To(Succeed())
+		Expect(q.Allows(&file.Usage{Files: 2}, 10)).To(Equal(quota.ErrFilesExceeded))
+	})
+
+	It("should refuse files past the bytes", func() {
+		q := quota.NewQuota("instance-1", quota.Quota{MaxBytes: 100})
+
+		Expect(q.Allows(&file.Usage{Bytes: 90}, 10)).To(Succeed())
+		Expect(q.Allows(&file.Usage{Bytes: 90}, 11)).To(Equal(quota.ErrBytesExceeded))
+	})
+})
@@ -0,0 +1,7 @@
+package quota
+
+type QuotaRepository interface {
+	// Save inserts or replaces the quota of the instance
+	Save(quota *Quota) error
+	Get(instanceID string) (*Quota, error)
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import "time"
+
+// Bucket is a token bucket holding up to capacity tokens, refilled at capacity tokens per period
+type Bucket struct {
+	capacity float64
+	tokens   float64
+	period   time.Duration
+	last     time.Time
+}
+
+func NewBucket(capacity int, period time.Duration, now time.Time) *Bucket {
+	return &Bucket{
+		capacity: float64(capacity),
+		tokens:   float64(capacity),
+		period:   period,
+		last:     now,
+	}
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.capacity, b.tokens+b.capacity*float64(elapsed)/float64(b.period))
+		b.last = now
+	}
+}
+
+// Wait returns how long until a token is available, zero when one is available now
+func (b *Bucket) Wait(now time.Time) time.Duration {
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) * float64(b.period) / b.capacity)
+}
+
+// Take spends a token, it must follow a Wait that returned zero
+func (b *Bucket) Take(now time.Time) {
+	b.refill(now)
+	b.tokens--
+}
+
+// RestoreBucket returns a bucket holding the tokens it had when it was last refilled
+func RestoreBucket(capacity int, period time.Duration, tokens float64, last time.Time) *Bucket {
+	return &Bucket{
+		capacity: float64(capacity),
+		tokens:   tokens,
+		period:   period,
+		last:     last,
+	}
+}
+
+// Tokens returns the tokens left at the last refill
+func (b *Bucket) Tokens() float64 {
+	return b.tokens
+}
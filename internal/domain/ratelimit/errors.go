@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRateLimited       = errors.New("send rate limit reached")
+	ErrRecipientCooldown = errors.New("too soon to send again to this chat")
+	ErrInvalidLimit      = errors.New("invalid rate limit")
+	ErrInvalidDelay      = errors.New("invalid delay, min_delay_ms must not be greater than max_delay_ms")
+	ErrInvalidAction     = errors.New("invalid on_limit, use reject or queue")
+)
+
+// LimitError tells how long to wait before the send is allowed
+type LimitError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,11 @@
+package ratelimit
+
+// Limiter keeps the send rate of each instance
+type Limiter interface {
+	// Take reserves a send of the instance to the chat following the policy, when a limit is hit nothing is
+	// reserved and a *LimitError is returned
+	Take(policy *Policy, chat string) error
+
+	// Forget drops the state of an instance
+	Forget(instanceID string)
+}
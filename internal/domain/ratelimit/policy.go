@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	MaxPerSecond         = 50
+	MaxPerMinute         = 1000
+	MaxRecipientCooldown = time.Hour
+	MaxDelay             = time.Minute
+)
+
+// Action is what happens to a send that hits a limit
+type Action string
+
+const (
+	ActionReject Action = "reject" // the send fails with a rate limit error
+	ActionQueue  Action = "queue"  // the send is queued and delivered once the limit allows
+)
+
+func (a Action) IsValid() bool {
+	return a == ActionReject || a == ActionQueue
+}
+
+// Policy is how fast an instance is allowed to send, a zero limit is disabled
+type Policy struct {
+	InstanceID string `json:"instance_id"`
+
+	PerSecond           int  `json:"per_second"`            // token bucket refilled every second
+	PerMinute           int  `json:"per_minute"`            // token bucket refilled every minute
+	RecipientCooldownMs int  `json:"recipient_cooldown_ms"` // minimum time between two sends to the same chat
+	MinDelayMs          int  `json:"min_delay_ms"`          // random wait before each send, between min and max
+	MaxDelayMs          int  `json:"max_delay_ms"`
+	Typing              bool `json:"typing"` // show typing, or recording for voice, during the wait
+
+	OnLimit Action `json:"on_limit"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewPolicy creates the policy of an instance from the defaults
+func NewPolicy(instanceID string, defaults Policy) *Policy {
+	now := time.Now().UTC()
+
+	policy := defaults
+	policy.InstanceID = instanceID
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+
+	if !policy.OnLimit.IsValid() {
+		policy.OnLimit = ActionReject
+	}
+
+	return &policy
+}
+
+// Validate checks the rules that involve more than one field, the ranges are checked by the input
+func (p *Policy) Validate() error {
+	if p.MinDelayMs > p.MaxDelayMs {
+		return ErrInvalidDelay
+	}
+
+	if !p.OnLimit.IsValid() {
+		return ErrInvalidAction
+	}
+
+	return nil
+}
+
+func (p *Policy) RecipientCooldown() time.Duration {
+	return time.Duration(p.RecipientCooldownMs) * time.Millisecond
+}
+
+// Delay picks the wait before a send, randomised so sends don't follow a machine rhythm
+func (p *Policy) Delay() time.Duration {
+	minDelay := time.Duration(p.MinDelayMs) * time.Millisecond
+	maxDelay := time.Duration(p.MaxDelayMs) * time.Millisecond
+
+	if maxDelay <= minDelay {
+		return minDelay
+	}
+
+	return minDelay + rand.N(maxDelay-minDelay)
+}
+
+func (p *Policy) QueuesOnLimit() bool {
+	return p.OnLimit == ActionQueue
+}
@@ -0,0 +1,57 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRateLimit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rate Limit Suite")
+}
+
+var _ = Describe("Rate limit", func() {
+	It("should refill the bucket over the period", func() {
+		now := time.Now()
+		b := ratelimit.NewBucket(2, time.Second, now)
+
+		Expect(b.Wait(now)).To(BeZero())
+		b.Take(now)
+		Expect(b.Wait(now)).To(BeZero())
+		b.Take(now)
+
+		Expect(b.Wait(now)).To(Equal(500 * time.Millisecond))
+		Expect(b.Wait(now.Add(500 * time.Millisecond))).To(BeZero())
+	})
+
+	It("should not refill above the capacity", func() {
+		now := time.Now()
+		b := ratelimit.NewBucket(1, time.Minute, now)
+		b.Take(now.Add(time.Hour))
+
+		Expect(b.Wait(now.Add(time.Hour))).To(Equal(time.Minute))
+	})
+
+	It("should pick a delay between min and max", func() {
+		p := ratelimit.NewPolicy("instance-1", ratelimit.Policy{MinDelayMs: 100, MaxDelayMs: 300})
+
+		for range 50 {
+			delay := p.Delay()
+			Expect(delay).To(BeNumerically(">=", 100*time.Millisecond))
+			Expect(delay).To(BeNumerically("<", 300*time.Millisecond))
+		}
+	})
+
+	It("should default to reject and validate the delays", func() {
+		p := ratelimit.NewPolicy("instance-1", ratelimit.Policy{})
+		Expect(p.OnLimit).To(Equal(ratelimit.ActionReject))
+		Expect(p.Validate()).To(Succeed())
+
+		p.MinDelayMs = 500
+		Expect(p.Validate()).To(Equal(ratelimit.ErrInvalidDelay))
+	})
+})
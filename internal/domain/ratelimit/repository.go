@@ -0,0 +1,22 @@
+package ratelimit
+
+import "time"
+
+type PolicyRepository interface {
+	// Save inserts or replaces the policy of the instance
+	Save(policy *Policy) error
+	Get(instanceID string) (*Policy, error)
+}
+
+// StateRepository keeps the rate of the instances where every replica sees it
+type StateRepository interface {
+	GetState(instanceID string) (*State, error)
+	// SaveState stores the state when no other version was stored since it was read, false when one was
+	SaveState(state *State) (bool, error)
+	// LastSent returns when the instance last sent to the chat, nil when it did not since the cooldown
+	LastSent(instanceID string, chat string) (*time.Time, error)
+	// MarkSent records a send to the chat unless another one was recorded after since, false when there was,
+	// the sends older than since are dropped
+	MarkSent(instanceID string, chat string, at time.Time, since time.Time) (bool, error)
+	ForgetState(instanceID string) error
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import "time"
+
+// State is the rate of an instance as seen by every replica, the tokens left in each bucket at the last refill
+type State struct {
+	InstanceID      string
+	PerSecond       int
+	PerSecondTokens float64
+	PerMinute       int
+	PerMinuteTokens float64
+	RefilledAt      time.Time
+	Version         int // zero until the state is stored
+}
+
+func NewState(instanceID string) *State {
+	return &State{InstanceID: instanceID}
+}
+
+// Buckets returns the buckets of the policy holding the stored tokens, a limit that changed starts full
+func (s *State) Buckets(policy *Policy, now time.Time) (perSecond *Bucket, perMinute *Bucket) {
+	if policy.PerSecond > 0 {
+		perSecond = NewBucket(policy.PerSecond, time.Second, now)
+		if s.PerSecond == policy.PerSecond {
+			perSecond = RestoreBucket(policy.PerSecond, time.Second, s.PerSecondTokens, s.RefilledAt)
+		}
+	}
+
+	if policy.PerMinute > 0 {
+		perMinute = NewBucket(policy.PerMinute, time.Minute, now)
+		if s.PerMinute == policy.PerMinute {
+			perMinute = RestoreBucket(policy.PerMinute, time.Minute, s.PerMinuteTokens, s.RefilledAt)
+		}
+	}
+
+	return perSecond, perMinute
+}
+
+// Keep stores the tokens left in the buckets of the policy, refilled up to now
+func (s *State) Keep(policy *Policy, perSecond *Bucket, perMinute *Bucket, now time.Time) {
+	s.PerSecond, s.PerSecondTokens = 0, 0
+	if perSecond != nil {
+		s.PerSecond, s.PerSecondTokens = policy.PerSecond, perSecond.Tokens()
+	}
+
+	s.PerMinute, s.PerMinuteTokens = 0, 0
+	if perMinute != nil {
+		s.PerMinute, s.PerMinuteTokens = policy.PerMinute, perMinute.Tokens()
+	}
+
+	s.RefilledAt = now
+}
@@ -0,0 +1,7 @@
+package retention
+
+import "errors"
+
+var (
+	ErrNoLimit = errors.New("an enabled retention rule needs max_age, max_bytes or max_count")
+)
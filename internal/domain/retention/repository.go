@@ -0,0 +1,7 @@
+package retention
+
+type RuleRepository interface {
+	// Save inserts or replaces the rule of the instance
+	Save(rule *Rule) error
+	Get(instanceID string) (*Rule, error)
+}
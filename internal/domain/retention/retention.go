@@ -0,0 +1,145 @@
+package retention
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+)
+
+type Reason string
+
+const (
+	ReasonMaxAge          Reason = "max_age"
+	ReasonMaxBytes        Reason = "max_bytes"
+	ReasonMaxCount        Reason = "max_count"
+	ReasonOrphanThumbnail Reason = "orphan_thumbnail" // a generated thumbnail no file uses anymore
+	ReasonOrphanBlob      Reason = "orphan_blob"      // an object in the storage without a file
+)
+
+// Rule is how long the uploads of an instance are kept, the oldest go first
+type Rule struct {
+	InstanceID string `json:"instance_id"`
+
+	Enabled    bool   `json:"enabled"`
+	MaxAge     uint64 `json:"max_age"`     // in seconds, zero keeps files of any age
+	MaxBytes   uint64 `json:"max_bytes"`   // total size of the files kept, zero keeps any size
+	MaxCount   uint64 `json:"max_count"`   // files kept, zero keeps any count
+	KeepPinned bool   `json:"keep_pinned"` // pinned files are never deleted and still count towards the limits
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewRule creates the rule of an instance from the defaults
+func NewRule(instanceID string, defaults Rule) *Rule {
+	now := time.Now().UTC()
+
+	rule := defaults
+	rule.InstanceID = instanceID
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	return &rule
+}
+
+func (r *Rule) Validate() error {
+	if r.Enabled && r.MaxAge == 0 && r.MaxBytes == 0 && r.MaxCount == 0 {
+		return ErrNoLimit
+	}
+
+	return nil
+}
+
+// Expired returns the files the rule deletes, files must come newest first. A file goes when it is older than
+// max age or keeping it would go over max count or max bytes
+func (r *Rule) Expired(files []*file.File, now time.Time) []Item {
+	if !r.Enabled {
+		return nil
+	}
+
+	var (
+		expired []Item
+		count   uint64
+		bytes   uint64
+	)
+
+	for _, f := range files {
+		if r.KeepPinned && f.Pinned {
+			count++
+			bytes += f.Size
+			continue
+		}
+
+		var reason Reason
+		switch {
+		case r.MaxAge > 0 && f.CreatedAt != nil && now.Sub(*f.CreatedAt) > time.Duration(r.MaxAge)*time.Second:
+			reason = ReasonMaxAge
+		case r.MaxCount > 0 && count+1 > r.MaxCount:
+			reason = ReasonMaxCount
+		case r.MaxBytes > 0 && bytes+f.Size > r.MaxBytes:
+			reason = ReasonMaxBytes
+		}
+
+		if reason != "" {
+			expired = append(expired, NewFileItem(f, reason))
+			continue
+		}
+
+		count++
+		bytes += f.Size
+	}
+
+	return expired
+}
+
+// Item is something a sweep deletes, a file with its stored object or an object alone
+type Item struct {
+	FileID     string  `json:"file_id,omitempty"`
+	InstanceID *string `json:"instance_id,omitempty"`
+	Path       string  `json:"path"`
+	Size       uint64  `json:"size"`
+	Reason     Reason  `json:"reason"`
+}
+
+func NewFileItem(f *file.File, reason Reason) Item {
+	return Item{
+		FileID:     f.ID,
+		InstanceID: f.InstanceID,
+		Path:       f.Path,
+		Size:       f.Size,
+		Reason:     reason,
+	}
+}
+
+// Report is what a sweep deleted, or would delete on a dry run
+type Report struct {
+	DryRun bool   `json:"dry_run"`
+	Items  []Item `json:"items"`
+	Files  uint64 `json:"files"` // file rows
+	Blobs  uint64 `json:"blobs"` // stored objects
+	Bytes  uint64 `json:"bytes"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+func NewReport(dryRun bool) *Report {
+	return &Report{
+		DryRun:    dryRun,
+		Items:     []Item{},
+		StartedAt: time.Now().UTC(),
+	}
+}
+
+func (r *Report) Add(item Item) {
+	r.Items = append(r.Items, item)
+	if item.FileID != "" {
+		r.Files++
+	}
+	r.Blobs++
+	r.Bytes += item.Size
+}
+
+func (r *Report) Finish() {
+	r.FinishedAt = time.Now().UTC()
+}
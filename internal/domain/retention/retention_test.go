@@ -0,0 +1,99 @@
+package retention_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/retention"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRetention(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Retention Suite")
+}
+
+func newFile(id string, size uint64, age time.Duration, pinned bool) *file.File {
+	createdAt := time.Now().UTC().Add(-age)
+	return &file.File{ID: id, Path: id, Size: size, Pinned: pinned, CreatedAt: &createdAt}
+}
+
+var _ = Describe("Retention rule", func() {
+	It("should delete nothing while disabled", func() {
+		r := retention.NewRule("instance-1", retention.Rule{MaxCount: 1})
+		files := []*file.File{newFile("a", 1, 0, false), newFile("b", 1, time.Hour, false)}
+
+		Expect(r.Expired(files, time.Now().UTC())).To(BeEmpty())
+	})
+
+	It("should delete files older than max age", func() {
+		r := retention.NewRule("instance-1", retention.Rule{Enabled: true, MaxAge: 60})
+		files := []*file.File{newFile("a", 1, 0, false), newFile("b", 1, time.Hour, false)}
+
+		expired := r.Expired(files, time.Now().UTC())
+		Expect(expired).To(HaveLen(1))
+		Expect(expired[0].FileID).To(Equal("b"))
+		Expect(expired[0].Reason).To(Equal(retention.ReasonMaxAge))
+	})
+
+	It("should keep the newest files within max count and max bytes", func() {
+		r := retention.NewRule("instance-1", retention.Rule{Enabled: true, MaxCount: 2, MaxBytes: 15})
+		files := []*file.File{
+			newFile("a", 10, 0, false),
+			newFile("b", 10, time.Minute, false),
+			newFile("c", 5, 2*time.Minute, false),
+			newFile("d", 1, 3*time.Minute, false),
+		}
+
+		expired := r.Expired(files, time.Now().UTC())
+		Expect(expired).To(HaveLen(2))
+		Expect(expired[0].FileID).To(Equal("b"))
+		Expect(expired[0].Reason).To(Equal(retention.ReasonMaxBytes))
+		Expect(expired[1].FileID).To(Equal("d"))
+		Expect(expired[1].Reason).To(Equal(retention.ReasonMaxCount))
+	})
+
+	It("should keep pinned files and count them towards the limits", func() {
+		r := retention.NewRule("instance-1", retention.Rule{Enabled: true, MaxCount: 1, MaxAge: 60, KeepPinned: true})
+		files := []*file.File{newFile("a", 1, time.Hour, true), newFile("b", 1, 0, false)}
+
+		expired := r.Expired(files, time.Now().UTC())
+		Expect(expired).To(HaveLen(1))
+		Expect(expired[0].FileID).To(Equal("b"))
+		Expect(expired[0].Reason).To(Equal(retention.ReasonMaxCount))
+
+		r.KeepPinned = false
+		expired = r.Expired(files, time.Now().UTC())
+		Expect(expired).To(HaveLen(1))
+		Expect(expired[0].FileID).To(Equal("a"))
+		Expect(expired[0].Reason).To(Equal(retention.ReasonMaxAge))
+	})
+
+	It("should require a limit when enabled", func() {
+		r := retention.NewRule("instance-1", retention.Rule{})
+		Expect(r.Validate()).To(Succeed())
+
+		r.Enabled = true
+		Expect(r.Validate()).To(Equal(retention.ErrNoLimit))
+
+		r.MaxBytes = 1024
+		Expect(r.Validate()).To(Succeed())
+	})
+})
+
+var _ = Describe("Retention report", func() {
+	It("should count files, blobs and bytes", func() {
+		report := retention.NewReport(true)
+		report.Add(retention.NewFileItem(newFile("a", 10, 0, false), retention.ReasonMaxAge))
+		report.Add(retention.Item{Path: "orphan.bin", Size: 5, Reason: retention.ReasonOrphanBlob})
+		report.Finish()
+
+		Expect(report.DryRun).To(BeTrue())
+		Expect(report.Files).To(Equal(uint64(1)))
+		Expect(report.Blobs).To(Equal(uint64(2)))
+		Expect(report.Bytes).To(Equal(uint64(15)))
+		Expect(report.FinishedAt).ToNot(BeZero())
+	})
+})
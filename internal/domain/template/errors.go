@@ -0,0 +1,43 @@
+package template
+
+import "errors"
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrInvalidName      = errors.New("invalid template name")
+	ErrNameTaken        = errors.New("template name already in use")
+	ErrUnsupportedKind  = errors.New("unsupported template type, use text, image, video or document")
+	ErrEmptyBody        = errors.New("template body is empty")
+	ErrBodyTooLong      = errors.New("template body is too long")
+	ErrMediaRequired    = errors.New("template media is required")
+	ErrMediaNotAllowed  = errors.New("text templates have no media")
+	ErrInvalidSyntax    = errors.New("invalid template syntax")
+	ErrMissingVariable  = errors.New("missing template variable")
+	ErrNothingToUpdate  = errors.New("nothing to update")
+)
+
+// SyntaxError tells where a body is broken, it matches ErrInvalidSyntax
+type SyntaxError struct {
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return ErrInvalidSyntax.Error() + ": " + e.Reason
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalidSyntax
+}
+
+// MissingVariableError names the variable a render needed, it matches ErrMissingVariable
+type MissingVariableError struct {
+	Name string
+}
+
+func (e *MissingVariableError) Error() string {
+	return ErrMissingVariable.Error() + ": " + e.Name
+}
+
+func (e *MissingVariableError) Unwrap() error {
+	return ErrMissingVariable
+}
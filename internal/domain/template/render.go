@@ -0,0 +1,239 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The syntax of a body:
+//
+//	{{name}}                     value of the variable, it must be supplied
+//	{{name|fallback}}            value of the variable or the fallback when missing or empty
+//	{{#if name}}..{{/if}}        kept when the variable is supplied and not empty
+//	{{#if name}}..{{else}}..{{/if}}
+//	{{#unless name}}..{{/unless}} kept when the variable is missing or empty
+//
+// Blocks can be nested.
+
+var variableName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+type node interface {
+	render(vars map[string]string, out *strings.Builder) error
+	variables(seen map[string]bool, names *[]string)
+}
+
+type textNode string
+
+func (n textNode) render(vars map[string]string, out *strings.Builder) error {
+	out.WriteString(string(n))
+	return nil
+}
+
+func (n textNode) variables(seen map[string]bool, names *[]string) {}
+
+type variableNode struct {
+	name     string
+	fallback *string
+}
+
+func (n variableNode) render(vars map[string]string, out *strings.Builder) error {
+	if value := vars[n.name]; value != "" {
+		out.WriteString(value)
+		return nil
+	}
+
+	if n.fallback != nil {
+		out.WriteString(*n.fallback)
+		return nil
+	}
+
+	// a variable supplied empty is written as is, only a missing one is an error
+	if _, ok := vars[n.name]; ok {
+		return nil
+	}
+
+	return &MissingVariableError{Name: n.name}
+}
+
+func (n variableNode) variables(seen map[string]bool, names *[]string) {
+	addVariable(n.name, seen, names)
+}
+
+type conditionNode struct {
+	name      string
+	negate    bool
+	then      []node
+	otherwise []node
+}
+
+func (n conditionNode) render(vars map[string]string, out *strings.Builder) error {
+	branch := n.then
+	if (vars[n.name] != "") == n.negate {
+		branch = n.otherwise
+	}
+
+	return renderNodes(branch, vars, out)
+}
+
+func (n conditionNode) variables(seen map[string]bool, names *[]string) {
+	addVariable(n.name, seen, names)
+	for _, child := range n.then {
+		child.variables(seen, names)
+	}
+	for _, child := range n.otherwise {
+		child.variables(seen, names)
+	}
+}
+
+func addVariable(name string, seen map[string]bool, names *[]string) {
+	if seen[name] {
+		return
+	}
+	seen[name] = true
+	*names = append(*names, name)
+}
+
+func renderNodes(nodes []node, vars map[string]string, out *strings.Builder) error {
+	for _, n := range nodes {
+		if err := n.render(vars, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Body is a parsed template body
+type Body struct {
+	nodes []node
+}
+
+// Parse checks the syntax of a body
+func Parse(source string) (*Body, error) {
+	p := &parser{source: source}
+
+	nodes, closing, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if closing != "" {
+		return nil, syntaxError("unexpected {{%s}}", closing)
+	}
+
+	return &Body{nodes: nodes}, nil
+}
+
+// Render fills the body with the variables
+func (b *Body) Render(vars map[string]string) (string, error) {
+	var out strings.Builder
+	if err := renderNodes(b.nodes, vars, &out); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Variables returns the names of the variables used by the body, in order of appearance
+func (b *Body) Variables() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, n := range b.nodes {
+		n.variables(seen, &names)
+	}
+	return names
+}
+
+type parser struct {
+	source string
+	pos    int
+}
+
+// parse reads nodes until the end of the source or a closing tag ("else", "/if" or "/unless"), which is returned
+func (p *parser) parse() ([]node, string, error) {
+	nodes := []node{}
+
+	for p.pos < len(p.source) {
+		start := strings.Index(p.source[p.pos:], "{{")
+		if start < 0 {
+			nodes = append(nodes, textNode(p.source[p.pos:]))
+			p.pos = len(p.source)
+			break
+		}
+
+		if start > 0 {
+			nodes = append(nodes, textNode(p.source[p.pos:p.pos+start]))
+		}
+		p.pos += start + 2
+
+		end := strings.Index(p.source[p.pos:], "}}")
+		if end < 0 {
+			return nil, "", syntaxError("unclosed {{")
+		}
+
+		tag := strings.TrimSpace(p.source[p.pos : p.pos+end])
+		p.pos += end + 2
+
+		switch {
+		case tag == "else" || tag == "/if" || tag == "/unless":
+			return nodes, tag, nil
+
+		case strings.HasPrefix(tag, "#if ") || strings.HasPrefix(tag, "#unless "):
+			block, err := p.parseBlock(tag)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, block)
+
+		default:
+			name, fallback, hasFallback := strings.Cut(tag, "|")
+			name = strings.TrimSpace(name)
+			if !variableName.MatchString(name) {
+				return nil, "", syntaxError("invalid variable {{%s}}", tag)
+			}
+
+			n := variableNode{name: name}
+			if hasFallback {
+				n.fallback = &fallback
+			}
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes, "", nil
+}
+
+func (p *parser) parseBlock(tag string) (node, error) {
+	keyword, name, _ := strings.Cut(tag, " ")
+	name = strings.TrimSpace(name)
+	if !variableName.MatchString(name) {
+		return nil, syntaxError("invalid variable in {{%s}}", tag)
+	}
+
+	block := conditionNode{name: name, negate: keyword == "#unless"}
+	end := "/" + strings.TrimPrefix(keyword, "#")
+
+	then, closing, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	block.then = then
+
+	if closing == "else" {
+		otherwise, closing2, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		block.otherwise = otherwise
+		closing = closing2
+	}
+
+	if closing != end {
+		return nil, syntaxError("{{%s}} is not closed by {{%s}}", tag, end)
+	}
+
+	return block, nil
+}
+
+func syntaxError(format string, args ...any) error {
+	return &SyntaxError{Reason: fmt.Sprintf(format, args...)}
+}
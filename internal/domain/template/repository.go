@@ -0,0 +1,37 @@
+package template
+
+type TemplateQueryOptions struct {
+	ID         *string `db:"id"`
+	InstanceID *string `db:"instance_id"`
+	Name       *string `db:"name"`
+}
+
+type TemplateQueryOption func(*TemplateQueryOptions)
+
+type TemplateRepository interface {
+	Insert(t *Template) error
+	Update(t *Template) error
+
+	Get(opts ...TemplateQueryOption) (*Template, error)
+	List(opts ...TemplateQueryOption) ([]*Template, error)
+
+	Delete(opts ...TemplateQueryOption) error
+}
+
+func WhereID(id string) TemplateQueryOption {
+	return func(o *TemplateQueryOptions) {
+		o.ID = &id
+	}
+}
+
+func WhereInstanceID(instanceID string) TemplateQueryOption {
+	return func(o *TemplateQueryOptions) {
+		o.InstanceID = &instanceID
+	}
+}
+
+func WhereName(name string) TemplateQueryOption {
+	return func(o *TemplateQueryOptions) {
+		o.Name = &name
+	}
+}
@@ -0,0 +1,171 @@
+package template
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+const (
+	MaxNameLength  = 100
+	MaxBodyLength  = message.MaxMessageTextLength
+	MaxMediaLength = 2048 // url or upload id, the media itself is not stored with the template
+)
+
+// IsSupportedKind tells if a template can be sent as the kind, a text or a media with its caption
+func IsSupportedKind(kind message.MessageKind) bool {
+	switch kind {
+	case message.MessageKindText, message.MessageKindImage, message.MessageKindVideo, message.MessageKindDocument:
+		return true
+	default:
+		return false
+	}
+}
+
+// Template is a message saved to be sent many times, its body is the text or the caption of the media and both
+// can use variables, see render.go for the syntax
+type Template struct {
+	ID         string              `json:"id"`
+	InstanceID string              `json:"instance_id"`
+	Name       string              `json:"name"`
+	Kind       message.MessageKind `json:"kind"`
+	Body       string              `json:"body"`
+	Media      *string             `json:"media"` // url or upload id of the media, only for media kinds
+	Variables  []string            `json:"variables"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// Rendered is a template filled for a recipient
+type Rendered struct {
+	Body  string  `json:"body"`
+	Media *string `json:"media"`
+}
+
+func New(instanceID string, name string, kind message.MessageKind, body string, media *string) *Template {
+	id, _ := uuid.NewV7()
+	now := time.Now().UTC()
+
+	t := &Template{
+		ID:         id.String(),
+		InstanceID: instanceID,
+		Name:       strings.TrimSpace(name),
+		Kind:       kind,
+		Body:       body,
+		Media:      media,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	t.Variables = t.ListVariables()
+
+	return t
+}
+
+func (t *Template) Update(name *string, body *string, media *string) {
+	if name != nil {
+		t.Name = strings.TrimSpace(*name)
+	}
+
+	if body != nil {
+		t.Body = *body
+	}
+
+	if media != nil {
+		t.Media = media
+	}
+
+	t.Variables = t.ListVariables()
+	t.UpdatedAt = time.Now().UTC()
+}
+
+func (t *Template) Validate() error {
+	if t.Name == "" || len(t.Name) > MaxNameLength {
+		return ErrInvalidName
+	}
+
+	if !IsSupportedKind(t.Kind) {
+		return ErrUnsupportedKind
+	}
+
+	if t.Kind == message.MessageKindText && t.Body == "" {
+		return ErrEmptyBody
+	}
+
+	if len(t.Body) > MaxBodyLength {
+		return ErrBodyTooLong
+	}
+
+	if t.Kind == message.MessageKindText && t.Media != nil {
+		return ErrMediaNotAllowed
+	}
+
+	if t.Kind != message.MessageKindText && (t.Media == nil || *t.Media == "" || len(*t.Media) > MaxMediaLength) {
+		return ErrMediaRequired
+	}
+
+	if _, err := Parse(t.Body); err != nil {
+		return err
+	}
+
+	if t.Media != nil {
+		if _, err := Parse(*t.Media); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListVariables returns the variables used by the body and the media, empty when they are broken
+func (t *Template) ListVariables() []string {
+	names := []string{}
+	seen := map[string]bool{}
+
+	sources := []string{t.Body}
+	if t.Media != nil {
+		sources = append(sources, *t.Media)
+	}
+
+	for _, source := range sources {
+		body, err := Parse(source)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range body.Variables() {
+			addVariable(name, seen, &names)
+		}
+	}
+
+	return names
+}
+
+// Render fills the body and the media with the variables
+func (t *Template) Render(vars map[string]string) (*Rendered, error) {
+	body, err := Parse(t.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := &Rendered{}
+	if rendered.Body, err = body.Render(vars); err != nil {
+		return nil, err
+	}
+
+	if t.Media != nil {
+		media, err := Parse(*t.Media)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := media.Render(vars)
+		if err != nil {
+			return nil, err
+		}
+		rendered.Media = &value
+	}
+
+	return rendered, nil
+}
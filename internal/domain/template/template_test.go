@@ -0,0 +1,127 @@
+package template_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/template"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTemplate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Template Entity Suite")
+}
+
+var _ = Describe("Template body", func() {
+	render := func(source string, vars map[string]string) (string, error) {
+		body, err := template.Parse(source)
+		Expect(err).ToNot(HaveOccurred())
+		return body.Render(vars)
+	}
+
+	It("should fill the variables", func() {
+		out, err := render("Hi {{ name }}, your order {{order.id}} is ready", map[string]string{"name": "Ana", "order.id": "42"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("Hi Ana, your order 42 is ready"))
+	})
+
+	It("should use the fallback of a missing or empty variable", func() {
+		out, err := render("Hi {{name|there}}!", map[string]string{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("Hi there!"))
+
+		out, err = render("Hi {{name|there}}!", map[string]string{"name": ""})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("Hi there!"))
+	})
+
+	It("should fail when a variable without fallback is missing", func() {
+		_, err := render("Hi {{name}}", map[string]string{})
+		Expect(errors.Is(err, template.ErrMissingVariable)).To(BeTrue())
+
+		var missing *template.MissingVariableError
+		Expect(errors.As(err, &missing)).To(BeTrue())
+		Expect(missing.Name).To(Equal("name"))
+	})
+
+	It("should keep the branch of the conditionals", func() {
+		source := "{{#if coupon}}Use {{coupon}}{{else}}No coupon{{/if}}{{#unless vip}}, join the club{{/unless}}"
+
+		out, err := render(source, map[string]string{"coupon": "OFF10"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("Use OFF10, join the club"))
+
+		out, err = render(source, map[string]string{"vip": "yes"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("No coupon"))
+	})
+
+	It("should nest conditionals", func() {
+		out, err := render("{{#if a}}A{{#if b}}B{{else}}-{{/if}}{{/if}}", map[string]string{"a": "1"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("A-"))
+	})
+
+	It("should list the variables in order of appearance", func() {
+		body, err := template.Parse("{{#if coupon}}{{coupon}} for {{name}}{{/if}} {{name|you}}")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(body.Variables()).To(Equal([]string{"coupon", "name"}))
+	})
+
+	DescribeTable("should reject a broken body",
+		func(source string) {
+			_, err := template.Parse(source)
+			Expect(errors.Is(err, template.ErrInvalidSyntax)).To(BeTrue())
+		},
+		Entry("unclosed tag", "Hi {{name"),
+		Entry("invalid variable", "Hi {{first name}}"),
+		Entry("unclosed block", "{{#if a}}A"),
+		Entry("mismatched block", "{{#if a}}A{{/unless}}"),
+		Entry("stray closing", "A{{/if}}"),
+		Entry("stray else", "A{{else}}B"),
+		Entry("two else", "{{#if a}}A{{else}}B{{else}}C{{/if}}"),
+	)
+})
+
+var _ = Describe("Template entity", func() {
+	It("should validate the template", func() {
+		media := "https://example.com/{{product}}.png"
+
+		Expect(template.New("instance-1", "welcome", message.MessageKindText, "Hi {{name}}", nil).Validate()).To(Succeed())
+		Expect(template.New("instance-1", "promo", message.MessageKindImage, "", &media).Validate()).To(Succeed())
+
+		Expect(template.New("instance-1", " ", message.MessageKindText, "Hi", nil).Validate()).To(Equal(template.ErrInvalidName))
+		Expect(template.New("instance-1", "a", message.MessageKindAudio, "Hi", &media).Validate()).To(Equal(template.ErrUnsupportedKind))
+		Expect(template.New("instance-1", "a", message.MessageKindText, "", nil).Validate()).To(Equal(template.ErrEmptyBody))
+		Expect(template.New("instance-1", "a", message.MessageKindText, "Hi", &media).Validate()).To(Equal(template.ErrMediaNotAllowed))
+		Expect(template.New("instance-1", "a", message.MessageKindImage, "Hi", nil).Validate()).To(Equal(template.ErrMediaRequired))
+
+		err := template.New("instance-1", "a", message.MessageKindText, "Hi {{name", nil).Validate()
+		Expect(errors.Is(err, template.ErrInvalidSyntax)).To(BeTrue())
+	})
+
+	It("should render the body and the media", func() {
+		media := "https://example.com/{{product}}.png"
+		t := template.New("instance-1", "promo", message.MessageKindImage, "{{product}} for {{price|a good price}}", &media)
+		Expect(t.Variables).To(Equal([]string{"product", "price"}))
+
+		rendered, err := t.Render(map[string]string{"product": "shoes"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.Body).To(Equal("shoes for a good price"))
+		Expect(*rendered.Media).To(Equal("https://example.com/shoes.png"))
+	})
+
+	It("should update the fields and the variables", func() {
+		t := template.New("instance-1", "welcome", message.MessageKindText, "Hi {{name}}", nil)
+
+		name := "greeting"
+		body := "Hello {{first_name}}"
+		t.Update(&name, &body, nil)
+
+		Expect(t.Name).To(Equal("greeting"))
+		Expect(t.Variables).To(Equal([]string{"first_name"}))
+	})
+})
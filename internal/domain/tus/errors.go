@@ -0,0 +1,14 @@
+package tus
+
+import "errors"
+
+var (
+	ErrUploadNotFound  = errors.New("upload not found")
+	ErrUploadExpired   = errors.New("upload expired")
+	ErrInvalidLength   = errors.New("upload length must be a positive number of bytes")
+	ErrUploadTooLarge  = errors.New("upload is larger than the server accepts")
+	ErrOffsetMismatch  = errors.New("upload offset does not match the received bytes")
+	ErrUploadLocked    = errors.New("upload is being written by another request")
+	ErrChunkTooLarge   = errors.New("chunk goes past the upload length")
+	ErrInvalidMetadata = errors.New("invalid upload metadata")
+)
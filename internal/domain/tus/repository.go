@@ -0,0 +1,38 @@
+package tus
+
+import "time"
+
+type UploadQueryOptions struct {
+	ID         *string `db:"id"`
+	InstanceID *string `db:"instance_id"`
+}
+
+type UploadQueryOption func(*UploadQueryOptions)
+
+type UploadRepository interface {
+	Insert(u *Upload) error
+	// Claim takes the upload for the request of u.Claim while it is at the offset of u and no other request holds
+	// it, a claim taken before staleBefore belongs to a request that is gone and is taken over
+	Claim(u *Upload, staleBefore time.Time) (bool, error)
+	// UpdateFrom stores the upload and releases its claim only while it is still at the given offset and claimed
+	// by u.Claim, it reports whether it was stored
+	UpdateFrom(u *Upload, offset uint64) (bool, error)
+
+	Get(opts ...UploadQueryOption) (*Upload, error)
+	Delete(opts ...UploadQueryOption) error
+
+	// Expired returns up to limit uploads expired before the given time
+	Expired(before time.Time, limit int) ([]*Upload, error)
+}
+
+func WhereID(id string) UploadQueryOption {
+	return func(o *UploadQueryOptions) {
+		o.ID = &id
+	}
+}
+
+func WhereInstanceID(instanceID string) UploadQueryOption {
+	return func(o *UploadQueryOptions) {
+		o.InstanceID = &instanceID
+	}
+}
@@ -0,0 +1,221 @@
+package tus
+
+import (
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+)
+
+const (
+	Version    = "1.0.0"
+	Extensions = "creation,termination,expiration"
+
+	MaxMetadataLength = 4096
+)
+
+// Part is a piece of the upload already written to the storage
+type Part struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+	Size   uint64 `json:"size"`
+}
+
+// Upload is a file sent in chunks over the tus protocol, chunks are written to the storage as they arrive and
+// the upload becomes a file.File once the last one lands
+type Upload struct {
+	ID          string
+	InstanceID  string
+	Length      uint64
+	Offset      uint64
+	Metadata    map[string]string
+	Mime        string // given in the metadata or detected on the first chunk
+	Path        string // key of the object in the storage, set with the first part
+	MultipartID string
+	Parts       []Part
+	Pending     uint64 // bytes kept aside until they fill a part, the storage may refuse small parts
+	HashState   []byte // sha256 of the bytes received so far, so the sum is ready when the last chunk lands
+	FileID      *string
+	Claim       string // request writing the upload, empty when none is
+	ClaimedAt   *time.Time
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func New(instanceID string, length uint64, metadata map[string]string, ttl time.Duration) *Upload {
+	uuid, _ := uuid.NewV7()
+	now := time.Now().UTC()
+
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	return &Upload{
+		ID:         uuid.String(),
+		InstanceID: instanceID,
+		Length:     length,
+		Metadata:   metadata,
+		Mime:       firstOf(metadata, "mime", "filetype"),
+		Parts:      []Part{},
+		ExpiresAt:  now.Add(ttl),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func (u *Upload) Validate(maxSize uint64) error {
+	if u.Length == 0 {
+		return ErrInvalidLength
+	}
+
+	if maxSize > 0 && u.Length > maxSize {
+		return ErrUploadTooLarge
+	}
+
+	return nil
+}
+
+// Accepts checks a chunk sent at offset, chunks must follow each other and stop at the length
+func (u *Upload) Accepts(offset uint64, size int) error {
+	if offset != u.Offset {
+		return ErrOffsetMismatch
+	}
+
+	if u.Offset+uint64(size) > u.Length {
+		return ErrChunkTooLarge
+	}
+
+	return nil
+}
+
+// NewClaim marks the upload as written by a new request, the claim only holds once the repository takes it
+func (u *Upload) NewClaim(now time.Time) {
+	id, _ := uuid.NewV7()
+	claimedAt := now.UTC()
+
+	u.Claim = id.String()
+	u.ClaimedAt = &claimedAt
+}
+
+// IsRegistered tells whether the completed upload already became a file
+func (u *Upload) IsRegistered() bool {
+	return u.FileID != nil
+}
+
+func (u *Upload) IsComplete() bool {
+	return u.Offset == u.Length
+}
+
+func (u *Upload) IsExpired(now time.Time) bool {
+	return !now.Before(u.ExpiresAt)
+}
+
+// FileMetadata reads the same fields the multipart upload takes as form values
+func (u *Upload) FileMetadata() file.Metadata {
+	metadata := file.Metadata{}
+
+	if name := firstOf(u.Metadata, "name", "filename"); name != "" {
+		metadata.Name = &name
+	}
+	if u.Mime != "" {
+		mime := u.Mime
+		metadata.Mime = &mime
+	}
+
+	metadata.Width = uint32Of(u.Metadata, "width")
+	metadata.Height = uint32Of(u.Metadata, "height")
+	metadata.Duration = uint32Of(u.Metadata, "duration")
+	metadata.Pages = uint32Of(u.Metadata, "pages")
+
+	return metadata
+}
+
+func (u *Upload) ThumbnailID() *string {
+	if id := u.Metadata["thumbnail_id"]; id != "" {
+		return &id
+	}
+	return nil
+}
+
+// ParseMetadata reads the Upload-Metadata header, comma separated pairs of a key and its base64 value, a key
+// may come alone when its value is empty
+func ParseMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+
+	if len(header) > MaxMetadataLength {
+		return nil, ErrInvalidMetadata
+	}
+
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, ErrInvalidMetadata
+		}
+
+		key := fields[0]
+		if _, ok := metadata[key]; ok {
+			return nil, ErrInvalidMetadata
+		}
+
+		value := ""
+		if len(fields) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, ErrInvalidMetadata
+			}
+			value = string(decoded)
+		}
+
+		metadata[key] = value
+	}
+
+	return metadata, nil
+}
+
+// EncodeMetadata is the inverse of ParseMetadata, keys are sorted so the header is stable
+func EncodeMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if metadata[key] == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func firstOf(metadata map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(metadata[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func uint32Of(metadata map[string]string, key string) *uint32 {
+	value, err := strconv.ParseUint(metadata[key], 10, 32)
+	if err != nil {
+		return nil
+	}
+
+	v := uint32(value)
+	return &v
+}
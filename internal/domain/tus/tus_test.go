@@ -0,0 +1,87 @@
+package tus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tus Suite")
+}
+
+var _ = Describe("Tus upload", func() {
+	It("should parse and encode the metadata", func() {
+		metadata, err := tus.ParseMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,filetype YXBwbGljYXRpb24vcGRm,is_confidential")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metadata).To(Equal(map[string]string{
+			"filename":        "world_domination_plan.pdf",
+			"filetype":        "application/pdf",
+			"is_confidential": "",
+		}))
+
+		again, err := tus.ParseMetadata(tus.EncodeMetadata(metadata))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again).To(Equal(metadata))
+
+		empty, err := tus.ParseMetadata("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(empty).To(BeEmpty())
+	})
+
+	It("should refuse broken metadata", func() {
+		for _, header := range []string{"filename not-base64!", "a YQ==,a Yg==", "a YQ== extra", "a YQ==,,b Yg=="} {
+			_, err := tus.ParseMetadata(header)
+			Expect(err).To(Equal(tus.ErrInvalidMetadata), header)
+		}
+	})
+
+	It("should validate the length", func() {
+		Expect(tus.New("instance-1", 0, nil, time.Hour).Validate(0)).To(Equal(tus.ErrInvalidLength))
+		Expect(tus.New("instance-1", 100, nil, time.Hour).Validate(50)).To(Equal(tus.ErrUploadTooLarge))
+		Expect(tus.New("instance-1", 100, nil, time.Hour).Validate(100)).To(Succeed())
+		Expect(tus.New("instance-1", 100, nil, time.Hour).Validate(0)).To(Succeed())
+	})
+
+	It("should only accept chunks that follow each other", func() {
+		u := tus.New("instance-1", 10, nil, time.Hour)
+
+		Expect(u.Accepts(0, 4)).To(Succeed())
+		Expect(u.Accepts(4, 4)).To(Equal(tus.ErrOffsetMismatch))
+		Expect(u.Accepts(0, 11)).To(Equal(tus.ErrChunkTooLarge))
+
+		u.Offset = 4
+		Expect(u.Accepts(4, 6)).To(Succeed())
+		Expect(u.IsComplete()).To(BeFalse())
+
+		u.Offset = 10
+		Expect(u.IsComplete()).To(BeTrue())
+	})
+
+	It("should read the file metadata", func() {
+		u := tus.New("instance-1", 10, map[string]string{
+			"filename":     "report.pdf",
+			"filetype":     "application/pdf",
+			"pages":        "12",
+			"width":        "wide",
+			"thumbnail_id": "thumb-1",
+		}, time.Hour)
+
+		metadata := u.FileMetadata()
+		Expect(*metadata.Name).To(Equal("report.pdf"))
+		Expect(*metadata.Mime).To(Equal("application/pdf"))
+		Expect(*metadata.Pages).To(Equal(uint32(12)))
+		Expect(metadata.Width).To(BeNil())
+		Expect(*u.ThumbnailID()).To(Equal("thumb-1"))
+	})
+
+	It("should expire", func() {
+		u := tus.New("instance-1", 10, nil, time.Hour)
+		Expect(u.IsExpired(time.Now())).To(BeFalse())
+		Expect(u.IsExpired(time.Now().Add(2 * time.Hour))).To(BeTrue())
+	})
+})
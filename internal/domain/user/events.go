@@ -10,10 +10,12 @@ const (
 	EventChangedPresence = "user:changed/presence" // Dispatched when the user's presence (online/offline) is changed
 
 	// To listen all new message events, use the prefix "user:new/*"
-	EventNewTextMessage     events.EventName = "user:new/text"     // Dispatched when a new text message is received from a user
-	EventNewImageMessage    events.EventName = "user:new/image"    // Dispatched when a new image message is received from a user
-	EventNewVideoMessage    events.EventName = "user:new/video"    // Dispatched when a new video message is received from a user
-	EventNewAudioMessage    events.EventName = "user:new/audio"    // Dispatched when a new audio message is received from a user
-	EventNewVoiceMessage    events.EventName = "user:new/voice"    // Dispatched when a new voice message is received from a user
-	EventNewDocumentMessage events.EventName = "user:new/document" // Dispatched when a new document message is received from a user
+	EventNewTextMessage     events.EventName = "user:new/text"         // Dispatched when a new text message is received from a user
+	EventNewImageMessage    events.EventName = "user:new/image"        // Dispatched when a new image message is received from a user
+	EventNewVideoMessage    events.EventName = "user:new/video"        // Dispatched when a new video message is received from a user
+	EventNewAudioMessage    events.EventName = "user:new/audio"        // Dispatched when a new audio message is received from a user
+	EventNewVoiceMessage    events.EventName = "user:new/voice"        // Dispatched when a new voice message is received from a user
+	EventNewDocumentMessage events.EventName = "user:new/document"     // Dispatched when a new document message is received from a user
+	EventNewButtonReply     events.EventName = "user:new/button_reply" // Dispatched when a user taps a button of a buttons or template message
+	EventNewListReply       events.EventName = "user:new/list_reply"   // Dispatched when a user picks a row of a list message
 )
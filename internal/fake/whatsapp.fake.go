@@ -0,0 +1,101 @@
+package fake
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+)
+
+// FakeWhatsAppGateway sends text messages, checks phones and downloads media without a connection, the other methods of the
+// gateway are not implemented and panic when called
+type FakeWhatsAppGateway struct {
+	whatsapp.WhatsAppGateway
+
+	mu      sync.Mutex
+	sent    []*message.Message
+	sendErr error
+	media   []byte
+}
+
+func NewFakeWhatsAppGateway() *FakeWhatsAppGateway {
+	return &FakeWhatsAppGateway{}
+}
+
+// FailSends makes the next sends fail with err, nil makes them succeed again
+func (g *FakeWhatsAppGateway) FailSends(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sendErr = err
+}
+
+func (g *FakeWhatsAppGateway) Sent() []*message.Message {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*message.Message(nil), g.sent...)
+}
+
+// ServeMedia makes every download decrypt to data
+func (g *FakeWhatsAppGateway) ServeMedia(data []byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.media = data
+}
+
+func (g *FakeWhatsAppGateway) Clear() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sent = nil
+	g.sendErr = nil
+	g.media = nil
+}
+
+func (g *FakeWhatsAppGateway) DownloadFile(ctx context.Context, inst *instance.Instance, f *file.File, kind whatsapp.MediaKind, dst whatsapp.DownloadTarget) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.media == nil {
+		return file.ErrMediaExpired
+	}
+
+	if err := dst.Truncate(0); err != nil {
+		return err
+	}
+	_, err := dst.WriteAt(g.media, 0)
+	return err
+}
+
+func (g *FakeWhatsAppGateway) Connect(ctx context.Context, inst *instance.Instance) error {
+	return nil
+}
+
+func (g *FakeWhatsAppGateway) SendTextMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.sendErr != nil {
+		return nil, g.sendErr
+	}
+
+	if msg.ExternalID == nil {
+		msg.ExternalID = utils.StringPtr(uuid.NewString())
+	}
+	msg.MarkAsSent(time.Now())
+	g.sent = append(g.sent, msg)
+	return msg, nil
+}
+
+// CheckPhones reports every phone as existing, with the jid of the phone
+func (g *FakeWhatsAppGateway) CheckPhones(ctx context.Context, inst *instance.Instance, phones []string) ([]whatsapp.PhoneStatus, error) {
+	statuses := make([]whatsapp.PhoneStatus, 0, len(phones))
+	for _, phone := range phones {
+		statuses = append(statuses, whatsapp.PhoneStatus{Original: phone, JID: phone + "@s.whatsapp.net", Phone: phone, Exists: true})
+	}
+	return statuses, nil
+}
@@ -4,6 +4,12 @@ import (
 	"time"
 
 	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/retention"
 )
 
 type AppConfig struct {
@@ -15,9 +21,52 @@ type AppConfig struct {
 
 	ADMIN_TOKEN string
 
-	CACHE_FILE_UPLOAD_TTL time.Duration
+	CACHE_FILE_UPLOAD_TTL  time.Duration
+	CACHE_LINK_PREVIEW_TTL time.Duration
 
 	MAX_WEBHOOKS int
+
+	HISTORY_SYNC_IDLE time.Duration
+
+	QUEUE_POLL_INTERVAL time.Duration
+	QUEUE_MAX_ATTEMPTS  int
+	QUEUE_CONCURRENCY   int
+
+	CAMPAIGN_POLL_INTERVAL time.Duration
+	CAMPAIGN_CONCURRENCY   int
+
+	IDEMPOTENCY_TTL time.Duration
+
+	RATE_LIMIT_PER_SECOND         int
+	RATE_LIMIT_PER_MINUTE         int
+	RATE_LIMIT_RECIPIENT_COOLDOWN time.Duration
+	RATE_LIMIT_MIN_DELAY          time.Duration
+	RATE_LIMIT_MAX_DELAY          time.Duration
+	RATE_LIMIT_TYPING             bool
+	RATE_LIMIT_ON_LIMIT           string
+
+	MEDIA_AUTO_SAVE               bool
+	MEDIA_AUTO_SAVE_TYPES         []string
+	MEDIA_AUTO_SAVE_MAX_SIZE      int
+	MEDIA_AUTO_SAVE_IGNORE_GROUPS bool
+	MEDIA_AUTO_SAVE_TIMEOUT       time.Duration
+
+	TUS_MAX_SIZE   int
+	TUS_EXPIRATION time.Duration
+
+	PRESIGNED_UPLOAD_EXPIRATION time.Duration
+
+	RETENTION_ENABLED      bool
+	RETENTION_MAX_AGE      time.Duration
+	RETENTION_MAX_BYTES    int
+	RETENTION_MAX_COUNT    int
+	RETENTION_KEEP_PINNED  bool
+	RETENTION_INTERVAL     time.Duration
+	RETENTION_ORPHAN_AGE   time.Duration
+	RETENTION_ORPHAN_BLOBS bool
+
+	QUOTA_MAX_BYTES int
+	QUOTA_MAX_FILES int
 }
 
 func (c *AppConfig) IsProduction() bool {
@@ -32,15 +81,108 @@ func (c *AppConfig) HasAdminToken() bool {
 	return c.ADMIN_TOKEN != ""
 }
 
+// RateLimitDefaults is the policy of the instances that never changed theirs
+func (c *AppConfig) RateLimitDefaults() ratelimit.Policy {
+	return ratelimit.Policy{
+		PerSecond:           c.RATE_LIMIT_PER_SECOND,
+		PerMinute:           c.RATE_LIMIT_PER_MINUTE,
+		RecipientCooldownMs: int(c.RATE_LIMIT_RECIPIENT_COOLDOWN / time.Millisecond),
+		MinDelayMs:          int(c.RATE_LIMIT_MIN_DELAY / time.Millisecond),
+		MaxDelayMs:          int(c.RATE_LIMIT_MAX_DELAY / time.Millisecond),
+		Typing:              c.RATE_LIMIT_TYPING,
+		OnLimit:             ratelimit.Action(c.RATE_LIMIT_ON_LIMIT),
+	}
+}
+
+// MediaPolicyDefaults is the media policy of the instances that never changed theirs
+func (c *AppConfig) MediaPolicyDefaults() media.Policy {
+	types := make([]file.MediaType, 0, len(c.MEDIA_AUTO_SAVE_TYPES))
+	for _, t := range c.MEDIA_AUTO_SAVE_TYPES {
+		if t != "" {
+			types = append(types, file.MediaType(t))
+		}
+	}
+
+	return media.Policy{
+		Enabled:      c.MEDIA_AUTO_SAVE,
+		Types:        types,
+		MaxSize:      uint64(max(c.MEDIA_AUTO_SAVE_MAX_SIZE, 0)),
+		IgnoreGroups: c.MEDIA_AUTO_SAVE_IGNORE_GROUPS,
+	}
+}
+
+// RetentionDefaults is the retention rule of the instances that never changed theirs
+func (c *AppConfig) RetentionDefaults() retention.Rule {
+	return retention.Rule{
+		Enabled:    c.RETENTION_ENABLED,
+		MaxAge:     uint64(max(c.RETENTION_MAX_AGE, 0) / time.Second),
+		MaxBytes:   uint64(max(c.RETENTION_MAX_BYTES, 0)),
+		MaxCount:   uint64(max(c.RETENTION_MAX_COUNT, 0)),
+		KeepPinned: c.RETENTION_KEEP_PINNED,
+	}
+}
+
+// QuotaDefaults is the storage quota of the instances without one of their own
+func (c *AppConfig) QuotaDefaults() quota.Quota {
+	return quota.Quota{
+		MaxBytes: uint64(max(c.QUOTA_MAX_BYTES, 0)),
+		MaxFiles: uint64(max(c.QUOTA_MAX_FILES, 0)),
+	}
+}
+
 func LoadAppConfig() *AppConfig {
 	return &AppConfig{
-		ENVIRONMENT:           GetEnvString("ENVIRONMENT", "development"),
-		APP_URL:               GetEnvString("APP_URL", "http://localhost"),
-		APP_PORT:              GetEnvString("APP_PORT", "8080"),
-		LOG_LEVEL:             GetEnvLogLevel("LOG_LEVEL", logger.LevelInfo),
-		TOKEN_HASHER:          GetEnvString("TOKEN_HASHER", "simple"), // bcrypt, simple
-		ADMIN_TOKEN:           GetEnvString("ADMIN_TOKEN", ""),
-		CACHE_FILE_UPLOAD_TTL: GetEnvDuration("CACHE_FILE_UPLOAD_TTL", 5*time.Minute),
-		MAX_WEBHOOKS:          GetEnvInt("MAX_WEBHOOKS", 1),
+		ENVIRONMENT:            GetEnvString("ENVIRONMENT", "development"),
+		APP_URL:                GetEnvString("APP_URL", "http://localhost"),
+		APP_PORT:               GetEnvString("APP_PORT", "8080"),
+		LOG_LEVEL:              GetEnvLogLevel("LOG_LEVEL", logger.LevelInfo),
+		TOKEN_HASHER:           GetEnvString("TOKEN_HASHER", "simple"), // bcrypt, simple
+		ADMIN_TOKEN:            GetEnvString("ADMIN_TOKEN", ""),
+		CACHE_FILE_UPLOAD_TTL:  GetEnvDuration("CACHE_FILE_UPLOAD_TTL", 5*time.Minute),
+		CACHE_LINK_PREVIEW_TTL: GetEnvDuration("CACHE_LINK_PREVIEW_TTL", time.Hour),
+		MAX_WEBHOOKS:           GetEnvInt("MAX_WEBHOOKS", 1),
+		HISTORY_SYNC_IDLE:      GetEnvInterval("HISTORY_SYNC_IDLE", time.Minute), // a history sync without progress is complete after it without chunks
+		QUEUE_POLL_INTERVAL:    GetEnvInterval("QUEUE_POLL_INTERVAL", time.Second),
+		QUEUE_MAX_ATTEMPTS:     GetEnvInt("QUEUE_MAX_ATTEMPTS", queue.DefaultMaxAttempts),
+		QUEUE_CONCURRENCY:      GetEnvInt("QUEUE_CONCURRENCY", 10), // chats sent side by side
+		CAMPAIGN_POLL_INTERVAL: GetEnvInterval("CAMPAIGN_POLL_INTERVAL", time.Second),
+		CAMPAIGN_CONCURRENCY:   GetEnvInt("CAMPAIGN_CONCURRENCY", 10),           // campaigns sent side by side
+		IDEMPOTENCY_TTL:        GetEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour), // how long a retry gets the first response
+
+		// zero disables a limit, every instance can change its own through /rate-limit
+		RATE_LIMIT_PER_SECOND:         GetEnvInt("RATE_LIMIT_PER_SECOND", 0),
+		RATE_LIMIT_PER_MINUTE:         GetEnvInt("RATE_LIMIT_PER_MINUTE", 0),
+		RATE_LIMIT_RECIPIENT_COOLDOWN: GetEnvDuration("RATE_LIMIT_RECIPIENT_COOLDOWN", 0),
+		RATE_LIMIT_MIN_DELAY:          GetEnvDuration("RATE_LIMIT_MIN_DELAY", 0),
+		RATE_LIMIT_MAX_DELAY:          GetEnvDuration("RATE_LIMIT_MAX_DELAY", 0),
+		RATE_LIMIT_TYPING:             GetEnvBool("RATE_LIMIT_TYPING", false),
+		RATE_LIMIT_ON_LIMIT:           GetEnvString("RATE_LIMIT_ON_LIMIT", string(ratelimit.ActionReject)), // reject, queue
+
+		// received media saved to the storage, every instance can change its own through /media-policy
+		MEDIA_AUTO_SAVE:               GetEnvBool("MEDIA_AUTO_SAVE", false),
+		MEDIA_AUTO_SAVE_TYPES:         GetEnvStringSlice("MEDIA_AUTO_SAVE_TYPES", []string{}), // image, video, audio, sticker, document, empty saves all
+		MEDIA_AUTO_SAVE_MAX_SIZE:      GetEnvInt("MEDIA_AUTO_SAVE_MAX_SIZE", 16*1024*1024),    // in bytes, zero saves any size
+		MEDIA_AUTO_SAVE_IGNORE_GROUPS: GetEnvBool("MEDIA_AUTO_SAVE_IGNORE_GROUPS", false),
+		MEDIA_AUTO_SAVE_TIMEOUT:       GetEnvDuration("MEDIA_AUTO_SAVE_TIMEOUT", time.Minute), // download and save of one media
+
+		// resumable uploads on /uploads/tus
+		TUS_MAX_SIZE:   GetEnvInt("TUS_MAX_SIZE", 2*1024*1024*1024),    // in bytes, zero takes any size
+		TUS_EXPIRATION: GetEnvDuration("TUS_EXPIRATION", 24*time.Hour), // an unfinished upload is discarded after it
+
+		PRESIGNED_UPLOAD_EXPIRATION: GetEnvDuration("PRESIGNED_UPLOAD_EXPIRATION", 15*time.Minute), // how long a presigned upload URL stays valid, zero disables presigned uploads
+
+		// uploads deleted by age, size or count, every instance can change its own through /retention
+		RETENTION_ENABLED:      GetEnvBool("RETENTION_ENABLED", false),
+		RETENTION_MAX_AGE:      GetEnvDuration("RETENTION_MAX_AGE", 0), // zero keeps files of any age
+		RETENTION_MAX_BYTES:    GetEnvInt("RETENTION_MAX_BYTES", 0),    // in bytes, zero keeps any size
+		RETENTION_MAX_COUNT:    GetEnvInt("RETENTION_MAX_COUNT", 0),    // zero keeps any count
+		RETENTION_KEEP_PINNED:  GetEnvBool("RETENTION_KEEP_PINNED", true),
+		RETENTION_INTERVAL:     GetEnvInterval("RETENTION_INTERVAL", time.Hour),
+		RETENTION_ORPHAN_AGE:   GetEnvDuration("RETENTION_ORPHAN_AGE", 24*time.Hour), // orphans younger than it may still be in use
+		RETENTION_ORPHAN_BLOBS: GetEnvBool("RETENTION_ORPHAN_BLOBS", false),          // stored objects without a file are only deleted when set
+
+		// storage each instance may take, the admin can change the quota of an instance through /quotas
+		QUOTA_MAX_BYTES: GetEnvInt("QUOTA_MAX_BYTES", 0), // in bytes, zero for no limit
+		QUOTA_MAX_FILES: GetEnvInt("QUOTA_MAX_FILES", 0), // zero for no limit
 	}
 }
@@ -82,6 +82,16 @@ func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// GetEnvInterval is a duration that drives a ticker, which panics on anything but a positive duration
+func GetEnvInterval(key string, defaultValue time.Duration) time.Duration {
+	duration := GetEnvDuration(key, defaultValue)
+	if duration <= 0 {
+		panic(fmt.Sprintf("Invalid interval value for %s: %s, it must be greater than zero", key, os.Getenv(key)))
+	}
+
+	return duration
+}
+
 func GetEnvLogLevel(key string, defaultValue logger.Level) logger.Level {
 	if value := os.Getenv(key); value != "" {
 		level, err := logger.ParseLogLevel(value)
@@ -11,15 +11,34 @@ func LoadLoggers(level l.Level) {
 	app.RegisterLogger(app.LogKeyInstanceService, logger.NewCuteLogger("INSTANCE SERVICE", level))
 	app.RegisterLogger(app.LogKeyFileService, logger.NewCuteLogger("FILE SERVICE", level))
 	app.RegisterLogger(app.LogKeyMessageService, logger.NewCuteLogger("MESSAGE SERVICE", level))
+	app.RegisterLogger(app.LogKeyPreviewService, logger.NewCuteLogger("PREVIEW SERVICE", level))
 	app.RegisterLogger(app.LogKeyChatService, logger.NewCuteLogger("CHAT SERVICE", level))
+	app.RegisterLogger(app.LogKeyHistoryService, logger.NewCuteLogger("HISTORY SERVICE", level))
+	app.RegisterLogger(app.LogKeyQueueService, logger.NewCuteLogger("QUEUE SERVICE", level))
+	app.RegisterLogger(app.LogKeyScheduleService, logger.NewCuteLogger("SCHEDULE SERVICE", level))
+	app.RegisterLogger(app.LogKeyRateLimitService, logger.NewCuteLogger("RATE LIMIT SERVICE", level))
+	app.RegisterLogger(app.LogKeyCampaignService, logger.NewCuteLogger("CAMPAIGN SERVICE", level))
+	app.RegisterLogger(app.LogKeyTemplateService, logger.NewCuteLogger("TEMPLATE SERVICE", level))
+	app.RegisterLogger(app.LogKeyIdempotencyService, logger.NewCuteLogger("IDEMPOTENCY SERVICE", level))
 	app.RegisterLogger(app.LogKeyContactService, logger.NewCuteLogger("CONTACT SERVICE", level))
 	app.RegisterLogger(app.LogKeyGroupService, logger.NewCuteLogger("GROUP SERVICE", level))
 	app.RegisterLogger(app.LogKeyPictureService, logger.NewCuteLogger("PICTURE SERVICE", level))
 	app.RegisterLogger(app.LogKeyUploadService, logger.NewCuteLogger("UPLOAD SERVICE", level))
+	app.RegisterLogger(app.LogKeyDownloadService, logger.NewCuteLogger("DOWNLOAD SERVICE", level))
+	app.RegisterLogger(app.LogKeyMediaService, logger.NewCuteLogger("MEDIA SERVICE", level))
+	app.RegisterLogger(app.LogKeyTranscodeService, logger.NewCuteLogger("TRANSCODE SERVICE", level))
+	app.RegisterLogger(app.LogKeyTranscoder, logger.NewCuteLogger("TRANSCODER", level))
+	app.RegisterLogger(app.LogKeyThumbnailService, logger.NewCuteLogger("THUMBNAIL SERVICE", level))
+	app.RegisterLogger(app.LogKeyTusService, logger.NewCuteLogger("TUS SERVICE", level))
+	app.RegisterLogger(app.LogKeyPresignService, logger.NewCuteLogger("PRESIGN SERVICE", level))
+	app.RegisterLogger(app.LogKeyRetentionService, logger.NewCuteLogger("RETENTION SERVICE", level))
+	app.RegisterLogger(app.LogKeyQuotaService, logger.NewCuteLogger("QUOTA SERVICE", level))
 	app.RegisterLogger(app.LogKeyBlocklistService, logger.NewCuteLogger("BLOCKLIST SERVICE", level))
 	app.RegisterLogger(app.LogKeyTokenService, logger.NewCuteLogger("TOKEN SERVICE", level))
 	app.RegisterLogger(app.LogKeyWebhookService, logger.NewCuteLogger("WEBHOOK SERVICE", level))
 	app.RegisterLogger(app.LogKeyWebhook, logger.NewCuteLogger("WEBHOOK", level))
+	app.RegisterLogger(app.LogKeyMessageConsumer, logger.NewCuteLogger("MESSAGE CONSUMER", level))
+	app.RegisterLogger(app.LogKeyChatConsumer, logger.NewCuteLogger("CHAT CONSUMER", level))
 	app.RegisterLogger(app.LogKeyWhatsapp, logger.NewCuteLogger("WHATSAPP", level))
 	app.RegisterLogger(app.LogKeyDatabase, logger.NewCuteLogger("DATABASE", level))
 	app.RegisterLogger(app.LogKeyCache, logger.NewCuteLogger("CACHE", level))
@@ -1,16 +1,21 @@
 package config
 
+import "time"
+
 type StorageDriver string
 
 const (
-	StorageDriverLocal StorageDriver = "local"
-	StorageDriverS3    StorageDriver = "s3"
-	StorageDriverNone  StorageDriver = "none"
+	StorageDriverLocal  StorageDriver = "local"
+	StorageDriverS3     StorageDriver = "s3"
+	StorageDriverGCS    StorageDriver = "gcs"
+	StorageDriverAzure  StorageDriver = "azure"
+	StorageDriverWebDAV StorageDriver = "webdav"
+	StorageDriverNone   StorageDriver = "none"
 )
 
 func (d StorageDriver) IsValid() bool {
 	switch d {
-	case StorageDriverLocal, StorageDriverS3, StorageDriverNone:
+	case StorageDriverLocal, StorageDriverS3, StorageDriverGCS, StorageDriverAzure, StorageDriverWebDAV, StorageDriverNone:
 		return true
 	default:
 		return false
@@ -21,15 +26,25 @@ type StorageConfig struct {
 	Driver StorageDriver
 	// Just for local storage
 	Path string
+	// S3, GCS, Azure and WebDAV storages, the credentials are the account name and key on Azure and the user and
+	// password on WebDAV, the bucket is the container on Azure
+	Key      string
+	Secret   string
+	Bucket   string
+	Endpoint string
 	// Just for S3 storage
-	Key       string
-	Secret    string
 	Region    string
-	Bucket    string
-	Endpoint  string
 	PathStyle bool
+	// Just for GCS storage, the service account key, the default credentials of the environment when empty
+	CredentialsFile string
+	// Just for WebDAV storage, how long a request to the server may take, the transfer of the file included
+	Timeout time.Duration
 	// Shared
 	URL string
+	// URLExpiration is how long download URLs stay valid, zero keeps permanent public links
+	URLExpiration time.Duration
+	// SigningKey signs the URLs of the local storage, it has no fallback so a leaked admin token cannot forge them
+	SigningKey string
 }
 
 func LoadStorageConfig() *StorageConfig {
@@ -39,22 +54,42 @@ func LoadStorageConfig() *StorageConfig {
 		return nil
 	}
 
-	url := GetEnvString("S3_URL", "")
+	conf := &StorageConfig{
+		Driver: StorageDriver(driver), // local, s3, gcs, azure, webdav
+		Path:   GetEnvString("STORAGE_PATH", "/storage"),
 
-	if driver == "local" {
-		url = GetEnvURL("APP_URL", "")
+		URLExpiration: GetEnvDuration("STORAGE_URL_EXPIRATION", time.Hour),
+		SigningKey:    GetEnvString("STORAGE_SIGNING_KEY", ""),
 	}
 
-	conf := &StorageConfig{
-		Driver:    StorageDriver(GetEnvString("STORAGE_DRIVER", "local")), // local, s3
-		Path:      GetEnvString("STORAGE_PATH", "/storage"),
-		Key:       GetEnvString("S3_KEY", ""),
-		Secret:    GetEnvString("S3_SECRET", ""),
-		Region:    GetEnvString("S3_REGION", ""),
-		Bucket:    GetEnvString("S3_BUCKET", ""),
-		Endpoint:  GetEnvURL("S3_ENDPOINT", ""),
-		PathStyle: GetEnvBool("S3_PATH_STYLE", false),
-		URL:       url,
+	switch conf.Driver {
+	case StorageDriverLocal:
+		conf.URL = GetEnvURL("APP_URL", "")
+	case StorageDriverS3:
+		conf.Key = GetEnvString("S3_KEY", "")
+		conf.Secret = GetEnvString("S3_SECRET", "")
+		conf.Region = GetEnvString("S3_REGION", "")
+		conf.Bucket = GetEnvString("S3_BUCKET", "")
+		conf.Endpoint = GetEnvURL("S3_ENDPOINT", "")
+		conf.PathStyle = GetEnvBool("S3_PATH_STYLE", false)
+		conf.URL = GetEnvString("S3_URL", "")
+	case StorageDriverGCS:
+		conf.Bucket = GetEnvString("GCS_BUCKET", "")
+		conf.CredentialsFile = GetEnvString("GCS_CREDENTIALS_FILE", "")
+		conf.Endpoint = GetEnvURL("GCS_ENDPOINT", "") // emulators only
+		conf.URL = GetEnvURL("GCS_URL", "https://storage.googleapis.com")
+	case StorageDriverAzure:
+		conf.Key = GetEnvString("AZURE_ACCOUNT", "")
+		conf.Secret = GetEnvString("AZURE_KEY", "")
+		conf.Bucket = GetEnvString("AZURE_CONTAINER", "")
+		conf.Endpoint = GetEnvURL("AZURE_ENDPOINT", "https://"+conf.Key+".blob.core.windows.net")
+		conf.URL = GetEnvURL("AZURE_URL", conf.Endpoint)
+	case StorageDriverWebDAV:
+		conf.Key = GetEnvString("WEBDAV_USER", "")
+		conf.Secret = GetEnvString("WEBDAV_PASS", "")
+		conf.Endpoint = GetEnvURL("WEBDAV_ENDPOINT", "")
+		conf.Timeout = GetEnvDuration("WEBDAV_TIMEOUT", 5*time.Minute)
+		conf.URL = GetEnvURL("APP_URL", "") // the server wants credentials, the API serves its files
 	}
 
 	if !conf.IsConfigured() {
@@ -73,13 +108,32 @@ func (c *StorageConfig) IsConfigured() bool {
 		panic("Invalid Storage Driver: " + string(c.Driver))
 	}
 
-	if c.Driver == StorageDriverS3 {
+	switch c.Driver {
+	case StorageDriverS3:
 		return c.Key != "" && c.Secret != "" && c.Region != "" && c.Bucket != ""
+	case StorageDriverGCS:
+		return c.Bucket != ""
+	case StorageDriverAzure:
+		return c.Key != "" && c.Secret != "" && c.Bucket != ""
+	case StorageDriverWebDAV:
+		return c.Endpoint != ""
 	}
 
 	return c.Path != "" || c.URL != ""
 }
 
+// NeedsSigningKey tells whether the storage signs its own URLs, the storages served by the API do it for expiring
+// downloads and for presigned uploads, which are enabled by a presign expiration
+func (c *StorageConfig) NeedsSigningKey(presignExpiration time.Duration) bool {
+	return c.IsServedByAPI() && (c.URLExpiration > 0 || presignExpiration > 0)
+}
+
+// IsServedByAPI tells whether the API serves the files on /storage, the local storage has no server of its own and
+// the WebDAV one wants credentials
+func (c *StorageConfig) IsServedByAPI() bool {
+	return c != nil && (c.Driver == StorageDriverLocal || c.Driver == StorageDriverWebDAV)
+}
+
 func (c *StorageConfig) IsS3() bool {
 	return c != nil && c.Driver == StorageDriverS3
 }
@@ -0,0 +1,38 @@
+package config
+
+import "time"
+
+type TranscoderConfig struct {
+	FFmpegPath   string
+	FFprobePath  string
+	PdftoppmPath string        // optional, without it pdfs get no thumbnail
+	Threads      int           // zero lets ffmpeg pick
+	Timeout      time.Duration // of each ffmpeg run
+	MaxInputSize int           // in bytes
+	MaxDuration  time.Duration
+	CacheTTL     time.Duration // transcoded media is cached by the hash of the source
+}
+
+func LoadTranscoderConfig() *TranscoderConfig {
+	if !GetEnvBool("TRANSCODER_ENABLED", true) {
+		return nil
+	}
+
+	return &TranscoderConfig{
+		FFmpegPath:   GetEnvString("FFMPEG_PATH", "ffmpeg"),
+		FFprobePath:  GetEnvString("FFPROBE_PATH", "ffprobe"),
+		PdftoppmPath: GetEnvString("PDFTOPPM_PATH", "pdftoppm"),
+		Threads:      GetEnvInt("TRANSCODER_THREADS", 0),
+		Timeout:      GetEnvDuration("TRANSCODER_TIMEOUT", 2*time.Minute),
+		MaxInputSize: GetEnvInt("TRANSCODER_MAX_INPUT_SIZE", 64*1024*1024),
+		MaxDuration:  GetEnvDuration("TRANSCODER_MAX_DURATION", 15*time.Minute),
+		CacheTTL:     GetEnvDuration("TRANSCODER_CACHE_TTL", 24*time.Hour),
+	}
+}
+
+func (c *TranscoderConfig) GetCacheTTL() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return c.CacheTTL
+}
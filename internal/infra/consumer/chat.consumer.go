@@ -0,0 +1,60 @@
+package consumer
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+)
+
+type ChatConsumer struct {
+	chatRepo chat.ChatRepository
+}
+
+func NewChatConsumer(chatRepo chat.ChatRepository) *ChatConsumer {
+	return &ChatConsumer{
+		chatRepo: chatRepo,
+	}
+}
+
+func (c *ChatConsumer) Handle(event events.Event) {
+	if event.InstanceID == nil {
+		return
+	}
+
+	switch event.Name {
+	case chat.ChatChangedDisappearing:
+		c.disappearing(event)
+	}
+}
+
+// disappearing keeps the timer of the chat up to date, it is used as the default expiration of the messages we send
+func (c *ChatConsumer) disappearing(event events.Event) {
+	l := app.GetChatConsumerLogger()
+
+	payload, err := decodePayload[chat.PayloadChatChangedDisappearing](event.Payload)
+	if err != nil {
+		l.Error("failed to decode disappearing payload", "error", err)
+		return
+	}
+
+	stored, err := c.chatRepo.Get(chat.WhereInstanceID(*event.InstanceID), chat.WhereJID(payload.Chat))
+	if err != nil {
+		l.Error("failed to find stored chat", "chat", payload.Chat, "error", err)
+		return
+	}
+
+	if stored == nil {
+		stored = chat.New(payload.Chat, *event.InstanceID)
+		stored.SetExpiration(payload.Expiration)
+		err = c.chatRepo.Save(stored)
+	} else if stored.SetExpiration(payload.Expiration) {
+		err = c.chatRepo.Update(stored)
+	}
+
+	if err != nil {
+		l.Error("failed to store chat disappearing timer", "chat", payload.Chat, "error", err)
+		return
+	}
+
+	l.Debug("chat disappearing timer stored", "chat", payload.Chat, "expiration", payload.Expiration)
+}
@@ -0,0 +1,78 @@
+package consumer_test
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/consumer"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chat consumer", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	chatRepo := repository.NewChatRepository(db)
+
+	chatConsumer := consumer.NewChatConsumer(chatRepo)
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	instanceID := "instance-1"
+	jid := "5514987654321@s.whatsapp.net"
+
+	disappearingEvent := func(expiration uint32) events.Event {
+		return events.New(chat.ChatChangedDisappearing, chat.PayloadChatChangedDisappearing{
+			Chat:       jid,
+			Sender:     jid,
+			Expiration: expiration,
+			Timestamp:  time.Now(),
+		}, &instanceID)
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID(instanceID).Create())).To(Succeed())
+	})
+
+	It("should store the timer of a new chat", func() {
+		chatConsumer.Handle(disappearingEvent(chat.DisappearingTimer7Days.ToExpiration()))
+
+		got, err := chatRepo.Get(chat.WhereInstanceID(instanceID), chat.WhereJID(jid))
+		Expect(err).To(BeNil())
+		Expect(got).ToNot(BeNil())
+		Expect(*got.Expiration).To(Equal(uint32(7 * 24 * 60 * 60)))
+	})
+
+	It("should update and disable the timer of a stored chat", func() {
+		stored := chat.New(jid, instanceID)
+		name := "Ana"
+		stored.Name = &name
+		Expect(chatRepo.Save(stored)).To(Succeed())
+
+		chatConsumer.Handle(disappearingEvent(chat.DisappearingTimer24Hours.ToExpiration()))
+
+		got, err := chatRepo.Get(chat.WhereID(stored.ID))
+		Expect(err).To(BeNil())
+		Expect(*got.Expiration).To(Equal(uint32(24 * 60 * 60)))
+		Expect(*got.Name).To(Equal("Ana"))
+
+		chatConsumer.Handle(disappearingEvent(0))
+
+		got, err = chatRepo.Get(chat.WhereID(stored.ID))
+		Expect(err).To(BeNil())
+		Expect(got.HasExpiration()).To(BeFalse())
+	})
+})
@@ -0,0 +1,181 @@
+package consumer
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+// storedEventPrefixes are the chats whose new messages are kept in the history, statuses are left out
+var storedEventPrefixes = []string{"user:new/", "group:new/", "newsletter:new/", "community:new/"}
+
+type MessageConsumer struct {
+	msgRepo     message.MessageRepository
+	receiptRepo message.ReceiptRepository
+}
+
+func NewMessageConsumer(msgRepo message.MessageRepository, receiptRepo message.ReceiptRepository) *MessageConsumer {
+	return &MessageConsumer{
+		msgRepo:     msgRepo,
+		receiptRepo: receiptRepo,
+	}
+}
+
+func (m *MessageConsumer) Handle(event events.Event) {
+	if event.InstanceID == nil {
+		return
+	}
+
+	switch {
+	case isStoredMessageEvent(event.Name):
+		m.store(event)
+	case event.Name == message.EventMessageEdited:
+		m.edit(event)
+	case event.Name == message.EventMessageDeleted:
+		m.delete(event)
+	case event.Name == message.EventMessageDelivered:
+		m.receipt(event, message.MessageStatusDelivered)
+	case event.Name == message.EventMessageRead:
+		m.receipt(event, message.MessageStatusRead)
+	case event.Name == message.EventMessagePlayed:
+		m.receipt(event, message.MessageStatusPlayed)
+	}
+}
+
+func (m *MessageConsumer) store(event events.Event) {
+	l := app.GetMessageConsumerLogger()
+
+	payload, err := decodePayload[message.PayloadNewMessage](event.Payload)
+	if err != nil {
+		l.Error("failed to decode new message payload", "event", event.Name, "error", err)
+		return
+	}
+
+	msg := payload.Message
+	if msg.ExternalID == nil || msg.Content == nil {
+		return
+	}
+
+	msg.InstanceID = event.InstanceID
+	if err := m.msgRepo.Insert(&msg); err != nil {
+		l.Error("failed to store message", "event", event.Name, "id", *msg.ExternalID, "error", err)
+		return
+	}
+
+	l.Debug("message stored", "id", msg.ID, "external_id", *msg.ExternalID, "chat", msg.Chat)
+}
+
+func (m *MessageConsumer) edit(event events.Event) {
+	l := app.GetMessageConsumerLogger()
+
+	payload, err := decodePayload[message.PayloadMessageEdited](event.Payload)
+	if err != nil {
+		l.Error("failed to decode edited message payload", "error", err)
+		return
+	}
+
+	msg := m.find(*event.InstanceID, payload.Chat, payload.Message)
+	if msg == nil || !msg.SetText(payload.Text) {
+		return
+	}
+
+	if err := m.msgRepo.Update(msg); err != nil {
+		l.Error("failed to update edited message", "id", msg.ID, "error", err)
+	}
+}
+
+func (m *MessageConsumer) delete(event events.Event) {
+	l := app.GetMessageConsumerLogger()
+
+	payload, err := decodePayload[message.PayloadMessageDeleted](event.Payload)
+	if err != nil {
+		l.Error("failed to decode deleted message payload", "error", err)
+		return
+	}
+
+	msg := m.find(*event.InstanceID, payload.Chat, payload.Message)
+	if msg == nil {
+		return
+	}
+
+	msg.MarkAsDeleted()
+	if err := m.msgRepo.Update(msg); err != nil {
+		l.Error("failed to update deleted message", "id", msg.ID, "error", err)
+	}
+}
+
+// receipt records the acknowledgement of each message by the sender of the receipt and moves the stored
+// message forward, only messages we sent are tracked
+func (m *MessageConsumer) receipt(event events.Event, status message.MessageStatus) {
+	l := app.GetMessageConsumerLogger()
+
+	// delivered, read and played receipts share the same payload shape, so all of them decode into it
+	payload, err := decodePayload[message.PayloadMessageDelivered](event.Payload)
+	if err != nil {
+		l.Error("failed to decode receipt payload", "event", event.Name, "error", err)
+		return
+	}
+
+	for _, id := range payload.Messages {
+		msg := m.find(*event.InstanceID, payload.Chat, id)
+		if msg == nil || !msg.IsFromMe {
+			continue
+		}
+
+		if err := m.receiptRepo.Insert(message.NewReceipt(msg.ID, payload.Sender, status, payload.Timestamp)); err != nil {
+			l.Error("failed to store receipt", "id", msg.ID, "status", status, "error", err)
+			continue
+		}
+
+		if !msg.ApplyReceipt(status, payload.Timestamp) {
+			continue
+		}
+
+		if err := m.msgRepo.Update(msg); err != nil {
+			l.Error("failed to update message status", "id", msg.ID, "status", status, "error", err)
+		}
+	}
+}
+
+func (m *MessageConsumer) find(instanceID string, chat string, externalID string) *message.Message {
+	msg, err := m.msgRepo.Get(
+		message.WhereInstanceID(instanceID),
+		message.WhereChat(chat),
+		message.WhereExternalID(externalID),
+	)
+	if err != nil {
+		app.GetMessageConsumerLogger().Error("failed to find stored message", "id", externalID, "error", err)
+		return nil
+	}
+
+	return msg
+}
+
+func isStoredMessageEvent(name events.EventName) bool {
+	for _, prefix := range storedEventPrefixes {
+		if strings.HasPrefix(string(name), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodePayload returns the typed payload, events coming from redis carry a generic map so they are
+// encoded back to json and decoded into the expected type
+func decodePayload[T any](payload any) (T, error) {
+	if typed, ok := payload.(T); ok {
+		return typed, nil
+	}
+
+	var decoded T
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return decoded, err
+	}
+
+	err = json.Unmarshal(data, &decoded)
+	return decoded, err
+}
@@ -0,0 +1,153 @@
+package consumer_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/user"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/consumer"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Message consumer", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	msgRepo := repository.NewMessageRepository(db)
+	receiptRepo := repository.NewReceiptRepository(db)
+
+	messageConsumer := consumer.NewMessageConsumer(msgRepo, receiptRepo)
+
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	instanceID := "instance-1"
+	chat := "5514987654321@s.whatsapp.net"
+
+	newMessageEvent := func(externalID string) events.Event {
+		m := message.NewMessage(&externalID, chat, chat, message.NewTextContent("hello", nil), &instanceID, nil, false)
+		return events.New(user.EventNewTextMessage, message.PayloadNewMessage{Chat: chat, Message: *m}, &instanceID)
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID(instanceID).Create())).To(Succeed())
+	})
+
+	It("should store new messages", func() {
+		messageConsumer.Handle(newMessageEvent("3EB0000000000001"))
+
+		got, err := msgRepo.Get(message.WhereExternalID("3EB0000000000001"))
+		Expect(err).To(BeNil())
+		Expect(got).ToNot(BeNil())
+		Expect(got.Chat).To(Equal(chat))
+		Expect(got.Content.(message.TextContent).Text).To(Equal("hello"))
+	})
+
+	It("should store new messages with a generic payload", func() {
+		// events that went through redis arrive with the payload decoded as a map
+		evt := newMessageEvent("3EB0000000000002")
+		data, err := json.Marshal(evt)
+		Expect(err).To(BeNil())
+
+		var decoded events.Event
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+
+		messageConsumer.Handle(decoded)
+
+		count, err := msgRepo.Count(message.WhereExternalID("3EB0000000000002"))
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(uint64(1)))
+	})
+
+	It("should apply edits and revokes to stored messages", func() {
+		messageConsumer.Handle(newMessageEvent("3EB0000000000003"))
+
+		messageConsumer.Handle(events.New(message.EventMessageEdited, message.PayloadMessageEdited{
+			Message:   "3EB0000000000003",
+			Chat:      chat,
+			Sender:    chat,
+			Text:      "edited",
+			Timestamp: time.Now(),
+		}, &instanceID))
+
+		got, err := msgRepo.Get(message.WhereExternalID("3EB0000000000003"))
+		Expect(err).To(BeNil())
+		Expect(got.Content.(message.TextContent).Text).To(Equal("edited"))
+
+		messageConsumer.Handle(events.New(message.EventMessageDeleted, message.PayloadMessageDeleted{
+			Message:   "3EB0000000000003",
+			Chat:      chat,
+			Sender:    chat,
+			Timestamp: time.Now(),
+		}, &instanceID))
+
+		got, err = msgRepo.Get(message.WhereExternalID("3EB0000000000003"))
+		Expect(err).To(BeNil())
+		Expect(got.Status).To(Equal(message.MessageStatusDeleted))
+	})
+
+	It("should track receipts of sent messages per participant", func() {
+		group := "120363000000000000@g.us"
+		externalID := "3EB0000000000004"
+		sent := message.NewMessage(&externalID, "me@s.whatsapp.net", group, message.NewTextContent("hello group", nil), &instanceID, nil, true)
+		sent.MarkAsSent(time.Now())
+		Expect(msgRepo.Insert(sent)).To(Succeed())
+
+		deliveredAt := time.Now().Add(time.Second)
+		messageConsumer.Handle(events.New(message.EventMessageDelivered, message.PayloadMessageDelivered{
+			Messages:  []string{externalID},
+			Chat:      group,
+			Sender:    "5511111111111@s.whatsapp.net",
+			Timestamp: deliveredAt,
+		}, &instanceID))
+
+		messageConsumer.Handle(events.New(message.EventMessageRead, message.PayloadMessageRead{
+			Messages:  []string{externalID},
+			Chat:      group,
+			Sender:    "5522222222222@s.whatsapp.net",
+			Timestamp: deliveredAt.Add(time.Second),
+		}, &instanceID))
+
+		got, err := msgRepo.Get(message.WhereID(sent.ID))
+		Expect(err).To(BeNil())
+		Expect(got.Status).To(Equal(message.MessageStatusRead))
+		Expect(*got.DeliveredAt).To(BeTemporally("~", deliveredAt, time.Second))
+		Expect(got.ReadAt).ToNot(BeNil())
+
+		receipts, err := receiptRepo.ListByMessage(sent.ID)
+		Expect(err).To(BeNil())
+		Expect(receipts).To(HaveLen(2))
+		Expect(receipts[0].Participant).To(Equal("5511111111111@s.whatsapp.net"))
+		Expect(receipts[0].Status).To(Equal(message.MessageStatusDelivered))
+		Expect(receipts[1].Participant).To(Equal("5522222222222@s.whatsapp.net"))
+		Expect(receipts[1].Status).To(Equal(message.MessageStatusRead))
+	})
+
+	It("should ignore receipts of received messages", func() {
+		messageConsumer.Handle(newMessageEvent("3EB0000000000005"))
+
+		messageConsumer.Handle(events.New(message.EventMessageRead, message.PayloadMessageRead{
+			Messages:  []string{"3EB0000000000005"},
+			Chat:      chat,
+			Sender:    chat,
+			Timestamp: time.Now(),
+		}, &instanceID))
+
+		got, err := msgRepo.Get(message.WhereExternalID("3EB0000000000005"))
+		Expect(err).To(BeNil())
+		Expect(got.ReadAt).To(BeNil())
+	})
+})
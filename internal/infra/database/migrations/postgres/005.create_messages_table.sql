@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS messages (
+    id VARCHAR(36) PRIMARY KEY,
+    external_id VARCHAR(128) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    chat VARCHAR(128) NOT NULL,
+    sender VARCHAR(128) NOT NULL,
+    content JSONB,
+    reply_to JSONB,
+    status VARCHAR(16) NOT NULL,
+    expiration INTEGER,
+    is_from_me BOOLEAN NOT NULL DEFAULT FALSE,
+    is_forwarded BOOLEAN NOT NULL DEFAULT FALSE,
+    forwarding_score INTEGER NOT NULL DEFAULT 0,
+    timestamp BIGINT NOT NULL,
+
+    sent_at TIMESTAMPTZ,
+    delivered_at TIMESTAMPTZ,
+    read_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    instance_id VARCHAR(36) NOT NULL REFERENCES instances(id) ON DELETE CASCADE,
+
+    UNIQUE (instance_id, chat, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS messages_history_index ON messages (instance_id, chat, timestamp, id);
+
+-- DOWN
+DROP INDEX IF EXISTS messages_history_index;
+DROP TABLE IF EXISTS messages;
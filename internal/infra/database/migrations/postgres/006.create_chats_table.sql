@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS chats (
+    id VARCHAR(36) PRIMARY KEY,
+    jid VARCHAR(128) NOT NULL,
+    name VARCHAR(255),
+    unread_count INTEGER NOT NULL DEFAULT 0,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    muted_until TIMESTAMPTZ,
+    expiration INTEGER,
+    last_message_at TIMESTAMPTZ,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    instance_id VARCHAR(36) NOT NULL REFERENCES instances(id) ON DELETE CASCADE,
+
+    UNIQUE (instance_id, jid)
+);
+
+-- DOWN
+DROP TABLE IF EXISTS chats;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS message_receipts (
+    id VARCHAR(36) PRIMARY KEY,
+    participant VARCHAR(128) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL,
+
+    message_id VARCHAR(36) NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+
+    UNIQUE (message_id, participant, status)
+);
+
+-- DOWN
+DROP TABLE IF EXISTS message_receipts;
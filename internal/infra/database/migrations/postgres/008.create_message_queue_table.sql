@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS message_queue (
+    id VARCHAR(36) PRIMARY KEY,
+    chat VARCHAR(128) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    message_id VARCHAR(36),
+    external_id VARCHAR(128),
+    sent_at TIMESTAMPTZ,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    instance_id VARCHAR(36) NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS message_queue_pending_index ON message_queue (status, instance_id, chat, created_at);
+
+-- DOWN
+DROP INDEX IF EXISTS message_queue_pending_index;
+DROP TABLE IF EXISTS message_queue;
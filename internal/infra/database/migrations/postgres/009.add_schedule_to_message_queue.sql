@@ -0,0 +1,6 @@
+ALTER TABLE message_queue ADD COLUMN scheduled_at TIMESTAMPTZ;
+ALTER TABLE message_queue ADD COLUMN timezone VARCHAR(64);
+
+-- DOWN
+ALTER TABLE message_queue DROP COLUMN timezone;
+ALTER TABLE message_queue DROP COLUMN scheduled_at;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS rate_limits (
+    instance_id VARCHAR(36) PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    per_second INTEGER NOT NULL DEFAULT 0,
+    per_minute INTEGER NOT NULL DEFAULT 0,
+    recipient_cooldown_ms INTEGER NOT NULL DEFAULT 0,
+    min_delay_ms INTEGER NOT NULL DEFAULT 0,
+    max_delay_ms INTEGER NOT NULL DEFAULT 0,
+    typing BOOLEAN NOT NULL DEFAULT FALSE,
+    on_limit VARCHAR(16) NOT NULL,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- DOWN
+DROP TABLE IF EXISTS rate_limits;
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    pacing JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    dispatched INTEGER NOT NULL DEFAULT 0,
+    next_send_at TIMESTAMPTZ NOT NULL,
+    version INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    instance_id VARCHAR(36) NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS campaigns_due_index ON campaigns (status, next_send_at);
+
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+    id VARCHAR(36) PRIMARY KEY,
+    original VARCHAR(128) NOT NULL,
+    recipient VARCHAR(128) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    message_id VARCHAR(36),
+    external_id VARCHAR(128),
+    error TEXT,
+    sent_at TIMESTAMPTZ,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    campaign_id VARCHAR(36) NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS campaign_recipients_status_index ON campaign_recipients (campaign_id, status, id);
+
+-- DOWN
+DROP INDEX IF EXISTS campaign_recipients_status_index;
+DROP TABLE IF EXISTS campaign_recipients;
+DROP INDEX IF EXISTS campaigns_due_index;
+DROP TABLE IF EXISTS campaigns;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS message_templates (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    body TEXT NOT NULL,
+    media TEXT,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    instance_id VARCHAR(36) NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS message_templates_name_index ON message_templates (instance_id, name);
+
+-- DOWN
+DROP INDEX IF EXISTS message_templates_name_index;
+DROP TABLE IF EXISTS message_templates;
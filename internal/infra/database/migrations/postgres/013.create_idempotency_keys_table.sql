@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    response_body BYTEA,
+
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    instance_id VARCHAR(36) NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idempotency_keys_key_index ON idempotency_keys (instance_id, idempotency_key);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_index ON idempotency_keys (expires_at);
+
+-- DOWN
+DROP INDEX IF EXISTS idempotency_keys_expires_at_index;
+DROP INDEX IF EXISTS idempotency_keys_key_index;
+DROP TABLE IF EXISTS idempotency_keys;
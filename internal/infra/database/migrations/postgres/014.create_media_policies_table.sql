@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS media_policies (
+    instance_id VARCHAR(36) PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    types JSONB NOT NULL,
+    max_size BIGINT NOT NULL DEFAULT 0,
+    chats JSONB NOT NULL,
+    ignore_groups BOOLEAN NOT NULL DEFAULT FALSE,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- DOWN
+DROP TABLE IF EXISTS media_policies;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS tus_uploads (
+    id VARCHAR(36) PRIMARY KEY,
+    upload_length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    metadata JSONB NOT NULL,
+    mime VARCHAR(255) NOT NULL DEFAULT '',
+    path VARCHAR(255) NOT NULL DEFAULT '',
+    multipart_id VARCHAR(1024) NOT NULL DEFAULT '',
+    parts JSONB NOT NULL,
+    pending BIGINT NOT NULL DEFAULT 0,
+    hash_state BYTEA,
+    file_id VARCHAR(36),
+
+    expires_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    instance_id VARCHAR(36) NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS tus_uploads_expires_at_index ON tus_uploads (expires_at);
+
+-- DOWN
+DROP INDEX IF EXISTS tus_uploads_expires_at_index;
+DROP TABLE IF EXISTS tus_uploads;
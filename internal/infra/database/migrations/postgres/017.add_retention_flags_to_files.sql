@@ -0,0 +1,8 @@
+ALTER TABLE files ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE files ADD COLUMN is_thumbnail BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS files_thumbnail_id_index ON files (thumbnail_id);
+
+-- DOWN
+DROP INDEX IF EXISTS files_thumbnail_id_index;
+ALTER TABLE files DROP COLUMN is_thumbnail;
+ALTER TABLE files DROP COLUMN pinned;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS retention_rules (
+    instance_id VARCHAR(36) PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    max_age BIGINT NOT NULL DEFAULT 0,
+    max_bytes BIGINT NOT NULL DEFAULT 0,
+    max_count BIGINT NOT NULL DEFAULT 0,
+    keep_pinned BOOLEAN NOT NULL DEFAULT TRUE,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- DOWN
+DROP TABLE IF EXISTS retention_rules;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS storage_quotas (
+    instance_id VARCHAR(36) PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    max_bytes BIGINT NOT NULL DEFAULT 0,
+    max_files BIGINT NOT NULL DEFAULT 0,
+
+    updated_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- DOWN
+DROP TABLE IF EXISTS storage_quotas;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS rate_limit_states (
+    instance_id VARCHAR(36) PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    per_second INTEGER NOT NULL DEFAULT 0,
+    per_second_tokens DOUBLE PRECISION NOT NULL DEFAULT 0,
+    per_minute INTEGER NOT NULL DEFAULT 0,
+    per_minute_tokens DOUBLE PRECISION NOT NULL DEFAULT 0,
+    refilled_at TIMESTAMPTZ NOT NULL,
+    version INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS rate_limit_sends (
+    instance_id VARCHAR(36) NOT NULL REFERENCES instances(id) ON DELETE CASCADE,
+    chat VARCHAR(128) NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (instance_id, chat)
+);
+
+-- DOWN
+DROP TABLE IF EXISTS rate_limit_sends;
+DROP TABLE IF EXISTS rate_limit_states;
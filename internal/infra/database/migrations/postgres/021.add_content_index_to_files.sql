@@ -0,0 +1,18 @@
+-- files of the same content an instance uploaded before uploads were deduplicated stay as they are, clients may hold
+-- their ids and other files their thumbnails, so all but the oldest are flagged and left out of the unique index
+ALTER TABLE files ADD COLUMN is_duplicate BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE files SET is_duplicate = TRUE
+WHERE instance_id IS NOT NULL AND is_thumbnail = FALSE AND id <> (
+    SELECT k.id FROM files k
+    WHERE k.instance_id = files.instance_id AND k.sha256 = files.sha256 AND k.is_thumbnail = FALSE
+    ORDER BY k.created_at, k.id LIMIT 1
+);
+
+DROP INDEX IF EXISTS files_sha256_index;
+CREATE UNIQUE INDEX IF NOT EXISTS files_content_index ON files (instance_id, sha256) WHERE is_thumbnail = FALSE AND is_duplicate = FALSE;
+
+-- DOWN
+DROP INDEX IF EXISTS files_content_index;
+CREATE INDEX IF NOT EXISTS files_sha256_index ON files (instance_id, sha256);
+ALTER TABLE files DROP COLUMN is_duplicate;
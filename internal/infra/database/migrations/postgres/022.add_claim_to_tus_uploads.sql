@@ -0,0 +1,6 @@
+ALTER TABLE tus_uploads ADD COLUMN claim VARCHAR(36) NOT NULL DEFAULT '';
+ALTER TABLE tus_uploads ADD COLUMN claimed_at TIMESTAMPTZ NULL DEFAULT NULL;
+
+-- DOWN
+ALTER TABLE tus_uploads DROP COLUMN claimed_at;
+ALTER TABLE tus_uploads DROP COLUMN claim;
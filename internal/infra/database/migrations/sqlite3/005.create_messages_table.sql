@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS messages (
+    id TEXT PRIMARY KEY,
+    external_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    chat TEXT NOT NULL,
+    sender TEXT NOT NULL,
+    content TEXT,
+    reply_to TEXT,
+    status TEXT NOT NULL,
+    expiration INTEGER,
+    is_from_me BOOLEAN NOT NULL DEFAULT FALSE,
+    is_forwarded BOOLEAN NOT NULL DEFAULT FALSE,
+    forwarding_score INTEGER NOT NULL DEFAULT 0,
+    timestamp INTEGER NOT NULL,
+
+    sent_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    read_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    instance_id TEXT NOT NULL REFERENCES instances(id) ON DELETE CASCADE,
+
+    UNIQUE (instance_id, chat, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS messages_history_index ON messages (instance_id, chat, timestamp, id);
+
+-- DOWN
+DROP INDEX IF EXISTS messages_history_index;
+DROP TABLE IF EXISTS messages;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS chats (
+    id TEXT PRIMARY KEY,
+    jid TEXT NOT NULL,
+    name TEXT,
+    unread_count INTEGER NOT NULL DEFAULT 0,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    muted_until TIMESTAMP,
+    expiration INTEGER,
+    last_message_at TIMESTAMP,
+
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    instance_id TEXT NOT NULL REFERENCES instances(id) ON DELETE CASCADE,
+
+    UNIQUE (instance_id, jid)
+);
+
+-- DOWN
+DROP TABLE IF EXISTS chats;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS message_receipts (
+    id TEXT PRIMARY KEY,
+    participant TEXT NOT NULL,
+    status TEXT NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+
+    message_id TEXT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+
+    UNIQUE (message_id, participant, status)
+);
+
+-- DOWN
+DROP TABLE IF EXISTS message_receipts;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS message_queue (
+    id TEXT PRIMARY KEY,
+    chat TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL,
+    message_id TEXT,
+    external_id TEXT,
+    sent_at TIMESTAMP,
+
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    instance_id TEXT NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS message_queue_pending_index ON message_queue (status, instance_id, chat, created_at);
+
+-- DOWN
+DROP INDEX IF EXISTS message_queue_pending_index;
+DROP TABLE IF EXISTS message_queue;
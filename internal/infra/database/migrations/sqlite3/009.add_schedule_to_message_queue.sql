@@ -0,0 +1,6 @@
+ALTER TABLE message_queue ADD COLUMN scheduled_at TIMESTAMP;
+ALTER TABLE message_queue ADD COLUMN timezone TEXT;
+
+-- DOWN
+ALTER TABLE message_queue DROP COLUMN timezone;
+ALTER TABLE message_queue DROP COLUMN scheduled_at;
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    pacing TEXT NOT NULL,
+    status TEXT NOT NULL,
+    dispatched INTEGER NOT NULL DEFAULT 0,
+    next_send_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    instance_id TEXT NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS campaigns_due_index ON campaigns (status, next_send_at);
+
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+    id TEXT PRIMARY KEY,
+    original TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    status TEXT NOT NULL,
+    message_id TEXT,
+    external_id TEXT,
+    error TEXT,
+    sent_at TIMESTAMP,
+
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS campaign_recipients_status_index ON campaign_recipients (campaign_id, status, id);
+
+-- DOWN
+DROP INDEX IF EXISTS campaign_recipients_status_index;
+DROP TABLE IF EXISTS campaign_recipients;
+DROP INDEX IF EXISTS campaigns_due_index;
+DROP TABLE IF EXISTS campaigns;
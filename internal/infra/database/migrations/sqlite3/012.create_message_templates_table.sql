@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS message_templates (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    body TEXT NOT NULL,
+    media TEXT,
+
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    instance_id TEXT NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS message_templates_name_index ON message_templates (instance_id, name);
+
+-- DOWN
+DROP INDEX IF EXISTS message_templates_name_index;
+DROP TABLE IF EXISTS message_templates;
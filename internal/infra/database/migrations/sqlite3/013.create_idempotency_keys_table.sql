@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id TEXT PRIMARY KEY,
+    idempotency_key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    status TEXT NOT NULL,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    response_body BLOB,
+
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+
+    instance_id TEXT NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idempotency_keys_key_index ON idempotency_keys (instance_id, idempotency_key);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_index ON idempotency_keys (expires_at);
+
+-- DOWN
+DROP INDEX IF EXISTS idempotency_keys_expires_at_index;
+DROP INDEX IF EXISTS idempotency_keys_key_index;
+DROP TABLE IF EXISTS idempotency_keys;
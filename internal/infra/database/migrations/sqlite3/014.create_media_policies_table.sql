@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS media_policies (
+    instance_id TEXT PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    types TEXT NOT NULL,
+    max_size INTEGER NOT NULL DEFAULT 0,
+    chats TEXT NOT NULL,
+    ignore_groups BOOLEAN NOT NULL DEFAULT FALSE,
+
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- DOWN
+DROP TABLE IF EXISTS media_policies;
@@ -0,0 +1,6 @@
+ALTER TABLE files ADD COLUMN refs INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS files_sha256_index ON files (instance_id, sha256);
+
+-- DOWN
+DROP INDEX IF EXISTS files_sha256_index;
+ALTER TABLE files DROP COLUMN refs;
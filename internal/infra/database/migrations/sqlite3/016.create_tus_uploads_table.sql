@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS tus_uploads (
+    id TEXT PRIMARY KEY,
+    upload_length INTEGER NOT NULL,
+    upload_offset INTEGER NOT NULL DEFAULT 0,
+    metadata TEXT NOT NULL,
+    mime TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    multipart_id TEXT NOT NULL DEFAULT '',
+    parts TEXT NOT NULL,
+    pending INTEGER NOT NULL DEFAULT 0,
+    hash_state BLOB,
+    file_id TEXT,
+
+    expires_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+
+    instance_id TEXT NOT NULL REFERENCES instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS tus_uploads_expires_at_index ON tus_uploads (expires_at);
+
+-- DOWN
+DROP INDEX IF EXISTS tus_uploads_expires_at_index;
+DROP TABLE IF EXISTS tus_uploads;
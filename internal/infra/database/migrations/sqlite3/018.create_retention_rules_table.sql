@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS retention_rules (
+    instance_id TEXT PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    max_age INTEGER NOT NULL DEFAULT 0,
+    max_bytes INTEGER NOT NULL DEFAULT 0,
+    max_count INTEGER NOT NULL DEFAULT 0,
+    keep_pinned BOOLEAN NOT NULL DEFAULT TRUE,
+
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- DOWN
+DROP TABLE IF EXISTS retention_rules;
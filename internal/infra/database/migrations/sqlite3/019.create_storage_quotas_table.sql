@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS storage_quotas (
+    instance_id TEXT PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    max_bytes INTEGER NOT NULL DEFAULT 0,
+    max_files INTEGER NOT NULL DEFAULT 0,
+
+    updated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- DOWN
+DROP TABLE IF EXISTS storage_quotas;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS rate_limit_states (
+    instance_id TEXT PRIMARY KEY REFERENCES instances(id) ON DELETE CASCADE,
+    per_second INTEGER NOT NULL DEFAULT 0,
+    per_second_tokens REAL NOT NULL DEFAULT 0,
+    per_minute INTEGER NOT NULL DEFAULT 0,
+    per_minute_tokens REAL NOT NULL DEFAULT 0,
+    refilled_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS rate_limit_sends (
+    instance_id TEXT NOT NULL REFERENCES instances(id) ON DELETE CASCADE,
+    chat TEXT NOT NULL,
+    sent_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (instance_id, chat)
+);
+
+-- DOWN
+DROP TABLE IF EXISTS rate_limit_sends;
+DROP TABLE IF EXISTS rate_limit_states;
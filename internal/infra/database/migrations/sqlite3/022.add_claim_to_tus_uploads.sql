@@ -0,0 +1,6 @@
+ALTER TABLE tus_uploads ADD COLUMN claim TEXT NOT NULL DEFAULT '';
+ALTER TABLE tus_uploads ADD COLUMN claimed_at TIMESTAMP NULL DEFAULT NULL;
+
+-- DOWN
+ALTER TABLE tus_uploads DROP COLUMN claimed_at;
+ALTER TABLE tus_uploads DROP COLUMN claim;
@@ -0,0 +1,111 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+)
+
+// chats remembered for the cooldown are pruned once an instance holds more than this
+const maxTrackedChats = 1024
+
+type instanceState struct {
+	perSecond    *ratelimit.Bucket
+	perSecondCap int
+	perMinute    *ratelimit.Bucket
+	perMinuteCap int
+	lastSent     map[string]time.Time
+}
+
+// InMemoryLimiter keeps the rate of each instance in the process, the limits only hold while a single replica
+// sends, several replicas must share a SharedLimiter
+type InMemoryLimiter struct {
+	mu        sync.Mutex
+	instances map[string]*instanceState
+}
+
+func NewInMemoryLimiter() *InMemoryLimiter {
+	return &InMemoryLimiter{
+		instances: make(map[string]*instanceState),
+	}
+}
+
+func (l *InMemoryLimiter) Take(policy *ratelimit.Policy, chat string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	state := l.state(policy, now)
+
+	if cooldown := policy.RecipientCooldown(); cooldown > 0 {
+		if last, ok := state.lastSent[chat]; ok && now.Sub(last) < cooldown {
+			return &ratelimit.LimitError{Err: ratelimit.ErrRecipientCooldown, RetryAfter: cooldown - now.Sub(last)}
+		}
+	}
+
+	var wait time.Duration
+	if state.perSecond != nil {
+		wait = max(wait, state.perSecond.Wait(now))
+	}
+	if state.perMinute != nil {
+		wait = max(wait, state.perMinute.Wait(now))
+	}
+
+	if wait > 0 {
+		return &ratelimit.LimitError{Err: ratelimit.ErrRateLimited, RetryAfter: wait}
+	}
+
+	if state.perSecond != nil {
+		state.perSecond.Take(now)
+	}
+	if state.perMinute != nil {
+		state.perMinute.Take(now)
+	}
+
+	if policy.RecipientCooldown() > 0 {
+		if len(state.lastSent) >= maxTrackedChats {
+			for c, last := range state.lastSent {
+				if now.Sub(last) >= policy.RecipientCooldown() {
+					delete(state.lastSent, c)
+				}
+			}
+		}
+		state.lastSent[chat] = now
+	}
+
+	return nil
+}
+
+func (l *InMemoryLimiter) Forget(instanceID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.instances, instanceID)
+}
+
+// state returns the state of the instance, the buckets are rebuilt when the policy limits changed
+func (l *InMemoryLimiter) state(policy *ratelimit.Policy, now time.Time) *instanceState {
+	state, ok := l.instances[policy.InstanceID]
+	if !ok {
+		state = &instanceState{lastSent: make(map[string]time.Time)}
+		l.instances[policy.InstanceID] = state
+	}
+
+	if state.perSecondCap != policy.PerSecond {
+		state.perSecondCap = policy.PerSecond
+		state.perSecond = nil
+		if policy.PerSecond > 0 {
+			state.perSecond = ratelimit.NewBucket(policy.PerSecond, time.Second, now)
+		}
+	}
+
+	if state.perMinuteCap != policy.PerMinute {
+		state.perMinuteCap = policy.PerMinute
+		state.perMinute = nil
+		if policy.PerMinute > 0 {
+			state.perMinute = ratelimit.NewBucket(policy.PerMinute, time.Minute, now)
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,122 @@
+package limiter_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/limiter"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLimiter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Limiter Suite")
+}
+
+var _ = AfterSuite(func() {
+	_ = os.Remove("test.db")
+})
+
+var _ = DescribeTableSubtree("Limiter", func(shared bool) {
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		l    ratelimit.Limiter
+		repo *repository.RateLimitRepository
+	)
+
+	BeforeEach(func() {
+		l = limiter.NewInMemoryLimiter()
+		if !shared {
+			return
+		}
+
+		db := database.New(&config.DatabaseConfig{Driver: config.DatabaseDriverSQLite, DbName: "test"})
+		DeferCleanup(db.Close)
+
+		database.NewMigrator(db, db.DriverName()).Reset()
+
+		instRepo := repository.NewInstanceRepository(db)
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-2").Create())).To(Succeed())
+
+		repo = repository.NewRateLimitRepository(db)
+		l = limiter.NewSharedLimiter(repo)
+	})
+
+	It("should allow everything when the policy has no limits", func() {
+		p := ratelimit.NewPolicy("instance-1", ratelimit.Policy{})
+		for range 100 {
+			Expect(l.Take(p, "123@s.whatsapp.net")).To(Succeed())
+		}
+	})
+
+	It("should limit the sends per second of each instance", func() {
+		p := ratelimit.NewPolicy("instance-1", ratelimit.Policy{PerSecond: 2})
+
+		Expect(l.Take(p, "1@s.whatsapp.net")).To(Succeed())
+		Expect(l.Take(p, "2@s.whatsapp.net")).To(Succeed())
+
+		err := l.Take(p, "3@s.whatsapp.net")
+		Expect(errors.Is(err, ratelimit.ErrRateLimited)).To(BeTrue())
+
+		var limitErr *ratelimit.LimitError
+		Expect(errors.As(err, &limitErr)).To(BeTrue())
+		Expect(limitErr.RetryAfter).To(BeNumerically(">", 0))
+		Expect(limitErr.RetryAfter).To(BeNumerically("<=", 500*time.Millisecond))
+
+		other := ratelimit.NewPolicy("instance-2", ratelimit.Policy{PerSecond: 2})
+		Expect(l.Take(other, "3@s.whatsapp.net")).To(Succeed())
+	})
+
+	It("should not spend the per second token when the per minute limit is hit", func() {
+		p := ratelimit.NewPolicy("instance-1", ratelimit.Policy{PerSecond: 10, PerMinute: 1})
+
+		Expect(l.Take(p, "1@s.whatsapp.net")).To(Succeed())
+		Expect(errors.Is(l.Take(p, "2@s.whatsapp.net"), ratelimit.ErrRateLimited)).To(BeTrue())
+
+		// raising the limit rebuilds the bucket
+		p.PerMinute = 5
+		Expect(l.Take(p, "2@s.whatsapp.net")).To(Succeed())
+	})
+
+	It("should wait the cooldown between sends to the same chat", func() {
+		p := ratelimit.NewPolicy("instance-1", ratelimit.Policy{RecipientCooldownMs: 60_000})
+
+		Expect(l.Take(p, "1@s.whatsapp.net")).To(Succeed())
+		Expect(l.Take(p, "2@s.whatsapp.net")).To(Succeed())
+
+		err := l.Take(p, "1@s.whatsapp.net")
+		Expect(errors.Is(err, ratelimit.ErrRecipientCooldown)).To(BeTrue())
+
+		l.Forget("instance-1")
+		Expect(l.Take(p, "1@s.whatsapp.net")).To(Succeed())
+	})
+
+	It("should share the limits between the replicas", func() {
+		if !shared {
+			Skip("the in memory limiter is local to the replica")
+		}
+
+		p := ratelimit.NewPolicy("instance-1", ratelimit.Policy{PerMinute: 2, RecipientCooldownMs: 60_000})
+		replica := limiter.NewSharedLimiter(repo)
+
+		Expect(l.Take(p, "1@s.whatsapp.net")).To(Succeed())
+		Expect(errors.Is(replica.Take(p, "1@s.whatsapp.net"), ratelimit.ErrRecipientCooldown)).To(BeTrue())
+
+		Expect(replica.Take(p, "2@s.whatsapp.net")).To(Succeed())
+		Expect(errors.Is(l.Take(p, "3@s.whatsapp.net"), ratelimit.ErrRateLimited)).To(BeTrue())
+	})
+},
+	Entry("InMemoryLimiter", false),
+	Entry("SharedLimiter", true),
+)
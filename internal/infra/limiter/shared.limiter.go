@@ -0,0 +1,107 @@
+package limiter
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+)
+
+const (
+	// a take is read again this many times when another replica stored the state of the instance first
+	sharedTakeAttempts = 5
+
+	// wait asked from a send that lost every attempt to other replicas
+	sharedContendedWait = 100 * time.Millisecond
+)
+
+// SharedLimiter keeps the rate of each instance in the database, so every replica sending for an instance takes
+// from the same buckets, the state is stored only when nobody stored it since it was read
+type SharedLimiter struct {
+	repo ratelimit.StateRepository
+}
+
+func NewSharedLimiter(repo ratelimit.StateRepository) *SharedLimiter {
+	return &SharedLimiter{repo: repo}
+}
+
+func (l *SharedLimiter) Take(policy *ratelimit.Policy, chat string) error {
+	for range sharedTakeAttempts {
+		taken, err := l.take(policy, chat, time.Now())
+		if err != nil || taken {
+			return err
+		}
+	}
+
+	return &ratelimit.LimitError{Err: ratelimit.ErrRateLimited, RetryAfter: sharedContendedWait}
+}
+
+// take reserves the send, false when another replica changed the state meanwhile and it must be read again
+func (l *SharedLimiter) take(policy *ratelimit.Policy, chat string, now time.Time) (bool, error) {
+	cooldown := policy.RecipientCooldown()
+	if cooldown > 0 {
+		last, err := l.repo.LastSent(policy.InstanceID, chat)
+		if err != nil {
+			return false, err
+		}
+
+		if last != nil && now.Sub(*last) < cooldown {
+			return false, &ratelimit.LimitError{Err: ratelimit.ErrRecipientCooldown, RetryAfter: cooldown - now.Sub(*last)}
+		}
+	}
+
+	state, err := l.repo.GetState(policy.InstanceID)
+	if err != nil {
+		return false, err
+	}
+	if state == nil {
+		state = ratelimit.NewState(policy.InstanceID)
+	}
+
+	perSecond, perMinute := state.Buckets(policy, now)
+
+	var wait time.Duration
+	if perSecond != nil {
+		wait = max(wait, perSecond.Wait(now))
+	}
+	if perMinute != nil {
+		wait = max(wait, perMinute.Wait(now))
+	}
+
+	if wait > 0 {
+		return false, &ratelimit.LimitError{Err: ratelimit.ErrRateLimited, RetryAfter: wait}
+	}
+
+	if perSecond != nil {
+		perSecond.Take(now)
+	}
+	if perMinute != nil {
+		perMinute.Take(now)
+	}
+
+	state.Keep(policy, perSecond, perMinute, now)
+	saved, err := l.repo.SaveState(state)
+	if err != nil || !saved {
+		return false, err
+	}
+
+	if cooldown > 0 {
+		// another replica sent to the chat since it was checked, the token taken above is lost
+		marked, err := l.repo.MarkSent(policy.InstanceID, chat, now, now.Add(-cooldown))
+		if err != nil {
+			return false, err
+		}
+
+		if !marked {
+			return false, &ratelimit.LimitError{Err: ratelimit.ErrRecipientCooldown, RetryAfter: cooldown}
+		}
+	}
+
+	return true, nil
+}
+
+func (l *SharedLimiter) Forget(instanceID string) {
+	if err := l.repo.ForgetState(instanceID); err != nil {
+		app.GetRateLimitServiceLogger().Error("Error forgetting rate limit state", "instance", instanceID, "error", err)
+	}
+}
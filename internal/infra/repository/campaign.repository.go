@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type CampaignRepository struct {
+	db *sqlx.DB
+}
+
+func NewCampaignRepository(db *sqlx.DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+func (r *CampaignRepository) Insert(c *campaign.Campaign) error {
+	sqlCampaign, err := models.FromCampaignEntity(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.NamedExec(`
+		INSERT INTO campaigns (
+			id, instance_id, name, kind, payload, pacing, status, dispatched, next_send_at, version, started_at, finished_at, created_at, updated_at
+		) VALUES (
+			:id, :instance_id, :name, :kind, :payload, :pacing, :status, :dispatched, :next_send_at, :version, :started_at, :finished_at, :created_at, :updated_at
+		)
+	`, sqlCampaign)
+	return err
+}
+
+func (r *CampaignRepository) Update(c *campaign.Campaign) (bool, error) {
+	sqlCampaign, err := models.FromCampaignEntity(c)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.db.NamedExec(`
+		UPDATE campaigns SET
+			status = :status,
+			dispatched = :dispatched,
+			next_send_at = :next_send_at,
+			version = version + 1,
+			finished_at = :finished_at,
+			updated_at = :updated_at
+		WHERE id = :id AND version = :version
+	`, sqlCampaign)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected != 1 {
+		return false, nil
+	}
+
+	c.Version++
+	return true, nil
+}
+
+func (r *CampaignRepository) Due(now time.Time, limit int) ([]*campaign.Campaign, error) {
+	args := map[string]interface{}{
+		"running": string(campaign.StatusRunning),
+		"now":     now.UTC(),
+		"limit":   limit,
+	}
+
+	var sqlCampaigns []models.SQLCampaign
+	nstmt, err := r.db.PrepareNamed(`
+		SELECT * FROM campaigns
+		WHERE status = :running AND next_send_at <= :now
+		ORDER BY next_send_at ASC, id ASC
+		LIMIT :limit`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Select(&sqlCampaigns, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCampaignEntities(sqlCampaigns), nil
+}
+
+func (r *CampaignRepository) Get(opts ...campaign.CampaignQueryOption) (*campaign.Campaign, error) {
+	queryOptions := &campaign.CampaignQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM campaigns WHERE 1=1`, queryOptions)
+	query += " LIMIT 1"
+
+	var sqlCampaign models.SQLCampaign
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlCampaign, args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlCampaign.ToEntity(), nil
+}
+
+func (r *CampaignRepository) List(opts ...campaign.CampaignQueryOption) ([]*campaign.Campaign, error) {
+	queryOptions := &campaign.CampaignQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM campaigns WHERE 1=1`, queryOptions)
+	query += " ORDER BY created_at DESC, id DESC"
+	if queryOptions.Limit != nil {
+		query += " LIMIT :limit"
+		args["limit"] = *queryOptions.Limit
+	}
+
+	var sqlCampaigns []models.SQLCampaign
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Select(&sqlCampaigns, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCampaignEntities(sqlCampaigns), nil
+}
+
+func (r *CampaignRepository) where(query string, queryOptions *campaign.CampaignQueryOptions) (string, map[string]interface{}) {
+	args := map[string]interface{}{}
+
+	if queryOptions.ID != nil {
+		query += " AND id = :id"
+		args["id"] = *queryOptions.ID
+	}
+	if queryOptions.InstanceID != nil {
+		query += " AND instance_id = :instance_id"
+		args["instance_id"] = *queryOptions.InstanceID
+	}
+	if queryOptions.Status != nil {
+		query += " AND status = :status"
+		args["status"] = string(*queryOptions.Status)
+	}
+
+	return query, args
+}
+
+func toCampaignEntities(sqlCampaigns []models.SQLCampaign) []*campaign.Campaign {
+	campaigns := make([]*campaign.Campaign, len(sqlCampaigns))
+	for i, s := range sqlCampaigns {
+		campaigns[i] = s.ToEntity()
+	}
+	return campaigns
+}
@@ -0,0 +1,227 @@
+package repository_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("CampaignRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo          campaign.CampaignRepository
+		recipientRepo campaign.RecipientRepository
+		instRepo      instance.InstanceRepository
+		db            *sqlx.DB
+		migrator      *database.Migrator
+	)
+
+	newCampaign := func() *campaign.Campaign {
+		return campaign.New("instance-1", "promo", message.MessageKindText, []byte(`{"text":"hi"}`), campaign.DefaultPacing())
+	}
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewCampaignRepository(db)
+		recipientRepo = repository.NewCampaignRecipientRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should insert and find a campaign by ID", func() {
+		c := newCampaign()
+		c.Pacing.BatchSize = 50
+		Expect(repo.Insert(c)).To(Succeed())
+
+		got, err := repo.Get(campaign.WhereInstanceID("instance-1"), campaign.WhereID(c.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Name).To(Equal("promo"))
+		Expect(got.Kind).To(Equal(message.MessageKindText))
+		Expect(got.Payload).To(MatchJSON(`{"text":"hi"}`))
+		Expect(got.Pacing).To(Equal(c.Pacing))
+		Expect(got.Status).To(Equal(campaign.StatusRunning))
+
+		got, err = repo.Get(campaign.WhereID("unknown"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+
+	It("should not store a campaign changed since it was loaded", func() {
+		c := newCampaign()
+		Expect(repo.Insert(c)).To(Succeed())
+
+		first, err := repo.Get(campaign.WhereID(c.ID))
+		Expect(err).ToNot(HaveOccurred())
+		second, err := repo.Get(campaign.WhereID(c.ID))
+		Expect(err).ToNot(HaveOccurred())
+
+		first.Advance()
+		Expect(repo.Update(first)).To(BeTrue())
+
+		Expect(second.Pause()).To(Succeed())
+		Expect(repo.Update(second)).To(BeFalse())
+
+		// the one loaded again sees the change and can be stored
+		got, err := repo.Get(campaign.WhereID(c.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Dispatched).To(Equal(1))
+		Expect(got.Pause()).To(Succeed())
+		Expect(repo.Update(got)).To(BeTrue())
+	})
+
+	It("should list only the due running campaigns", func() {
+		due := newCampaign()
+		Expect(repo.Insert(due)).To(Succeed())
+
+		later := newCampaign()
+		later.Postpone(time.Hour)
+		Expect(repo.Insert(later)).To(Succeed())
+
+		paused := newCampaign()
+		Expect(paused.Pause()).To(Succeed())
+		Expect(repo.Insert(paused)).To(Succeed())
+
+		got, err := repo.Due(time.Now().Add(time.Second), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(HaveLen(1))
+		Expect(got[0].ID).To(Equal(due.ID))
+	})
+
+	It("should hand the pending recipients in order and count them by status", func() {
+		c := newCampaign()
+		Expect(repo.Insert(c)).To(Succeed())
+
+		first := campaign.NewRecipient(c.ID, "5511111111111", "5511111111111@s.whatsapp.net")
+		second := campaign.NewRecipient(c.ID, "5522222222222", "5522222222222@s.whatsapp.net")
+		third := campaign.NewRecipient(c.ID, "5533333333333", "5533333333333@s.whatsapp.net")
+		invalid := campaign.NewInvalidRecipient(c.ID, "5500000000000")
+		Expect(recipientRepo.InsertMany([]*campaign.Recipient{first, second, third, invalid})).To(Succeed())
+
+		next, err := recipientRepo.Next(c.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(next.ID).To(Equal(first.ID))
+
+		next.MarkSending()
+		Expect(recipientRepo.UpdateFrom(next, campaign.RecipientPending)).To(BeTrue())
+		Expect(recipientRepo.UpdateFrom(next, campaign.RecipientPending)).To(BeFalse())
+
+		next.Fail(errors.New("boom"))
+		Expect(recipientRepo.Update(next)).To(Succeed())
+
+		next, err = recipientRepo.Next(c.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(next.ID).To(Equal(second.ID))
+
+		Expect(recipientRepo.Skip(c.ID)).To(Equal(int64(2)))
+
+		progress, err := recipientRepo.Progress(c.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*progress).To(Equal(campaign.Progress{Total: 4, Failed: 1, Invalid: 1, Skipped: 2}))
+
+		next, err = recipientRepo.Next(c.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(next).To(BeNil())
+	})
+
+	It("should release the recipients left as sending", func() {
+		c := newCampaign()
+		Expect(repo.Insert(c)).To(Succeed())
+
+		r := campaign.NewRecipient(c.ID, "5511111111111", "5511111111111@s.whatsapp.net")
+		r.MarkSending()
+		r.UpdatedAt = time.Now().Add(-time.Hour)
+		Expect(recipientRepo.InsertMany([]*campaign.Recipient{r})).To(Succeed())
+
+		Expect(recipientRepo.Release(time.Now().Add(-time.Minute))).To(Equal(int64(1)))
+
+		next, err := recipientRepo.Next(c.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(next.ID).To(Equal(r.ID))
+	})
+
+	It("should not release a recipient whose claim was renewed", func() {
+		c := newCampaign()
+		Expect(repo.Insert(c)).To(Succeed())
+
+		r := campaign.NewRecipient(c.ID, "5511111111111", "5511111111111@s.whatsapp.net")
+		r.MarkSending()
+		r.UpdatedAt = time.Now().Add(-time.Hour)
+		Expect(recipientRepo.InsertMany([]*campaign.Recipient{r})).To(Succeed())
+
+		Expect(recipientRepo.Renew(r.ID, time.Now())).To(BeTrue())
+		Expect(recipientRepo.Release(time.Now().Add(-time.Minute))).To(BeZero())
+
+		r.Release()
+		Expect(recipientRepo.Update(r)).To(Succeed())
+		Expect(recipientRepo.Renew(r.ID, time.Now())).To(BeFalse())
+	})
+
+	It("should paginate the recipients with the after cursor", func() {
+		c := newCampaign()
+		Expect(repo.Insert(c)).To(Succeed())
+
+		recipients := []*campaign.Recipient{}
+		for _, phone := range []string{"1", "2", "3", "4", "5"} {
+			recipients = append(recipients, campaign.NewRecipient(c.ID, phone, phone+"@s.whatsapp.net"))
+		}
+		Expect(recipientRepo.InsertMany(recipients)).To(Succeed())
+
+		page, err := recipientRepo.List(campaign.WhereCampaignID(c.ID), campaign.RecipientsLimit(2))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(HaveLen(2))
+		Expect(page[1].ID).To(Equal(recipients[1].ID))
+
+		page, err = recipientRepo.List(campaign.WhereCampaignID(c.ID), campaign.WhereAfter(page[1].ID), campaign.RecipientsLimit(10))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(HaveLen(3))
+		Expect(page[0].ID).To(Equal(recipients[2].ID))
+
+		page, err = recipientRepo.List(campaign.WhereCampaignID(c.ID), campaign.WhereRecipientStatus(campaign.RecipientSent))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(BeEmpty())
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
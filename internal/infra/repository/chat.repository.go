@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type ChatRepository struct {
+	db *sqlx.DB
+}
+
+func NewChatRepository(db *sqlx.DB) *ChatRepository {
+	return &ChatRepository{db: db}
+}
+
+const saveChat = `
+	INSERT INTO chats (
+		id, jid, name, unread_count, archived, pinned, muted_until, expiration, last_message_at, instance_id, created_at, updated_at
+	) VALUES (
+		:id, :jid, :name, :unread_count, :archived, :pinned, :muted_until, :expiration, :last_message_at, :instance_id, :created_at, :updated_at
+	)
+	ON CONFLICT (instance_id, jid) DO UPDATE SET
+		name = COALESCE(excluded.name, chats.name),
+		unread_count = excluded.unread_count,
+		archived = excluded.archived,
+		pinned = excluded.pinned,
+		muted_until = excluded.muted_until,
+		expiration = excluded.expiration,
+		last_message_at = COALESCE(excluded.last_message_at, chats.last_message_at),
+		updated_at = excluded.updated_at
+	RETURNING id`
+
+func (r *ChatRepository) Save(c *chat.Chat) error {
+	rows, err := r.db.NamedQuery(saveChat, models.FromChatEntity(c))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// when the chat was already stored the original id is kept, so the entity points to the stored row
+	if rows.Next() {
+		if err := rows.Scan(&c.ID); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *ChatRepository) SaveMany(chats []*chat.Chat) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareNamed(saveChat)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range chats {
+		if err := stmt.QueryRowx(models.FromChatEntity(c)).Scan(&c.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ChatRepository) Update(c *chat.Chat) error {
+	_, err := r.db.NamedExec(`
+		UPDATE chats SET
+			jid = :jid,
+			name = :name,
+			unread_count = :unread_count,
+			archived = :archived,
+			pinned = :pinned,
+			muted_until = :muted_until,
+			expiration = :expiration,
+			last_message_at = :last_message_at,
+			instance_id = :instance_id,
+			created_at = :created_at,
+			updated_at = :updated_at
+		WHERE id = :id
+	`, models.FromChatEntity(c))
+	return err
+}
+
+func (r *ChatRepository) Get(opts ...chat.ChatQueryOption) (*chat.Chat, error) {
+	queryOptions := &chat.ChatQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM chats WHERE 1=1`, queryOptions)
+	query += " LIMIT 1"
+
+	var sqlChat models.SQLChat
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	err = nstmt.Get(&sqlChat, args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlChat.ToEntity(), nil
+}
+
+func (r *ChatRepository) List(opts ...chat.ChatQueryOption) ([]*chat.Chat, error) {
+	queryOptions := &chat.ChatQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM chats WHERE 1=1`, queryOptions)
+	query += " ORDER BY last_message_at DESC, id DESC"
+	if queryOptions.Limit != nil {
+		query += " LIMIT :limit"
+		args["limit"] = *queryOptions.Limit
+	}
+
+	var sqlChats []models.SQLChat
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	err = nstmt.Select(&sqlChats, args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []*chat.Chat{}, nil
+		}
+		return nil, err
+	}
+
+	chats := make([]*chat.Chat, len(sqlChats))
+	for i, sqlChat := range sqlChats {
+		chats[i] = sqlChat.ToEntity()
+	}
+
+	return chats, nil
+}
+
+func (r *ChatRepository) where(query string, queryOptions *chat.ChatQueryOptions) (string, map[string]interface{}) {
+	args := map[string]interface{}{}
+
+	if queryOptions.ID != nil {
+		query += " AND id = :id"
+		args["id"] = *queryOptions.ID
+	}
+	if queryOptions.JID != nil {
+		query += " AND jid = :jid"
+		args["jid"] = *queryOptions.JID
+	}
+	if queryOptions.InstanceID != nil {
+		query += " AND instance_id = :instance_id"
+		args["instance_id"] = *queryOptions.InstanceID
+	}
+
+	return query, args
+}
@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("ChatRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     chat.ChatRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewChatRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should save and find a chat by JID", func() {
+		c := chat.New("123@s.whatsapp.net", "instance-1")
+		c.Name = utils.StringPtr("John")
+		c.Expiration = utils.Uint32Ptr(86400)
+
+		Expect(repo.Save(c)).To(Succeed())
+
+		got, err := repo.Get(chat.WhereInstanceID("instance-1"), chat.WhereJID("123@s.whatsapp.net"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.ID).To(Equal(c.ID))
+		Expect(*got.Name).To(Equal("John"))
+		Expect(*got.Expiration).To(Equal(uint32(86400)))
+		Expect(got.HasExpiration()).To(BeTrue())
+	})
+
+	It("should not duplicate a chat synced twice and keep its name", func() {
+		first := chat.New("123@s.whatsapp.net", "instance-1")
+		first.Name = utils.StringPtr("John")
+		Expect(repo.Save(first)).To(Succeed())
+
+		lastMessageAt := time.Now()
+		again := chat.New("123@s.whatsapp.net", "instance-1")
+		again.UnreadCount = 3
+		again.LastMessageAt = &lastMessageAt
+		Expect(repo.Save(again)).To(Succeed())
+		Expect(again.ID).To(Equal(first.ID))
+
+		chats, err := repo.List(chat.WhereInstanceID("instance-1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(chats).To(HaveLen(1))
+		Expect(*chats[0].Name).To(Equal("John"))
+		Expect(chats[0].UnreadCount).To(Equal(uint32(3)))
+		Expect(*chats[0].LastMessageAt).To(BeTemporally("~", lastMessageAt, time.Second))
+	})
+
+	It("should update a chat", func() {
+		c := chat.New("123@s.whatsapp.net", "instance-1")
+		Expect(repo.Save(c)).To(Succeed())
+
+		c.Expiration = nil
+		c.Archived = true
+		Expect(repo.Update(c)).To(Succeed())
+
+		got, err := repo.Get(chat.WhereID(c.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Archived).To(BeTrue())
+		Expect(got.HasExpiration()).To(BeFalse())
+	})
+
+	It("should return nil when the chat does not exist", func() {
+		got, err := repo.Get(chat.WhereJID("unknown"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
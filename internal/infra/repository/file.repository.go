@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
@@ -27,12 +28,12 @@ func (r *FileRepository) Insert(f *file.File) error {
 			id, name, mime, size, sha256, extension, 
 			path, url,
 			width, height, duration, pages,
-			created_at, updated_at, instance_id, thumbnail_id
+			created_at, updated_at, refs, pinned, is_thumbnail, instance_id, thumbnail_id
 		) VALUES (
 			:id, :name, :mime, :size, :sha256, :extension, 
 			:path, :url, 
 			:width, :height, :duration, :pages,
-			:created_at, :updated_at, :instance_id, :thumbnail_id
+			:created_at, :updated_at, :refs, :pinned, :is_thumbnail, :instance_id, :thumbnail_id
 		)
 	`, sqlFile)
 	return err
@@ -53,12 +54,12 @@ func (r *FileRepository) InsertMany(files []*file.File) error {
 				id, name, mime, size, sha256, extension, 
 				path, url,
 				width, height, duration, pages,
-				created_at, updated_at, instance_id, thumbnail_id
+				created_at, updated_at, refs, pinned, is_thumbnail, instance_id, thumbnail_id
 			) VALUES (
 				:id, :name, :mime, :size, :sha256, :extension, 
 				:path, :url, 
 				:width, :height, :duration, :pages,
-				:created_at, :updated_at, :instance_id, :thumbnail_id
+				:created_at, :updated_at, :refs, :pinned, :is_thumbnail, :instance_id, :thumbnail_id
 			)
 		`, sqlFile)
 
@@ -96,12 +97,132 @@ func (r *FileRepository) Update(f *file.File) error {
 
 			updated_at = :updated_at,
 
+			pinned = :pinned,
+
 			thumbnail_id = :thumbnail_id
 		WHERE id = :id
 	`, sqlFile)
 	return err
 }
 
+// InsertOrReference relies on the unique content index of the files of an instance, the insert of a content the
+// instance already has counts one more reference of the existing file instead
+func (r *FileRepository) InsertOrReference(f *file.File) (*file.File, error) {
+	sqlFile, err := models.FromFileEntity(f)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.NamedQuery(`
+		INSERT INTO files (
+			id, name, mime, size, sha256, extension, 
+			path, url,
+			width, height, duration, pages,
+			created_at, updated_at, refs, pinned, is_thumbnail, instance_id, thumbnail_id
+		) VALUES (
+			:id, :name, :mime, :size, :sha256, :extension, 
+			:path, :url, 
+			:width, :height, :duration, :pages,
+			:created_at, :updated_at, :refs, :pinned, :is_thumbnail, :instance_id, :thumbnail_id
+		)
+		ON CONFLICT (instance_id, sha256) WHERE is_thumbnail = FALSE AND is_duplicate = FALSE DO UPDATE SET
+			refs = files.refs + 1,
+			updated_at = excluded.updated_at
+		RETURNING id
+	`, sqlFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+	if rows.Next() {
+		err = rows.Scan(&id)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	rows.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if id == f.ID {
+		return f, nil
+	}
+
+	return r.Get(file.WhereID(id), file.WithThumbnail())
+}
+
+func (r *FileRepository) Reference(id string) error {
+	result, err := r.db.NamedExec(`
+		UPDATE files SET refs = refs + 1, updated_at = :now WHERE id = :id
+	`, map[string]interface{}{
+		"id":  id,
+		"now": time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return file.ErrFileNotFound
+	}
+
+	return nil
+}
+
+func (r *FileRepository) Release(id string) (uint32, error) {
+	rows, err := r.db.NamedQuery(`
+		UPDATE files SET refs = refs - 1, updated_at = :now WHERE id = :id AND refs > 0
+		RETURNING refs
+	`, map[string]interface{}{
+		"id":  id,
+		"now": time.Now().UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, file.ErrFileNotFound
+	}
+
+	var refs uint32
+	if err := rows.Scan(&refs); err != nil {
+		return 0, err
+	}
+
+	return refs, rows.Err()
+}
+
+func (r *FileRepository) DeleteReleased(id string) (bool, error) {
+	result, err := r.db.NamedExec(`
+		DELETE FROM files WHERE id = :id AND refs = 0
+	`, map[string]interface{}{
+		"id": id,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
 func (r *FileRepository) Get(opts ...file.FileQueryOption) (*file.File, error) {
 	params := &file.FileQueryOptions{}
 	for _, opt := range opts {
@@ -151,6 +272,10 @@ func (r *FileRepository) Get(opts ...file.FileQueryOption) (*file.File, error) {
 		query += " AND f.sha256 = :sha256"
 	}
 
+	if params.IsThumbnail != nil {
+		query += " AND f.is_thumbnail = :is_thumbnail"
+	}
+
 	if params.HasThumbnail != nil {
 		if *params.HasThumbnail {
 			query += " AND thumbnail_id IS NOT NULL"
@@ -237,6 +362,10 @@ func (r *FileRepository) List(opts ...file.FileQueryOption) ([]*file.File, error
 		query += " AND f.created_at <= :cursor"
 	}
 
+	if params.IsThumbnail != nil {
+		query += " AND f.is_thumbnail = :is_thumbnail"
+	}
+
 	if params.ID != nil {
 		query += " AND f.id = :id"
 	}
@@ -321,6 +450,10 @@ func (r *FileRepository) Count(opts ...file.FileQueryOption) (uint64, error) {
 		query += " AND instance_id = :instance_id"
 	}
 
+	if params.Path != nil {
+		query += " AND path = :path"
+	}
+
 	if params.HasThumbnail != nil {
 		if *params.HasThumbnail {
 			query += " AND thumbnail_id IS NOT NULL"
@@ -343,3 +476,78 @@ func (r *FileRepository) Count(opts ...file.FileQueryOption) (uint64, error) {
 
 	return count, nil
 }
+
+func (r *FileRepository) OrphanThumbnails(before time.Time, limit int) ([]*file.File, error) {
+	nstmt, err := r.db.PrepareNamed(`
+		SELECT f.* FROM files f
+		WHERE f.is_thumbnail AND f.created_at < :before
+		AND NOT EXISTS (SELECT 1 FROM files o WHERE o.thumbnail_id = f.id)
+		ORDER BY f.created_at ASC
+		LIMIT :limit
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	var sqlFiles []models.SQLFile
+	err = nstmt.Select(&sqlFiles, map[string]interface{}{
+		"before": before.UTC(),
+		"limit":  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*file.File, 0, len(sqlFiles))
+	for _, sf := range sqlFiles {
+		f, err := sf.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+func (r *FileRepository) Usage(instanceID string) (*file.Usage, error) {
+	nstmt, err := r.db.PrepareNamed(`
+		SELECT
+			COALESCE(SUM(CASE WHEN is_thumbnail THEN 0 ELSE 1 END), 0) AS files,
+			COALESCE(SUM(size), 0) AS bytes
+		FROM files
+		WHERE instance_id = :instance_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	usage := file.Usage{InstanceID: instanceID}
+	err = nstmt.Get(&usage, map[string]interface{}{"instance_id": instanceID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+func (r *FileRepository) UsageByInstance() ([]*file.Usage, error) {
+	var usages []*file.Usage
+	err := r.db.Select(&usages, `
+		SELECT
+			instance_id,
+			COALESCE(SUM(CASE WHEN is_thumbnail THEN 0 ELSE 1 END), 0) AS files,
+			COALESCE(SUM(size), 0) AS bytes
+		FROM files
+		WHERE instance_id IS NOT NULL
+		GROUP BY instance_id
+		ORDER BY bytes DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return usages, nil
+}
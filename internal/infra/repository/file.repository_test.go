@@ -195,6 +195,175 @@ var _ = DescribeTableSubtree("FileRepository", func(driver string) {
 		Expect(err).To(MatchError(file.ErrFileNotFound))
 	})
 
+	It("should count references of a file", func() {
+		testFile := fake.FileFactory().Create()
+		Expect(repo.Insert(testFile)).To(Succeed())
+
+		f, _ := repo.Get(file.WhereID(testFile.ID))
+		Expect(f.References).To(Equal(uint32(1)))
+
+		Expect(repo.Reference(testFile.ID)).To(Succeed())
+		Expect(repo.Reference(testFile.ID)).To(Succeed())
+
+		f, _ = repo.Get(file.WhereID(testFile.ID))
+		Expect(f.References).To(Equal(uint32(3)))
+
+		for _, left := range []uint32{2, 1, 0} {
+			references, err := repo.Release(testFile.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(references).To(Equal(left))
+		}
+
+		_, err := repo.Release(testFile.ID)
+		Expect(err).To(MatchError(file.ErrFileNotFound))
+
+		_, err = repo.Release("non-existent-id")
+		Expect(err).To(MatchError(file.ErrFileNotFound))
+
+		Expect(repo.Reference("non-existent-id")).To(MatchError(file.ErrFileNotFound))
+	})
+
+	It("should reference the file of the instance with the same content instead of inserting it", func() {
+		inst := fake.InstanceFactory().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		first := fake.FileFactory().WithInstanceID(&inst.ID).Create()
+		stored, err := repo.InsertOrReference(first)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored.ID).To(Equal(first.ID))
+
+		same := fake.FileFactory().WithInstanceID(&inst.ID).WithSha256(first.Sha256).Create()
+		stored, err = repo.InsertOrReference(same)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored.ID).To(Equal(first.ID))
+		Expect(stored.References).To(Equal(uint32(2)))
+
+		Expect(repo.Count(file.WhereInstanceID(inst.ID))).To(Equal(uint64(1)))
+		Expect(repo.Insert(same)).ToNot(Succeed())
+
+		// another instance keeps its own copy
+		other := fake.InstanceFactory().Create()
+		Expect(instRepo.Insert(other)).To(Succeed())
+
+		copied := fake.FileFactory().WithInstanceID(&other.ID).WithSha256(first.Sha256).Create()
+		stored, err = repo.InsertOrReference(copied)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored.ID).To(Equal(copied.ID))
+	})
+
+	It("should keep the files of the same content stored before uploads were deduplicated", func() {
+		inst := fake.InstanceFactory().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		// back to before the content index, where an instance could store the same content twice
+		for {
+			applied := 0
+			Expect(db.Get(&applied, "SELECT COUNT(*) FROM migrations WHERE id = '021'")).To(Succeed())
+			if applied == 0 {
+				break
+			}
+			Expect(migrator.Down(1)).To(Succeed())
+		}
+
+		oldest := fake.FileFactory().WithInstanceID(&inst.ID).Create()
+		Expect(repo.Insert(oldest)).To(Succeed())
+		time.Sleep(10 * time.Millisecond)
+		newer := fake.FileFactory().WithInstanceID(&inst.ID).WithSha256(oldest.Sha256).Create()
+		Expect(repo.Insert(newer)).To(Succeed())
+
+		Expect(migrator.Up()).To(Succeed())
+
+		// clients may hold the ids of both
+		Expect(repo.Count(file.WhereInstanceID(inst.ID))).To(Equal(uint64(2)))
+
+		stored, err := repo.InsertOrReference(fake.FileFactory().WithInstanceID(&inst.ID).WithSha256(oldest.Sha256).Create())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored.ID).To(Equal(oldest.ID))
+		Expect(stored.References).To(Equal(uint32(2)))
+	})
+
+	It("should only delete a released file without references", func() {
+		testFile := fake.FileFactory().Create()
+		Expect(repo.Insert(testFile)).To(Succeed())
+
+		deleted, err := repo.DeleteReleased(testFile.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(BeFalse())
+
+		_, err = repo.Release(testFile.ID)
+		Expect(err).ToNot(HaveOccurred())
+
+		deleted, err = repo.DeleteReleased(testFile.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(BeTrue())
+
+		_, err = repo.Get(file.WhereID(testFile.ID))
+		Expect(err).To(MatchError(file.ErrFileNotFound))
+	})
+
+	It("should save whether a file is pinned", func() {
+		testFile := fake.FileFactory().Create()
+		Expect(repo.Insert(testFile)).To(Succeed())
+
+		testFile.Pinned = true
+		Expect(repo.Update(testFile)).To(Succeed())
+
+		f, err := repo.Get(file.WhereID(testFile.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Pinned).To(BeTrue())
+	})
+
+	It("should find thumbnails no file uses anymore", func() {
+		used := fake.FileFactory().Image().Create()
+		used.IsThumbnail = true
+		orphan := fake.FileFactory().Image().Create()
+		orphan.IsThumbnail = true
+		Expect(repo.InsertMany([]*file.File{used, orphan})).To(Succeed())
+
+		thumbnail, err := used.ToImageFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repo.Insert(fake.FileFactory().WithThumbnail(thumbnail).Create())).To(Succeed())
+
+		files, err := repo.OrphanThumbnails(time.Now().UTC().Add(time.Minute), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+		Expect(files[0].ID).To(Equal(orphan.ID))
+
+		files, err = repo.OrphanThumbnails(time.Now().UTC().Add(-time.Hour), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+
+	It("should sum the usage of each instance", func() {
+		instanceID := "instance-usage"
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID(instanceID).Create())).To(Succeed())
+
+		usage, err := repo.Usage(instanceID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(usage.InstanceID).To(Equal(instanceID))
+		Expect(usage.Files).To(BeZero())
+		Expect(usage.Bytes).To(BeZero())
+
+		thumbnail := fake.FileFactory().Image().WithSize(5).WithInstanceID(&instanceID).Create()
+		thumbnail.IsThumbnail = true
+		Expect(repo.InsertMany([]*file.File{
+			fake.FileFactory().WithSize(10).WithInstanceID(&instanceID).Create(),
+			fake.FileFactory().WithSize(20).WithInstanceID(&instanceID).Create(),
+			thumbnail,
+			fake.FileFactory().WithSize(100).Create(),
+		})).To(Succeed())
+
+		usage, err = repo.Usage(instanceID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(usage.Files).To(Equal(uint64(2)))
+		Expect(usage.Bytes).To(Equal(uint64(35)))
+
+		usages, err := repo.UsageByInstance()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(usages).To(HaveLen(1))
+		Expect(*usages[0]).To(Equal(*usage))
+	})
+
 	// Thumbnail relationship
 	It("should insert file with thumbnail", func() {
 		thumbFile := fake.FileFactory().Image().Create()
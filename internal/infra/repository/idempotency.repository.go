@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/idempotency"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type IdempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (r *IdempotencyRepository) Insert(rec *idempotency.Record) (bool, error) {
+	result, err := r.db.NamedExec(`
+		INSERT INTO idempotency_keys (
+			id, instance_id, idempotency_key, fingerprint, status, response_status, response_body, created_at, expires_at
+		) VALUES (
+			:id, :instance_id, :idempotency_key, :fingerprint, :status, :response_status, :response_body, :created_at, :expires_at
+		)
+		ON CONFLICT (instance_id, idempotency_key) DO NOTHING
+	`, models.FromIdempotencyRecordEntity(rec))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepository) Update(rec *idempotency.Record) error {
+	_, err := r.db.NamedExec(`
+		UPDATE idempotency_keys SET
+			status = :status,
+			response_status = :response_status,
+			response_body = :response_body,
+			expires_at = :expires_at
+		WHERE id = :id
+	`, models.FromIdempotencyRecordEntity(rec))
+	return err
+}
+
+func (r *IdempotencyRepository) Get(opts ...idempotency.IdempotencyQueryOption) (*idempotency.Record, error) {
+	queryOptions := &idempotency.IdempotencyQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM idempotency_keys WHERE 1=1`, queryOptions)
+	query += " LIMIT 1"
+
+	var sqlRecord models.SQLIdempotencyRecord
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlRecord, args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlRecord.ToEntity(), nil
+}
+
+func (r *IdempotencyRepository) Delete(opts ...idempotency.IdempotencyQueryOption) error {
+	queryOptions := &idempotency.IdempotencyQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`DELETE FROM idempotency_keys WHERE 1=1`, queryOptions)
+
+	_, err := r.db.NamedExec(query, args)
+	return err
+}
+
+func (r *IdempotencyRepository) DeleteExpired(before time.Time) (int64, error) {
+	result, err := r.db.NamedExec(`DELETE FROM idempotency_keys WHERE expires_at <= :before`, map[string]interface{}{
+		"before": before.UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *IdempotencyRepository) where(query string, queryOptions *idempotency.IdempotencyQueryOptions) (string, map[string]interface{}) {
+	args := map[string]interface{}{}
+
+	if queryOptions.ID != nil {
+		query += " AND id = :id"
+		args["id"] = *queryOptions.ID
+	}
+	if queryOptions.InstanceID != nil {
+		query += " AND instance_id = :instance_id"
+		args["instance_id"] = *queryOptions.InstanceID
+	}
+	if queryOptions.Key != nil {
+		query += " AND idempotency_key = :idempotency_key"
+		args["idempotency_key"] = *queryOptions.Key
+	}
+
+	return query, args
+}
@@ -0,0 +1,120 @@
+package repository_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/idempotency"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("IdempotencyRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     idempotency.IdempotencyRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewIdempotencyRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should store a key only once per instance", func() {
+		first := idempotency.New("instance-1", "order-42", "fingerprint", time.Hour)
+		stored, err := repo.Insert(first)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored).To(BeTrue())
+
+		stored, err = repo.Insert(idempotency.New("instance-1", "order-42", "fingerprint", time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored).To(BeFalse())
+
+		got, err := repo.Get(idempotency.WhereInstanceID("instance-1"), idempotency.WhereKey("order-42"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.ID).To(Equal(first.ID))
+		Expect(got.Status).To(Equal(idempotency.StatusProcessing))
+	})
+
+	It("should store the response", func() {
+		rec := idempotency.New("instance-1", "order-42", "fingerprint", time.Hour)
+		_, err := repo.Insert(rec)
+		Expect(err).ToNot(HaveOccurred())
+
+		rec.Complete(200, []byte(`{"success":true}`))
+		Expect(repo.Update(rec)).To(Succeed())
+
+		got, err := repo.Get(idempotency.WhereID(rec.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.IsCompleted()).To(BeTrue())
+		Expect(got.ResponseStatus).To(Equal(200))
+		Expect(got.ResponseBody).To(Equal([]byte(`{"success":true}`)))
+	})
+
+	It("should delete the expired keys", func() {
+		expired := idempotency.New("instance-1", "old", "fingerprint", time.Minute)
+		expired.ExpiresAt = time.Now().Add(-time.Minute).UTC()
+		_, err := repo.Insert(expired)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = repo.Insert(idempotency.New("instance-1", "new", "fingerprint", time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+
+		removed, err := repo.DeleteExpired(time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(removed).To(Equal(int64(1)))
+
+		got, err := repo.Get(idempotency.WhereKey("old"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+
+		Expect(repo.Delete(idempotency.WhereKey("new"))).To(Succeed())
+		got, err = repo.Get(idempotency.WhereKey("new"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
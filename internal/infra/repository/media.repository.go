@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type MediaPolicyRepository struct {
+	db *sqlx.DB
+}
+
+func NewMediaPolicyRepository(db *sqlx.DB) *MediaPolicyRepository {
+	return &MediaPolicyRepository{db: db}
+}
+
+func (r *MediaPolicyRepository) Save(policy *media.Policy) error {
+	sqlPolicy, err := models.FromMediaPolicyEntity(policy)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.NamedExec(`
+		INSERT INTO media_policies (
+			instance_id, enabled, types, max_size, chats, ignore_groups, created_at, updated_at
+		) VALUES (
+			:instance_id, :enabled, :types, :max_size, :chats, :ignore_groups, :created_at, :updated_at
+		)
+		ON CONFLICT (instance_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			types = excluded.types,
+			max_size = excluded.max_size,
+			chats = excluded.chats,
+			ignore_groups = excluded.ignore_groups,
+			updated_at = excluded.updated_at
+	`, sqlPolicy)
+	return err
+}
+
+func (r *MediaPolicyRepository) Get(instanceID string) (*media.Policy, error) {
+	var sqlPolicy models.SQLMediaPolicy
+	nstmt, err := r.db.PrepareNamed(`SELECT * FROM media_policies WHERE instance_id = :instance_id LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlPolicy, map[string]interface{}{"instance_id": instanceID})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlPolicy.ToEntity(), nil
+}
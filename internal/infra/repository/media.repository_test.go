@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("MediaPolicyRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     media.PolicyRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewMediaPolicyRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should return nil when the instance has no policy", func() {
+		got, err := repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+
+	It("should save and replace the policy of an instance", func() {
+		p := media.NewPolicy("instance-1", media.Policy{Enabled: true, MaxSize: 1024})
+		Expect(repo.Save(p)).To(Succeed())
+
+		got, err := repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Enabled).To(BeTrue())
+		Expect(got.Types).To(BeEmpty())
+		Expect(got.Chats).To(BeEmpty())
+
+		p.Types = []file.MediaType{file.MediaTypeImage, file.MediaTypeVideo}
+		p.Chats = []string{"5511999999999@s.whatsapp.net"}
+		p.IgnoreGroups = true
+		Expect(repo.Save(p)).To(Succeed())
+
+		got, err = repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.MaxSize).To(Equal(uint64(1024)))
+		Expect(got.Types).To(Equal([]file.MediaType{file.MediaTypeImage, file.MediaTypeVideo}))
+		Expect(got.Chats).To(Equal([]string{"5511999999999@s.whatsapp.net"}))
+		Expect(got.IgnoreGroups).To(BeTrue())
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
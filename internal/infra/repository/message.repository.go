@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type MessageRepository struct {
+	db *sqlx.DB
+}
+
+func NewMessageRepository(db *sqlx.DB) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+// insertMessage only fills in what a message stored again is missing, so the edits, deletions and receipts
+// already stored survive the history sync and retried events
+var insertMessage = `
+	INSERT INTO messages (
+		id, external_id, type, chat, sender, content, reply_to, status, expiration, is_from_me, is_forwarded,
+		forwarding_score, timestamp, sent_at, delivered_at, read_at, expires_at, instance_id, created_at, updated_at
+	) VALUES (
+		:id, :external_id, :type, :chat, :sender, :content, :reply_to, :status, :expiration, :is_from_me, :is_forwarded,
+		:forwarding_score, :timestamp, :sent_at, :delivered_at, :read_at, :expires_at, :instance_id, :created_at, :updated_at
+	)
+	ON CONFLICT (instance_id, chat, external_id) DO UPDATE SET
+		type = COALESCE(messages.type, excluded.type),
+		content = COALESCE(messages.content, excluded.content),
+		reply_to = COALESCE(messages.reply_to, excluded.reply_to),
+		status = CASE
+			WHEN messages.status = 'deleted' THEN messages.status
+			WHEN excluded.status = 'deleted' THEN excluded.status
+			WHEN ` + messageStatusRank("excluded") + ` > ` + messageStatusRank("messages") + ` THEN excluded.status
+			ELSE messages.status
+		END,
+		sent_at = COALESCE(messages.sent_at, excluded.sent_at),
+		delivered_at = COALESCE(messages.delivered_at, excluded.delivered_at),
+		read_at = COALESCE(messages.read_at, excluded.read_at),
+		updated_at = excluded.updated_at
+	RETURNING id`
+
+// messageStatusRank orders the statuses of the table like message.MessageStatus.IsAfter does, so a message
+// stored again, from the history sync or a retried event, never moves back
+func messageStatusRank(table string) string {
+	return `CASE ` + table + `.status
+		WHEN 'pending' THEN 1
+		WHEN 'sent' THEN 2
+		WHEN 'delivered' THEN 3
+		WHEN 'read' THEN 4
+		WHEN 'played' THEN 5
+		ELSE 0
+	END`
+}
+
+func (r *MessageRepository) Insert(m *message.Message) error {
+	sqlMessage, err := models.FromMessageEntity(m)
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.NamedQuery(insertMessage, sqlMessage)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// when the message was already stored the original id is kept, so the entity points to the stored row
+	if rows.Next() {
+		if err := rows.Scan(&m.ID); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *MessageRepository) InsertMany(messages []*message.Message) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareNamed(insertMessage)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, m := range messages {
+		sqlMessage, err := models.FromMessageEntity(m)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := stmt.QueryRowx(sqlMessage).Scan(&m.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *MessageRepository) Update(m *message.Message) error {
+	sqlMessage, err := models.FromMessageEntity(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.NamedExec(`
+		UPDATE messages SET
+			external_id = :external_id,
+			type = :type,
+			chat = :chat,
+			sender = :sender,
+			content = :content,
+			reply_to = :reply_to,
+			status = :status,
+			expiration = :expiration,
+			is_from_me = :is_from_me,
+			is_forwarded = :is_forwarded,
+			forwarding_score = :forwarding_score,
+			timestamp = :timestamp,
+			sent_at = :sent_at,
+			delivered_at = :delivered_at,
+			read_at = :read_at,
+			expires_at = :expires_at,
+			instance_id = :instance_id,
+			created_at = :created_at,
+			updated_at = :updated_at
+		WHERE id = :id
+	`, sqlMessage)
+	return err
+}
+
+func (r *MessageRepository) Get(opts ...message.MessageQueryOption) (*message.Message, error) {
+	queryOptions := &message.MessageQueryOptions{
+		OrderBy: "timestamp",
+		SortBy:  message.SortByDesc,
+	}
+
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM messages WHERE 1=1`, queryOptions)
+	query += " ORDER BY " + queryOptions.OrderBy + " " + string(queryOptions.SortBy)
+	query += " LIMIT 1"
+
+	var sqlMessage models.SQLMessage
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	err = nstmt.Get(&sqlMessage, args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlMessage.ToEntity()
+}
+
+func (r *MessageRepository) List(opts ...message.MessageQueryOption) ([]*message.Message, error) {
+	queryOptions := &message.MessageQueryOptions{
+		OrderBy: "timestamp",
+		SortBy:  message.SortByDesc,
+	}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM messages WHERE 1=1`, queryOptions)
+
+	// the id breaks ties between messages sent in the same millisecond, keeping the cursor stable
+	query += " ORDER BY " + queryOptions.OrderBy + " " + string(queryOptions.SortBy) + ", id " + string(queryOptions.SortBy)
+	if queryOptions.Limit != nil {
+		query += " LIMIT :limit"
+		args["limit"] = *queryOptions.Limit
+	}
+
+	var sqlMessages []models.SQLMessage
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	err = nstmt.Select(&sqlMessages, args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []*message.Message{}, nil
+		}
+		return nil, err
+	}
+
+	messages := make([]*message.Message, len(sqlMessages))
+	for i, sqlMessage := range sqlMessages {
+		m, err := sqlMessage.ToEntity()
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = m
+	}
+
+	return messages, nil
+}
+
+func (r *MessageRepository) Delete(opts ...message.MessageQueryOption) error {
+	queryOptions := &message.MessageQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`DELETE FROM messages WHERE 1=1`, queryOptions)
+
+	_, err := r.db.NamedExec(query, args)
+	return err
+}
+
+func (r *MessageRepository) Count(opts ...message.MessageQueryOption) (uint64, error) {
+	queryOptions := &message.MessageQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT COUNT(*) FROM messages WHERE 1=1`, queryOptions)
+
+	var count uint64
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return 0, err
+	}
+	err = nstmt.Get(&count, args)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *MessageRepository) where(query string, queryOptions *message.MessageQueryOptions) (string, map[string]interface{}) {
+	args := map[string]interface{}{}
+
+	if queryOptions.ID != nil {
+		query += " AND id = :id"
+		args["id"] = *queryOptions.ID
+	}
+	if queryOptions.ExternalID != nil {
+		query += " AND external_id = :external_id"
+		args["external_id"] = *queryOptions.ExternalID
+	}
+	if queryOptions.Chat != nil {
+		query += " AND chat = :chat"
+		args["chat"] = *queryOptions.Chat
+	}
+	if queryOptions.InstanceID != nil {
+		query += " AND instance_id = :instance_id"
+		args["instance_id"] = *queryOptions.InstanceID
+	}
+	if queryOptions.Before != nil {
+		query += " AND (timestamp < :before_timestamp OR (timestamp = :before_timestamp AND id < :before_id))"
+		args["before_timestamp"] = queryOptions.Before.Timestamp
+		args["before_id"] = queryOptions.Before.ID
+	}
+
+	return query, args
+}
@@ -0,0 +1,205 @@
+package repository_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	"github.com/mauriciorobertodev/whappy-go/internal/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("MessageRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     message.MessageRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	newMessage := func(externalID string, chat string, sentAt time.Time) *message.Message {
+		m := message.NewMessage(&externalID, "me@s.whatsapp.net", chat, message.NewTextContent("hello "+externalID, nil), utils.StringPtr("instance-1"), nil, true)
+		m.MarkAsSent(sentAt)
+		return m
+	}
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewMessageRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should insert and find a message by ID", func() {
+		m := newMessage("3EB0000000000001", "123@s.whatsapp.net", time.Now())
+		m.ReplyTo = &message.ReplyTo{ID: "3EB0000000000000", Sender: "123@s.whatsapp.net"}
+
+		Expect(repo.Insert(m)).To(Succeed())
+
+		got, err := repo.Get(message.WhereID(m.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.ID).To(Equal(m.ID))
+		Expect(*got.ExternalID).To(Equal("3EB0000000000001"))
+		Expect(got.Type).To(Equal(message.MessageKindText))
+		Expect(got.Status).To(Equal(message.MessageStatusSent))
+		Expect(got.Content).To(Equal(m.Content))
+		Expect(got.ReplyTo.ID).To(Equal("3EB0000000000000"))
+		Expect(*got.SentAt).To(BeTemporally("~", *m.SentAt, time.Second))
+	})
+
+	It("should not duplicate a message delivered twice", func() {
+		first := newMessage("3EB0000000000001", "123@s.whatsapp.net", time.Now())
+		Expect(repo.Insert(first)).To(Succeed())
+
+		again := newMessage("3EB0000000000001", "123@s.whatsapp.net", time.Now())
+		again.SetText("edited")
+		Expect(repo.Insert(again)).To(Succeed())
+		Expect(again.ID).To(Equal(first.ID))
+
+		count, err := repo.Count(message.WhereInstanceID("instance-1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(uint64(1)))
+
+		got, err := repo.Get(message.WhereExternalID("3EB0000000000001"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Content.(message.TextContent).Text).To(Equal("hello 3EB0000000000001"))
+	})
+
+	It("should keep the stored status and content when a message is stored again", func() {
+		read := newMessage("3EB0000000000001", "123@s.whatsapp.net", time.Now())
+		read.ApplyReceipt(message.MessageStatusRead, time.Now())
+		Expect(repo.Insert(read)).To(Succeed())
+
+		deleted := newMessage("3EB0000000000002", "123@s.whatsapp.net", time.Now())
+		Expect(repo.Insert(deleted)).To(Succeed())
+		deleted.MarkAsDeleted()
+		Expect(repo.Update(deleted)).To(Succeed())
+
+		edited := newMessage("3EB0000000000003", "123@s.whatsapp.net", time.Now())
+		Expect(repo.Insert(edited)).To(Succeed())
+		edited.SetText("edited")
+		Expect(repo.Update(edited)).To(Succeed())
+
+		history := []*message.Message{
+			newMessage("3EB0000000000001", "123@s.whatsapp.net", time.Now()),
+			newMessage("3EB0000000000002", "123@s.whatsapp.net", time.Now()),
+			newMessage("3EB0000000000003", "123@s.whatsapp.net", time.Now()),
+		}
+		history[0].ApplyReceipt(message.MessageStatusDelivered, time.Now())
+		Expect(repo.InsertMany(history)).To(Succeed())
+
+		got, err := repo.Get(message.WhereID(read.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Status).To(Equal(message.MessageStatusRead))
+		Expect(got.ReadAt).ToNot(BeNil())
+
+		got, err = repo.Get(message.WhereID(deleted.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Status).To(Equal(message.MessageStatusDeleted))
+
+		got, err = repo.Get(message.WhereID(edited.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Content.(message.TextContent).Text).To(Equal("edited"))
+	})
+
+	It("should update a message", func() {
+		m := newMessage("3EB0000000000001", "123@s.whatsapp.net", time.Now())
+		Expect(repo.Insert(m)).To(Succeed())
+
+		m.MarkAsDeleted()
+		Expect(repo.Update(m)).To(Succeed())
+
+		got, err := repo.Get(message.WhereID(m.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Status).To(Equal(message.MessageStatusDeleted))
+	})
+
+	It("should return nil when the message does not exist", func() {
+		got, err := repo.Get(message.WhereID("unknown"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+
+	It("should paginate the chat history with a cursor", func() {
+		now := time.Now()
+		for i, id := range []string{"A1", "A2", "A3", "A4", "A5"} {
+			Expect(repo.Insert(newMessage(id, "123@s.whatsapp.net", now.Add(time.Duration(i)*time.Minute)))).To(Succeed())
+		}
+		Expect(repo.Insert(newMessage("B1", "456@s.whatsapp.net", now))).To(Succeed())
+
+		page, err := repo.List(message.WhereInstanceID("instance-1"), message.WhereChat("123@s.whatsapp.net"), message.Limit(2))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(HaveLen(2))
+		Expect(*page[0].ExternalID).To(Equal("A5"))
+		Expect(*page[1].ExternalID).To(Equal("A4"))
+
+		cursor := message.NewCursor(page[1])
+		decoded, err := message.DecodeCursor(cursor.Encode())
+		Expect(err).ToNot(HaveOccurred())
+
+		page, err = repo.List(message.WhereInstanceID("instance-1"), message.WhereChat("123@s.whatsapp.net"), message.WhereBefore(*decoded), message.Limit(10))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(HaveLen(3))
+		Expect(*page[0].ExternalID).To(Equal("A3"))
+		Expect(*page[2].ExternalID).To(Equal("A1"))
+	})
+
+	It("should store each receipt once and list them in order", func() {
+		m := newMessage("3EB0000000000001", "123@g.us", time.Now())
+		Expect(repo.Insert(m)).To(Succeed())
+
+		receiptRepo := repository.NewReceiptRepository(db)
+		now := time.Now()
+
+		Expect(receiptRepo.Insert(message.NewReceipt(m.ID, "111@s.whatsapp.net", message.MessageStatusRead, now.Add(time.Second)))).To(Succeed())
+		Expect(receiptRepo.Insert(message.NewReceipt(m.ID, "111@s.whatsapp.net", message.MessageStatusDelivered, now))).To(Succeed())
+		Expect(receiptRepo.Insert(message.NewReceipt(m.ID, "111@s.whatsapp.net", message.MessageStatusDelivered, now))).To(Succeed())
+
+		receipts, err := receiptRepo.ListByMessage(m.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receipts).To(HaveLen(2))
+		Expect(receipts[0].Status).To(Equal(message.MessageStatusDelivered))
+		Expect(receipts[1].Status).To(Equal(message.MessageStatusRead))
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
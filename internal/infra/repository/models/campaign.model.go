@@ -0,0 +1,118 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+type SQLCampaign struct {
+	ID         string     `db:"id"`
+	InstanceID string     `db:"instance_id"`
+	Name       string     `db:"name"`
+	Kind       string     `db:"kind"`
+	Payload    string     `db:"payload"` // json body of the send endpoint, without "to"
+	Pacing     string     `db:"pacing"`  // json
+	Status     string     `db:"status"`
+	Dispatched int        `db:"dispatched"`
+	NextSendAt time.Time  `db:"next_send_at"`
+	Version    int        `db:"version"`
+	StartedAt  time.Time  `db:"started_at"`
+	FinishedAt *time.Time `db:"finished_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+}
+
+func (s *SQLCampaign) ToEntity() *campaign.Campaign {
+	var pacing campaign.Pacing
+	_ = json.Unmarshal([]byte(s.Pacing), &pacing)
+
+	return &campaign.Campaign{
+		ID:         s.ID,
+		InstanceID: s.InstanceID,
+		Name:       s.Name,
+		Kind:       message.MessageKind(s.Kind),
+		Payload:    json.RawMessage(s.Payload),
+		Pacing:     pacing,
+		Status:     campaign.Status(s.Status),
+		Dispatched: s.Dispatched,
+		NextSendAt: s.NextSendAt.UTC(),
+		Version:    s.Version,
+		StartedAt:  s.StartedAt.UTC(),
+		FinishedAt: utcOrNil(s.FinishedAt),
+		CreatedAt:  s.CreatedAt.UTC(),
+		UpdatedAt:  s.UpdatedAt.UTC(),
+	}
+}
+
+func FromCampaignEntity(ent *campaign.Campaign) (*SQLCampaign, error) {
+	pacing, err := json.Marshal(ent.Pacing)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLCampaign{
+		ID:         ent.ID,
+		InstanceID: ent.InstanceID,
+		Name:       ent.Name,
+		Kind:       string(ent.Kind),
+		Payload:    string(ent.Payload),
+		Pacing:     string(pacing),
+		Status:     string(ent.Status),
+		Dispatched: ent.Dispatched,
+		NextSendAt: ent.NextSendAt.UTC(),
+		Version:    ent.Version,
+		StartedAt:  ent.StartedAt.UTC(),
+		FinishedAt: utcOrNil(ent.FinishedAt),
+		CreatedAt:  ent.CreatedAt.UTC(),
+		UpdatedAt:  ent.UpdatedAt.UTC(),
+	}, nil
+}
+
+type SQLCampaignRecipient struct {
+	ID         string     `db:"id"`
+	CampaignID string     `db:"campaign_id"`
+	Original   string     `db:"original"`
+	Recipient  string     `db:"recipient"`
+	Status     string     `db:"status"`
+	MessageID  *string    `db:"message_id"`
+	ExternalID *string    `db:"external_id"`
+	Error      *string    `db:"error"`
+	SentAt     *time.Time `db:"sent_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+}
+
+func (s *SQLCampaignRecipient) ToEntity() *campaign.Recipient {
+	return &campaign.Recipient{
+		ID:         s.ID,
+		CampaignID: s.CampaignID,
+		Original:   s.Original,
+		To:         s.Recipient,
+		Status:     campaign.RecipientStatus(s.Status),
+		MessageID:  s.MessageID,
+		ExternalID: s.ExternalID,
+		Error:      s.Error,
+		SentAt:     utcOrNil(s.SentAt),
+		CreatedAt:  s.CreatedAt.UTC(),
+		UpdatedAt:  s.UpdatedAt.UTC(),
+	}
+}
+
+func FromCampaignRecipientEntity(ent *campaign.Recipient) *SQLCampaignRecipient {
+	return &SQLCampaignRecipient{
+		ID:         ent.ID,
+		CampaignID: ent.CampaignID,
+		Original:   ent.Original,
+		Recipient:  ent.To,
+		Status:     string(ent.Status),
+		MessageID:  ent.MessageID,
+		ExternalID: ent.ExternalID,
+		Error:      ent.Error,
+		SentAt:     utcOrNil(ent.SentAt),
+		CreatedAt:  ent.CreatedAt.UTC(),
+		UpdatedAt:  ent.UpdatedAt.UTC(),
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+)
+
+type SQLChat struct {
+	ID            string     `db:"id"`
+	JID           string     `db:"jid"`
+	Name          *string    `db:"name"`
+	UnreadCount   uint32     `db:"unread_count"`
+	Archived      bool       `db:"archived"`
+	Pinned        bool       `db:"pinned"`
+	MutedUntil    *time.Time `db:"muted_until"`
+	Expiration    *uint32    `db:"expiration"`
+	LastMessageAt *time.Time `db:"last_message_at"`
+	InstanceID    string     `db:"instance_id"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+}
+
+func (s *SQLChat) ToEntity() *chat.Chat {
+	return &chat.Chat{
+		ID:            s.ID,
+		JID:           s.JID,
+		Name:          s.Name,
+		UnreadCount:   s.UnreadCount,
+		Archived:      s.Archived,
+		Pinned:        s.Pinned,
+		MutedUntil:    utcOrNil(s.MutedUntil),
+		Expiration:    s.Expiration,
+		LastMessageAt: utcOrNil(s.LastMessageAt),
+		InstanceID:    s.InstanceID,
+		CreatedAt:     s.CreatedAt.UTC(),
+		UpdatedAt:     s.UpdatedAt.UTC(),
+	}
+}
+
+func FromChatEntity(ent *chat.Chat) *SQLChat {
+	return &SQLChat{
+		ID:            ent.ID,
+		JID:           ent.JID,
+		Name:          ent.Name,
+		UnreadCount:   ent.UnreadCount,
+		Archived:      ent.Archived,
+		Pinned:        ent.Pinned,
+		MutedUntil:    utcOrNil(ent.MutedUntil),
+		Expiration:    ent.Expiration,
+		LastMessageAt: utcOrNil(ent.LastMessageAt),
+		InstanceID:    ent.InstanceID,
+		CreatedAt:     ent.CreatedAt.UTC(),
+		UpdatedAt:     ent.UpdatedAt.UTC(),
+	}
+}
@@ -83,6 +83,12 @@ type SQLFile struct {
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 
+	Refs uint32 `db:"refs"`
+
+	Pinned      bool `db:"pinned"`
+	IsThumbnail bool `db:"is_thumbnail"`
+	IsDuplicate bool `db:"is_duplicate"` // stored before uploads were deduplicated, out of the content index
+
 	InstanceID  *string `db:"instance_id"`
 	ThumbnailID *string `db:"thumbnail_id"`
 
@@ -125,6 +131,11 @@ func (s *SQLFile) ToEntity() (*file.File, error) {
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
 
+		References: s.Refs,
+
+		Pinned:      s.Pinned,
+		IsThumbnail: s.IsThumbnail,
+
 		InstanceID: s.InstanceID,
 		Thumbnail:  thumbnail,
 	}, nil
@@ -173,6 +184,11 @@ func FromFileEntity(file *file.File) (*SQLFile, error) {
 		CreatedAt: *createdAt,
 		UpdatedAt: *updatedAt,
 
+		Refs: max(file.References, 1),
+
+		Pinned:      file.Pinned,
+		IsThumbnail: file.IsThumbnail,
+
 		InstanceID:  file.InstanceID,
 		ThumbnailID: thumbnailID,
 	}, nil
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/idempotency"
+)
+
+type SQLIdempotencyRecord struct {
+	ID             string    `db:"id"`
+	InstanceID     string    `db:"instance_id"`
+	Key            string    `db:"idempotency_key"`
+	Fingerprint    string    `db:"fingerprint"`
+	Status         string    `db:"status"`
+	ResponseStatus int       `db:"response_status"`
+	ResponseBody   []byte    `db:"response_body"`
+	CreatedAt      time.Time `db:"created_at"`
+	ExpiresAt      time.Time `db:"expires_at"`
+}
+
+func (s *SQLIdempotencyRecord) ToEntity() *idempotency.Record {
+	return &idempotency.Record{
+		ID:             s.ID,
+		InstanceID:     s.InstanceID,
+		Key:            s.Key,
+		Fingerprint:    s.Fingerprint,
+		Status:         idempotency.Status(s.Status),
+		ResponseStatus: s.ResponseStatus,
+		ResponseBody:   s.ResponseBody,
+		CreatedAt:      s.CreatedAt.UTC(),
+		ExpiresAt:      s.ExpiresAt.UTC(),
+	}
+}
+
+func FromIdempotencyRecordEntity(ent *idempotency.Record) *SQLIdempotencyRecord {
+	return &SQLIdempotencyRecord{
+		ID:             ent.ID,
+		InstanceID:     ent.InstanceID,
+		Key:            ent.Key,
+		Fingerprint:    ent.Fingerprint,
+		Status:         string(ent.Status),
+		ResponseStatus: ent.ResponseStatus,
+		ResponseBody:   ent.ResponseBody,
+		CreatedAt:      ent.CreatedAt.UTC(),
+		ExpiresAt:      ent.ExpiresAt.UTC(),
+	}
+}
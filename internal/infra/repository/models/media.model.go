@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/media"
+)
+
+type SQLMediaPolicy struct {
+	InstanceID   string    `db:"instance_id"`
+	Enabled      bool      `db:"enabled"`
+	Types        string    `db:"types"`
+	MaxSize      int64     `db:"max_size"`
+	Chats        string    `db:"chats"`
+	IgnoreGroups bool      `db:"ignore_groups"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+func (s *SQLMediaPolicy) ToEntity() *media.Policy {
+	types := []file.MediaType{}
+	_ = json.Unmarshal([]byte(s.Types), &types)
+
+	chats := []string{}
+	_ = json.Unmarshal([]byte(s.Chats), &chats)
+
+	return &media.Policy{
+		InstanceID:   s.InstanceID,
+		Enabled:      s.Enabled,
+		Types:        types,
+		MaxSize:      uint64(s.MaxSize),
+		Chats:        chats,
+		IgnoreGroups: s.IgnoreGroups,
+		CreatedAt:    s.CreatedAt.UTC(),
+		UpdatedAt:    s.UpdatedAt.UTC(),
+	}
+}
+
+func FromMediaPolicyEntity(ent *media.Policy) (*SQLMediaPolicy, error) {
+	types, err := json.Marshal(nonNil(ent.Types))
+	if err != nil {
+		return nil, err
+	}
+
+	chats, err := json.Marshal(nonNil(ent.Chats))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLMediaPolicy{
+		InstanceID:   ent.InstanceID,
+		Enabled:      ent.Enabled,
+		Types:        string(types),
+		MaxSize:      int64(ent.MaxSize),
+		Chats:        string(chats),
+		IgnoreGroups: ent.IgnoreGroups,
+		CreatedAt:    ent.CreatedAt.UTC(),
+		UpdatedAt:    ent.UpdatedAt.UTC(),
+	}, nil
+}
+
+// nonNil keeps empty lists as [] in the database instead of null
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
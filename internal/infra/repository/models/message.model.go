@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+type SQLMessage struct {
+	ID              string     `db:"id"`
+	ExternalID      string     `db:"external_id"`
+	Type            string     `db:"type"`
+	Chat            string     `db:"chat"`
+	Sender          string     `db:"sender"`
+	Content         *string    `db:"content"`  // json encoded content, decoded according to the type
+	ReplyTo         *string    `db:"reply_to"` // json encoded reply reference
+	Status          string     `db:"status"`
+	Expiration      *uint32    `db:"expiration"`
+	IsFromMe        bool       `db:"is_from_me"`
+	IsForwarded     bool       `db:"is_forwarded"`
+	ForwardingScore uint32     `db:"forwarding_score"`
+	Timestamp       int64      `db:"timestamp"` // unix milliseconds, used to sort and paginate the history
+	SentAt          *time.Time `db:"sent_at"`
+	DeliveredAt     *time.Time `db:"delivered_at"`
+	ReadAt          *time.Time `db:"read_at"`
+	ExpiresAt       *time.Time `db:"expires_at"`
+	InstanceID      string     `db:"instance_id"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+}
+
+func (s *SQLMessage) ToEntity() (*message.Message, error) {
+	m := message.Message{
+		ID:              s.ID,
+		Type:            message.MessageKind(s.Type),
+		Chat:            s.Chat,
+		Sender:          s.Sender,
+		Status:          message.MessageStatus(s.Status),
+		Expiration:      s.Expiration,
+		IsFromMe:        s.IsFromMe,
+		IsForwarded:     s.IsForwarded,
+		ForwardingScore: s.ForwardingScore,
+		SentAt:          utcOrNil(s.SentAt),
+		DeliveredAt:     utcOrNil(s.DeliveredAt),
+		ReadAt:          utcOrNil(s.ReadAt),
+		ExpiresAt:       utcOrNil(s.ExpiresAt),
+		InstanceID:      &s.InstanceID,
+		ExternalID:      &s.ExternalID,
+		CreatedAt:       s.CreatedAt.UTC(),
+		UpdatedAt:       s.UpdatedAt.UTC(),
+	}
+
+	if s.Content != nil {
+		content, err := message.DecodeContent(m.Type, []byte(*s.Content))
+		if err != nil {
+			return nil, err
+		}
+		m.Content = content
+	}
+
+	if s.ReplyTo != nil {
+		var reply message.ReplyTo
+		if err := json.Unmarshal([]byte(*s.ReplyTo), &reply); err != nil {
+			return nil, err
+		}
+		m.ReplyTo = &reply
+	}
+
+	return &m, nil
+}
+
+func FromMessageEntity(ent *message.Message) (*SQLMessage, error) {
+	s := &SQLMessage{
+		ID:              ent.ID,
+		Type:            string(ent.Type),
+		Chat:            ent.Chat,
+		Sender:          ent.Sender,
+		Status:          string(ent.Status),
+		Expiration:      ent.Expiration,
+		IsFromMe:        ent.IsFromMe,
+		IsForwarded:     ent.IsForwarded,
+		ForwardingScore: ent.ForwardingScore,
+		Timestamp:       ent.Timestamp().UnixMilli(),
+		SentAt:          utcOrNil(ent.SentAt),
+		DeliveredAt:     utcOrNil(ent.DeliveredAt),
+		ReadAt:          utcOrNil(ent.ReadAt),
+		ExpiresAt:       utcOrNil(ent.ExpiresAt),
+		CreatedAt:       ent.CreatedAt.UTC(),
+		UpdatedAt:       ent.UpdatedAt.UTC(),
+	}
+
+	if ent.ExternalID != nil {
+		s.ExternalID = *ent.ExternalID
+	}
+
+	if ent.InstanceID != nil {
+		s.InstanceID = *ent.InstanceID
+	}
+
+	if ent.Content != nil {
+		data, err := json.Marshal(ent.Content)
+		if err != nil {
+			return nil, err
+		}
+		content := string(data)
+		s.Content = &content
+	}
+
+	if ent.ReplyTo != nil {
+		data, err := json.Marshal(ent.ReplyTo)
+		if err != nil {
+			return nil, err
+		}
+		reply := string(data)
+		s.ReplyTo = &reply
+	}
+
+	return s, nil
+}
+
+func utcOrNil(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
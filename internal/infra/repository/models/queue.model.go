@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+)
+
+type SQLQueuedMessage struct {
+	ID            string     `db:"id"`
+	InstanceID    string     `db:"instance_id"`
+	Chat          string     `db:"chat"`
+	Kind          string     `db:"kind"`
+	Payload       string     `db:"payload"` // json body of the send endpoint
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	LastError     *string    `db:"last_error"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	MessageID     *string    `db:"message_id"`
+	ExternalID    *string    `db:"external_id"`
+	SentAt        *time.Time `db:"sent_at"`
+	ScheduledAt   *time.Time `db:"scheduled_at"`
+	Timezone      *string    `db:"timezone"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+}
+
+func (s *SQLQueuedMessage) ToEntity() *queue.QueuedMessage {
+	return &queue.QueuedMessage{
+		ID:            s.ID,
+		InstanceID:    s.InstanceID,
+		Chat:          s.Chat,
+		Kind:          message.MessageKind(s.Kind),
+		Payload:       json.RawMessage(s.Payload),
+		Status:        queue.Status(s.Status),
+		Attempts:      s.Attempts,
+		LastError:     s.LastError,
+		NextAttemptAt: s.NextAttemptAt.UTC(),
+		MessageID:     s.MessageID,
+		ExternalID:    s.ExternalID,
+		SentAt:        utcOrNil(s.SentAt),
+		ScheduledAt:   utcOrNil(s.ScheduledAt),
+		Timezone:      s.Timezone,
+		CreatedAt:     s.CreatedAt.UTC(),
+		UpdatedAt:     s.UpdatedAt.UTC(),
+	}
+}
+
+func FromQueuedMessageEntity(ent *queue.QueuedMessage) *SQLQueuedMessage {
+	return &SQLQueuedMessage{
+		ID:            ent.ID,
+		InstanceID:    ent.InstanceID,
+		Chat:          ent.Chat,
+		Kind:          string(ent.Kind),
+		Payload:       string(ent.Payload),
+		Status:        string(ent.Status),
+		Attempts:      ent.Attempts,
+		LastError:     ent.LastError,
+		NextAttemptAt: ent.NextAttemptAt.UTC(),
+		MessageID:     ent.MessageID,
+		ExternalID:    ent.ExternalID,
+		SentAt:        utcOrNil(ent.SentAt),
+		ScheduledAt:   utcOrNil(ent.ScheduledAt),
+		Timezone:      ent.Timezone,
+		CreatedAt:     ent.CreatedAt.UTC(),
+		UpdatedAt:     ent.UpdatedAt.UTC(),
+	}
+}
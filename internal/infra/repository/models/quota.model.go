@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+)
+
+type SQLQuota struct {
+	InstanceID string    `db:"instance_id"`
+	MaxBytes   int64     `db:"max_bytes"`
+	MaxFiles   int64     `db:"max_files"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (s *SQLQuota) ToEntity() *quota.Quota {
+	return &quota.Quota{
+		InstanceID: s.InstanceID,
+		MaxBytes:   uint64(s.MaxBytes),
+		MaxFiles:   uint64(s.MaxFiles),
+		CreatedAt:  s.CreatedAt.UTC(),
+		UpdatedAt:  s.UpdatedAt.UTC(),
+	}
+}
+
+func FromQuotaEntity(ent *quota.Quota) *SQLQuota {
+	return &SQLQuota{
+		InstanceID: ent.InstanceID,
+		MaxBytes:   int64(ent.MaxBytes),
+		MaxFiles:   int64(ent.MaxFiles),
+		CreatedAt:  ent.CreatedAt.UTC(),
+		UpdatedAt:  ent.UpdatedAt.UTC(),
+	}
+}
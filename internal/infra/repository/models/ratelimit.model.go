@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+)
+
+type SQLRateLimit struct {
+	InstanceID          string    `db:"instance_id"`
+	PerSecond           int       `db:"per_second"`
+	PerMinute           int       `db:"per_minute"`
+	RecipientCooldownMs int       `db:"recipient_cooldown_ms"`
+	MinDelayMs          int       `db:"min_delay_ms"`
+	MaxDelayMs          int       `db:"max_delay_ms"`
+	Typing              bool      `db:"typing"`
+	OnLimit             string    `db:"on_limit"`
+	CreatedAt           time.Time `db:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at"`
+}
+
+func (s *SQLRateLimit) ToEntity() *ratelimit.Policy {
+	return &ratelimit.Policy{
+		InstanceID:          s.InstanceID,
+		PerSecond:           s.PerSecond,
+		PerMinute:           s.PerMinute,
+		RecipientCooldownMs: s.RecipientCooldownMs,
+		MinDelayMs:          s.MinDelayMs,
+		MaxDelayMs:          s.MaxDelayMs,
+		Typing:              s.Typing,
+		OnLimit:             ratelimit.Action(s.OnLimit),
+		CreatedAt:           s.CreatedAt.UTC(),
+		UpdatedAt:           s.UpdatedAt.UTC(),
+	}
+}
+
+func FromRateLimitEntity(ent *ratelimit.Policy) *SQLRateLimit {
+	return &SQLRateLimit{
+		InstanceID:          ent.InstanceID,
+		PerSecond:           ent.PerSecond,
+		PerMinute:           ent.PerMinute,
+		RecipientCooldownMs: ent.RecipientCooldownMs,
+		MinDelayMs:          ent.MinDelayMs,
+		MaxDelayMs:          ent.MaxDelayMs,
+		Typing:              ent.Typing,
+		OnLimit:             string(ent.OnLimit),
+		CreatedAt:           ent.CreatedAt.UTC(),
+		UpdatedAt:           ent.UpdatedAt.UTC(),
+	}
+}
+
+type SQLRateLimitState struct {
+	InstanceID      string    `db:"instance_id"`
+	PerSecond       int       `db:"per_second"`
+	PerSecondTokens float64   `db:"per_second_tokens"`
+	PerMinute       int       `db:"per_minute"`
+	PerMinuteTokens float64   `db:"per_minute_tokens"`
+	RefilledAt      time.Time `db:"refilled_at"`
+	Version         int       `db:"version"`
+}
+
+func (s *SQLRateLimitState) ToEntity() *ratelimit.State {
+	return &ratelimit.State{
+		InstanceID:      s.InstanceID,
+		PerSecond:       s.PerSecond,
+		PerSecondTokens: s.PerSecondTokens,
+		PerMinute:       s.PerMinute,
+		PerMinuteTokens: s.PerMinuteTokens,
+		RefilledAt:      s.RefilledAt.UTC(),
+		Version:         s.Version,
+	}
+}
+
+func FromRateLimitStateEntity(ent *ratelimit.State) *SQLRateLimitState {
+	return &SQLRateLimitState{
+		InstanceID:      ent.InstanceID,
+		PerSecond:       ent.PerSecond,
+		PerSecondTokens: ent.PerSecondTokens,
+		PerMinute:       ent.PerMinute,
+		PerMinuteTokens: ent.PerMinuteTokens,
+		RefilledAt:      ent.RefilledAt.UTC(),
+		Version:         ent.Version,
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+)
+
+type SQLReceipt struct {
+	ID          string    `db:"id"`
+	MessageID   string    `db:"message_id"`
+	Participant string    `db:"participant"`
+	Status      string    `db:"status"`
+	Timestamp   time.Time `db:"timestamp"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (s *SQLReceipt) ToEntity() *message.Receipt {
+	return &message.Receipt{
+		ID:          s.ID,
+		MessageID:   s.MessageID,
+		Participant: s.Participant,
+		Status:      message.MessageStatus(s.Status),
+		Timestamp:   s.Timestamp.UTC(),
+		CreatedAt:   s.CreatedAt.UTC(),
+	}
+}
+
+func FromReceiptEntity(ent *message.Receipt) *SQLReceipt {
+	return &SQLReceipt{
+		ID:          ent.ID,
+		MessageID:   ent.MessageID,
+		Participant: ent.Participant,
+		Status:      string(ent.Status),
+		Timestamp:   ent.Timestamp.UTC(),
+		CreatedAt:   ent.CreatedAt.UTC(),
+	}
+}
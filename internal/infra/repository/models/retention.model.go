@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/retention"
+)
+
+type SQLRetentionRule struct {
+	InstanceID string    `db:"instance_id"`
+	Enabled    bool      `db:"enabled"`
+	MaxAge     int64     `db:"max_age"`
+	MaxBytes   int64     `db:"max_bytes"`
+	MaxCount   int64     `db:"max_count"`
+	KeepPinned bool      `db:"keep_pinned"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (s *SQLRetentionRule) ToEntity() *retention.Rule {
+	return &retention.Rule{
+		InstanceID: s.InstanceID,
+		Enabled:    s.Enabled,
+		MaxAge:     uint64(s.MaxAge),
+		MaxBytes:   uint64(s.MaxBytes),
+		MaxCount:   uint64(s.MaxCount),
+		KeepPinned: s.KeepPinned,
+		CreatedAt:  s.CreatedAt.UTC(),
+		UpdatedAt:  s.UpdatedAt.UTC(),
+	}
+}
+
+func FromRetentionRuleEntity(ent *retention.Rule) *SQLRetentionRule {
+	return &SQLRetentionRule{
+		InstanceID: ent.InstanceID,
+		Enabled:    ent.Enabled,
+		MaxAge:     int64(ent.MaxAge),
+		MaxBytes:   int64(ent.MaxBytes),
+		MaxCount:   int64(ent.MaxCount),
+		KeepPinned: ent.KeepPinned,
+		CreatedAt:  ent.CreatedAt.UTC(),
+		UpdatedAt:  ent.UpdatedAt.UTC(),
+	}
+}
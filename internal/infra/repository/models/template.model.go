@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/template"
+)
+
+type SQLTemplate struct {
+	ID         string    `db:"id"`
+	InstanceID string    `db:"instance_id"`
+	Name       string    `db:"name"`
+	Kind       string    `db:"kind"`
+	Body       string    `db:"body"`
+	Media      *string   `db:"media"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (s *SQLTemplate) ToEntity() *template.Template {
+	t := &template.Template{
+		ID:         s.ID,
+		InstanceID: s.InstanceID,
+		Name:       s.Name,
+		Kind:       message.MessageKind(s.Kind),
+		Body:       s.Body,
+		Media:      s.Media,
+		CreatedAt:  s.CreatedAt.UTC(),
+		UpdatedAt:  s.UpdatedAt.UTC(),
+	}
+	t.Variables = t.ListVariables()
+
+	return t
+}
+
+func FromTemplateEntity(ent *template.Template) *SQLTemplate {
+	return &SQLTemplate{
+		ID:         ent.ID,
+		InstanceID: ent.InstanceID,
+		Name:       ent.Name,
+		Kind:       string(ent.Kind),
+		Body:       ent.Body,
+		Media:      ent.Media,
+		CreatedAt:  ent.CreatedAt.UTC(),
+		UpdatedAt:  ent.UpdatedAt.UTC(),
+	}
+}
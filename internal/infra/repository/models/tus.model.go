@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+)
+
+type SQLTusUpload struct {
+	ID          string     `db:"id"`
+	InstanceID  string     `db:"instance_id"`
+	Length      int64      `db:"upload_length"`
+	Offset      int64      `db:"upload_offset"`
+	Metadata    string     `db:"metadata"`
+	Mime        string     `db:"mime"`
+	Path        string     `db:"path"`
+	MultipartID string     `db:"multipart_id"`
+	Parts       string     `db:"parts"`
+	Pending     int64      `db:"pending"`
+	HashState   []byte     `db:"hash_state"`
+	FileID      *string    `db:"file_id"`
+	Claim       string     `db:"claim"`
+	ClaimedAt   *time.Time `db:"claimed_at"`
+	ExpiresAt   time.Time  `db:"expires_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+func (s *SQLTusUpload) ToEntity() *tus.Upload {
+	metadata := map[string]string{}
+	_ = json.Unmarshal([]byte(s.Metadata), &metadata)
+
+	parts := []tus.Part{}
+	_ = json.Unmarshal([]byte(s.Parts), &parts)
+
+	return &tus.Upload{
+		ID:          s.ID,
+		InstanceID:  s.InstanceID,
+		Length:      uint64(s.Length),
+		Offset:      uint64(s.Offset),
+		Metadata:    metadata,
+		Mime:        s.Mime,
+		Path:        s.Path,
+		MultipartID: s.MultipartID,
+		Parts:       parts,
+		Pending:     uint64(s.Pending),
+		HashState:   s.HashState,
+		FileID:      s.FileID,
+		Claim:       s.Claim,
+		ClaimedAt:   utcOrNil(s.ClaimedAt),
+		ExpiresAt:   s.ExpiresAt.UTC(),
+		UpdatedAt:   s.UpdatedAt.UTC(),
+		CreatedAt:   s.CreatedAt.UTC(),
+	}
+}
+
+func FromTusUploadEntity(ent *tus.Upload) (*SQLTusUpload, error) {
+	metadata := ent.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	encodedMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := json.Marshal(nonNil(ent.Parts))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLTusUpload{
+		ID:          ent.ID,
+		InstanceID:  ent.InstanceID,
+		Length:      int64(ent.Length),
+		Offset:      int64(ent.Offset),
+		Metadata:    string(encodedMetadata),
+		Mime:        ent.Mime,
+		Path:        ent.Path,
+		MultipartID: ent.MultipartID,
+		Parts:       string(parts),
+		Pending:     int64(ent.Pending),
+		HashState:   ent.HashState,
+		FileID:      ent.FileID,
+		Claim:       ent.Claim,
+		ClaimedAt:   utcOrNil(ent.ClaimedAt),
+		ExpiresAt:   ent.ExpiresAt.UTC(),
+		UpdatedAt:   ent.UpdatedAt.UTC(),
+		CreatedAt:   ent.CreatedAt.UTC(),
+	}, nil
+}
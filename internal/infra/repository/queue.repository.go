@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type QueueRepository struct {
+	db *sqlx.DB
+}
+
+func NewQueueRepository(db *sqlx.DB) *QueueRepository {
+	return &QueueRepository{db: db}
+}
+
+func (r *QueueRepository) Insert(q *queue.QueuedMessage) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO message_queue (
+			id, instance_id, chat, kind, payload, status, attempts, last_error, next_attempt_at, message_id, external_id, sent_at, scheduled_at, timezone, created_at, updated_at
+		) VALUES (
+			:id, :instance_id, :chat, :kind, :payload, :status, :attempts, :last_error, :next_attempt_at, :message_id, :external_id, :sent_at, :scheduled_at, :timezone, :created_at, :updated_at
+		)
+	`, models.FromQueuedMessageEntity(q))
+	return err
+}
+
+const updateQueuedMessage = `
+	UPDATE message_queue SET
+		chat = :chat,
+		kind = :kind,
+		payload = :payload,
+		status = :status,
+		attempts = :attempts,
+		last_error = :last_error,
+		next_attempt_at = :next_attempt_at,
+		message_id = :message_id,
+		external_id = :external_id,
+		sent_at = :sent_at,
+		scheduled_at = :scheduled_at,
+		timezone = :timezone,
+		updated_at = :updated_at
+	WHERE id = :id`
+
+func (r *QueueRepository) Update(q *queue.QueuedMessage) error {
+	_, err := r.db.NamedExec(updateQueuedMessage, models.FromQueuedMessageEntity(q))
+	return err
+}
+
+func (r *QueueRepository) UpdateFrom(q *queue.QueuedMessage, from queue.Status) (bool, error) {
+	res, err := r.db.NamedExec(updateQueuedMessage+" AND status = :from_status", struct {
+		*models.SQLQueuedMessage
+		FromStatus string `db:"from_status"`
+	}{models.FromQueuedMessageEntity(q), string(from)})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *QueueRepository) Ready(now time.Time, limit int) ([]*queue.QueuedMessage, error) {
+	// a pending message with an older one still pending in the same chat is not ready, whatever its due time,
+	// a scheduled message is placed in the chat by its scheduled time so it never holds the messages before it
+	query := `
+		SELECT q.* FROM message_queue q
+		WHERE q.status = :queued AND q.next_attempt_at <= :now
+		AND NOT EXISTS (
+			SELECT 1 FROM message_queue p
+			WHERE p.instance_id = q.instance_id AND p.chat = q.chat
+			AND p.status IN (:queued, :sending)
+			AND (
+				COALESCE(p.scheduled_at, p.created_at) < COALESCE(q.scheduled_at, q.created_at)
+				OR (COALESCE(p.scheduled_at, p.created_at) = COALESCE(q.scheduled_at, q.created_at) AND p.id < q.id)
+			)
+		)
+		ORDER BY q.next_attempt_at ASC, q.id ASC
+		LIMIT :limit`
+
+	args := map[string]interface{}{
+		"queued":  string(queue.StatusQueued),
+		"sending": string(queue.StatusSending),
+		"now":     now.UTC(),
+		"limit":   limit,
+	}
+
+	var sqlQueued []models.SQLQueuedMessage
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Select(&sqlQueued, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return toQueuedEntities(sqlQueued), nil
+}
+
+func (r *QueueRepository) Release(before time.Time) (int64, error) {
+	res, err := r.db.NamedExec(`
+		UPDATE message_queue SET status = :queued, updated_at = :now
+		WHERE status = :sending AND updated_at < :before
+	`, map[string]interface{}{
+		"queued":  string(queue.StatusQueued),
+		"sending": string(queue.StatusSending),
+		"now":     time.Now().UTC(),
+		"before":  before.UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *QueueRepository) Renew(id string, now time.Time) (bool, error) {
+	res, err := r.db.NamedExec(`
+		UPDATE message_queue SET updated_at = :now
+		WHERE id = :id AND status = :sending
+	`, map[string]interface{}{
+		"id":      id,
+		"sending": string(queue.StatusSending),
+		"now":     now.UTC(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *QueueRepository) Get(opts ...queue.QueueQueryOption) (*queue.QueuedMessage, error) {
+	queryOptions := &queue.QueueQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM message_queue WHERE 1=1`, queryOptions)
+	query += " LIMIT 1"
+
+	var sqlQueued models.SQLQueuedMessage
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlQueued, args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlQueued.ToEntity(), nil
+}
+
+func (r *QueueRepository) List(opts ...queue.QueueQueryOption) ([]*queue.QueuedMessage, error) {
+	queryOptions := &queue.QueueQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM message_queue WHERE 1=1`, queryOptions)
+	query += " ORDER BY COALESCE(scheduled_at, created_at) ASC, id ASC"
+	if queryOptions.Limit != nil {
+		query += " LIMIT :limit"
+		args["limit"] = *queryOptions.Limit
+	}
+
+	var sqlQueued []models.SQLQueuedMessage
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Select(&sqlQueued, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return toQueuedEntities(sqlQueued), nil
+}
+
+func (r *QueueRepository) where(query string, queryOptions *queue.QueueQueryOptions) (string, map[string]interface{}) {
+	args := map[string]interface{}{}
+
+	if queryOptions.ID != nil {
+		query += " AND id = :id"
+		args["id"] = *queryOptions.ID
+	}
+	if queryOptions.InstanceID != nil {
+		query += " AND instance_id = :instance_id"
+		args["instance_id"] = *queryOptions.InstanceID
+	}
+	if queryOptions.Status != nil {
+		query += " AND status = :status"
+		args["status"] = string(*queryOptions.Status)
+	}
+	if queryOptions.Scheduled != nil {
+		if *queryOptions.Scheduled {
+			query += " AND scheduled_at IS NOT NULL"
+		} else {
+			query += " AND scheduled_at IS NULL"
+		}
+	}
+
+	return query, args
+}
+
+func toQueuedEntities(sqlQueued []models.SQLQueuedMessage) []*queue.QueuedMessage {
+	queued := make([]*queue.QueuedMessage, len(sqlQueued))
+	for i, s := range sqlQueued {
+		queued[i] = s.ToEntity()
+	}
+	return queued
+}
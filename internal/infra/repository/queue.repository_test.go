@@ -0,0 +1,198 @@
+package repository_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/queue"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("QueueRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     queue.QueueRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	newQueued := func(chat string, createdAt time.Time) *queue.QueuedMessage {
+		q := queue.New("instance-1", chat, message.MessageKindText, []byte(`{"to":"`+chat+`","text":"hi"}`))
+		q.CreatedAt = createdAt
+		q.NextAttemptAt = createdAt
+		return q
+	}
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewQueueRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should insert and find a queued message", func() {
+		q := newQueued("123@s.whatsapp.net", time.Now())
+		Expect(repo.Insert(q)).To(Succeed())
+
+		got, err := repo.Get(queue.WhereInstanceID("instance-1"), queue.WhereID(q.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Kind).To(Equal(message.MessageKindText))
+		Expect(got.Status).To(Equal(queue.StatusQueued))
+		Expect(string(got.Payload)).To(MatchJSON(`{"to":"123@s.whatsapp.net","text":"hi"}`))
+	})
+
+	It("should only return the head of each chat as ready", func() {
+		now := time.Now().Add(-time.Minute)
+		first := newQueued("123@s.whatsapp.net", now)
+		second := newQueued("123@s.whatsapp.net", now.Add(time.Second))
+		other := newQueued("456@s.whatsapp.net", now.Add(2*time.Second))
+		for _, q := range []*queue.QueuedMessage{first, second, other} {
+			Expect(repo.Insert(q)).To(Succeed())
+		}
+
+		ready, err := repo.Ready(time.Now(), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(HaveLen(2))
+		Expect(ready[0].ID).To(Equal(first.ID))
+		Expect(ready[1].ID).To(Equal(other.ID))
+
+		// while the head is waiting for a retry the rest of the chat waits too
+		first.MarkSending()
+		first.Retry(errors.New("timeout"), queue.DefaultMaxAttempts)
+		Expect(repo.Update(first)).To(Succeed())
+
+		ready, err = repo.Ready(time.Now(), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(HaveLen(1))
+		Expect(ready[0].ID).To(Equal(other.ID))
+
+		Expect(first.Cancel()).To(Succeed())
+		Expect(repo.Update(first)).To(Succeed())
+
+		ready, err = repo.Ready(time.Now(), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(HaveLen(2))
+		Expect(ready[0].ID).To(Equal(second.ID))
+	})
+
+	It("should keep a scheduled message from holding the chat before its time", func() {
+		now := time.Now().Add(-time.Minute)
+		scheduled := queue.NewScheduled("instance-1", "123@s.whatsapp.net", message.MessageKindText, []byte(`{"to":"123@s.whatsapp.net","text":"later"}`), time.Now().Add(time.Hour), "America/Sao_Paulo")
+		scheduled.CreatedAt = now
+		queued := newQueued("123@s.whatsapp.net", now.Add(time.Second))
+		for _, q := range []*queue.QueuedMessage{scheduled, queued} {
+			Expect(repo.Insert(q)).To(Succeed())
+		}
+
+		ready, err := repo.Ready(time.Now(), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(HaveLen(1))
+		Expect(ready[0].ID).To(Equal(queued.ID))
+
+		ready, err = repo.Ready(time.Now().Add(2*time.Hour), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(HaveLen(1))
+		Expect(ready[0].ID).To(Equal(queued.ID))
+
+		list, err := repo.List(queue.WhereInstanceID("instance-1"), queue.WhereScheduled(true))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].ID).To(Equal(scheduled.ID))
+		Expect(*list[0].Timezone).To(Equal("America/Sao_Paulo"))
+		Expect(*list[0].ScheduledAt).To(BeTemporally("~", *scheduled.ScheduledAt, time.Second))
+	})
+
+	It("should claim a queued message only once", func() {
+		q := newQueued("123@s.whatsapp.net", time.Now())
+		Expect(repo.Insert(q)).To(Succeed())
+
+		q.MarkSending()
+		claimed, err := repo.UpdateFrom(q, queue.StatusQueued)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(claimed).To(BeTrue())
+
+		claimed, err = repo.UpdateFrom(q, queue.StatusQueued)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(claimed).To(BeFalse())
+	})
+
+	It("should release messages left as sending", func() {
+		q := newQueued("123@s.whatsapp.net", time.Now())
+		q.MarkSending()
+		q.UpdatedAt = time.Now().Add(-time.Hour)
+		Expect(repo.Insert(q)).To(Succeed())
+
+		released, err := repo.Release(time.Now().Add(-time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(released).To(Equal(int64(1)))
+
+		got, err := repo.Get(queue.WhereID(q.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Status).To(Equal(queue.StatusQueued))
+	})
+
+	It("should not release a message whose claim was renewed", func() {
+		q := newQueued("123@s.whatsapp.net", time.Now())
+		q.MarkSending()
+		q.UpdatedAt = time.Now().Add(-time.Hour)
+		Expect(repo.Insert(q)).To(Succeed())
+
+		renewed, err := repo.Renew(q.ID, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(renewed).To(BeTrue())
+
+		released, err := repo.Release(time.Now().Add(-time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(released).To(BeZero())
+
+		q.Fail(errors.New("not on whatsapp"))
+		Expect(repo.Update(q)).To(Succeed())
+
+		renewed, err = repo.Renew(q.ID, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(renewed).To(BeFalse())
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
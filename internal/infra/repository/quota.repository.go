@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type QuotaRepository struct {
+	db *sqlx.DB
+}
+
+func NewQuotaRepository(db *sqlx.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+func (r *QuotaRepository) Save(q *quota.Quota) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO storage_quotas (
+			instance_id, max_bytes, max_files, created_at, updated_at
+		) VALUES (
+			:instance_id, :max_bytes, :max_files, :created_at, :updated_at
+		)
+		ON CONFLICT (instance_id) DO UPDATE SET
+			max_bytes = excluded.max_bytes,
+			max_files = excluded.max_files,
+			updated_at = excluded.updated_at
+	`, models.FromQuotaEntity(q))
+	return err
+}
+
+func (r *QuotaRepository) Get(instanceID string) (*quota.Quota, error) {
+	var sqlQuota models.SQLQuota
+	nstmt, err := r.db.PrepareNamed(`SELECT * FROM storage_quotas WHERE instance_id = :instance_id LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlQuota, map[string]interface{}{"instance_id": instanceID})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlQuota.ToEntity(), nil
+}
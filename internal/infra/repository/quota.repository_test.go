@@ -0,0 +1,88 @@
+package repository_test
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/quota"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("QuotaRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     quota.QuotaRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewQuotaRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should return nil when the instance has no quota", func() {
+		got, err := repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+
+	It("should save and replace the quota of an instance", func() {
+		q := quota.NewQuota("instance-1", quota.Quota{MaxBytes: 1024})
+		Expect(repo.Save(q)).To(Succeed())
+
+		got, err := repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.MaxBytes).To(Equal(uint64(1024)))
+		Expect(got.MaxFiles).To(BeZero())
+
+		q.MaxFiles = 10
+		Expect(repo.Save(q)).To(Succeed())
+
+		got, err = repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.MaxBytes).To(Equal(uint64(1024)))
+		Expect(got.MaxFiles).To(Equal(uint64(10)))
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
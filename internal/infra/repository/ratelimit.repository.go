@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type RateLimitRepository struct {
+	db *sqlx.DB
+}
+
+func NewRateLimitRepository(db *sqlx.DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db}
+}
+
+func (r *RateLimitRepository) Save(policy *ratelimit.Policy) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO rate_limits (
+			instance_id, per_second, per_minute, recipient_cooldown_ms, min_delay_ms, max_delay_ms, typing, on_limit, created_at, updated_at
+		) VALUES (
+			:instance_id, :per_second, :per_minute, :recipient_cooldown_ms, :min_delay_ms, :max_delay_ms, :typing, :on_limit, :created_at, :updated_at
+		)
+		ON CONFLICT (instance_id) DO UPDATE SET
+			per_second = excluded.per_second,
+			per_minute = excluded.per_minute,
+			recipient_cooldown_ms = excluded.recipient_cooldown_ms,
+			min_delay_ms = excluded.min_delay_ms,
+			max_delay_ms = excluded.max_delay_ms,
+			typing = excluded.typing,
+			on_limit = excluded.on_limit,
+			updated_at = excluded.updated_at
+	`, models.FromRateLimitEntity(policy))
+	return err
+}
+
+func (r *RateLimitRepository) Get(instanceID string) (*ratelimit.Policy, error) {
+	var sqlRateLimit models.SQLRateLimit
+	nstmt, err := r.db.PrepareNamed(`SELECT * FROM rate_limits WHERE instance_id = :instance_id LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlRateLimit, map[string]interface{}{"instance_id": instanceID})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlRateLimit.ToEntity(), nil
+}
+
+func (r *RateLimitRepository) GetState(instanceID string) (*ratelimit.State, error) {
+	var sqlState models.SQLRateLimitState
+	nstmt, err := r.db.PrepareNamed(`SELECT * FROM rate_limit_states WHERE instance_id = :instance_id LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlState, map[string]interface{}{"instance_id": instanceID})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlState.ToEntity(), nil
+}
+
+func (r *RateLimitRepository) SaveState(state *ratelimit.State) (bool, error) {
+	// a state never stored is inserted, when another replica inserted it first nothing is inserted
+	query := `
+		INSERT INTO rate_limit_states (
+			instance_id, per_second, per_second_tokens, per_minute, per_minute_tokens, refilled_at, version
+		) VALUES (
+			:instance_id, :per_second, :per_second_tokens, :per_minute, :per_minute_tokens, :refilled_at, 1
+		)
+		ON CONFLICT (instance_id) DO NOTHING`
+	if state.Version > 0 {
+		query = `
+			UPDATE rate_limit_states SET
+				per_second = :per_second,
+				per_second_tokens = :per_second_tokens,
+				per_minute = :per_minute,
+				per_minute_tokens = :per_minute_tokens,
+				refilled_at = :refilled_at,
+				version = version + 1
+			WHERE instance_id = :instance_id AND version = :version`
+	}
+
+	res, err := r.db.NamedExec(query, models.FromRateLimitStateEntity(state))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected != 1 {
+		return false, nil
+	}
+
+	state.Version++
+	return true, nil
+}
+
+func (r *RateLimitRepository) LastSent(instanceID string, chat string) (*time.Time, error) {
+	var sentAt time.Time
+	nstmt, err := r.db.PrepareNamed(`SELECT sent_at FROM rate_limit_sends WHERE instance_id = :instance_id AND chat = :chat LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sentAt, map[string]interface{}{"instance_id": instanceID, "chat": chat})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	sentAt = sentAt.UTC()
+	return &sentAt, nil
+}
+
+func (r *RateLimitRepository) MarkSent(instanceID string, chat string, at time.Time, since time.Time) (bool, error) {
+	args := map[string]interface{}{
+		"instance_id": instanceID,
+		"chat":        chat,
+		"sent_at":     at.UTC(),
+		"since":       since.UTC(),
+	}
+
+	if _, err := r.db.NamedExec(`DELETE FROM rate_limit_sends WHERE instance_id = :instance_id AND sent_at <= :since`, args); err != nil {
+		return false, err
+	}
+
+	res, err := r.db.NamedExec(`
+		INSERT INTO rate_limit_sends (instance_id, chat, sent_at) VALUES (:instance_id, :chat, :sent_at)
+		ON CONFLICT (instance_id, chat) DO UPDATE SET sent_at = excluded.sent_at
+		WHERE rate_limit_sends.sent_at <= :since
+	`, args)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *RateLimitRepository) ForgetState(instanceID string) error {
+	args := map[string]interface{}{"instance_id": instanceID}
+
+	if _, err := r.db.NamedExec(`DELETE FROM rate_limit_sends WHERE instance_id = :instance_id`, args); err != nil {
+		return err
+	}
+
+	_, err := r.db.NamedExec(`DELETE FROM rate_limit_states WHERE instance_id = :instance_id`, args)
+	return err
+}
@@ -0,0 +1,132 @@
+package repository_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/ratelimit"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("RateLimitRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     ratelimit.PolicyRepository
+		states   ratelimit.StateRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewRateLimitRepository(db)
+		states = repository.NewRateLimitRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should return nil when the instance has no policy", func() {
+		got, err := repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+
+	It("should save and replace the policy of an instance", func() {
+		p := ratelimit.NewPolicy("instance-1", ratelimit.Policy{PerSecond: 1, PerMinute: 20})
+		Expect(repo.Save(p)).To(Succeed())
+
+		p.PerMinute = 30
+		p.Typing = true
+		p.OnLimit = ratelimit.ActionQueue
+		p.UpdatedAt = time.Now().UTC()
+		Expect(repo.Save(p)).To(Succeed())
+
+		got, err := repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.PerSecond).To(Equal(1))
+		Expect(got.PerMinute).To(Equal(30))
+		Expect(got.Typing).To(BeTrue())
+		Expect(got.OnLimit).To(Equal(ratelimit.ActionQueue))
+	})
+
+	It("should only save a state nobody saved since it was read", func() {
+		state := ratelimit.NewState("instance-1")
+		state.PerSecond, state.PerSecondTokens, state.RefilledAt = 2, 1.5, time.Now().UTC()
+
+		Expect(states.SaveState(state)).To(BeTrue())
+		Expect(states.SaveState(ratelimit.NewState("instance-1"))).To(BeFalse())
+
+		stale, err := states.GetState("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stale.PerSecondTokens).To(Equal(1.5))
+
+		state.PerSecondTokens = 0.5
+		Expect(states.SaveState(state)).To(BeTrue())
+		Expect(states.SaveState(stale)).To(BeFalse())
+
+		got, err := states.GetState("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.PerSecondTokens).To(Equal(0.5))
+		Expect(got.Version).To(Equal(2))
+	})
+
+	It("should only mark a send to a chat outside the cooldown", func() {
+		now := time.Now().UTC()
+
+		Expect(states.MarkSent("instance-1", "1@s.whatsapp.net", now, now.Add(-time.Minute))).To(BeTrue())
+		Expect(states.MarkSent("instance-1", "1@s.whatsapp.net", now, now.Add(-time.Minute))).To(BeFalse())
+
+		last, err := states.LastSent("instance-1", "1@s.whatsapp.net")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*last).To(BeTemporally("~", now, time.Millisecond))
+
+		later := now.Add(2 * time.Minute)
+		Expect(states.MarkSent("instance-1", "1@s.whatsapp.net", later, later.Add(-time.Minute))).To(BeTrue())
+
+		Expect(states.ForgetState("instance-1")).To(Succeed())
+		last, err = states.LastSent("instance-1", "1@s.whatsapp.net")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(last).To(BeNil())
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
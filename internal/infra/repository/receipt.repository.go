@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type ReceiptRepository struct {
+	db *sqlx.DB
+}
+
+func NewReceiptRepository(db *sqlx.DB) *ReceiptRepository {
+	return &ReceiptRepository{db: db}
+}
+
+func (r *ReceiptRepository) Insert(receipt *message.Receipt) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO message_receipts (id, message_id, participant, status, timestamp, created_at)
+		VALUES (:id, :message_id, :participant, :status, :timestamp, :created_at)
+		ON CONFLICT (message_id, participant, status) DO NOTHING
+	`, models.FromReceiptEntity(receipt))
+	return err
+}
+
+func (r *ReceiptRepository) ListByMessage(messageID string) ([]*message.Receipt, error) {
+	var sqlReceipts []models.SQLReceipt
+	nstmt, err := r.db.PrepareNamed(`SELECT * FROM message_receipts WHERE message_id = :message_id ORDER BY timestamp ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Select(&sqlReceipts, map[string]interface{}{"message_id": messageID})
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]*message.Receipt, len(sqlReceipts))
+	for i, sqlReceipt := range sqlReceipts {
+		receipts[i] = sqlReceipt.ToEntity()
+	}
+
+	return receipts, nil
+}
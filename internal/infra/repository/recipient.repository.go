@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type CampaignRecipientRepository struct {
+	db *sqlx.DB
+}
+
+func NewCampaignRecipientRepository(db *sqlx.DB) *CampaignRecipientRepository {
+	return &CampaignRecipientRepository{db: db}
+}
+
+func (r *CampaignRecipientRepository) InsertMany(recipients []*campaign.Recipient) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareNamed(`
+		INSERT INTO campaign_recipients (
+			id, campaign_id, original, recipient, status, message_id, external_id, error, sent_at, created_at, updated_at
+		) VALUES (
+			:id, :campaign_id, :original, :recipient, :status, :message_id, :external_id, :error, :sent_at, :created_at, :updated_at
+		)
+	`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, recipient := range recipients {
+		if _, err := stmt.Exec(models.FromCampaignRecipientEntity(recipient)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+const updateCampaignRecipient = `
+	UPDATE campaign_recipients SET
+		status = :status,
+		message_id = :message_id,
+		external_id = :external_id,
+		error = :error,
+		sent_at = :sent_at,
+		updated_at = :updated_at
+	WHERE id = :id`
+
+func (r *CampaignRecipientRepository) Update(recipient *campaign.Recipient) error {
+	_, err := r.db.NamedExec(updateCampaignRecipient, models.FromCampaignRecipientEntity(recipient))
+	return err
+}
+
+func (r *CampaignRecipientRepository) UpdateFrom(recipient *campaign.Recipient, from campaign.RecipientStatus) (bool, error) {
+	res, err := r.db.NamedExec(updateCampaignRecipient+" AND status = :from_status", struct {
+		*models.SQLCampaignRecipient
+		FromStatus string `db:"from_status"`
+	}{models.FromCampaignRecipientEntity(recipient), string(from)})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *CampaignRecipientRepository) Next(campaignID string) (*campaign.Recipient, error) {
+	var sqlRecipient models.SQLCampaignRecipient
+	nstmt, err := r.db.PrepareNamed(`
+		SELECT * FROM campaign_recipients
+		WHERE campaign_id = :campaign_id AND status = :pending
+		ORDER BY id ASC
+		LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlRecipient, map[string]interface{}{
+		"campaign_id": campaignID,
+		"pending":     string(campaign.RecipientPending),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlRecipient.ToEntity(), nil
+}
+
+func (r *CampaignRecipientRepository) Release(before time.Time) (int64, error) {
+	res, err := r.db.NamedExec(`
+		UPDATE campaign_recipients SET status = :pending, updated_at = :now
+		WHERE status = :sending AND updated_at < :before
+	`, map[string]interface{}{
+		"pending": string(campaign.RecipientPending),
+		"sending": string(campaign.RecipientSending),
+		"now":     time.Now().UTC(),
+		"before":  before.UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *CampaignRecipientRepository) Renew(id string, now time.Time) (bool, error) {
+	res, err := r.db.NamedExec(`
+		UPDATE campaign_recipients SET updated_at = :now
+		WHERE id = :id AND status = :sending
+	`, map[string]interface{}{
+		"id":      id,
+		"sending": string(campaign.RecipientSending),
+		"now":     now.UTC(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *CampaignRecipientRepository) Skip(campaignID string) (int64, error) {
+	res, err := r.db.NamedExec(`
+		UPDATE campaign_recipients SET status = :skipped, updated_at = :now
+		WHERE campaign_id = :campaign_id AND status = :pending
+	`, map[string]interface{}{
+		"skipped":     string(campaign.RecipientSkipped),
+		"pending":     string(campaign.RecipientPending),
+		"campaign_id": campaignID,
+		"now":         time.Now().UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *CampaignRecipientRepository) Progress(campaignID string) (*campaign.Progress, error) {
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+
+	nstmt, err := r.db.PrepareNamed(`
+		SELECT status, COUNT(*) AS count FROM campaign_recipients
+		WHERE campaign_id = :campaign_id
+		GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Select(&rows, map[string]interface{}{"campaign_id": campaignID})
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &campaign.Progress{}
+	for _, row := range rows {
+		progress.Add(campaign.RecipientStatus(row.Status), row.Count)
+	}
+
+	return progress, nil
+}
+
+func (r *CampaignRecipientRepository) List(opts ...campaign.RecipientQueryOption) ([]*campaign.Recipient, error) {
+	queryOptions := &campaign.RecipientQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query := `SELECT * FROM campaign_recipients WHERE 1=1`
+	args := map[string]interface{}{}
+
+	if queryOptions.CampaignID != nil {
+		query += " AND campaign_id = :campaign_id"
+		args["campaign_id"] = *queryOptions.CampaignID
+	}
+	if queryOptions.Status != nil {
+		query += " AND status = :status"
+		args["status"] = string(*queryOptions.Status)
+	}
+	if queryOptions.After != nil {
+		query += " AND id > :after"
+		args["after"] = *queryOptions.After
+	}
+
+	query += " ORDER BY id ASC"
+	if queryOptions.Limit != nil {
+		query += " LIMIT :limit"
+		args["limit"] = *queryOptions.Limit
+	}
+
+	var sqlRecipients []models.SQLCampaignRecipient
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Select(&sqlRecipients, args)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := make([]*campaign.Recipient, len(sqlRecipients))
+	for i, s := range sqlRecipients {
+		recipients[i] = s.ToEntity()
+	}
+
+	return recipients, nil
+}
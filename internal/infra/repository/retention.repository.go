@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/retention"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type RetentionRuleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRetentionRuleRepository(db *sqlx.DB) *RetentionRuleRepository {
+	return &RetentionRuleRepository{db: db}
+}
+
+func (r *RetentionRuleRepository) Save(rule *retention.Rule) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO retention_rules (
+			instance_id, enabled, max_age, max_bytes, max_count, keep_pinned, created_at, updated_at
+		) VALUES (
+			:instance_id, :enabled, :max_age, :max_bytes, :max_count, :keep_pinned, :created_at, :updated_at
+		)
+		ON CONFLICT (instance_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			max_age = excluded.max_age,
+			max_bytes = excluded.max_bytes,
+			max_count = excluded.max_count,
+			keep_pinned = excluded.keep_pinned,
+			updated_at = excluded.updated_at
+	`, models.FromRetentionRuleEntity(rule))
+	return err
+}
+
+func (r *RetentionRuleRepository) Get(instanceID string) (*retention.Rule, error) {
+	var sqlRule models.SQLRetentionRule
+	nstmt, err := r.db.PrepareNamed(`SELECT * FROM retention_rules WHERE instance_id = :instance_id LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlRule, map[string]interface{}{"instance_id": instanceID})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlRule.ToEntity(), nil
+}
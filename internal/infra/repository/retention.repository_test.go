@@ -0,0 +1,92 @@
+package repository_test
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/retention"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("RetentionRuleRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     retention.RuleRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewRetentionRuleRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should return nil when the instance has no rule", func() {
+		got, err := repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+
+	It("should save and replace the rule of an instance", func() {
+		r := retention.NewRule("instance-1", retention.Rule{Enabled: true, MaxAge: 3600, KeepPinned: true})
+		Expect(repo.Save(r)).To(Succeed())
+
+		got, err := repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Enabled).To(BeTrue())
+		Expect(got.MaxAge).To(Equal(uint64(3600)))
+		Expect(got.KeepPinned).To(BeTrue())
+
+		r.MaxBytes = 1 << 30
+		r.MaxCount = 100
+		r.KeepPinned = false
+		Expect(repo.Save(r)).To(Succeed())
+
+		got, err = repo.Get("instance-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.MaxBytes).To(Equal(uint64(1 << 30)))
+		Expect(got.MaxCount).To(Equal(uint64(100)))
+		Expect(got.KeepPinned).To(BeFalse())
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
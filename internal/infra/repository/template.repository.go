@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/template"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type TemplateRepository struct {
+	db *sqlx.DB
+}
+
+func NewTemplateRepository(db *sqlx.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+func (r *TemplateRepository) Insert(t *template.Template) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO message_templates (
+			id, instance_id, name, kind, body, media, created_at, updated_at
+		) VALUES (
+			:id, :instance_id, :name, :kind, :body, :media, :created_at, :updated_at
+		)
+	`, models.FromTemplateEntity(t))
+	return err
+}
+
+func (r *TemplateRepository) Update(t *template.Template) error {
+	_, err := r.db.NamedExec(`
+		UPDATE message_templates SET
+			name = :name,
+			body = :body,
+			media = :media,
+			updated_at = :updated_at
+		WHERE id = :id
+	`, models.FromTemplateEntity(t))
+	return err
+}
+
+func (r *TemplateRepository) Get(opts ...template.TemplateQueryOption) (*template.Template, error) {
+	queryOptions := &template.TemplateQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM message_templates WHERE 1=1`, queryOptions)
+	query += " LIMIT 1"
+
+	var sqlTemplate models.SQLTemplate
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Get(&sqlTemplate, args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlTemplate.ToEntity(), nil
+}
+
+func (r *TemplateRepository) List(opts ...template.TemplateQueryOption) ([]*template.Template, error) {
+	queryOptions := &template.TemplateQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM message_templates WHERE 1=1`, queryOptions)
+	query += " ORDER BY name ASC"
+
+	var sqlTemplates []models.SQLTemplate
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	err = nstmt.Select(&sqlTemplates, args)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]*template.Template, len(sqlTemplates))
+	for i, s := range sqlTemplates {
+		templates[i] = s.ToEntity()
+	}
+
+	return templates, nil
+}
+
+func (r *TemplateRepository) Delete(opts ...template.TemplateQueryOption) error {
+	queryOptions := &template.TemplateQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`DELETE FROM message_templates WHERE 1=1`, queryOptions)
+
+	_, err := r.db.NamedExec(query, args)
+	return err
+}
+
+func (r *TemplateRepository) where(query string, queryOptions *template.TemplateQueryOptions) (string, map[string]interface{}) {
+	args := map[string]interface{}{}
+
+	if queryOptions.ID != nil {
+		query += " AND id = :id"
+		args["id"] = *queryOptions.ID
+	}
+	if queryOptions.InstanceID != nil {
+		query += " AND instance_id = :instance_id"
+		args["instance_id"] = *queryOptions.InstanceID
+	}
+	if queryOptions.Name != nil {
+		query += " AND name = :name"
+		args["name"] = *queryOptions.Name
+	}
+
+	return query, args
+}
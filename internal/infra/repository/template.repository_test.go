@@ -0,0 +1,113 @@
+package repository_test
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/template"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("TemplateRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     template.TemplateRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewTemplateRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should insert and find a template", func() {
+		media := "https://example.com/{{product}}.png"
+		t := template.New("instance-1", "promo", message.MessageKindImage, "{{product}} on sale", &media)
+		Expect(repo.Insert(t)).To(Succeed())
+
+		got, err := repo.Get(template.WhereInstanceID("instance-1"), template.WhereID(t.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Name).To(Equal("promo"))
+		Expect(got.Kind).To(Equal(message.MessageKindImage))
+		Expect(*got.Media).To(Equal(media))
+		Expect(got.Variables).To(Equal([]string{"product"}))
+
+		got, err = repo.Get(template.WhereInstanceID("instance-1"), template.WhereName("promo"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.ID).To(Equal(t.ID))
+
+		got, err = repo.Get(template.WhereID("unknown"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+
+	It("should not store two templates with the same name in an instance", func() {
+		Expect(repo.Insert(template.New("instance-1", "welcome", message.MessageKindText, "Hi", nil))).To(Succeed())
+		Expect(repo.Insert(template.New("instance-1", "welcome", message.MessageKindText, "Hello", nil))).ToNot(Succeed())
+	})
+
+	It("should update, list and delete templates", func() {
+		welcome := template.New("instance-1", "welcome", message.MessageKindText, "Hi {{name}}", nil)
+		Expect(repo.Insert(welcome)).To(Succeed())
+		Expect(repo.Insert(template.New("instance-1", "bye", message.MessageKindText, "Bye", nil))).To(Succeed())
+
+		body := "Hello {{name}}"
+		welcome.Update(nil, &body, nil)
+		Expect(repo.Update(welcome)).To(Succeed())
+
+		templates, err := repo.List(template.WhereInstanceID("instance-1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(templates).To(HaveLen(2))
+		Expect(templates[0].Name).To(Equal("bye"))
+		Expect(templates[1].Body).To(Equal("Hello {{name}}"))
+
+		Expect(repo.Delete(template.WhereInstanceID("instance-1"), template.WhereID(welcome.ID))).To(Succeed())
+
+		templates, err = repo.List(template.WhereInstanceID("instance-1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(templates).To(HaveLen(1))
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
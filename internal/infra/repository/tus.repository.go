@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository/models"
+)
+
+type TusUploadRepository struct {
+	db *sqlx.DB
+}
+
+func NewTusUploadRepository(db *sqlx.DB) *TusUploadRepository {
+	return &TusUploadRepository{db: db}
+}
+
+func (r *TusUploadRepository) Insert(u *tus.Upload) error {
+	sqlUpload, err := models.FromTusUploadEntity(u)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.NamedExec(`
+		INSERT INTO tus_uploads (
+			id, instance_id, upload_length, upload_offset, metadata, mime, path, multipart_id, parts, pending,
+			hash_state, file_id, expires_at, updated_at, created_at
+		) VALUES (
+			:id, :instance_id, :upload_length, :upload_offset, :metadata, :mime, :path, :multipart_id, :parts, :pending,
+			:hash_state, :file_id, :expires_at, :updated_at, :created_at
+		)
+	`, sqlUpload)
+	return err
+}
+
+func (r *TusUploadRepository) Claim(u *tus.Upload, staleBefore time.Time) (bool, error) {
+	sqlUpload, err := models.FromTusUploadEntity(u)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.NamedExec(`
+		UPDATE tus_uploads SET claim = :claim, claimed_at = :claimed_at
+		WHERE id = :id AND upload_offset = :upload_offset AND (claim = '' OR claimed_at <= :stale_before)
+	`, map[string]interface{}{
+		"id":            sqlUpload.ID,
+		"upload_offset": sqlUpload.Offset,
+		"claim":         sqlUpload.Claim,
+		"claimed_at":    sqlUpload.ClaimedAt,
+		"stale_before":  staleBefore.UTC(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *TusUploadRepository) UpdateFrom(u *tus.Upload, offset uint64) (bool, error) {
+	sqlUpload, err := models.FromTusUploadEntity(u)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.NamedExec(`
+		UPDATE tus_uploads SET
+			upload_offset = :upload_offset,
+			mime = :mime,
+			path = :path,
+			multipart_id = :multipart_id,
+			parts = :parts,
+			pending = :pending,
+			hash_state = :hash_state,
+			file_id = :file_id,
+			claim = '',
+			claimed_at = NULL,
+			updated_at = :updated_at
+		WHERE id = :id AND upload_offset = :from_offset AND claim = :claim
+	`, map[string]interface{}{
+		"id":            sqlUpload.ID,
+		"upload_offset": sqlUpload.Offset,
+		"mime":          sqlUpload.Mime,
+		"path":          sqlUpload.Path,
+		"multipart_id":  sqlUpload.MultipartID,
+		"parts":         sqlUpload.Parts,
+		"pending":       sqlUpload.Pending,
+		"hash_state":    sqlUpload.HashState,
+		"file_id":       sqlUpload.FileID,
+		"updated_at":    sqlUpload.UpdatedAt,
+		"from_offset":   int64(offset),
+		"claim":         sqlUpload.Claim,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *TusUploadRepository) Get(opts ...tus.UploadQueryOption) (*tus.Upload, error) {
+	queryOptions := &tus.UploadQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`SELECT * FROM tus_uploads WHERE 1=1`, queryOptions)
+	query += " LIMIT 1"
+
+	nstmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	var sqlUpload models.SQLTusUpload
+	if err := nstmt.Get(&sqlUpload, args); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return sqlUpload.ToEntity(), nil
+}
+
+func (r *TusUploadRepository) Delete(opts ...tus.UploadQueryOption) error {
+	queryOptions := &tus.UploadQueryOptions{}
+	for _, opt := range opts {
+		opt(queryOptions)
+	}
+
+	query, args := r.where(`DELETE FROM tus_uploads WHERE 1=1`, queryOptions)
+
+	_, err := r.db.NamedExec(query, args)
+	return err
+}
+
+func (r *TusUploadRepository) Expired(before time.Time, limit int) ([]*tus.Upload, error) {
+	nstmt, err := r.db.PrepareNamed(`SELECT * FROM tus_uploads WHERE expires_at <= :before ORDER BY expires_at LIMIT :limit`)
+	if err != nil {
+		return nil, err
+	}
+	defer nstmt.Close()
+
+	var sqlUploads []models.SQLTusUpload
+	if err := nstmt.Select(&sqlUploads, map[string]interface{}{
+		"before": before.UTC(),
+		"limit":  limit,
+	}); err != nil {
+		return nil, err
+	}
+
+	uploads := make([]*tus.Upload, 0, len(sqlUploads))
+	for _, sqlUpload := range sqlUploads {
+		uploads = append(uploads, sqlUpload.ToEntity())
+	}
+
+	return uploads, nil
+}
+
+func (r *TusUploadRepository) where(query string, queryOptions *tus.UploadQueryOptions) (string, map[string]interface{}) {
+	args := map[string]interface{}{}
+
+	if queryOptions.ID != nil {
+		query += " AND id = :id"
+		args["id"] = *queryOptions.ID
+	}
+	if queryOptions.InstanceID != nil {
+		query += " AND instance_id = :instance_id"
+		args["instance_id"] = *queryOptions.InstanceID
+	}
+
+	return query, args
+}
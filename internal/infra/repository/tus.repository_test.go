@@ -0,0 +1,157 @@
+package repository_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTableSubtree("TusUploadRepository", func(driver string) {
+	Expect(godotenv.Load("./../../../.env")).ToNot(HaveOccurred())
+	config.LoadLoggers(logger.LevelNone)
+
+	var (
+		repo     tus.UploadRepository
+		instRepo instance.InstanceRepository
+		db       *sqlx.DB
+		migrator *database.Migrator
+	)
+
+	BeforeEach(func() {
+		var conf config.DatabaseConfig
+
+		if driver == "sqlite" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverSQLite,
+				DbName: ":memory:",
+			}
+		}
+
+		if driver == "postgres" {
+			conf = config.DatabaseConfig{
+				Driver: config.DatabaseDriverPostgres,
+				DbName: config.GetEnvString("DB_NAME", ""),
+				DbUser: config.GetEnvString("DB_USER", ""),
+				DbPass: config.GetEnvString("DB_PASS", ""),
+				DbHost: config.GetEnvString("DB_HOST", ""),
+				DbPort: config.GetEnvString("DB_PORT", ""),
+			}
+		}
+
+		db = database.New(&conf)
+
+		migrator = database.NewMigrator(db, conf.CodeDriver())
+
+		migrator.Reset()
+
+		repo = repository.NewTusUploadRepository(db)
+		instRepo = repository.NewInstanceRepository(db)
+
+		Expect(instRepo.Insert(fake.InstanceFactory().WithID("instance-1").Create())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	It("should store an upload", func() {
+		u := tus.New("instance-1", 1024, map[string]string{"filename": "report.pdf"}, time.Hour)
+		Expect(repo.Insert(u)).To(Succeed())
+
+		got, err := repo.Get(tus.WhereID(u.ID), tus.WhereInstanceID("instance-1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Length).To(Equal(uint64(1024)))
+		Expect(got.Offset).To(BeZero())
+		Expect(got.Metadata).To(Equal(map[string]string{"filename": "report.pdf"}))
+		Expect(got.Parts).To(BeEmpty())
+		Expect(got.FileID).To(BeNil())
+
+		missing, err := repo.Get(tus.WhereID(u.ID), tus.WhereInstanceID("instance-2"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(BeNil())
+	})
+
+	It("should only update from the expected offset", func() {
+		u := tus.New("instance-1", 1024, nil, time.Hour)
+		Expect(repo.Insert(u)).To(Succeed())
+
+		u.Offset = 512
+		u.Path = u.ID + ".pdf"
+		u.MultipartID = "multipart-1"
+		u.Parts = []tus.Part{{Number: 1, ETag: "etag-1", Size: 512}}
+		u.HashState = []byte("state")
+
+		stored, err := repo.UpdateFrom(u, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored).To(BeTrue())
+
+		stored, err = repo.UpdateFrom(u, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored).To(BeFalse())
+
+		got, err := repo.Get(tus.WhereID(u.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Offset).To(Equal(uint64(512)))
+		Expect(got.MultipartID).To(Equal("multipart-1"))
+		Expect(got.Parts).To(Equal(u.Parts))
+		Expect(got.HashState).To(Equal([]byte("state")))
+	})
+
+	It("should let one request at a time claim an upload", func() {
+		u := tus.New("instance-1", 1024, nil, time.Hour)
+		Expect(repo.Insert(u)).To(Succeed())
+
+		first, second := *u, *u
+		first.NewClaim(time.Now())
+		second.NewClaim(time.Now())
+
+		Expect(repo.Claim(&first, time.Now().Add(-time.Minute))).To(BeTrue())
+		Expect(repo.Claim(&second, time.Now().Add(-time.Minute))).To(BeFalse())
+
+		// only the request holding the claim stores the upload, which releases it
+		second.Offset = 512
+		Expect(repo.UpdateFrom(&second, 0)).To(BeFalse())
+
+		first.Offset = 512
+		Expect(repo.UpdateFrom(&first, 0)).To(BeTrue())
+
+		got, err := repo.Get(tus.WhereID(u.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Claim).To(BeEmpty())
+		Expect(got.ClaimedAt).To(BeNil())
+
+		// a claim is taken at the offset the upload is
+		second.Offset = 0
+		Expect(repo.Claim(&second, time.Now().Add(-time.Minute))).To(BeFalse())
+		second.Offset = 512
+		Expect(repo.Claim(&second, time.Now().Add(-time.Minute))).To(BeTrue())
+	})
+
+	It("should list and delete expired uploads", func() {
+		expired := tus.New("instance-1", 10, nil, -time.Minute)
+		alive := tus.New("instance-1", 10, nil, time.Hour)
+		Expect(repo.Insert(expired)).To(Succeed())
+		Expect(repo.Insert(alive)).To(Succeed())
+
+		uploads, err := repo.Expired(time.Now(), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(uploads).To(HaveLen(1))
+		Expect(uploads[0].ID).To(Equal(expired.ID))
+
+		Expect(repo.Delete(tus.WhereID(expired.ID))).To(Succeed())
+
+		uploads, err = repo.Expired(time.Now(), 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(uploads).To(BeEmpty())
+	})
+}, Entry("with SQLite", "sqlite"), Entry("with Postgres", "postgres"))
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+)
+
+// AzureStorage keeps the files as block blobs of a container, Key and Secret are the account name and key
+type AzureStorage struct {
+	cfg    *config.StorageConfig
+	client *container.Client
+}
+
+func NewAzureStorage(cfg *config.StorageConfig) *AzureStorage {
+	cred, err := azblob.NewSharedKeyCredential(cfg.Key, cfg.Secret)
+	if err != nil {
+		panic(fmt.Sprintf("invalid Azure credentials: %v", err))
+	}
+
+	containerURL, err := url.JoinPath(cfg.Endpoint, cfg.Bucket)
+	if err != nil {
+		panic(fmt.Sprintf("invalid Azure endpoint: %v", err))
+	}
+
+	client, err := container.NewClientWithSharedKeyCredential(containerURL, cred, nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create Azure client: %v", err))
+	}
+
+	return &AzureStorage{
+		cfg:    cfg,
+		client: client,
+	}
+}
+
+func (s *AzureStorage) Save(ctx context.Context, key string, r io.Reader) error {
+	mimeType := mime.TypeByExtension(path.Ext(key))
+	_, err := s.client.NewBlockBlobClient(key).UploadStream(ctx, r, &blockblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &mimeType},
+	})
+
+	return err
+}
+
+func (s *AzureStorage) Load(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.client.NewBlobClient(key).DownloadStream(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *AzureStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := s.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func (s *AzureStorage) Put(ctx context.Context, key string, data []byte) error {
+	return s.Save(ctx, key, bytes.NewReader(data))
+}
+
+func (s *AzureStorage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.NewBlobClient(key).Delete(ctx, nil)
+	return err
+}
+
+func (s *AzureStorage) URL(ctx context.Context, key string) (string, error) {
+	if s.cfg.URLExpiration > 0 {
+		return s.client.NewBlobClient(key).GetSASURL(sas.BlobPermissions{Read: true}, time.Now().Add(s.cfg.URLExpiration), nil)
+	}
+
+	return url.JoinPath(s.cfg.URL, s.cfg.Bucket, key)
+}
+
+// PresignPut gives a SAS that can only create or write the blob, Azure needs the blob type with every PUT
+func (s *AzureStorage) PresignPut(ctx context.Context, key, mime string, ttl time.Duration) (string, map[string]string, error) {
+	signed, err := s.client.NewBlobClient(key).GetSASURL(sas.BlobPermissions{Create: true, Write: true}, time.Now().Add(ttl), nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, map[string]string{"x-ms-blob-type": string(blob.BlobTypeBlockBlob)}, nil
+}
+
+func (s *AzureStorage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.NewBlobClient(key).GetProperties(ctx, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (s *AzureStorage) Walk(ctx context.Context, fn func(storage.Object) error) error {
+	pages := s.client.NewListBlobsFlatPager(nil)
+
+	for pages.More() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			o := storage.Object{Key: *item.Name}
+			if item.Properties != nil {
+				if item.Properties.ContentLength != nil {
+					o.Size = uint64(*item.Properties.ContentLength)
+				}
+				if item.Properties.LastModified != nil {
+					o.ModifiedAt = item.Properties.LastModified.UTC()
+				}
+			}
+
+			if err := fn(o); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *AzureStorage) Healthy(ctx context.Context) error {
+	if _, err := s.client.GetProperties(ctx, nil); err != nil {
+		return fmt.Errorf("health failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+type GCSStorage struct {
+	cfg    *config.StorageConfig
+	client *gcs.Client
+	bucket *gcs.BucketHandle
+}
+
+func NewGCSStorage(cfg *config.StorageConfig) *GCSStorage {
+	var opts []option.ClientOption
+
+	// emulators take no credentials
+	if cfg.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(strings.TrimSuffix(cfg.Endpoint, "/")+"/storage/v1/"), option.WithoutAuthentication())
+	} else if cfg.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+	}
+
+	client, err := gcs.NewClient(context.Background(), opts...)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create GCS client: %v", err))
+	}
+
+	return &GCSStorage{
+		cfg:    cfg,
+		client: client,
+		bucket: client.Bucket(cfg.Bucket),
+	}
+}
+
+func (s *GCSStorage) Save(ctx context.Context, key string, r io.Reader) error {
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = mime.TypeByExtension(path.Ext(key))
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+
+	// the object is only written once the writer closes
+	return w.Close()
+}
+
+func (s *GCSStorage) Load(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.bucket.Object(key).NewReader(ctx)
+}
+
+func (s *GCSStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := s.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func (s *GCSStorage) Put(ctx context.Context, key string, data []byte) error {
+	return s.Save(ctx, key, bytes.NewReader(data))
+}
+
+func (s *GCSStorage) Delete(ctx context.Context, key string) error {
+	return s.bucket.Object(key).Delete(ctx)
+}
+
+func (s *GCSStorage) URL(ctx context.Context, key string) (string, error) {
+	if s.cfg.URLExpiration > 0 {
+		return s.bucket.SignedURL(key, &gcs.SignedURLOptions{
+			Method:  http.MethodGet,
+			Expires: time.Now().Add(s.cfg.URLExpiration),
+			Scheme:  gcs.SigningSchemeV4,
+		})
+	}
+
+	return url.JoinPath(s.cfg.URL, s.cfg.Bucket, key)
+}
+
+func (s *GCSStorage) PresignPut(ctx context.Context, key, mime string, ttl time.Duration) (string, map[string]string, error) {
+	opts := &gcs.SignedURLOptions{
+		Method:  http.MethodPut,
+		Expires: time.Now().Add(ttl),
+		Scheme:  gcs.SigningSchemeV4,
+	}
+	if mime != "" {
+		opts.ContentType = mime
+	}
+
+	signed, err := s.bucket.SignedURL(key, opts)
+	return signed, nil, err
+}
+
+func (s *GCSStorage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.bucket.Object(key).Attrs(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (s *GCSStorage) Walk(ctx context.Context, fn func(storage.Object) error) error {
+	it := s.bucket.Objects(ctx, nil)
+
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fn(storage.Object{Key: attrs.Name, Size: uint64(attrs.Size), ModifiedAt: attrs.Updated.UTC()}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *GCSStorage) Healthy(ctx context.Context) error {
+	if _, err := s.bucket.Attrs(ctx); err != nil {
+		return fmt.Errorf("health failed: %w", err)
+	}
+	return nil
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
 )
 
+// LocalStorage keeps the files on disk, the API serves them on /storage with URLs it signs itself
 type LocalStorage struct {
+	apiSigner
 	cfg *config.StorageConfig
 }
 
@@ -19,7 +23,7 @@ func NewLocalStorage(cfg *config.StorageConfig) *LocalStorage {
 	if err != nil {
 		panic(fmt.Sprintf("failed to create storage directory: %v", err))
 	}
-	return &LocalStorage{cfg: cfg}
+	return &LocalStorage{apiSigner: apiSigner{cfg: cfg}, cfg: cfg}
 }
 
 func (s *LocalStorage) Save(ctx context.Context, key string, r io.Reader) error {
@@ -52,8 +56,38 @@ func (s *LocalStorage) Delete(ctx context.Context, key string) error {
 	return os.Remove(path)
 }
 
+func (s *LocalStorage) Walk(ctx context.Context, fn func(storage.Object) error) error {
+	return filepath.WalkDir(s.cfg.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		key, err := filepath.Rel(s.cfg.Path, path)
+		if err != nil {
+			return err
+		}
+
+		return fn(storage.Object{
+			Key:        filepath.ToSlash(key),
+			Size:       uint64(info.Size()),
+			ModifiedAt: info.ModTime().UTC(),
+		})
+	})
+}
+
 func (s *LocalStorage) URL(ctx context.Context, key string) (string, error) {
-	return fmt.Sprintf("%s/%s/%s", s.cfg.URL, "storage", key), nil
+	return s.url(key), nil
 }
 
 func (s *LocalStorage) Get(ctx context.Context, key string) ([]byte, error) {
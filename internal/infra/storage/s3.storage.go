@@ -9,11 +9,13 @@ import (
 	"mime"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
 )
 
@@ -108,6 +110,17 @@ func (s *S3Storage) Delete(ctx context.Context, key string) error {
 }
 
 func (s *S3Storage) URL(ctx context.Context, key string) (string, error) {
+	if s.cfg.URLExpiration > 0 {
+		req, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(s.cfg.Bucket),
+			Key:    aws.String(key),
+		}, s3.WithPresignExpires(s.cfg.URLExpiration))
+		if err != nil {
+			return "", err
+		}
+		return req.URL, nil
+	}
+
 	if s.cfg.URL != "" {
 		u, err := url.JoinPath(s.cfg.URL, s.cfg.Bucket, key)
 		if err != nil {
@@ -119,6 +132,23 @@ func (s *S3Storage) URL(ctx context.Context, key string) (string, error) {
 	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.cfg.Bucket, s.cfg.Region, key), nil
 }
 
+func (s *S3Storage) PresignPut(ctx context.Context, key, mime string, ttl time.Duration) (string, map[string]string, error) {
+	in := &s3.PutObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(key),
+	}
+	if mime != "" {
+		in.ContentType = aws.String(mime)
+	}
+
+	req, err := s3.NewPresignClient(s.client).PresignPutObject(ctx, in, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return req.URL, nil, nil
+}
+
 func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
 	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(s.cfg.Bucket),
@@ -136,6 +166,98 @@ func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
 	return true, nil
 }
 
+func (s *S3Storage) Walk(ctx context.Context, fn func(storage.Object) error) error {
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.cfg.Bucket),
+	})
+
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range page.Contents {
+			o := storage.Object{Key: aws.ToString(obj.Key), Size: uint64(aws.ToInt64(obj.Size))}
+			if obj.LastModified != nil {
+				o.ModifiedAt = obj.LastModified.UTC()
+			}
+
+			if err := fn(o); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *S3Storage) StartMultipart(ctx context.Context, key string) (string, error) {
+	mimeType := mime.TypeByExtension(path.Ext(key))
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.cfg.Bucket),
+		Key:         aws.String(key),
+		ContentType: &mimeType,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(out.UploadId), nil
+}
+
+func (s *S3Storage) PutPart(ctx context.Context, key string, uploadID string, number int32, data []byte) (string, error) {
+	out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(s.cfg.Bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(number),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(out.ETag), nil
+}
+
+func (s *S3Storage) CompleteMultipart(ctx context.Context, key string, uploadID string, parts []storage.Part) error {
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, types.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int32(part.Number),
+		})
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.cfg.Bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+
+	return err
+}
+
+func (s *S3Storage) AbortMultipart(ctx context.Context, key string, uploadID string, parts []storage.Part) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.cfg.Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+
+	return err
+}
+
+// MinPartSize is the smallest part S3 takes
+func (s *S3Storage) MinPartSize() int {
+	return 5 * 1024 * 1024
+}
+
 func (s *S3Storage) EnsureBucket(ctx context.Context) error {
 	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
 		Bucket: aws.String(s.cfg.Bucket),
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+)
+
+// apiSigner signs the URLs of the storages the API serves itself on /storage, the signature covers the method,
+// the key and the expiration and is made with the SigningKey
+type apiSigner struct {
+	cfg *config.StorageConfig
+}
+
+// url is where the API serves the key, signed while download URLs expire
+func (s apiSigner) url(key string) string {
+	if s.cfg.URLExpiration <= 0 {
+		return fmt.Sprintf("%s/%s/%s", s.cfg.URL, "storage", key)
+	}
+
+	return s.signedURL(http.MethodGet, key, s.cfg.URLExpiration)
+}
+
+// PresignPut signs a PUT to the key, the API writes the body to the storage once the signature checks out
+func (s apiSigner) PresignPut(ctx context.Context, key, mime string, ttl time.Duration) (string, map[string]string, error) {
+	if s.cfg.SigningKey == "" {
+		return "", nil, storage.ErrPresignUnsupported
+	}
+
+	return s.signedURL(http.MethodPut, key, ttl), nil, nil
+}
+
+// Verify checks the signature of a request to a key, downloads need none while URLs are permanent
+func (s apiSigner) Verify(method, key, expires, signature string) error {
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+
+	if method == http.MethodGet && s.cfg.URLExpiration <= 0 {
+		return nil
+	}
+
+	// without a key anyone could compute the signature
+	if s.cfg.SigningKey == "" {
+		return storage.ErrInvalidSignature
+	}
+
+	at, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return storage.ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(method, key, at))) {
+		return storage.ErrInvalidSignature
+	}
+
+	if time.Now().Unix() > at {
+		return storage.ErrSignatureExpired
+	}
+
+	return nil
+}
+
+func (s apiSigner) signedURL(method, key string, ttl time.Duration) string {
+	expires := time.Now().Add(ttl).Unix()
+	return fmt.Sprintf("%s/%s/%s?expires=%d&signature=%s", s.cfg.URL, "storage", key, expires, s.sign(method, key, expires))
+}
+
+func (s apiSigner) sign(method, key string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.SigningKey))
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -7,8 +7,15 @@ import (
 
 func New(cfg *config.StorageConfig) storage.Storage {
 	if cfg != nil && cfg.IsConfigured() {
-		if cfg.Driver == config.StorageDriverS3 {
+		switch cfg.Driver {
+		case config.StorageDriverS3:
 			return NewS3Storage(cfg)
+		case config.StorageDriverGCS:
+			return NewGCSStorage(cfg)
+		case config.StorageDriverAzure:
+			return NewAzureStorage(cfg)
+		case config.StorageDriverWebDAV:
+			return NewWebDAVStorage(cfg)
 		}
 
 		return NewLocalStorage(cfg)
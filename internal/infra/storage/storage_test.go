@@ -3,9 +3,21 @@ package storage_test
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/joho/godotenv"
 	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
 	intf "github.com/mauriciorobertodev/whappy-go/internal/app/storage"
@@ -47,6 +59,48 @@ var _ = DescribeTableSubtree("Storage", func(driver string) {
 				Endpoint:  config.GetEnvString("S3_ENDPOINT", ""),
 				URL:       config.GetEnvString("S3_URL", ""),
 				PathStyle: true,
+
+				URLExpiration: time.Hour,
+			})
+		}
+
+		// the emulator takes no credentials, so its URLs cannot be signed
+		if driver == "gcs" {
+			cfg := &config.StorageConfig{
+				Driver:   config.StorageDriverGCS,
+				Bucket:   config.GetEnvString("GCS_BUCKET", ""),
+				Endpoint: config.GetEnvString("GCS_ENDPOINT", ""),
+				URL:      config.GetEnvString("GCS_URL", ""),
+			}
+			createGCSBucket(cfg)
+			store = storage.New(cfg)
+		}
+
+		if driver == "azure" {
+			cfg := &config.StorageConfig{
+				Driver:   config.StorageDriverAzure,
+				Key:      config.GetEnvString("AZURE_ACCOUNT", ""),
+				Secret:   config.GetEnvString("AZURE_KEY", ""),
+				Bucket:   config.GetEnvString("AZURE_CONTAINER", ""),
+				Endpoint: config.GetEnvString("AZURE_ENDPOINT", ""),
+				URL:      config.GetEnvString("AZURE_URL", ""),
+
+				URLExpiration: time.Hour,
+			}
+			createAzureContainer(cfg)
+			store = storage.New(cfg)
+		}
+
+		if driver == "webdav" {
+			store = storage.New(&config.StorageConfig{
+				Driver:   config.StorageDriverWebDAV,
+				Key:      config.GetEnvString("WEBDAV_USER", ""),
+				Secret:   config.GetEnvString("WEBDAV_PASS", ""),
+				Endpoint: config.GetEnvString("WEBDAV_ENDPOINT", ""),
+				URL:      config.GetEnvURL("APP_URL", ""),
+
+				URLExpiration: time.Hour,
+				SigningKey:    "secret",
 			})
 		}
 
@@ -55,6 +109,9 @@ var _ = DescribeTableSubtree("Storage", func(driver string) {
 				Driver: config.StorageDriverLocal,
 				Path:   "./../../../" + config.GetEnvString("STORAGE_PATH", "") + "/tests",
 				URL:    config.GetEnvURL("APP_URL", ""),
+
+				URLExpiration: time.Hour,
+				SigningKey:    "secret",
 			})
 		}
 	})
@@ -132,4 +189,190 @@ var _ = DescribeTableSubtree("Storage", func(driver string) {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(url).To(ContainSubstring(testKey))
 	})
-}, Entry("with Local", "local"), Entry("with S3", "s3"))
+
+	It("should sign the URLs that expire", func() {
+		if driver == "gcs" {
+			Skip("the GCS emulator cannot sign URLs")
+		}
+
+		Expect(store.Put(ctx, testKey, testData)).To(Succeed())
+
+		link, err := store.URL(ctx, testKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		u, err := url.Parse(link)
+		Expect(err).ToNot(HaveOccurred())
+
+		// the API serves these itself, after checking the signature
+		if signed, ok := store.(intf.SignedStorage); ok {
+			key, expires, sig := strings.TrimPrefix(u.Path, "/storage/"), u.Query().Get("expires"), u.Query().Get("signature")
+			Expect(signed.Verify("GET", key, expires, sig)).To(Succeed())
+			Expect(signed.Verify("GET", key, expires, "")).To(MatchError(intf.ErrInvalidSignature))
+			return
+		}
+
+		resp, err := http.Get(link)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(testData))
+
+		u.RawQuery = ""
+		unsigned, err := http.Get(u.String())
+		Expect(err).ToNot(HaveOccurred())
+		defer unsigned.Body.Close()
+		Expect(unsigned.StatusCode).ToNot(Equal(http.StatusOK))
+	})
+
+	It("should presign uploads", func() {
+		if driver == "gcs" {
+			Skip("the GCS emulator cannot sign URLs")
+		}
+
+		presigner := intf.Presigned(store)
+		Expect(presigner).ToNot(BeNil())
+
+		link, headers, err := presigner.PresignPut(ctx, testKey, "text/plain", time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+
+		u, err := url.Parse(link)
+		Expect(err).ToNot(HaveOccurred())
+
+		if signed, ok := store.(intf.SignedStorage); ok {
+			key, expires, sig := strings.TrimPrefix(u.Path, "/storage/"), u.Query().Get("expires"), u.Query().Get("signature")
+			Expect(signed.Verify("PUT", key, expires, sig)).To(Succeed())
+			Expect(signed.Verify("GET", key, expires, sig)).To(MatchError(intf.ErrInvalidSignature))
+			return
+		}
+
+		req, err := http.NewRequest(http.MethodPut, link, bytes.NewReader(testData))
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Content-Type", "text/plain")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(BeNumerically("<", 300))
+
+		data, err := store.Get(ctx, testKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(testData))
+	})
+
+	It("should walk the stored objects", func() {
+		Expect(store.Put(ctx, testKey, testData)).To(Succeed())
+
+		var found *intf.Object
+		err := intf.Listable(store).Walk(ctx, func(o intf.Object) error {
+			if o.Key == testKey {
+				found = &o
+			}
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).ToNot(BeNil())
+		Expect(found.Size).To(Equal(uint64(len(testData))))
+		Expect(found.ModifiedAt).ToNot(BeZero())
+	})
+},
+	Entry("with Local", "local"),
+	Entry("with S3", "s3"),
+	Entry("with GCS", "gcs"),
+	Entry("with Azure", "azure"),
+	Entry("with WebDAV", "webdav"),
+)
+
+// The emulators start empty, the bucket and the container are created like an operator would
+func createGCSBucket(cfg *config.StorageConfig) {
+	body := strings.NewReader(`{"name":"` + cfg.Bucket + `"}`)
+	resp, err := http.Post(cfg.Endpoint+"/storage/v1/b?project=test", "application/json", body)
+	Expect(err).ToNot(HaveOccurred())
+	defer resp.Body.Close()
+	Expect(resp.StatusCode).To(Or(Equal(http.StatusOK), Equal(http.StatusConflict)))
+}
+
+func createAzureContainer(cfg *config.StorageConfig) {
+	cred, err := azblob.NewSharedKeyCredential(cfg.Key, cfg.Secret)
+	Expect(err).ToNot(HaveOccurred())
+
+	client, err := container.NewClientWithSharedKeyCredential(cfg.Endpoint+"/"+cfg.Bucket, cred, nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	if _, err := client.Create(context.Background(), nil); err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		Expect(err).ToNot(HaveOccurred())
+	}
+}
+
+var _ = Describe("LocalStorage signed URLs", func() {
+	var (
+		ctx   context.Context
+		store *storage.LocalStorage
+	)
+
+	signature := func(raw string) (string, string, string) {
+		u, err := url.Parse(raw)
+		Expect(err).ToNot(HaveOccurred())
+		return strings.TrimPrefix(u.Path, "/storage/"), u.Query().Get("expires"), u.Query().Get("signature")
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		store = storage.NewLocalStorage(&config.StorageConfig{
+			Driver:        config.StorageDriverLocal,
+			Path:          GinkgoT().TempDir(),
+			URL:           "http://localhost:8080",
+			URLExpiration: time.Hour,
+			SigningKey:    "secret",
+		})
+	})
+
+	It("should keep permanent links without an expiration", func() {
+		public := storage.NewLocalStorage(&config.StorageConfig{Path: GinkgoT().TempDir(), URL: "http://localhost:8080"})
+
+		link, err := public.URL(ctx, "file.txt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(link).To(Equal("http://localhost:8080/storage/file.txt"))
+		Expect(public.Verify("GET", "file.txt", "", "")).To(Succeed())
+	})
+
+	It("should sign download URLs", func() {
+		link, err := store.URL(ctx, "file.txt")
+		Expect(err).ToNot(HaveOccurred())
+
+		key, expires, sig := signature(link)
+		Expect(key).To(Equal("file.txt"))
+		Expect(store.Verify("GET", key, expires, sig)).To(Succeed())
+		Expect(store.Verify("HEAD", key, expires, sig)).To(Succeed())
+
+		Expect(store.Verify("GET", "other.txt", expires, sig)).To(MatchError(intf.ErrInvalidSignature))
+		Expect(store.Verify("GET", key, expires, "")).To(MatchError(intf.ErrInvalidSignature))
+		Expect(store.Verify("PUT", key, expires, sig)).To(MatchError(intf.ErrInvalidSignature))
+	})
+
+	It("should refuse expired signatures", func() {
+		link, _, err := store.PresignPut(ctx, "file.txt", "text/plain", -time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, expires, sig := signature(link)
+		Expect(store.Verify("PUT", key, expires, sig)).To(MatchError(intf.ErrSignatureExpired))
+	})
+
+	It("should not sign anything without a signing key", func() {
+		unsigned := storage.NewLocalStorage(&config.StorageConfig{Path: GinkgoT().TempDir(), URL: "http://localhost:8080"})
+
+		_, _, err := unsigned.PresignPut(ctx, "file.txt", "text/plain", time.Minute)
+		Expect(err).To(MatchError(intf.ErrPresignUnsupported))
+
+		// a signature made with an empty key is what anyone could forge
+		expires := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+		mac := hmac.New(sha256.New, nil)
+		fmt.Fprintf(mac, "PUT\nfile.txt\n%s", expires)
+		Expect(unsigned.Verify("PUT", "file.txt", expires, hex.EncodeToString(mac.Sum(nil)))).To(MatchError(intf.ErrInvalidSignature))
+	})
+})
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+)
+
+// WebDAVStorage keeps the files on a WebDAV server, Key and Secret are the basic auth credentials. The server
+// wants them for every request, so the API serves the files on /storage with URLs it signs itself, like the local
+// storage
+type WebDAVStorage struct {
+	apiSigner
+	cfg    *config.StorageConfig
+	client *http.Client
+}
+
+func NewWebDAVStorage(cfg *config.StorageConfig) *WebDAVStorage {
+	return &WebDAVStorage{
+		apiSigner: apiSigner{cfg: cfg},
+		cfg:       cfg,
+		client:    &http.Client{Timeout: cfg.Timeout}, // the handlers give no deadline, a stalled server must not hold them
+	}
+}
+
+func (s *WebDAVStorage) Save(ctx context.Context, key string, r io.Reader) error {
+	if err := s.mkdirs(ctx, path.Dir(key)); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, r, map[string]string{"Content-Type": mime.TypeByExtension(path.Ext(key))})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return s.expect(resp, http.MethodPut, key, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+}
+
+func (s *WebDAVStorage) Load(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.expect(resp, http.MethodGet, key, http.StatusOK); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *WebDAVStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := s.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func (s *WebDAVStorage) Put(ctx context.Context, key string, data []byte) error {
+	return s.Save(ctx, key, bytes.NewReader(data))
+}
+
+func (s *WebDAVStorage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return s.expect(resp, http.MethodDelete, key, http.StatusOK, http.StatusNoContent)
+}
+
+func (s *WebDAVStorage) URL(ctx context.Context, key string) (string, error) {
+	return s.url(key), nil
+}
+
+func (s *WebDAVStorage) Exists(ctx context.Context, key string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	return true, s.expect(resp, http.MethodHead, key, http.StatusOK)
+}
+
+// Walk lists the collections one level at a time, servers commonly refuse infinite depth
+func (s *WebDAVStorage) Walk(ctx context.Context, fn func(storage.Object) error) error {
+	dirs := []string{""}
+
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+
+		entries, err := s.list(ctx, dir)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			if e.dir {
+				dirs = append(dirs, e.key)
+				continue
+			}
+
+			if err := fn(e.Object); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *WebDAVStorage) Healthy(ctx context.Context) error {
+	if _, err := s.list(ctx, ""); err != nil {
+		return fmt.Errorf("health failed: %w", err)
+	}
+	return nil
+}
+
+func (s *WebDAVStorage) do(ctx context.Context, method, key string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	u, err := url.JoinPath(s.cfg.Endpoint, key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cfg.Key != "" {
+		req.SetBasicAuth(s.cfg.Key, s.cfg.Secret)
+	}
+	for k, v := range headers {
+		if v != "" {
+			req.Header.Set(k, v)
+		}
+	}
+
+	return s.client.Do(req)
+}
+
+func (s *WebDAVStorage) expect(resp *http.Response, method, key string, statuses ...int) error {
+	for _, status := range statuses {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("webdav %s %s: %s", method, key, resp.Status)
+}
+
+// mkdirs creates the collections of a key, WebDAV does not create them on PUT
+func (s *WebDAVStorage) mkdirs(ctx context.Context, dir string) error {
+	if dir == "." || dir == "/" || dir == "" {
+		return nil
+	}
+
+	current := ""
+	for _, part := range strings.Split(dir, "/") {
+		current = path.Join(current, part)
+
+		resp, err := s.do(ctx, "MKCOL", current+"/", nil, nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		// 405 is answered when the collection already exists
+		if err := s.expect(resp, "MKCOL", current, http.StatusCreated, http.StatusMethodNotAllowed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type webdavEntry struct {
+	storage.Object
+	key string
+	dir bool
+}
+
+type webdavMultistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+		Prop struct {
+			Length       string `xml:"getcontentlength"`
+			LastModified string `xml:"getlastmodified"`
+			Type         struct {
+				Collection *struct{} `xml:"collection"`
+			} `xml:"resourcetype"`
+		} `xml:"propstat>prop"`
+	} `xml:"response"`
+}
+
+const webdavPropfind = `<?xml version="1.0" encoding="utf-8"?>
+<propfind xmlns="DAV:"><prop><resourcetype/><getcontentlength/><getlastmodified/></prop></propfind>`
+
+// list returns what is right inside the collection, keys are relative to the endpoint
+func (s *WebDAVStorage) list(ctx context.Context, dir string) ([]webdavEntry, error) {
+	resp, err := s.do(ctx, "PROPFIND", dir+"/", strings.NewReader(webdavPropfind), map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := s.expect(resp, "PROPFIND", dir, http.StatusMultiStatus); err != nil {
+		return nil, err
+	}
+
+	var ms webdavMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSuffix(base.Path, "/") + "/"
+
+	entries := make([]webdavEntry, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			return nil, err
+		}
+
+		key := strings.Trim(strings.TrimPrefix(href.Path, root), "/")
+		if key == strings.Trim(dir, "/") {
+			continue // the collection itself
+		}
+
+		e := webdavEntry{key: key, dir: r.Prop.Type.Collection != nil}
+		e.Key = key
+		e.Size, _ = strconv.ParseUint(r.Prop.Length, 10, 64)
+		if t, err := time.Parse(http.TimeFormat, r.Prop.LastModified); err == nil {
+			e.ModifiedAt = t.UTC()
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
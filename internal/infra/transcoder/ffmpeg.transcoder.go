@@ -0,0 +1,366 @@
+package transcoder
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/transcoder"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+)
+
+const waveformSampleRate = 8000 // plenty for 64 bars and cheap to decode
+
+// playlistFormats read other files or urls named in the media, they are never followed
+var playlistFormats = []string{"hls", "dash", "concat"}
+
+type FFmpegTranscoder struct {
+	cfg      *config.TranscoderConfig
+	pdftoppm string // resolved path, empty when not installed
+}
+
+func NewFFmpegTranscoder(cfg *config.TranscoderConfig) *FFmpegTranscoder {
+	return &FFmpegTranscoder{cfg: cfg}
+}
+
+func (t *FFmpegTranscoder) Probe(ctx context.Context, data []byte) (*transcoder.Probe, error) {
+	dir, input, err := t.prepare(data)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	return t.probe(ctx, input, "")
+}
+
+func (t *FFmpegTranscoder) Voice(ctx context.Context, data []byte) (*transcoder.Result, error) {
+	dir, input, err := t.prepare(data)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	probe, err := t.probe(ctx, input, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if !probe.HasAudio() {
+		return nil, transcoder.ErrNoAudioStream
+	}
+
+	output := input
+	// an opus in ogg is already a voice note, it only needs the waveform
+	if probe.AudioCodec != "opus" || !strings.Contains(probe.Format, "ogg") || probe.HasVideo() {
+		output = filepath.Join(dir, "voice.ogg")
+		if _, err := t.ffmpeg(ctx,
+			"-f", demuxer(probe), "-i", input, "-map", "0:a:0", "-vn",
+			"-c:a", "libopus", "-b:a", "32k", "-ac", "1", "-ar", "48000", "-application", "voip",
+			"-f", "ogg", output,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	converted, err := os.ReadFile(output)
+	if err != nil {
+		return nil, transcoder.ErrTranscodeFailed
+	}
+
+	pcm, err := t.ffmpeg(ctx, "-f", "ogg", "-i", output, "-map", "0:a:0", "-f", "s16le", "-ac", "1", "-ar", strconv.Itoa(waveformSampleRate), "-")
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]int16, len(pcm)/2)
+	if err := binary.Read(bytes.NewReader(pcm[:len(samples)*2]), binary.LittleEndian, samples); err != nil {
+		return nil, transcoder.ErrTranscodeFailed
+	}
+
+	duration := uint32(math.Ceil(float64(len(samples)) / waveformSampleRate))
+
+	return &transcoder.Result{
+		Data:     converted,
+		Mime:     transcoder.MimeVoice,
+		Duration: &duration,
+		Waveform: file.GenerateWaveform(samples),
+	}, nil
+}
+
+func (t *FFmpegTranscoder) Video(ctx context.Context, data []byte) (*transcoder.Result, error) {
+	dir, input, err := t.prepare(data)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	probe, err := t.probe(ctx, input, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if !probe.HasVideo() {
+		return nil, transcoder.ErrNoVideoStream
+	}
+
+	output := filepath.Join(dir, "video.mp4")
+	args := []string{"-f", demuxer(probe), "-i", input, "-map", "0:v:0", "-map", "0:a:0?"}
+
+	// h264 with aac only needs the index moved to the start, anything else is encoded again
+	if probe.VideoCodec == "h264" && (probe.AudioCodec == "aac" || !probe.HasAudio()) {
+		args = append(args, "-c", "copy")
+	} else {
+		args = append(args,
+			"-c:v", "libx264", "-preset", "veryfast", "-crf", "23", "-pix_fmt", "yuv420p",
+			"-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2", // yuv420p needs even dimensions
+			"-c:a", "aac", "-b:a", "128k",
+		)
+	}
+
+	args = append(args, "-movflags", "+faststart", "-f", "mp4", output)
+	if _, err := t.ffmpeg(ctx, args...); err != nil {
+		return nil, err
+	}
+
+	converted, err := os.ReadFile(output)
+	if err != nil {
+		return nil, transcoder.ErrTranscodeFailed
+	}
+
+	result, err := t.probe(ctx, output, "mp4")
+	if err != nil {
+		return nil, err
+	}
+
+	return &transcoder.Result{
+		Data:     converted,
+		Mime:     transcoder.MimeVideo,
+		Duration: result.Duration,
+		Width:    result.Width,
+		Height:   result.Height,
+	}, nil
+}
+
+func (t *FFmpegTranscoder) Frame(ctx context.Context, data []byte) ([]byte, error) {
+	dir, input, err := t.prepare(data)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	probe, err := t.probe(ctx, input, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if !probe.HasVideo() {
+		return nil, transcoder.ErrNoVideoStream
+	}
+
+	if probe.Width != nil && probe.Height != nil && uint64(*probe.Width)*uint64(*probe.Height) > file.ThumbnailMaxPixels {
+		return nil, file.ErrImageTooLarge
+	}
+
+	// the thumbnail filter skips the black or blurry frames videos often start with, the frame is scaled down
+	// like the pages so what is decoded after stays small
+	size := strconv.Itoa(file.ThumbnailMaxSize * 2)
+	filter := "thumbnail,scale=w=" + size + ":h=" + size + ":force_original_aspect_ratio=decrease"
+	frame, err := t.ffmpeg(ctx, "-f", demuxer(probe), "-i", input, "-map", "0:v:0", "-vf", filter, "-frames:v", "1", "-c:v", "mjpeg", "-f", "image2pipe", "-")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(frame) == 0 {
+		return nil, transcoder.ErrTranscodeFailed
+	}
+
+	return frame, nil
+}
+
+func (t *FFmpegTranscoder) Page(ctx context.Context, data []byte) ([]byte, error) {
+	if t.pdftoppm == "" {
+		return nil, transcoder.ErrRendererNotConfigured
+	}
+
+	dir, input, err := t.prepare(data)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	page, err := t.run(ctx, t.pdftoppm, "-f", "1", "-l", "1", "-singlefile", "-jpeg", "-scale-to", strconv.Itoa(file.ThumbnailMaxSize*2), input, "-")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(page) == 0 {
+		return nil, transcoder.ErrTranscodeFailed
+	}
+
+	return page, nil
+}
+
+// prepare writes the media to a temporary dir, ffmpeg needs a seekable input to read mp4 indexes at the end
+func (t *FFmpegTranscoder) prepare(data []byte) (string, string, error) {
+	if t.cfg.MaxInputSize > 0 && len(data) > t.cfg.MaxInputSize {
+		return "", "", transcoder.ErrMediaTooLarge
+	}
+
+	dir, err := os.MkdirTemp("", "whappy-transcode-")
+	if err != nil {
+		app.GetTranscoderLogger().Error("Error creating temporary dir", "error", err)
+		return "", "", transcoder.ErrTranscodeFailed
+	}
+
+	input := filepath.Join(dir, "input")
+	if err := os.WriteFile(input, data, 0o600); err != nil {
+		os.RemoveAll(dir)
+		app.GetTranscoderLogger().Error("Error writing temporary input", "error", err)
+		return "", "", transcoder.ErrTranscodeFailed
+	}
+
+	return dir, input, nil
+}
+
+// probe reads the streams of the file, the format is guessed from the content when it is not given. Only the file
+// protocol is allowed, so media crafted to point at urls cannot make ffprobe or ffmpeg open them
+func (t *FFmpegTranscoder) probe(ctx context.Context, path string, format string) (*transcoder.Probe, error) {
+	args := []string{"-v", "error", "-protocol_whitelist", "file"}
+	if format != "" {
+		args = append(args, "-f", format)
+	}
+
+	out, err := t.run(ctx, t.cfg.FFprobePath, append(args, "-print_format", "json", "-show_format", "-show_streams", path)...)
+	if err != nil {
+		return nil, err
+	}
+
+	probe, err := parseProbe(out)
+	if err != nil {
+		app.GetTranscoderLogger().Error("Error parsing probe", "error", err)
+		return nil, transcoder.ErrTranscodeFailed
+	}
+
+	if demuxer(probe) == "" || slices.Contains(playlistFormats, demuxer(probe)) {
+		app.GetTranscoderLogger().Error("Refusing media of an unsupported format", "format", probe.Format)
+		return nil, transcoder.ErrTranscodeFailed
+	}
+
+	if t.cfg.MaxDuration > 0 && probe.Duration != nil && time.Duration(*probe.Duration)*time.Second > t.cfg.MaxDuration {
+		return nil, transcoder.ErrMediaTooLong
+	}
+
+	return probe, nil
+}
+
+func (t *FFmpegTranscoder) ffmpeg(ctx context.Context, args ...string) ([]byte, error) {
+	// the whitelist applies to the input, the outputs are paths or the pipe chosen here
+	base := []string{"-hide_banner", "-loglevel", "error", "-nostdin", "-y", "-protocol_whitelist", "file"}
+	if t.cfg.Threads > 0 {
+		base = append(base, "-threads", strconv.Itoa(t.cfg.Threads))
+	}
+
+	return t.run(ctx, t.cfg.FFmpegPath, append(base, args...)...)
+}
+
+// run executes the binary within the timeout, the details of a failure are only logged
+func (t *FFmpegTranscoder) run(ctx context.Context, bin string, args ...string) ([]byte, error) {
+	if t.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		app.GetTranscoderLogger().Error("Error running "+filepath.Base(bin), "error", err, "stderr", strings.TrimSpace(stderr.String()))
+		return nil, transcoder.ErrTranscodeFailed
+	}
+
+	return stdout.Bytes(), nil
+}
+
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType    string            `json:"codec_type"`
+		CodecName    string            `json:"codec_name"`
+		Width        int               `json:"width"`
+		Height       int               `json:"height"`
+		Tags         map[string]string `json:"tags"`
+		SideDataList []struct {
+			Rotation int `json:"rotation"`
+		} `json:"side_data_list"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+	} `json:"format"`
+}
+
+func parseProbe(out []byte) (*transcoder.Probe, error) {
+	var parsed ffprobeOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, err
+	}
+
+	probe := &transcoder.Probe{Format: parsed.Format.FormatName}
+
+	if seconds, err := strconv.ParseFloat(parsed.Format.Duration, 64); err == nil && seconds > 0 {
+		duration := uint32(math.Ceil(seconds))
+		probe.Duration = &duration
+	}
+
+	for _, stream := range parsed.Streams {
+		switch stream.CodecType {
+		case "audio":
+			if probe.AudioCodec == "" {
+				probe.AudioCodec = stream.CodecName
+			}
+		case "video":
+			// cover art of audio files shows up as a video stream
+			if probe.VideoCodec != "" || stream.CodecName == "mjpeg" || stream.CodecName == "png" {
+				continue
+			}
+
+			probe.VideoCodec = stream.CodecName
+			width, height := uint32(stream.Width), uint32(stream.Height)
+
+			// phones record sideways and tell the player to rotate
+			rotation, _ := strconv.Atoi(stream.Tags["rotate"])
+			for _, side := range stream.SideDataList {
+				if side.Rotation != 0 {
+					rotation = side.Rotation
+				}
+			}
+			if rotation%180 != 0 {
+				width, height = height, width
+			}
+
+			probe.Width = &width
+			probe.Height = &height
+		}
+	}
+
+	return probe, nil
+}
+
+// demuxer is the name ffmpeg takes with -f for a probed format, ffprobe lists every alias like "mov,mp4,m4a"
+func demuxer(probe *transcoder.Probe) string {
+	name, _, _ := strings.Cut(probe.Format, ",")
+	return name
+}
@@ -0,0 +1,31 @@
+package transcoder
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app/transcoder"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+)
+
+// New returns the ffmpeg transcoder, nil when it is disabled, an error when the binaries are not found
+func New(cfg *config.TranscoderConfig) (transcoder.Transcoder, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	for _, bin := range []string{cfg.FFmpegPath, cfg.FFprobePath} {
+		if _, err := exec.LookPath(bin); err != nil {
+			return nil, fmt.Errorf("%s not found: %w", bin, err)
+		}
+	}
+
+	t := NewFFmpegTranscoder(cfg)
+
+	// pdf thumbnails are a nice to have, a missing pdftoppm only disables them
+	if path, err := exec.LookPath(cfg.PdftoppmPath); err == nil {
+		t.pdftoppm = path
+	}
+
+	return t, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/mauriciorobertodev/whappy-go/internal/app/cache"
 	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/events"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
 	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
@@ -24,6 +25,9 @@ type WhatsmeowGateway struct {
 	storage   storage.Storage
 	eventbus  events.EventBus
 	cache     cache.Cache
+
+	historyHandler whatsapp.HistorySyncHandler
+	mediaHandler   whatsapp.MediaHandler
 }
 
 func New(ctx context.Context, config *config.DatabaseConfig, storage storage.Storage, eventbus events.EventBus, cache cache.Cache) *WhatsmeowGateway {
@@ -2,6 +2,7 @@ package meow
 
 import (
 	"context"
+	"time"
 
 	"github.com/mauriciorobertodev/whappy-go/internal/app"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
@@ -43,3 +44,20 @@ func (g *WhatsmeowGateway) SendChatPresence(ctx context.Context, inst *instance.
 
 	return nil
 }
+
+func (g *WhatsmeowGateway) SetChatDisappearingTimer(ctx context.Context, inst *instance.Instance, chatJID string, timer chat.DisappearingTimer) error {
+	l := app.GetWhatsappLogger()
+	l.Info("Setting chat disappearing timer", "chat", chatJID, "duration", timer)
+
+	client, err := g.getOnlineClient(inst.ID)
+	if err != nil {
+		return err
+	}
+
+	jid, err := types.ParseJID(chatJID)
+	if err != nil {
+		return err
+	}
+
+	return client.SetDisappearingTimer(jid, timer.ToDuration(), time.Now())
+}
@@ -100,7 +100,7 @@ func (g *WhatsmeowGateway) Connect(ctx context.Context, inst *instance.Instance)
 					case *events.TemporaryBan:
 						fmt.Println("User got a temporary ban!", v.Code, v.Expire)
 					case *events.HistorySync:
-						fmt.Println("History sync completed!", v.Data.SyncType)
+						g.handleHistorySync(inst, client, v)
 					case *events.UndecryptableMessage:
 						fmt.Println("Received an undecryptable message!")
 					case *events.NewsletterMessageMeta:
@@ -108,6 +108,21 @@ func (g *WhatsmeowGateway) Connect(ctx context.Context, inst *instance.Instance)
 					case *events.Message:
 						fmt.Println("New message received from:", v.Info.Sender.String(), "in chat:", v.Info.Chat.String(), "with id:", v.Info.ID)
 
+						if protocol := v.Message.GetProtocolMessage(); protocol != nil {
+							switch protocol.GetType() {
+							case waE2E.ProtocolMessage_MESSAGE_EDIT:
+								g.emitMessageEdited(inst, v)
+							case waE2E.ProtocolMessage_REVOKE:
+								g.emitMessageDeleted(inst, v)
+							case waE2E.ProtocolMessage_EPHEMERAL_SETTING:
+								// groups report their timer through the group info instead
+								if !IsGroup(v.Info.Chat) {
+									g.emitChatChangedDisappearing(inst, v)
+								}
+							}
+							return
+						}
+
 						if IsStatus(v.Info.Chat) {
 							fmt.Println("Status message received")
 							g.emitStatusNew(inst, v)
@@ -0,0 +1,118 @@
+package meow
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"go.mau.fi/whatsmeow"
+)
+
+func (g *WhatsmeowGateway) OnMedia(handler whatsapp.MediaHandler) {
+	g.mediaHandler = handler
+}
+
+// storeMedia hands a received media to the media handler after its event is published, own messages are
+// left out
+func (g *WhatsmeowGateway) storeMedia(inst *instance.Instance, m *message.Message) {
+	if g.mediaHandler == nil || m.IsFromMe {
+		return
+	}
+
+	if media, _ := m.MediaFile(); media == nil {
+		return
+	}
+
+	g.mediaHandler(inst, m)
+}
+
+func (g *WhatsmeowGateway) DownloadFile(ctx context.Context, inst *instance.Instance, f *file.File, kind whatsapp.MediaKind, dst whatsapp.DownloadTarget) error {
+	l := app.GetWhatsappLogger()
+
+	client, err := g.getOnlineClient(inst.ID)
+	if err != nil {
+		return err
+	}
+
+	mediaKey, err := hex.DecodeString(f.MediaKey)
+	if err != nil {
+		return file.ErrMediaNotDownloadable
+	}
+	sha256Enc, err := hex.DecodeString(f.Sha256Enc)
+	if err != nil {
+		return file.ErrMediaNotDownloadable
+	}
+	sha256, err := hex.DecodeString(f.Sha256)
+	if err != nil {
+		return file.ErrMediaHashMismatch
+	}
+
+	size := -1
+	if f.Size > 0 {
+		size = int(f.Size)
+	}
+
+	mediaType := mediaKindToWhatsmeowMediaType(kind)
+
+	// the direct path works with any media host, the url is only used when there is no direct path and only
+	// when it points to a media host of whatsapp
+	switch {
+	case f.DirectPath != "":
+		err = client.DownloadMediaWithPathToFile(ctx, f.DirectPath, sha256Enc, sha256, mediaKey, size, mediaType, "", dst)
+	case file.IsWhatsAppMediaURL(f.URL):
+		err = client.DownloadToFile(ctx, &downloadable{url: f.URL, mediaKey: mediaKey, sha256: sha256, sha256Enc: sha256Enc, mediaType: mediaType}, dst)
+	default:
+		return file.ErrInvalidMediaURL
+	}
+
+	if err != nil {
+		l.Error("Failed to download media", "instance", inst.ID, "error", err)
+		return translateDownloadError(err)
+	}
+
+	l.Debug("Media downloaded", "instance", inst.ID)
+	return nil
+}
+
+func translateDownloadError(err error) error {
+	switch {
+	case errors.Is(err, file.ErrFileTooLarge):
+		return file.ErrFileTooLarge
+	case errors.Is(err, whatsmeow.ErrInvalidMediaHMAC),
+		errors.Is(err, whatsmeow.ErrInvalidMediaEncSHA256),
+		errors.Is(err, whatsmeow.ErrInvalidMediaSHA256),
+		errors.Is(err, whatsmeow.ErrFileLengthMismatch),
+		errors.Is(err, whatsmeow.ErrTooShortFile):
+		return file.ErrMediaHashMismatch
+	case errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith403),
+		errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith404),
+		errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith410):
+		return file.ErrMediaExpired
+	case errors.Is(err, whatsmeow.ErrNoURLPresent):
+		return file.ErrMediaNotDownloadable
+	default:
+		return file.ErrDownloadFailed
+	}
+}
+
+// downloadable describes a media by its url for whatsmeow, it is used when the direct path is not known, the
+// plaintext hash still verifies the content
+type downloadable struct {
+	url       string
+	mediaKey  []byte
+	sha256    []byte
+	sha256Enc []byte
+	mediaType whatsmeow.MediaType
+}
+
+func (d *downloadable) GetURL() string                    { return d.url }
+func (d *downloadable) GetDirectPath() string             { return "" }
+func (d *downloadable) GetMediaKey() []byte               { return d.mediaKey }
+func (d *downloadable) GetFileSHA256() []byte             { return d.sha256 }
+func (d *downloadable) GetFileEncSHA256() []byte          { return d.sha256Enc }
+func (d *downloadable) GetMediaType() whatsmeow.MediaType { return d.mediaType }
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/blocklist"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/community"
@@ -16,6 +17,7 @@ import (
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/newsletter"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/privacy"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/user"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
 	meowEvents "go.mau.fi/whatsmeow/types/events"
 )
@@ -60,6 +62,35 @@ func (g *WhatsmeowGateway) emitMessagePlayed(inst *instance.Instance, evt *meowE
 	))
 }
 
+func (g *WhatsmeowGateway) emitMessageEdited(inst *instance.Instance, evt *meowEvents.Message) {
+	protocol := evt.Message.GetProtocolMessage()
+
+	g.eventbus.Publish(events.New(
+		message.EventMessageEdited,
+		message.PayloadMessageEdited{
+			Message:   protocol.GetKey().GetID(),
+			Chat:      evt.Info.Chat.String(),
+			Sender:    evt.Info.Sender.String(),
+			Text:      getTextFromMessage(protocol.GetEditedMessage()),
+			Timestamp: evt.Info.Timestamp,
+		},
+		&inst.ID,
+	))
+}
+
+func (g *WhatsmeowGateway) emitMessageDeleted(inst *instance.Instance, evt *meowEvents.Message) {
+	g.eventbus.Publish(events.New(
+		message.EventMessageDeleted,
+		message.PayloadMessageDeleted{
+			Message:   evt.Message.GetProtocolMessage().GetKey().GetID(),
+			Chat:      evt.Info.Chat.String(),
+			Sender:    evt.Info.Sender.String(),
+			Timestamp: evt.Info.Timestamp,
+		},
+		&inst.ID,
+	))
+}
+
 // #region Status Event Emitters
 func (gate *WhatsmeowGateway) emitStatusNew(inst *instance.Instance, evt *meowEvents.Message) {
 	// TODO: Implement status handling if needed
@@ -194,6 +225,11 @@ func (g *WhatsmeowGateway) emitUserNewMessage(inst *instance.Instance, evt *meow
 	m := whatsmeowMessageToDomainMessage(evt)
 	m.InstanceID = &inst.ID
 
+	if m.Content == nil {
+		app.GetWhatsappLogger().Debug("Unsupported message type", "type", evt.Info.Type, "media", evt.Info.MediaType, "id", evt.Info.ID)
+		return
+	}
+
 	var eventName events.EventName
 
 	switch m.Content.Kind() {
@@ -209,6 +245,10 @@ func (g *WhatsmeowGateway) emitUserNewMessage(inst *instance.Instance, evt *meow
 		eventName = user.EventNewVoiceMessage
 	case message.MessageKindDocument:
 		eventName = user.EventNewDocumentMessage
+	case message.MessageKindButtonReply:
+		eventName = user.EventNewButtonReply
+	case message.MessageKindListReply:
+		eventName = user.EventNewListReply
 	case message.MessageKindReaction:
 		eventName = message.EventMessageReactionNew
 		if reaction, ok := m.Content.(message.ReactionContent); ok {
@@ -231,6 +271,8 @@ func (g *WhatsmeowGateway) emitUserNewMessage(inst *instance.Instance, evt *meow
 		},
 		&inst.ID,
 	))
+
+	g.storeMedia(inst, m)
 }
 
 // #region Newsletter Event Handlers
@@ -256,6 +298,11 @@ func (g *WhatsmeowGateway) emitNewsletterNewPost(inst *instance.Instance, evt *m
 	m := whatsmeowMessageToDomainMessage(evt)
 	m.InstanceID = &inst.ID
 
+	if m.Content == nil {
+		app.GetWhatsappLogger().Debug("Unsupported message type", "type", evt.Info.Type, "media", evt.Info.MediaType, "id", evt.Info.ID)
+		return
+	}
+
 	var eventName events.EventName
 
 	switch m.Content.Kind() {
@@ -293,6 +340,8 @@ func (g *WhatsmeowGateway) emitNewsletterNewPost(inst *instance.Instance, evt *m
 		},
 		&inst.ID,
 	))
+
+	g.storeMedia(inst, m)
 }
 
 // #region Group Event Handlers
@@ -520,6 +569,11 @@ func (g *WhatsmeowGateway) emitGroupNewMessage(inst *instance.Instance, evt *meo
 	m := whatsmeowMessageToDomainMessage(evt)
 	m.InstanceID = &inst.ID
 
+	if m.Content == nil {
+		app.GetWhatsappLogger().Debug("Unsupported message type", "type", evt.Info.Type, "media", evt.Info.MediaType, "id", evt.Info.ID)
+		return
+	}
+
 	var eventName events.EventName
 
 	switch m.Content.Kind() {
@@ -535,6 +589,10 @@ func (g *WhatsmeowGateway) emitGroupNewMessage(inst *instance.Instance, evt *meo
 		eventName = group.EventNewVoiceMessage
 	case message.MessageKindDocument:
 		eventName = group.EventNewDocumentMessage
+	case message.MessageKindButtonReply:
+		eventName = group.EventNewButtonReply
+	case message.MessageKindListReply:
+		eventName = group.EventNewListReply
 	case message.MessageKindReaction:
 		eventName = message.EventMessageReactionNew
 		if reaction, ok := m.Content.(message.ReactionContent); ok {
@@ -556,6 +614,8 @@ func (g *WhatsmeowGateway) emitGroupNewMessage(inst *instance.Instance, evt *meo
 		},
 		&inst.ID,
 	))
+
+	g.storeMedia(inst, m)
 }
 
 // #region Community Event Handlers
@@ -581,6 +641,11 @@ func (g *WhatsmeowGateway) emitCommunityAnnouncement(inst *instance.Instance, ev
 	m := whatsmeowMessageToDomainMessage(evt)
 	m.InstanceID = &inst.ID
 
+	if m.Content == nil {
+		app.GetWhatsappLogger().Debug("Unsupported message type", "type", evt.Info.Type, "media", evt.Info.MediaType, "id", evt.Info.ID)
+		return
+	}
+
 	var eventName events.EventName
 
 	switch m.Content.Kind() {
@@ -618,6 +683,8 @@ func (g *WhatsmeowGateway) emitCommunityAnnouncement(inst *instance.Instance, ev
 		},
 		&inst.ID,
 	))
+
+	g.storeMedia(inst, m)
 }
 
 // #region Chat Event Handlers
@@ -729,6 +796,19 @@ func (g *WhatsmeowGateway) emitChatChangedArchive(inst *instance.Instance, evt *
 	))
 }
 
+func (g *WhatsmeowGateway) emitChatChangedDisappearing(inst *instance.Instance, evt *meowEvents.Message) {
+	g.eventbus.Publish(events.New(
+		chat.ChatChangedDisappearing,
+		chat.PayloadChatChangedDisappearing{
+			Chat:       evt.Info.Chat.String(),
+			Sender:     evt.Info.Sender.String(),
+			Expiration: evt.Message.GetProtocolMessage().GetEphemeralExpiration(),
+			Timestamp:  evt.Info.Timestamp,
+		},
+		&inst.ID,
+	))
+}
+
 // #region Blocklist Event Emitters
 func (g *WhatsmeowGateway) emitBlocklistChanged(inst *instance.Instance, evt *meowEvents.Blocklist) {
 	changes := make([]blocklist.BlocklistChange, len(evt.Changes))
@@ -763,10 +843,12 @@ func whatsmeowMessageToDomainMessage(msg *meowEvents.Message) *message.Message {
 
 		mentioned := raw.GetContextInfo().GetMentionedJID()
 
-		content = message.NewTextContent(
+		text := message.NewTextContent(
 			raw.GetText(),
 			&mentioned,
 		)
+		text.Preview = getLinkPreview(raw)
+		content = text
 	}
 
 	if msg.Message.GetConversation() != "" {
@@ -789,14 +871,15 @@ func whatsmeowMessageToDomainMessage(msg *meowEvents.Message) *message.Message {
 		height := raw.GetHeight()
 		imageFile := file.ImageFile{
 			File: file.File{
-				URL:       raw.GetURL(),
-				Path:      raw.GetDirectPath(),
-				Mime:      raw.GetMimetype(),
-				Size:      raw.GetFileLength(),
-				Sha256:    fmt.Sprintf("%x", raw.GetFileSHA256()),
-				Sha256Enc: fmt.Sprintf("%x", raw.GetFileEncSHA256()),
-				MediaKey:  fmt.Sprintf("%x", raw.GetMediaKey()),
-				Extension: file.DetectExtension(raw.GetMimetype()),
+				URL:        raw.GetURL(),
+				Path:       raw.GetDirectPath(),
+				DirectPath: raw.GetDirectPath(),
+				Mime:       raw.GetMimetype(),
+				Size:       raw.GetFileLength(),
+				Sha256:     fmt.Sprintf("%x", raw.GetFileSHA256()),
+				Sha256Enc:  fmt.Sprintf("%x", raw.GetFileEncSHA256()),
+				MediaKey:   fmt.Sprintf("%x", raw.GetMediaKey()),
+				Extension:  file.DetectExtension(raw.GetMimetype()),
 			},
 			Width:  &width,
 			Height: &height,
@@ -829,14 +912,15 @@ func whatsmeowMessageToDomainMessage(msg *meowEvents.Message) *message.Message {
 		duration := raw.GetSeconds()
 		videoFile := file.VideoFile{
 			File: file.File{
-				URL:       raw.GetURL(),
-				Path:      raw.GetDirectPath(),
-				Mime:      raw.GetMimetype(),
-				Size:      raw.GetFileLength(),
-				Sha256:    fmt.Sprintf("%x", raw.GetFileSHA256()),
-				Sha256Enc: fmt.Sprintf("%x", raw.GetFileEncSHA256()),
-				MediaKey:  fmt.Sprintf("%x", raw.GetMediaKey()),
-				Extension: file.DetectExtension(raw.GetMimetype()),
+				URL:        raw.GetURL(),
+				Path:       raw.GetDirectPath(),
+				DirectPath: raw.GetDirectPath(),
+				Mime:       raw.GetMimetype(),
+				Size:       raw.GetFileLength(),
+				Sha256:     fmt.Sprintf("%x", raw.GetFileSHA256()),
+				Sha256Enc:  fmt.Sprintf("%x", raw.GetFileEncSHA256()),
+				MediaKey:   fmt.Sprintf("%x", raw.GetMediaKey()),
+				Extension:  file.DetectExtension(raw.GetMimetype()),
 			},
 			Width:    &width,
 			Height:   &height,
@@ -865,14 +949,15 @@ func whatsmeowMessageToDomainMessage(msg *meowEvents.Message) *message.Message {
 		expiration = raw.GetContextInfo().Expiration
 
 		f := file.File{
-			URL:       raw.GetURL(),
-			Path:      raw.GetDirectPath(),
-			Mime:      raw.GetMimetype(),
-			Size:      raw.GetFileLength(),
-			Sha256:    hex.EncodeToString(raw.GetFileSHA256()),
-			Sha256Enc: hex.EncodeToString(raw.GetFileEncSHA256()),
-			MediaKey:  hex.EncodeToString(raw.GetMediaKey()),
-			Extension: file.DetectExtension(raw.GetMimetype()),
+			URL:        raw.GetURL(),
+			Path:       raw.GetDirectPath(),
+			DirectPath: raw.GetDirectPath(),
+			Mime:       raw.GetMimetype(),
+			Size:       raw.GetFileLength(),
+			Sha256:     hex.EncodeToString(raw.GetFileSHA256()),
+			Sha256Enc:  hex.EncodeToString(raw.GetFileEncSHA256()),
+			MediaKey:   hex.EncodeToString(raw.GetMediaKey()),
+			Extension:  file.DetectExtension(raw.GetMimetype()),
 		}
 
 		if msg.Message.AudioMessage.PTT != nil && *msg.Message.AudioMessage.PTT {
@@ -890,16 +975,17 @@ func whatsmeowMessageToDomainMessage(msg *meowEvents.Message) *message.Message {
 
 		pages := raw.GetPageCount()
 		docFile := file.File{
-			Name:      raw.GetFileName(),
-			URL:       raw.GetURL(),
-			Path:      raw.GetDirectPath(),
-			Mime:      raw.GetMimetype(),
-			Size:      raw.GetFileLength(),
-			Sha256:    hex.EncodeToString(raw.GetFileSHA256()),
-			Sha256Enc: hex.EncodeToString(raw.GetFileEncSHA256()),
-			MediaKey:  hex.EncodeToString(raw.GetMediaKey()),
-			Extension: file.DetectExtension(raw.GetMimetype()),
-			Pages:     &pages,
+			Name:       raw.GetFileName(),
+			URL:        raw.GetURL(),
+			Path:       raw.GetDirectPath(),
+			DirectPath: raw.GetDirectPath(),
+			Mime:       raw.GetMimetype(),
+			Size:       raw.GetFileLength(),
+			Sha256:     hex.EncodeToString(raw.GetFileSHA256()),
+			Sha256Enc:  hex.EncodeToString(raw.GetFileEncSHA256()),
+			MediaKey:   hex.EncodeToString(raw.GetMediaKey()),
+			Extension:  file.DetectExtension(raw.GetMimetype()),
+			Pages:      &pages,
 		}
 
 		thumbnail := new(string)
@@ -923,6 +1009,34 @@ func whatsmeowMessageToDomainMessage(msg *meowEvents.Message) *message.Message {
 		)
 	}
 
+	if msg.Message.GetButtonsResponseMessage() != nil {
+		raw := msg.Message.GetButtonsResponseMessage()
+		content = message.NewButtonReplyContent(
+			raw.GetSelectedButtonID(),
+			raw.GetSelectedDisplayText(),
+			raw.GetContextInfo().GetStanzaID(),
+		)
+	}
+
+	if msg.Message.GetTemplateButtonReplyMessage() != nil {
+		raw := msg.Message.GetTemplateButtonReplyMessage()
+		content = message.NewButtonReplyContent(
+			raw.GetSelectedID(),
+			raw.GetSelectedDisplayText(),
+			raw.GetContextInfo().GetStanzaID(),
+		)
+	}
+
+	if msg.Message.GetListResponseMessage() != nil {
+		raw := msg.Message.GetListResponseMessage()
+		content = message.NewListReplyContent(
+			raw.GetSingleSelectReply().GetSelectedRowID(),
+			raw.GetTitle(),
+			raw.Description,
+			raw.GetContextInfo().GetStanzaID(),
+		)
+	}
+
 	message := message.NewMessage(
 		&msg.Info.ID,
 		msg.Info.Sender.String(),
@@ -933,9 +1047,75 @@ func whatsmeowMessageToDomainMessage(msg *meowEvents.Message) *message.Message {
 		msg.Info.IsFromMe,
 	)
 
+	sentAt := msg.Info.Timestamp
+	message.SentAt = &sentAt
+
+	context := getContextInfo(msg.Message)
+	message.ReplyTo = getReplyFromContextInfo(context)
+	message.IsForwarded = context.GetIsForwarded()
+	message.ForwardingScore = context.GetForwardingScore()
+
 	return message
 }
 
+func getContextInfo(msg *waE2E.Message) *waE2E.ContextInfo {
+	switch {
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetContextInfo()
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetContextInfo()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetContextInfo()
+	case msg.GetAudioMessage() != nil:
+		return msg.GetAudioMessage().GetContextInfo()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetContextInfo()
+	case msg.GetButtonsResponseMessage() != nil:
+		return msg.GetButtonsResponseMessage().GetContextInfo()
+	case msg.GetTemplateButtonReplyMessage() != nil:
+		return msg.GetTemplateButtonReplyMessage().GetContextInfo()
+	case msg.GetListResponseMessage() != nil:
+		return msg.GetListResponseMessage().GetContextInfo()
+	}
+
+	return nil
+}
+
+func getReplyFromContextInfo(ctx *waE2E.ContextInfo) *message.ReplyTo {
+	if ctx.GetStanzaID() == "" {
+		return nil
+	}
+
+	reply := &message.ReplyTo{
+		ID:     ctx.GetStanzaID(),
+		Sender: ctx.GetParticipant(),
+	}
+
+	if text := getTextFromMessage(ctx.GetQuotedMessage()); text != "" {
+		reply.Content = &text
+	}
+
+	return reply
+}
+
+// getTextFromMessage returns the text of a text message or the caption of a media message
+func getTextFromMessage(msg *waE2E.Message) string {
+	switch {
+	case msg.GetConversation() != "":
+		return msg.GetConversation()
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetText()
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetCaption()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetCaption()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetCaption()
+	}
+
+	return ""
+}
+
 func getSenderFromMessage(msg *meowEvents.Message) message.Sender {
 	phone := ""
 	jid, lid := GetJIDAndLID(msg.Info.Sender)
@@ -957,3 +1137,22 @@ func getSenderFromMessage(msg *meowEvents.Message) message.Sender {
 		Name:  msg.Info.PushName,
 	}
 }
+
+func getLinkPreview(raw *waE2E.ExtendedTextMessage) *message.LinkPreview {
+	if raw.GetMatchedText() == "" {
+		return nil
+	}
+
+	preview := &message.LinkPreview{
+		URL:         raw.GetMatchedText(),
+		Title:       raw.Title,
+		Description: raw.Description,
+	}
+
+	if len(raw.GetJPEGThumbnail()) > 0 {
+		thumbnail := base64.StdEncoding.EncodeToString(raw.GetJPEGThumbnail())
+		preview.Thumbnail = &thumbnail
+	}
+
+	return preview
+}
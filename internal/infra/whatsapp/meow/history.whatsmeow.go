@@ -0,0 +1,178 @@
+package meow
+
+import (
+	"context"
+	"time"
+
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/whatsapp"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/chat"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	meowEvents "go.mau.fi/whatsmeow/types/events"
+)
+
+func (g *WhatsmeowGateway) OnHistorySync(handler whatsapp.HistorySyncHandler) {
+	g.historyHandler = handler
+}
+
+func (g *WhatsmeowGateway) RequestHistory(ctx context.Context, inst *instance.Instance, anchor *message.Message, count int) error {
+	client, err := g.getOnlineClient(inst.ID)
+	if err != nil {
+		return err
+	}
+
+	chatJID, err := types.ParseJID(anchor.Chat)
+	if err != nil {
+		return err
+	}
+
+	info := &types.MessageInfo{
+		MessageSource: types.MessageSource{
+			Chat:     chatJID,
+			IsFromMe: anchor.IsFromMe,
+		},
+		ID:        *anchor.ExternalID,
+		Timestamp: anchor.Timestamp(),
+	}
+
+	// the request goes to our own phone, it answers with an on demand history sync
+	_, err = client.SendMessage(ctx, client.Store.ID.ToNonAD(), client.BuildHistorySyncRequest(info, count), whatsmeow.SendRequestExtra{Peer: true})
+	return err
+}
+
+func (g *WhatsmeowGateway) handleHistorySync(inst *instance.Instance, client *whatsmeow.Client, evt *meowEvents.HistorySync) {
+	l := app.GetWhatsappLogger()
+
+	kind, ok := historySyncType(evt.Data.GetSyncType())
+	if !ok {
+		l.Debug("Ignoring history sync without conversations", "instance", inst.ID, "type", evt.Data.GetSyncType().String())
+		return
+	}
+
+	if g.historyHandler == nil {
+		return
+	}
+
+	history := whatsapp.HistorySync{
+		Type:     kind,
+		Chunk:    evt.Data.GetChunkOrder(),
+		Progress: evt.Data.Progress,
+		Chats:    make([]*chat.Chat, 0, len(evt.Data.GetConversations())),
+		Messages: []*message.Message{},
+	}
+
+	for _, conv := range evt.Data.GetConversations() {
+		chatJID, err := types.ParseJID(conv.GetID())
+		if err != nil {
+			l.Warn("Skipping history conversation with invalid jid", "instance", inst.ID, "jid", conv.GetID())
+			continue
+		}
+
+		history.Chats = append(history.Chats, whatsmeowConversationToDomainChat(inst, chatJID, conv))
+
+		for _, hsm := range conv.GetMessages() {
+			webMsg := hsm.GetMessage()
+			if webMsg == nil {
+				continue
+			}
+
+			evt, err := client.ParseWebMessage(chatJID, webMsg)
+			if err != nil {
+				l.Debug("Skipping unparsable history message", "instance", inst.ID, "chat", chatJID.String(), "error", err)
+				continue
+			}
+
+			if evt.Message.GetProtocolMessage() != nil {
+				continue
+			}
+
+			msg := whatsmeowMessageToDomainMessage(evt)
+			if msg.Content == nil {
+				continue
+			}
+
+			msg.InstanceID = &inst.ID
+			if msg.IsFromMe {
+				msg.Status = historyMessageStatus(webMsg.GetStatus())
+			}
+
+			history.Messages = append(history.Messages, msg)
+		}
+	}
+
+	l.Info("History sync received", "instance", inst.ID, "type", kind, "chunk", history.Chunk, "chats", len(history.Chats), "messages", len(history.Messages))
+
+	g.historyHandler(inst, history)
+}
+
+func historySyncType(t waHistorySync.HistorySync_HistorySyncType) (whatsapp.HistorySyncType, bool) {
+	switch t {
+	case waHistorySync.HistorySync_INITIAL_BOOTSTRAP:
+		return whatsapp.HistorySyncInitial, true
+	case waHistorySync.HistorySync_RECENT:
+		return whatsapp.HistorySyncRecent, true
+	case waHistorySync.HistorySync_FULL:
+		return whatsapp.HistorySyncFull, true
+	case waHistorySync.HistorySync_ON_DEMAND:
+		return whatsapp.HistorySyncOnDemand, true
+	default:
+		return "", false
+	}
+}
+
+func historyMessageStatus(status waWeb.WebMessageInfo_Status) message.MessageStatus {
+	switch status {
+	case waWeb.WebMessageInfo_ERROR:
+		return message.MessageStatusFailed
+	case waWeb.WebMessageInfo_PENDING:
+		return message.MessageStatusPending
+	case waWeb.WebMessageInfo_DELIVERY_ACK:
+		return message.MessageStatusDelivered
+	case waWeb.WebMessageInfo_READ:
+		return message.MessageStatusRead
+	case waWeb.WebMessageInfo_PLAYED:
+		return message.MessageStatusPlayed
+	default:
+		return message.MessageStatusSent
+	}
+}
+
+func whatsmeowConversationToDomainChat(inst *instance.Instance, jid types.JID, conv *waHistorySync.Conversation) *chat.Chat {
+	c := chat.New(jid.String(), inst.ID)
+	c.UnreadCount = conv.GetUnreadCount()
+	c.Archived = conv.GetArchived()
+	c.Pinned = conv.GetPinned() > 0
+
+	if name := conv.GetName(); name != "" {
+		c.Name = &name
+	}
+
+	if expiration := conv.GetEphemeralExpiration(); expiration > 0 {
+		c.Expiration = &expiration
+	}
+
+	if ts := conv.GetConversationTimestamp(); ts > 0 {
+		at := time.Unix(int64(ts), 0).UTC()
+		c.LastMessageAt = &at
+	}
+
+	if mute := conv.GetMuteEndTime(); mute > 0 {
+		at := unixToTime(mute)
+		c.MutedUntil = &at
+	}
+
+	return c
+}
+
+// unixToTime accepts both seconds and milliseconds, the phone is not consistent about the unit
+func unixToTime(v uint64) time.Time {
+	if v > 1e12 {
+		return time.UnixMilli(int64(v)).UTC()
+	}
+	return time.Unix(int64(v), 0).UTC()
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/group"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
@@ -29,173 +30,97 @@ func (g *WhatsmeowGateway) GenerateMessageID(ctx context.Context, inst *instance
 }
 
 func (g *WhatsmeowGateway) SendTextMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
-	l := app.GetMessageServiceLogger()
-
 	if msg.Content.Kind() != message.MessageKindText {
 		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindText, msg.Content.Kind())
 	}
 
-	client, err := g.getOnlineClient(inst.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	content := msg.Content.(message.TextContent)
+	return g.sendMessage(ctx, inst, msg)
+}
 
-	context := &waE2E.ContextInfo{
-		Expiration: msg.Expiration,
+func (g *WhatsmeowGateway) SendImageMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content.Kind() != message.MessageKindImage {
+		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindImage, msg.Content.Kind())
 	}
 
-	if content.HasMentions() {
-		context.MentionedJID = *content.Mentions
-	}
+	return g.sendMessage(ctx, inst, msg)
+}
 
-	extended := &waE2E.ExtendedTextMessage{
-		Text:        &content.Text,
-		ContextInfo: context,
+func (g *WhatsmeowGateway) SendVideoMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content.Kind() != message.MessageKindVideo {
+		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindVideo, msg.Content.Kind())
 	}
 
-	whatsappMessage := &waE2E.Message{
-		ExtendedTextMessage: extended,
-	}
+	return g.sendMessage(ctx, inst, msg)
+}
 
-	to, err := types.ParseJID(msg.Chat)
-	if err != nil {
-		return nil, err
+func (g *WhatsmeowGateway) SendAudioMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content.Kind() != message.MessageKindAudio {
+		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindAudio, msg.Content.Kind())
 	}
 
-	extra := whatsmeow.SendRequestExtra{}
-	if msg.ExternalID != nil {
-		extra.ID = *msg.ExternalID
-	}
+	return g.sendMessage(ctx, inst, msg)
+}
 
-	resp, err := client.SendMessage(ctx, to, whatsappMessage, extra)
-	if err != nil {
-		if err.Error() == ErrServerStatus420.Error() {
-			if to.Server == types.GroupServer {
-				l.Error("Failed to send message to group, maybe the instance is not in the group", "phone", inst.Phone, "chat", msg.Chat)
-				return nil, group.ErrMaybeNotMember
-			}
-		}
-		return nil, err
+func (g *WhatsmeowGateway) SendVoiceMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content.Kind() != message.MessageKindVoice {
+		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindVoice, msg.Content.Kind())
 	}
 
-	msg.ExternalID = &resp.ID
-
-	return msg, nil
+	return g.sendMessage(ctx, inst, msg)
 }
 
-func (g *WhatsmeowGateway) SendImageMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
-	if msg.Content.Kind() != message.MessageKindImage {
-		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindImage, msg.Content.Kind())
-	}
-
-	client, err := g.getOnlineClient(inst.ID)
-	if err != nil {
-		return nil, err
+func (g *WhatsmeowGateway) SendDocumentMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content.Kind() != message.MessageKindDocument {
+		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindDocument, msg.Content.Kind())
 	}
 
-	content := msg.Content.(message.ImageContent)
-
-	context := &waE2E.ContextInfo{
-		Expiration: msg.Expiration,
-	}
+	return g.sendMessage(ctx, inst, msg)
+}
 
-	if content.HasMentions() {
-		context.MentionedJID = *content.Mentions
+func (g *WhatsmeowGateway) SendButtonsMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content.Kind() != message.MessageKindButtons {
+		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindButtons, msg.Content.Kind())
 	}
 
-	mediaKey, _ := hex.DecodeString(content.Image.MediaKey)
-	sa256Enc, _ := hex.DecodeString(content.Image.Sha256Enc)
-	sa256, _ := hex.DecodeString(content.Image.Sha256)
+	return g.sendMessage(ctx, inst, msg)
+}
 
-	whatsappMessage := &waE2E.Message{
-		ImageMessage: &waE2E.ImageMessage{
-			URL:           &content.Image.URL,
-			DirectPath:    &content.Image.DirectPath,
-			Mimetype:      &content.Image.Mime,
-			MediaKey:      mediaKey,
-			FileEncSHA256: sa256Enc,
-			FileSHA256:    sa256,
-			FileLength:    &content.Image.Size,
-			Caption:       content.Caption,
-			Height:        content.Image.Height,
-			Width:         content.Image.Width,
-			ContextInfo:   context,
-			ViewOnce:      content.ViewOnce,
-		},
+func (g *WhatsmeowGateway) SendListMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content.Kind() != message.MessageKindList {
+		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindList, msg.Content.Kind())
 	}
 
-	if content.HasThumbnail() {
-		data, err := base64.StdEncoding.DecodeString(*content.Thumbnail)
-		if err == nil {
-			whatsappMessage.ImageMessage.JPEGThumbnail = data
-		}
-	}
+	return g.sendMessage(ctx, inst, msg)
+}
 
-	to, err := types.ParseJID(msg.Chat)
-	if err != nil {
-		return nil, err
+func (g *WhatsmeowGateway) SendTemplateMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content.Kind() != message.MessageKindTemplate {
+		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindTemplate, msg.Content.Kind())
 	}
 
-	extra := whatsmeow.SendRequestExtra{}
-	if msg.ExternalID != nil {
-		extra.ID = *msg.ExternalID
-	}
+	return g.sendMessage(ctx, inst, msg)
+}
 
-	resp, err := client.SendMessage(ctx, to, whatsappMessage, extra)
-	if err != nil {
-		return nil, err
+func (g *WhatsmeowGateway) ForwardMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	if msg.Content == nil || msg.Content.Kind() == message.MessageKindReaction {
+		return nil, message.ErrNotForwardable
 	}
 
-	msg.ExternalID = &resp.ID
-
-	return msg, nil
+	return g.sendMessage(ctx, inst, msg)
 }
 
-func (g *WhatsmeowGateway) SendVideoMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
-	if msg.Content.Kind() != message.MessageKindVideo {
-		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindVideo, msg.Content.Kind())
-	}
+// sendMessage converts the domain message and sends it to its chat, the message external id is filled with the sent id
+func (g *WhatsmeowGateway) sendMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
+	l := app.GetMessageServiceLogger()
 
 	client, err := g.getOnlineClient(inst.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	content := msg.Content.(*message.VideoContent)
-
-	context := &waE2E.ContextInfo{
-		Expiration: msg.Expiration,
-	}
-
-	if content.HasMentions() {
-		context.MentionedJID = *content.Mentions
-	}
-
-	whatsappMessage := &waE2E.Message{
-		VideoMessage: &waE2E.VideoMessage{
-			URL:           &content.Video.URL,
-			DirectPath:    &content.Video.DirectPath,
-			MediaKey:      []byte(content.Video.MediaKey),
-			Mimetype:      &content.Video.Mime,
-			FileEncSHA256: []byte(content.Video.Sha256Enc),
-			FileSHA256:    []byte(content.Video.Sha256),
-			FileLength:    &content.Video.Size,
-			Caption:       content.Caption,
-			Height:        content.Video.Height,
-			Width:         content.Video.Width,
-			Seconds:       content.Video.Duration,
-			ContextInfo:   context,
-			ViewOnce:      content.ViewOnce,
-		},
-	}
-
-	if content.HasThumbnail() {
-		data, err := base64.StdEncoding.DecodeString(*content.Thumbnail)
-		if err == nil {
-			whatsappMessage.ImageMessage.JPEGThumbnail = data
-		}
+	whatsappMessage, err := toWhatsmeowMessage(msg)
+	if err != nil {
+		return nil, err
 	}
 
 	to, err := types.ParseJID(msg.Chat)
@@ -210,174 +135,311 @@ func (g *WhatsmeowGateway) SendVideoMessage(ctx context.Context, inst *instance.
 
 	resp, err := client.SendMessage(ctx, to, whatsappMessage, extra)
 	if err != nil {
+		if err.Error() == ErrServerStatus420.Error() {
+			if to.Server == types.GroupServer {
+				l.Error("Failed to send message to group, maybe the instance is not in the group", "phone", inst.Phone, "chat", msg.Chat)
+				return nil, group.ErrMaybeNotMember
+			}
+		}
 		return nil, err
 	}
 
 	msg.ExternalID = &resp.ID
+	msg.MarkAsSent(resp.Timestamp)
 
 	return msg, nil
 }
 
-func (g *WhatsmeowGateway) SendAudioMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
-	if msg.Content.Kind() != message.MessageKindAudio {
-		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindAudio, msg.Content.Kind())
-	}
+// toWhatsmeowMessage builds the whatsmeow message for text and media contents, media is referenced by the
+// already uploaded direct path and media key so nothing is uploaded again
+func toWhatsmeowMessage(msg *message.Message) (*waE2E.Message, error) {
+	context := newContextInfo(msg)
 
-	client, err := g.getOnlineClient(inst.ID)
-	if err != nil {
-		return nil, err
-	}
+	switch content := msg.Content.(type) {
+	case message.TextContent:
+		if content.HasMentions() {
+			context.MentionedJID = *content.Mentions
+		}
 
-	content := msg.Content.(*message.AudioContent)
+		extended := &waE2E.ExtendedTextMessage{
+			Text:        &content.Text,
+			ContextInfo: context,
+		}
 
-	context := &waE2E.ContextInfo{
-		Expiration: msg.Expiration,
-	}
+		if content.HasPreview() {
+			withLinkPreview(extended, content.Preview)
+		}
 
-	whatsappMessage := &waE2E.Message{
-		AudioMessage: &waE2E.AudioMessage{
-			URL:           &content.Audio.URL,
-			DirectPath:    &content.Audio.DirectPath,
-			MediaKey:      []byte(content.Audio.MediaKey),
-			Mimetype:      &content.Audio.Mime,
-			FileEncSHA256: []byte(content.Audio.Sha256Enc),
-			FileSHA256:    []byte(content.Audio.Sha256),
-			FileLength:    &content.Audio.Size,
-			PTT:           proto.Bool(false),
-			Seconds:       content.Audio.Duration,
-			ContextInfo:   context,
-		},
-	}
+		return &waE2E.Message{
+			ExtendedTextMessage: extended,
+		}, nil
 
-	to, err := types.ParseJID(msg.Chat)
-	if err != nil {
-		return nil, err
-	}
+	case message.ImageContent:
+		if content.HasMentions() {
+			context.MentionedJID = *content.Mentions
+		}
 
-	extra := whatsmeow.SendRequestExtra{}
-	if msg.ExternalID != nil {
-		extra.ID = *msg.ExternalID
-	}
+		mediaKey, sha256Enc, sha256 := decodeFileKeys(&content.Image.File)
+
+		return &waE2E.Message{
+			ImageMessage: &waE2E.ImageMessage{
+				URL:           &content.Image.URL,
+				DirectPath:    &content.Image.DirectPath,
+				Mimetype:      &content.Image.Mime,
+				MediaKey:      mediaKey,
+				FileEncSHA256: sha256Enc,
+				FileSHA256:    sha256,
+				FileLength:    &content.Image.Size,
+				Caption:       content.Caption,
+				Height:        content.Image.Height,
+				Width:         content.Image.Width,
+				ContextInfo:   context,
+				ViewOnce:      content.ViewOnce,
+				JPEGThumbnail: decodeThumbnail(content.Thumbnail),
+			},
+		}, nil
 
-	resp, err := client.SendMessage(ctx, to, whatsappMessage, extra)
-	if err != nil {
-		return nil, err
-	}
+	case *message.VideoContent:
+		if content.HasMentions() {
+			context.MentionedJID = *content.Mentions
+		}
 
-	msg.ExternalID = &resp.ID
+		mediaKey, sha256Enc, sha256 := decodeFileKeys(&content.Video.File)
+
+		return &waE2E.Message{
+			VideoMessage: &waE2E.VideoMessage{
+				URL:           &content.Video.URL,
+				DirectPath:    &content.Video.DirectPath,
+				MediaKey:      mediaKey,
+				Mimetype:      &content.Video.Mime,
+				FileEncSHA256: sha256Enc,
+				FileSHA256:    sha256,
+				FileLength:    &content.Video.Size,
+				Caption:       content.Caption,
+				Height:        content.Video.Height,
+				Width:         content.Video.Width,
+				Seconds:       content.Video.Duration,
+				ContextInfo:   context,
+				ViewOnce:      content.ViewOnce,
+				JPEGThumbnail: decodeThumbnail(content.Thumbnail),
+			},
+		}, nil
+
+	case *message.AudioContent:
+		mediaKey, sha256Enc, sha256 := decodeFileKeys(&content.Audio.File)
+
+		return &waE2E.Message{
+			AudioMessage: &waE2E.AudioMessage{
+				URL:           &content.Audio.URL,
+				DirectPath:    &content.Audio.DirectPath,
+				MediaKey:      mediaKey,
+				Mimetype:      &content.Audio.Mime,
+				FileEncSHA256: sha256Enc,
+				FileSHA256:    sha256,
+				FileLength:    &content.Audio.Size,
+				PTT:           proto.Bool(false),
+				Seconds:       content.Audio.Duration,
+				ContextInfo:   context,
+			},
+		}, nil
+
+	case *message.VoiceContent:
+		mediaKey, sha256Enc, sha256 := decodeFileKeys(&content.Voice.File)
+
+		return &waE2E.Message{
+			AudioMessage: &waE2E.AudioMessage{
+				URL:           &content.Voice.URL,
+				DirectPath:    &content.Voice.DirectPath,
+				MediaKey:      mediaKey,
+				Mimetype:      &content.Voice.Mime,
+				FileEncSHA256: sha256Enc,
+				FileSHA256:    sha256,
+				FileLength:    &content.Voice.Size,
+				PTT:           proto.Bool(true),
+				Seconds:       content.Voice.Duration,
+				Waveform:      content.Voice.Waveform,
+				ContextInfo:   context,
+				ViewOnce:      content.ViewOnce,
+			},
+		}, nil
 
-	return msg, nil
-}
+	case *message.DocumentContent:
+		if content.Mentions != nil && len(*content.Mentions) > 0 {
+			context.MentionedJID = *content.Mentions
+		}
 
-func (g *WhatsmeowGateway) SendVoiceMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
-	if msg.Content.Kind() != message.MessageKindVoice {
-		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindVoice, msg.Content.Kind())
-	}
+		mediaKey, sha256Enc, sha256 := decodeFileKeys(&content.Document)
+
+		return &waE2E.Message{
+			DocumentMessage: &waE2E.DocumentMessage{
+				URL:           &content.Document.URL,
+				DirectPath:    &content.Document.DirectPath,
+				MediaKey:      mediaKey,
+				Mimetype:      &content.Document.Mime,
+				FileEncSHA256: sha256Enc,
+				FileSHA256:    sha256,
+				FileLength:    &content.Document.Size,
+				Title:         proto.String(content.Document.Name),
+				FileName:      proto.String(content.Document.Name),
+				Caption:       content.Caption,
+				PageCount:     content.Document.Pages,
+				ContextInfo:   context,
+				JPEGThumbnail: decodeThumbnail(content.Thumbnail),
+			},
+		}, nil
+
+	case message.ButtonsContent:
+		buttons := make([]*waE2E.ButtonsMessage_Button, len(content.Buttons))
+		for i, button := range content.Buttons {
+			buttons[i] = &waE2E.ButtonsMessage_Button{
+				ButtonID:   proto.String(button.ID),
+				ButtonText: &waE2E.ButtonsMessage_Button_ButtonText{DisplayText: proto.String(button.Text)},
+				Type:       waE2E.ButtonsMessage_Button_RESPONSE.Enum(),
+			}
+		}
 
-	client, err := g.getOnlineClient(inst.ID)
-	if err != nil {
-		return nil, err
-	}
+		buttonsMessage := &waE2E.ButtonsMessage{
+			ContentText: proto.String(content.Text),
+			FooterText:  content.Footer,
+			Buttons:     buttons,
+			ContextInfo: context,
+			HeaderType:  waE2E.ButtonsMessage_EMPTY.Enum(),
+		}
 
-	content := msg.Content.(*message.VoiceContent)
+		if content.Header != nil {
+			buttonsMessage.HeaderType = waE2E.ButtonsMessage_TEXT.Enum()
+			buttonsMessage.Header = &waE2E.ButtonsMessage_Text{Text: *content.Header}
+		}
 
-	context := &waE2E.ContextInfo{
-		Expiration: msg.Expiration,
-	}
+		return wrapInteractiveMessage(&waE2E.Message{ButtonsMessage: buttonsMessage}), nil
+
+	case message.ListContent:
+		sections := make([]*waE2E.ListMessage_Section, len(content.Sections))
+		for i, section := range content.Sections {
+			rows := make([]*waE2E.ListMessage_Row, len(section.Rows))
+			for j, row := range section.Rows {
+				rows[j] = &waE2E.ListMessage_Row{
+					RowID:       proto.String(row.ID),
+					Title:       proto.String(row.Title),
+					Description: row.Description,
+				}
+			}
+			sections[i] = &waE2E.ListMessage_Section{
+				Title: proto.String(section.Title),
+				Rows:  rows,
+			}
+		}
 
-	whatsappMessage := &waE2E.Message{
-		AudioMessage: &waE2E.AudioMessage{
-			URL:           &content.Voice.URL,
-			DirectPath:    &content.Voice.DirectPath,
-			MediaKey:      []byte(content.Voice.MediaKey),
-			Mimetype:      &content.Voice.Mime,
-			FileEncSHA256: []byte(content.Voice.Sha256Enc),
-			FileSHA256:    []byte(content.Voice.Sha256),
-			FileLength:    &content.Voice.Size,
-			PTT:           proto.Bool(true),
-			Seconds:       content.Voice.Duration,
-			ContextInfo:   context,
-			ViewOnce:      content.ViewOnce,
-		},
-	}
+		return wrapInteractiveMessage(&waE2E.Message{
+			ListMessage: &waE2E.ListMessage{
+				Title:       proto.String(content.Title),
+				Description: proto.String(content.Text),
+				ButtonText:  proto.String(content.ButtonText),
+				FooterText:  content.Footer,
+				ListType:    waE2E.ListMessage_SINGLE_SELECT.Enum(),
+				Sections:    sections,
+				ContextInfo: context,
+			},
+		}), nil
+
+	case message.TemplateContent:
+		buttons := make([]*waE2E.HydratedTemplateButton, len(content.Buttons))
+		for i, button := range content.Buttons {
+			buttons[i] = &waE2E.HydratedTemplateButton{Index: proto.Uint32(uint32(i))}
+
+			switch button.Type {
+			case message.TemplateButtonURL:
+				buttons[i].HydratedButton = &waE2E.HydratedTemplateButton_UrlButton{
+					UrlButton: &waE2E.HydratedTemplateButton_HydratedURLButton{DisplayText: proto.String(button.Text), URL: button.URL},
+				}
+			case message.TemplateButtonCall:
+				buttons[i].HydratedButton = &waE2E.HydratedTemplateButton_CallButton{
+					CallButton: &waE2E.HydratedTemplateButton_HydratedCallButton{DisplayText: proto.String(button.Text), PhoneNumber: button.Phone},
+				}
+			default:
+				buttons[i].HydratedButton = &waE2E.HydratedTemplateButton_QuickReplyButton{
+					QuickReplyButton: &waE2E.HydratedTemplateButton_HydratedQuickReplyButton{DisplayText: proto.String(button.Text), ID: button.ID},
+				}
+			}
+		}
 
-	to, err := types.ParseJID(msg.Chat)
-	if err != nil {
-		return nil, err
-	}
+		template := &waE2E.TemplateMessage_HydratedFourRowTemplate{
+			HydratedContentText: proto.String(content.Text),
+			HydratedFooterText:  content.Footer,
+			HydratedButtons:     buttons,
+		}
 
-	extra := whatsmeow.SendRequestExtra{}
-	if msg.ExternalID != nil {
-		extra.ID = *msg.ExternalID
-	}
+		if content.Title != nil {
+			template.Title = &waE2E.TemplateMessage_HydratedFourRowTemplate_HydratedTitleText{HydratedTitleText: *content.Title}
+		}
 
-	resp, err := client.SendMessage(ctx, to, whatsappMessage, extra)
-	if err != nil {
-		return nil, err
+		return wrapInteractiveMessage(&waE2E.Message{
+			TemplateMessage: &waE2E.TemplateMessage{
+				ContextInfo:      context,
+				HydratedTemplate: template,
+				Format:           &waE2E.TemplateMessage_HydratedFourRowTemplate_{HydratedFourRowTemplate: template},
+			},
+		}), nil
 	}
 
-	msg.ExternalID = &resp.ID
-
-	return msg, nil
+	return nil, fmt.Errorf("unsupported message content kind %s", msg.Content.Kind())
 }
 
-func (g *WhatsmeowGateway) SendDocumentMessage(ctx context.Context, inst *instance.Instance, msg *message.Message) (*message.Message, error) {
-	if msg.Content.Kind() != message.MessageKindDocument {
-		return nil, fmt.Errorf("invalid message content kind, expected %s but got %s", message.MessageKindDocument, msg.Content.Kind())
+// wrapInteractiveMessage wraps buttons, lists and templates in a view once message, the only way they are still
+// rendered by current WhatsApp clients
+func wrapInteractiveMessage(msg *waE2E.Message) *waE2E.Message {
+	msg.MessageContextInfo = &waE2E.MessageContextInfo{
+		DeviceListMetadata:        &waE2E.DeviceListMetadata{},
+		DeviceListMetadataVersion: proto.Int32(2),
 	}
 
-	client, err := g.getOnlineClient(inst.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	content := msg.Content.(*message.DocumentContent)
-
-	context := &waE2E.ContextInfo{
-		Expiration: msg.Expiration,
+	return &waE2E.Message{
+		ViewOnceMessage: &waE2E.FutureProofMessage{Message: msg},
 	}
+}
 
-	whatsappMessage := &waE2E.Message{
-		DocumentMessage: &waE2E.DocumentMessage{
-			URL:           &content.Document.URL,
-			DirectPath:    &content.Document.DirectPath,
-			MediaKey:      []byte(content.Document.MediaKey),
-			Mimetype:      &content.Document.Mime,
-			FileEncSHA256: []byte(content.Document.Sha256Enc),
-			FileSHA256:    []byte(content.Document.Sha256),
-			FileLength:    &content.Document.Size,
-			Title:         proto.String(content.Document.Name),
-			Caption:       content.Caption,
-			PageCount:     content.Document.Pages,
-			ContextInfo:   context,
-		},
-	}
+// decodeFileKeys decodes the hex encoded media key and hashes of an uploaded file
+func decodeFileKeys(f *file.File) (mediaKey, sha256Enc, sha256 []byte) {
+	mediaKey, _ = hex.DecodeString(f.MediaKey)
+	sha256Enc, _ = hex.DecodeString(f.Sha256Enc)
+	sha256, _ = hex.DecodeString(f.Sha256)
+	return mediaKey, sha256Enc, sha256
+}
 
-	if content.HasThumbnail() {
-		data, err := base64.StdEncoding.DecodeString(*content.Thumbnail)
-		if err == nil {
-			whatsappMessage.ImageMessage.JPEGThumbnail = data
-		}
+// withLinkPreview fills the preview card, when the thumbnail was uploaded the message references it
+// so the recipient downloads the high quality version instead of the inline one
+func withLinkPreview(extended *waE2E.ExtendedTextMessage, preview *message.LinkPreview) {
+	extended.MatchedText = &preview.URL
+	extended.Title = preview.Title
+	extended.Description = preview.Description
+	extended.PreviewType = waE2E.ExtendedTextMessage_NONE.Enum()
+	extended.JPEGThumbnail = decodeThumbnail(preview.Thumbnail)
+
+	if preview.HasImage() {
+		mediaKey, sha256Enc, sha256 := decodeFileKeys(&preview.Image.File)
+		mediaKeyTimestamp := time.Now().Unix()
+
+		extended.ThumbnailDirectPath = &preview.Image.DirectPath
+		extended.ThumbnailSHA256 = sha256
+		extended.ThumbnailEncSHA256 = sha256Enc
+		extended.MediaKey = mediaKey
+		extended.MediaKeyTimestamp = &mediaKeyTimestamp
+		extended.ThumbnailWidth = preview.Image.Width
+		extended.ThumbnailHeight = preview.Image.Height
 	}
+}
 
-	to, err := types.ParseJID(msg.Chat)
-	if err != nil {
-		return nil, err
+func decodeThumbnail(thumbnail *string) []byte {
+	if thumbnail == nil || *thumbnail == "" {
+		return nil
 	}
 
-	extra := whatsmeow.SendRequestExtra{}
-	if msg.ExternalID != nil {
-		extra.ID = *msg.ExternalID
-	}
-
-	resp, err := client.SendMessage(ctx, to, whatsappMessage, extra)
+	data, err := base64.StdEncoding.DecodeString(*thumbnail)
 	if err != nil {
-		return nil, err
+		return nil
 	}
 
-	msg.ExternalID = &resp.ID
-
-	return msg, nil
+	return data
 }
 
 func (g *WhatsmeowGateway) ReadMessages(ctx context.Context, inst *instance.Instance, chat string, ids []string, sender string) error {
@@ -440,6 +502,84 @@ func (g *WhatsmeowGateway) SendReaction(ctx context.Context, inst *instance.Inst
 	}
 
 	msg.ExternalID = &resp.ID
+	msg.MarkAsSent(resp.Timestamp)
 
 	return msg, nil
 }
+
+// newContextInfo builds the context shared by every outgoing message, expiration and the quoted message when replying
+func newContextInfo(msg *message.Message) *waE2E.ContextInfo {
+	context := &waE2E.ContextInfo{
+		Expiration: msg.Expiration,
+	}
+
+	if msg.IsForwarded {
+		context.IsForwarded = proto.Bool(true)
+		context.ForwardingScore = proto.Uint32(msg.ForwardingScore)
+	}
+
+	if msg.IsReply() {
+		context.StanzaID = proto.String(msg.ReplyTo.ID)
+		context.Participant = proto.String(msg.ReplyTo.Sender)
+		context.QuotedMessage = &waE2E.Message{
+			Conversation: proto.String(""),
+		}
+
+		if msg.ReplyTo.Content != nil {
+			context.QuotedMessage.Conversation = msg.ReplyTo.Content
+		}
+	}
+
+	return context
+}
+
+func (g *WhatsmeowGateway) EditMessage(ctx context.Context, inst *instance.Instance, chat string, id string, kind message.MessageKind, text string) error {
+	client, err := g.getOnlineClient(inst.ID)
+	if err != nil {
+		return err
+	}
+
+	chatJID, err := types.ParseJID(chat)
+	if err != nil {
+		return err
+	}
+
+	var content *waE2E.Message
+	switch kind {
+	case message.MessageKindImage:
+		content = &waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String(text)}}
+	case message.MessageKindVideo:
+		content = &waE2E.Message{VideoMessage: &waE2E.VideoMessage{Caption: proto.String(text)}}
+	case message.MessageKindDocument:
+		content = &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{Caption: proto.String(text)}}
+	default:
+		content = &waE2E.Message{Conversation: proto.String(text)}
+	}
+
+	_, err = client.SendMessage(ctx, chatJID, client.BuildEdit(chatJID, id, content))
+	return err
+}
+
+func (g *WhatsmeowGateway) RevokeMessage(ctx context.Context, inst *instance.Instance, chat string, id string, sender *string) error {
+	client, err := g.getOnlineClient(inst.ID)
+	if err != nil {
+		return err
+	}
+
+	chatJID, err := types.ParseJID(chat)
+	if err != nil {
+		return err
+	}
+
+	// an empty sender revokes our own message, a sender revokes someone else's message as group admin
+	senderJID := types.EmptyJID
+	if sender != nil && *sender != "" {
+		senderJID, err = types.ParseJID(*sender)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = client.SendMessage(ctx, chatJID, client.BuildRevoke(chatJID, senderJID, id))
+	return err
+}
@@ -52,6 +52,8 @@ func mediaKindToWhatsmeowMediaType(kind whatsapp.MediaKind) whatsmeow.MediaType
 		return whatsmeow.MediaVideo
 	case whatsapp.MediaAudio:
 		return whatsmeow.MediaAudio
+	case whatsapp.MediaLinkThumbnail:
+		return whatsmeow.MediaLinkThumbnail
 	default:
 		return whatsmeow.MediaDocument
 	}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/campaign"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type CampaignHandler struct {
+	campaignService *service.CampaignService
+}
+
+func NewCampaignHandler(campaignService *service.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		campaignService: campaignService,
+	}
+}
+
+func (h *CampaignHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware, idemMiddleware *middleware.IdempotencyMiddleware) {
+	cp := r.Group("/campaigns", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+
+	// the phones are checked on whatsapp when the campaign is created, the sends connect on their own
+	connect := instMiddleware.ConnectInstance()
+
+	cp.Post("/", idemMiddleware.Idempotent(), connect, h.CreateCampaign)
+	cp.Get("/", h.ListCampaigns)
+	cp.Get("/:id", h.GetCampaign)
+	cp.Get("/:id/recipients", h.ListRecipients)
+	cp.Post("/:id/pause", h.PauseCampaign)
+	cp.Post("/:id/resume", h.ResumeCampaign)
+	cp.Post("/:id/cancel", h.CancelCampaign)
+}
+
+func (h *CampaignHandler) CreateCampaign(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.CreateCampaignInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	created, appErr := h.campaignService.Create(context.Background(), inst, req)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to create campaign", appErr))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(http.NewSuccessResponse("Campaign created successfully", fiber.Map{
+		"campaign": created,
+	}))
+}
+
+func (h *CampaignHandler) ListCampaigns(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	campaigns, appErr := h.campaignService.List(context.Background(), inst)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to list campaigns", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Campaigns retrieved successfully", fiber.Map{
+		"campaigns": campaigns,
+	}))
+}
+
+func (h *CampaignHandler) GetCampaign(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	found, appErr := h.campaignService.Get(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		return h.fail(c, "Failed to get campaign", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Campaign retrieved successfully", fiber.Map{
+		"campaign": found,
+	}))
+}
+
+func (h *CampaignHandler) ListRecipients(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	inp := input.ListCampaignRecipientsInput{}
+
+	if status := c.Query("status"); status != "" {
+		recipientStatus := campaign.RecipientStatus(status)
+		inp.Status = &recipientStatus
+	}
+
+	if after := c.Query("after"); after != "" {
+		inp.After = &after
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Invalid limit parameter", nil))
+		}
+		inp.Limit = &parsed
+	}
+
+	recipients, appErr := h.campaignService.Recipients(context.Background(), inst, c.Params("id"), inp)
+	if appErr != nil {
+		return h.fail(c, "Failed to list campaign recipients", appErr)
+	}
+
+	var nextCursor *string
+	if len(recipients) == *inp.Limit {
+		nextCursor = &recipients[len(recipients)-1].ID
+	}
+
+	return c.JSON(http.NewSuccessResponse("Campaign recipients retrieved successfully", fiber.Map{
+		"recipients":  recipients,
+		"next_cursor": nextCursor,
+	}))
+}
+
+func (h *CampaignHandler) PauseCampaign(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	paused, appErr := h.campaignService.Pause(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		return h.fail(c, "Failed to pause campaign", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Campaign paused successfully", fiber.Map{
+		"campaign": paused,
+	}))
+}
+
+func (h *CampaignHandler) ResumeCampaign(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	resumed, appErr := h.campaignService.Resume(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		return h.fail(c, "Failed to resume campaign", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Campaign resumed successfully", fiber.Map{
+		"campaign": resumed,
+	}))
+}
+
+func (h *CampaignHandler) CancelCampaign(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	canceled, appErr := h.campaignService.Cancel(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		return h.fail(c, "Failed to cancel campaign", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Campaign canceled successfully", fiber.Map{
+		"campaign": canceled,
+	}))
+}
+
+func (h *CampaignHandler) fail(c fiber.Ctx, msg string, appErr *app.AppError) error {
+	switch appErr.Code {
+	case app.CodeCampaignNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Campaign not found", appErr))
+	case app.CodeCampaignNotRunning, app.CodeCampaignNotPaused, app.CodeCampaignFinished, app.CodeCampaignConflict:
+		return c.Status(fiber.StatusConflict).JSON(http.NewErrorResponse(msg, appErr))
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse(msg, appErr))
+}
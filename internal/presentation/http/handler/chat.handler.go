@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/mauriciorobertodev/whappy-go/internal/app"
@@ -13,18 +14,25 @@ import (
 )
 
 type ChatHandler struct {
-	chatService *service.ChatService
+	chatService    *service.ChatService
+	historyService *service.HistoryService
 }
 
-func NewChatHandler(chatService *service.ChatService) *ChatHandler {
+func NewChatHandler(chatService *service.ChatService, historyService *service.HistoryService) *ChatHandler {
 	return &ChatHandler{
-		chatService: chatService,
+		chatService:    chatService,
+		historyService: historyService,
 	}
 }
 
 func (h *ChatHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
 	msg := r.Group("/chat", authMiddleware.Authenticate(), instMiddleware.AttachInstance(), instMiddleware.ConnectInstance())
 	msg.Post("/presence", h.Presence)
+
+	chats := r.Group("/chats", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+	chats.Get("/:jid/messages", h.ListMessages)
+	chats.Post("/:jid/history", instMiddleware.ConnectInstance(), h.RequestHistory)
+	chats.Patch("/:jid/disappearing", instMiddleware.ConnectInstance(), h.UpdateDisappearing)
 }
 
 func (h *ChatHandler) Presence(c fiber.Ctx) error {
@@ -42,3 +50,75 @@ func (h *ChatHandler) Presence(c fiber.Ctx) error {
 
 	return c.Status(fiber.StatusNoContent).JSON(http.NewSuccessResponse("Presence sent successfully", nil))
 }
+
+func (h *ChatHandler) ListMessages(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	inp := input.ListChatMessagesInput{
+		Chat: c.Params("jid"),
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		inp.Cursor = &cursor
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Invalid limit parameter", nil))
+		}
+		inp.Limit = &parsed
+	}
+
+	page, err := h.chatService.ListMessages(context.Background(), inst, inp)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to list messages", err))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Messages retrieved successfully", fiber.Map{
+		"messages":    page.Messages,
+		"next_cursor": page.NextCursor,
+	}))
+}
+
+func (h *ChatHandler) RequestHistory(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.RequestChatHistoryInput
+
+	// the body is optional, without it the default amount of messages is requested
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+		}
+	}
+	req.Chat = c.Params("jid")
+
+	if err := h.historyService.RequestHistory(context.Background(), inst, req); err != nil {
+		status := fiber.StatusBadRequest
+		if err.Code == app.CodeNoHistoryAnchor {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(http.NewErrorResponse("Failed to request history", err))
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(http.NewSuccessResponse("History requested successfully", nil))
+}
+
+func (h *ChatHandler) UpdateDisappearing(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.UpdateChatDisappearingInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+	req.Chat = c.Params("jid")
+
+	updated, err := h.chatService.UpdateDisappearing(context.Background(), inst, req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to update disappearing timer", err))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Disappearing timer updated successfully", fiber.Map{
+		"chat": updated,
+	}))
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"mime"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type DownloadHandler struct {
+	downloadService *service.DownloadService
+}
+
+func NewDownloadHandler(downloadService *service.DownloadService) *DownloadHandler {
+	return &DownloadHandler{
+		downloadService: downloadService,
+	}
+}
+
+func (h *DownloadHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
+	d := r.Group("/download", authMiddleware.Authenticate(), instMiddleware.AttachInstance(), instMiddleware.ConnectInstance())
+
+	d.Get("/image", h.Download(file.MediaTypeImage))
+	d.Get("/video", h.Download(file.MediaTypeVideo))
+	d.Get("/audio", h.Download(file.MediaTypeAudio))
+	d.Get("/sticker", h.Download(file.MediaTypeSticker))
+	d.Get("/document", h.Download(file.MediaTypeDocument))
+}
+
+// Download answers with the decrypted media itself, not a json, errors are answered as json as usual
+func (h *DownloadHandler) Download(mediaType file.MediaType) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		inst := c.Locals("instance").(*instance.Instance)
+		var req input.DownloadMediaInput
+
+		if err := c.Bind().Query(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Invalid query parameters", nil))
+		}
+		req.Type = mediaType
+
+		f, content, appErr := h.downloadService.Download(context.Background(), inst, req)
+		if appErr != nil {
+			return h.fail(c, appErr)
+		}
+
+		disposition := "inline"
+		if mediaType == file.MediaTypeDocument {
+			disposition = "attachment"
+		}
+
+		c.Set(fiber.HeaderContentType, f.Mime)
+		c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{
+			"filename": f.DownloadName(string(mediaType)),
+		}))
+
+		// the stream is closed once sent, which removes the temporary file holding the media
+		return c.SendStream(content, int(f.Size))
+	}
+}
+
+func (h *DownloadHandler) fail(c fiber.Ctx, appErr *app.AppError) error {
+	switch appErr.Code {
+	case app.CodeMessageNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Message not found", appErr))
+	case app.CodeMediaExpired:
+		return c.Status(fiber.StatusGone).JSON(http.NewErrorResponse("Media is no longer available", appErr))
+	case app.CodeMediaHashMismatch, app.CodeFileDownloadFailed:
+		return c.Status(fiber.StatusBadGateway).JSON(http.NewErrorResponse("Failed to download media", appErr))
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to download media", appErr))
+}
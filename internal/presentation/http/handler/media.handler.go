@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type MediaPolicyHandler struct {
+	mediaService *service.MediaService
+}
+
+func NewMediaPolicyHandler(mediaService *service.MediaService) *MediaPolicyHandler {
+	return &MediaPolicyHandler{
+		mediaService: mediaService,
+	}
+}
+
+func (h *MediaPolicyHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
+	mp := r.Group("/media-policy", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+
+	mp.Get("/", h.GetMediaPolicy)
+	mp.Patch("/", h.UpdateMediaPolicy)
+}
+
+func (h *MediaPolicyHandler) GetMediaPolicy(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	policy, appErr := h.mediaService.GetPolicy(context.Background(), inst)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to get media policy", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Media policy retrieved successfully", fiber.Map{
+		"media_policy": policy,
+	}))
+}
+
+func (h *MediaPolicyHandler) UpdateMediaPolicy(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.UpdateMediaPolicyInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	policy, appErr := h.mediaService.UpdatePolicy(context.Background(), inst, req)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to update media policy", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Media policy updated successfully", fiber.Map{
+		"media_policy": policy,
+	}))
+}
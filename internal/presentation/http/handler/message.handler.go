@@ -3,38 +3,114 @@ package handler
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/mauriciorobertodev/whappy-go/internal/app"
 	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
 	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
 	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
 	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
 	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
 	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/requests"
 )
 
 type MessageHandler struct {
-	messageService *service.MessageService
+	messageService   *service.MessageService
+	queueService     *service.QueueService
+	rateLimitService *service.RateLimitService
 }
 
-func NewMessageHandler(messageService *service.MessageService) *MessageHandler {
+func NewMessageHandler(messageService *service.MessageService, queueService *service.QueueService, rateLimitService *service.RateLimitService) *MessageHandler {
 	return &MessageHandler{
-		messageService: messageService,
+		messageService:   messageService,
+		queueService:     queueService,
+		rateLimitService: rateLimitService,
 	}
 }
 
-func (h *MessageHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
-	msg := r.Group("/messages", authMiddleware.Authenticate(), instMiddleware.AttachInstance(), instMiddleware.ConnectInstance())
-	msg.Get("/id", h.GetMessageIDs)
-	msg.Post("/text", h.SendText)
-	msg.Post("/image", h.SendImage)
-	msg.Post("/video", h.SendVideo)
-	msg.Post("/audio", h.SendAudio)
-	msg.Post("/voice", h.SendVoice)
-	msg.Post("/document", h.SendDocument)
-	msg.Post("/reaction", h.SendReaction)
-	msg.Post("/read", h.MarkMessagesAsRead)
+// messageIDRoute takes the uuids and the WhatsApp ids of the messages, both are hex so /messages/scheduled is never
+// taken for an id whatever the order of the routes, routes are lowercased by fiber hence the case insensitive flag
+const messageIDRoute = "/:id<regex(^(?i)[0-9a-f-]+$)>"
+
+func (h *MessageHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware, idemMiddleware *middleware.IdempotencyMiddleware) {
+	// the connection is required per route, async sends are queued even while the instance is offline
+	msg := r.Group("/messages", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+	connect := instMiddleware.ConnectInstance()
+	idempotent := idemMiddleware.Idempotent()
+
+	msg.Get("/id", connect, h.GetMessageIDs)
+	msg.Post("/text", idempotent, h.QueueIfAsync(message.MessageKindText), connect, h.SendText)
+	msg.Post("/image", idempotent, h.QueueIfAsync(message.MessageKindImage), connect, h.SendImage)
+	msg.Post("/video", idempotent, h.QueueIfAsync(message.MessageKindVideo), connect, h.SendVideo)
+	msg.Post("/audio", idempotent, h.QueueIfAsync(message.MessageKindAudio), connect, h.SendAudio)
+	msg.Post("/voice", idempotent, h.QueueIfAsync(message.MessageKindVoice), connect, h.SendVoice)
+	msg.Post("/document", idempotent, h.QueueIfAsync(message.MessageKindDocument), connect, h.SendDocument)
+	msg.Post("/buttons", idempotent, h.QueueIfAsync(message.MessageKindButtons), connect, h.SendButtons)
+	msg.Post("/list", idempotent, h.QueueIfAsync(message.MessageKindList), connect, h.SendList)
+	msg.Post("/template", idempotent, h.QueueIfAsync(message.MessageKindTemplate), connect, h.SendTemplate)
+	msg.Post("/reaction", idempotent, connect, h.SendReaction)
+	msg.Post("/read", connect, h.MarkMessagesAsRead)
+	msg.Post("/forward", idempotent, connect, h.ForwardMessage)
+	msg.Get(messageIDRoute, h.GetMessage)
+	msg.Get(messageIDRoute+"/status", h.GetMessageStatus)
+	msg.Patch(messageIDRoute, connect, h.EditMessage)
+	msg.Delete(messageIDRoute, connect, h.RevokeMessage)
+}
+
+// QueueIfAsync queues the send when ?async=true and answers right away with the queued message, otherwise
+// the request goes on to be sent synchronously
+func (h *MessageHandler) QueueIfAsync(kind message.MessageKind) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Query("async", "false") != "true" {
+			return c.Next()
+		}
+
+		inst := c.Locals("instance").(*instance.Instance)
+
+		queued, err := h.queueService.Enqueue(context.Background(), inst, kind, c.Body())
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to queue message", err))
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(http.NewSuccessResponse("Message queued successfully", fiber.Map{
+			"queued": queued,
+		}))
+	}
+}
+
+// sendFailed answers a send that failed, a send that hit the rate limit is queued when the policy of the
+// instance asks for it, otherwise it is answered with 429 and Retry-After. An error WhatsApp answered with is a
+// 502, the message may have gone out and an idempotent retry gets the same answer instead of sending it again
+func (h *MessageHandler) sendFailed(c fiber.Ctx, kind message.MessageKind, err error) error {
+	appErr := app.TranslateError("message handler", err)
+
+	if appErr.Code == app.CodeUnknown {
+		return c.Status(fiber.StatusBadGateway).JSON(http.NewErrorResponse("Failed to send message", appErr))
+	}
+
+	if appErr.Code != app.CodeRateLimited && appErr.Code != app.CodeRecipientCooldown {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to send message", appErr))
+	}
+
+	inst := c.Locals("instance").(*instance.Instance)
+
+	if h.rateLimitService.QueuesOnLimit(context.Background(), inst) {
+		queued, queueErr := h.queueService.Enqueue(context.Background(), inst, kind, c.Body())
+		if queueErr == nil {
+			return c.Status(fiber.StatusAccepted).JSON(http.NewSuccessResponse("Send rate limit reached, message queued", fiber.Map{
+				"queued": queued,
+			}))
+		}
+	}
+
+	if after := service.RetryAfter(appErr); after > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(after.Seconds()))))
+	}
+
+	return c.Status(fiber.StatusTooManyRequests).JSON(http.NewErrorResponse("Send rate limit reached", appErr))
 }
 
 func (h *MessageHandler) GetMessageIDs(c fiber.Ctx) error {
@@ -71,8 +147,7 @@ func (h *MessageHandler) SendText(c fiber.Ctx) error {
 
 	msg, err := h.messageService.SendTextMessage(context.Background(), inst, req)
 	if err != nil {
-		appErr := app.TranslateError("message handler", err)
-		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to send message", appErr))
+		return h.sendFailed(c, message.MessageKindText, err)
 	}
 
 	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
@@ -90,8 +165,7 @@ func (h *MessageHandler) SendImage(c fiber.Ctx) error {
 
 	msg, err := h.messageService.SendImageMessage(context.Background(), inst, req)
 	if err != nil {
-		appErr := app.TranslateError("message handler", err)
-		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to send message", appErr))
+		return h.sendFailed(c, message.MessageKindImage, err)
 	}
 
 	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
@@ -109,8 +183,7 @@ func (h *MessageHandler) SendVideo(c fiber.Ctx) error {
 
 	msg, err := h.messageService.SendVideoMessage(context.Background(), inst, req)
 	if err != nil {
-		appErr := app.TranslateError("message handler", err)
-		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to send message", appErr))
+		return h.sendFailed(c, message.MessageKindVideo, err)
 	}
 
 	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
@@ -128,8 +201,7 @@ func (h *MessageHandler) SendAudio(c fiber.Ctx) error {
 
 	msg, err := h.messageService.SendAudioMessage(context.Background(), inst, req)
 	if err != nil {
-		appErr := app.TranslateError("message handler", err)
-		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to send message", appErr))
+		return h.sendFailed(c, message.MessageKindAudio, err)
 	}
 
 	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
@@ -147,8 +219,7 @@ func (h *MessageHandler) SendVoice(c fiber.Ctx) error {
 
 	msg, err := h.messageService.SendVoiceMessage(context.Background(), inst, req)
 	if err != nil {
-		appErr := app.TranslateError("message handler", err)
-		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to send message", appErr))
+		return h.sendFailed(c, message.MessageKindVoice, err)
 	}
 
 	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
@@ -166,8 +237,61 @@ func (h *MessageHandler) SendDocument(c fiber.Ctx) error {
 
 	msg, err := h.messageService.SendDocumentMessage(context.Background(), inst, req)
 	if err != nil {
-		appErr := app.TranslateError("message handler", err)
-		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to send message", appErr))
+		return h.sendFailed(c, message.MessageKindDocument, err)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
+		"message": msg,
+	}))
+}
+
+func (h *MessageHandler) SendButtons(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.SendButtonsMessageInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	msg, err := h.messageService.SendButtonsMessage(context.Background(), inst, req)
+	if err != nil {
+		return h.sendFailed(c, message.MessageKindButtons, err)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
+		"message": msg,
+	}))
+}
+
+func (h *MessageHandler) SendList(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.SendListMessageInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	msg, err := h.messageService.SendListMessage(context.Background(), inst, req)
+	if err != nil {
+		return h.sendFailed(c, message.MessageKindList, err)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
+		"message": msg,
+	}))
+}
+
+func (h *MessageHandler) SendTemplate(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.SendTemplateMessageInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	msg, err := h.messageService.SendTemplateMessage(context.Background(), inst, req)
+	if err != nil {
+		return h.sendFailed(c, message.MessageKindTemplate, err)
 	}
 
 	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
@@ -185,8 +309,7 @@ func (h *MessageHandler) SendReaction(c fiber.Ctx) error {
 
 	msg, err := h.messageService.SendReaction(context.Background(), inst, req.ToInput())
 	if err != nil {
-		appErr := app.TranslateError("message handler", err)
-		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to send message", appErr))
+		return h.sendFailed(c, message.MessageKindReaction, err)
 	}
 
 	return c.JSON(http.NewSuccessResponse("Reaction sent successfully", fiber.Map{
@@ -215,3 +338,98 @@ func (h *MessageHandler) MarkMessagesAsRead(c fiber.Ctx) error {
 
 	return c.JSON(http.NewSuccessResponse("Messages marked as read successfully", nil))
 }
+
+func (h *MessageHandler) EditMessage(c fiber.Ctx) error {
+	ctx := context.Background()
+	inst := c.Locals("instance").(*instance.Instance)
+
+	var req requests.EditMessageRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	if bag := req.Validate(); !bag.IsEmpty() {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewValidationErrorResponse(bag))
+	}
+
+	err := h.messageService.EditMessage(ctx, inst, req.ToInput(c.Params("id")))
+	if err != nil {
+		appErr := app.TranslateError("message handler", err)
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to edit message", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message edited successfully", nil))
+}
+
+func (h *MessageHandler) GetMessage(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	msg, err := h.messageService.GetMessage(context.Background(), inst, c.Params("id"))
+	if err != nil {
+		if err.Code == app.CodeMessageNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Message not found", err))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to get message", err))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message retrieved successfully", fiber.Map{
+		"message": msg,
+	}))
+}
+
+func (h *MessageHandler) GetMessageStatus(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	timeline, err := h.messageService.GetMessageStatus(context.Background(), inst, c.Params("id"))
+	if err != nil {
+		if err.Code == app.CodeMessageNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Message not found", err))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to get message status", err))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message status retrieved successfully", fiber.Map{
+		"status": timeline,
+	}))
+}
+
+func (h *MessageHandler) RevokeMessage(c fiber.Ctx) error {
+	ctx := context.Background()
+	inst := c.Locals("instance").(*instance.Instance)
+
+	var sender *string
+	if s := c.Query("sender"); s != "" {
+		sender = &s
+	}
+
+	err := h.messageService.RevokeMessage(ctx, inst, input.RevokeMessageInput{
+		ID:     c.Params("id"),
+		Chat:   c.Query("chat"),
+		Sender: sender,
+	})
+	if err != nil {
+		appErr := app.TranslateError("message handler", err)
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to revoke message", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message revoked successfully", nil))
+}
+
+func (h *MessageHandler) ForwardMessage(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.ForwardMessageInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	results, err := h.messageService.ForwardMessage(context.Background(), inst, req)
+	if err != nil {
+		appErr := app.TranslateError("message handler", err)
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to forward message", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message forwarded successfully", fiber.Map{
+		"results": results,
+	}))
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/requests"
+)
+
+type PresignHandler struct {
+	presignService *service.PresignService
+}
+
+func NewPresignHandler(presignService *service.PresignService) *PresignHandler {
+	return &PresignHandler{
+		presignService: presignService,
+	}
+}
+
+func (h *PresignHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
+	up := r.Group("/uploads/presigned", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+
+	up.Post("/", h.Presign)
+	up.Post("/:id/complete", h.Complete)
+}
+
+func (h *PresignHandler) Presign(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	var req requests.PresignUploadRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	upload, appErr := h.presignService.Presign(context.Background(), inst, req.ToInput())
+	if appErr != nil {
+		return h.fail(c, "Failed to presign upload", appErr)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(http.NewSuccessResponse("Upload presigned successfully", fiber.Map{
+		"upload": upload,
+	}))
+}
+
+func (h *PresignHandler) Complete(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	var req requests.CompletePresignedUploadRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+		}
+	}
+
+	f, appErr := h.presignService.Complete(context.Background(), inst, req.ToInput(c.Params("id")))
+	if appErr != nil {
+		return h.fail(c, "Failed to complete upload", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("File uploaded successfully", fiber.Map{
+		"file": f,
+	}))
+}
+
+func (h *PresignHandler) fail(c fiber.Ctx, msg string, appErr *app.AppError) error {
+	switch appErr.Code {
+	case app.CodePresignedUploadNotFound, app.CodeFileNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse(msg, appErr))
+	case app.CodePresignedUploadMissing:
+		return c.Status(fiber.StatusConflict).JSON(http.NewErrorResponse(msg, appErr))
+	case app.CodeMimeRequired, app.CodeInvalidImage:
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse(msg, appErr))
+	case app.CodePresignUnsupported, app.GLOBAL_STORAGE_UNAVAILABLE:
+		return c.Status(fiber.StatusNotImplemented).JSON(http.NewErrorResponse(msg, appErr))
+	case app.CodeQuotaBytesExceeded, app.CodeQuotaFilesExceeded:
+		return c.Status(fiber.StatusInsufficientStorage).JSON(http.NewErrorResponse("Storage quota exceeded", appErr))
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("presign handler", appErr))
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type QueueHandler struct {
+	queueService *service.QueueService
+}
+
+func NewQueueHandler(queueService *service.QueueService) *QueueHandler {
+	return &QueueHandler{
+		queueService: queueService,
+	}
+}
+
+func (h *QueueHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
+	q := r.Group("/queue", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+
+	q.Get("/", h.ListQueued)
+	q.Get("/:id", h.GetQueued)
+	q.Delete("/:id", h.CancelQueued)
+}
+
+func (h *QueueHandler) ListQueued(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	queued, appErr := h.queueService.List(context.Background(), inst)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to list queued messages", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Queued messages retrieved successfully", fiber.Map{
+		"queued": queued,
+	}))
+}
+
+func (h *QueueHandler) GetQueued(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	queued, appErr := h.queueService.Get(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		if appErr.Code == app.CodeQueuedMessageNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Queued message not found", appErr))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to get queued message", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Queued message retrieved successfully", fiber.Map{
+		"queued": queued,
+	}))
+}
+
+func (h *QueueHandler) CancelQueued(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	queued, appErr := h.queueService.Cancel(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		switch appErr.Code {
+		case app.CodeQueuedMessageNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Queued message not found", appErr))
+		case app.CodeQueuedMessageNotCancelable:
+			return c.Status(fiber.StatusConflict).JSON(http.NewErrorResponse("Queued message can't be canceled", appErr))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to cancel queued message", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Queued message canceled successfully", fiber.Map{
+		"queued": queued,
+	}))
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type QuotaHandler struct {
+	quotaService *service.QuotaService
+}
+
+func NewQuotaHandler(quotaService *service.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+func (h *QuotaHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
+	r.Get("/uploads/usage", authMiddleware.Authenticate(), instMiddleware.AttachInstance(), h.GetUsage)
+
+	adm := r.Group("/quotas", authMiddleware.Authenticate(), authMiddleware.IsAdmin())
+
+	adm.Get("", h.ListUsage)
+	adm.Get("/:id", h.GetInstanceUsage)
+	adm.Patch("/:id", h.UpdateQuota)
+}
+
+func (h *QuotaHandler) GetUsage(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	summary, appErr := h.quotaService.Usage(context.Background(), inst.ID)
+	if appErr != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("quota handler", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Storage usage retrieved successfully", summary))
+}
+
+func (h *QuotaHandler) ListUsage(c fiber.Ctx) error {
+	summaries, total, appErr := h.quotaService.UsageByInstance(context.Background())
+	if appErr != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("quota handler", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Storage usage retrieved successfully", fiber.Map{
+		"instances": summaries,
+		"total":     total,
+	}))
+}
+
+func (h *QuotaHandler) GetInstanceUsage(c fiber.Ctx) error {
+	summary, appErr := h.quotaService.Usage(context.Background(), c.Params("id"))
+	if appErr != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("quota handler", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Storage usage retrieved successfully", summary))
+}
+
+func (h *QuotaHandler) UpdateQuota(c fiber.Ctx) error {
+	var req input.UpdateQuotaInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	q, appErr := h.quotaService.UpdateQuota(context.Background(), c.Params("id"), req)
+	if appErr != nil {
+		if appErr.Code == app.CodeInstanceNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Instance not found", appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("quota handler", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Storage quota updated successfully", fiber.Map{
+		"quota": q,
+	}))
+}
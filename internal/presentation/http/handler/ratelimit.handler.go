@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type RateLimitHandler struct {
+	rateLimitService *service.RateLimitService
+}
+
+func NewRateLimitHandler(rateLimitService *service.RateLimitService) *RateLimitHandler {
+	return &RateLimitHandler{
+		rateLimitService: rateLimitService,
+	}
+}
+
+func (h *RateLimitHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
+	rl := r.Group("/rate-limit", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+
+	rl.Get("/", h.GetRateLimit)
+	rl.Patch("/", h.UpdateRateLimit)
+}
+
+func (h *RateLimitHandler) GetRateLimit(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	policy, appErr := h.rateLimitService.Get(context.Background(), inst)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to get rate limit", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Rate limit retrieved successfully", fiber.Map{
+		"rate_limit": policy,
+	}))
+}
+
+func (h *RateLimitHandler) UpdateRateLimit(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.UpdateRateLimitInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	policy, appErr := h.rateLimitService.Update(context.Background(), inst, req)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to update rate limit", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Rate limit updated successfully", fiber.Map{
+		"rate_limit": policy,
+	}))
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type RetentionHandler struct {
+	retentionService *service.RetentionService
+}
+
+func NewRetentionHandler(retentionService *service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{
+		retentionService: retentionService,
+	}
+}
+
+func (h *RetentionHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
+	// the report covers every instance, the middleware goes on each route so the rule ones never take it
+	r.Get("/retention/report", authMiddleware.Authenticate(), authMiddleware.IsAdmin(), h.Report)
+
+	auth, attach := authMiddleware.Authenticate(), instMiddleware.AttachInstance()
+	r.Get("/retention", auth, attach, h.GetRetentionRule)
+	r.Patch("/retention", auth, attach, h.UpdateRetentionRule)
+}
+
+func (h *RetentionHandler) GetRetentionRule(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	rule, appErr := h.retentionService.GetRule(context.Background(), inst)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to get retention rule", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Retention rule retrieved successfully", fiber.Map{
+		"retention": rule,
+	}))
+}
+
+func (h *RetentionHandler) UpdateRetentionRule(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.UpdateRetentionRuleInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	rule, appErr := h.retentionService.UpdateRule(context.Background(), inst, req)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to update retention rule", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Retention rule updated successfully", fiber.Map{
+		"retention": rule,
+	}))
+}
+
+// Report runs a dry sweep, listing what the next sweep would delete without deleting anything
+func (h *RetentionHandler) Report(c fiber.Ctx) error {
+	report, appErr := h.retentionService.Sweep(context.Background(), true)
+	if appErr != nil {
+		if appErr.Code == app.GLOBAL_STORAGE_UNAVAILABLE {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(http.NewErrorResponse("Storage is not configured", appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("retention handler", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Retention report generated successfully", fiber.Map{
+		"report": report,
+	}))
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type ScheduleHandler struct {
+	scheduleService *service.ScheduleService
+}
+
+func NewScheduleHandler(scheduleService *service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+func (h *ScheduleHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware, idemMiddleware *middleware.IdempotencyMiddleware) {
+	// scheduling does not need the instance connected, it is only required when the message is sent
+	s := r.Group("/messages/scheduled", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+
+	s.Post("/", idemMiddleware.Idempotent(), h.ScheduleMessage)
+	s.Get("/", h.ListScheduled)
+	s.Get("/:id", h.GetScheduled)
+	s.Patch("/:id", h.UpdateScheduled)
+	s.Delete("/:id", h.CancelScheduled)
+}
+
+func (h *ScheduleHandler) ScheduleMessage(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.ScheduleMessageInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	scheduled, appErr := h.scheduleService.Schedule(context.Background(), inst, req)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to schedule message", appErr))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(http.NewSuccessResponse("Message scheduled successfully", fiber.Map{
+		"scheduled": scheduled,
+	}))
+}
+
+func (h *ScheduleHandler) ListScheduled(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	scheduled, appErr := h.scheduleService.List(context.Background(), inst)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to list scheduled messages", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Scheduled messages retrieved successfully", fiber.Map{
+		"scheduled": scheduled,
+	}))
+}
+
+func (h *ScheduleHandler) GetScheduled(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	scheduled, appErr := h.scheduleService.Get(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		if appErr.Code == app.CodeScheduledMessageNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Scheduled message not found", appErr))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to get scheduled message", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Scheduled message retrieved successfully", fiber.Map{
+		"scheduled": scheduled,
+	}))
+}
+
+func (h *ScheduleHandler) UpdateScheduled(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.UpdateScheduledMessageInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	scheduled, appErr := h.scheduleService.Update(context.Background(), inst, c.Params("id"), req)
+	if appErr != nil {
+		switch appErr.Code {
+		case app.CodeScheduledMessageNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Scheduled message not found", appErr))
+		case app.CodeScheduledMessageNotEditable:
+			return c.Status(fiber.StatusConflict).JSON(http.NewErrorResponse("Scheduled message can't be updated", appErr))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to update scheduled message", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Scheduled message updated successfully", fiber.Map{
+		"scheduled": scheduled,
+	}))
+}
+
+func (h *ScheduleHandler) CancelScheduled(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	scheduled, appErr := h.scheduleService.Cancel(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		switch appErr.Code {
+		case app.CodeScheduledMessageNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Scheduled message not found", appErr))
+		case app.CodeQueuedMessageNotCancelable:
+			return c.Status(fiber.StatusConflict).JSON(http.NewErrorResponse("Scheduled message can't be canceled", appErr))
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to cancel scheduled message", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Scheduled message canceled successfully", fiber.Map{
+		"scheduled": scheduled,
+	}))
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime"
+	"path"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/storage"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+)
+
+// StorageHandler serves the storages without a server of their own the clients can reach, signed URLs stand in
+// for the credentials so none are asked for
+type StorageHandler struct {
+	storage storage.Storage
+	signed  storage.SignedStorage
+}
+
+func NewStorageHandler(st storage.Storage) *StorageHandler {
+	signed, _ := st.(storage.SignedStorage)
+
+	return &StorageHandler{
+		storage: st,
+		signed:  signed,
+	}
+}
+
+// RegisterRoutes takes the handler that serves the files, downloads only check the signature before it
+func (h *StorageHandler) RegisterRoutes(r fiber.Router, files fiber.Handler) {
+	r.Get("/storage/*", h.verify, files)
+	r.Put("/storage/*", h.verify, h.Upload)
+}
+
+// Serve streams the file from the storage, for the storages whose files are not on disk
+func (h *StorageHandler) Serve(c fiber.Ctx) error {
+	key := c.Params("*")
+
+	exists, err := h.storage.Exists(context.Background(), key)
+	if err != nil {
+		appErr := app.NewAppError("storage handler", app.CodeFileStorageFailed, err)
+		return c.Status(fiber.StatusBadGateway).JSON(http.NewErrorResponse("Failed to load file", appErr))
+	}
+	if !exists {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	content, err := h.storage.Load(context.Background(), key)
+	if err != nil {
+		appErr := app.NewAppError("storage handler", app.CodeFileStorageFailed, err)
+		return c.Status(fiber.StatusBadGateway).JSON(http.NewErrorResponse("Failed to load file", appErr))
+	}
+
+	if mimeType := mime.TypeByExtension(path.Ext(key)); mimeType != "" {
+		c.Set(fiber.HeaderContentType, mimeType)
+	}
+
+	// the stream is closed once sent
+	return c.SendStream(content)
+}
+
+func (h *StorageHandler) Upload(c fiber.Ctx) error {
+	if err := h.storage.Save(context.Background(), c.Params("*"), bytes.NewReader(c.Body())); err != nil {
+		appErr := app.NewAppError("storage handler", app.CodeFileStorageFailed, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(http.NewErrorResponse("Failed to store file", appErr))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *StorageHandler) verify(c fiber.Ctx) error {
+	if h.signed == nil {
+		if c.Method() == fiber.MethodPut {
+			appErr := app.TranslateError("storage handler", storage.ErrPresignUnsupported)
+			return c.Status(fiber.StatusMethodNotAllowed).JSON(http.NewErrorResponse("Uploads are not accepted here", appErr))
+		}
+		return c.Next()
+	}
+
+	err := h.signed.Verify(c.Method(), c.Params("*"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		msg := "Invalid signature"
+		if errors.Is(err, storage.ErrSignatureExpired) {
+			msg = "Signature expired"
+		}
+		return c.Status(fiber.StatusForbidden).JSON(http.NewErrorResponse(msg, app.TranslateError("storage handler", err)))
+	}
+
+	return c.Next()
+}
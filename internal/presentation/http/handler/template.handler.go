@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+type TemplateHandler struct {
+	templateService *service.TemplateService
+}
+
+func NewTemplateHandler(templateService *service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+	}
+}
+
+func (h *TemplateHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware, idemMiddleware *middleware.IdempotencyMiddleware) {
+	// only sending needs the instance connected, templates are managed while offline
+	t := r.Group("/templates", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+	connect := instMiddleware.ConnectInstance()
+
+	t.Post("/", h.CreateTemplate)
+	t.Get("/", h.ListTemplates)
+	t.Get("/:id", h.GetTemplate)
+	t.Patch("/:id", h.UpdateTemplate)
+	t.Delete("/:id", h.DeleteTemplate)
+	t.Post("/:id/render", h.RenderTemplate)
+	t.Post("/:id/send", idemMiddleware.Idempotent(), connect, h.SendTemplate)
+}
+
+func (h *TemplateHandler) CreateTemplate(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.CreateTemplateInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	created, appErr := h.templateService.Create(context.Background(), inst, req)
+	if appErr != nil {
+		return h.fail(c, "Failed to create template", appErr)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(http.NewSuccessResponse("Template created successfully", fiber.Map{
+		"template": created,
+	}))
+}
+
+func (h *TemplateHandler) ListTemplates(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	templates, appErr := h.templateService.List(context.Background(), inst)
+	if appErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Failed to list templates", appErr))
+	}
+
+	return c.JSON(http.NewSuccessResponse("Templates retrieved successfully", fiber.Map{
+		"templates": templates,
+	}))
+}
+
+func (h *TemplateHandler) GetTemplate(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	found, appErr := h.templateService.Get(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		return h.fail(c, "Failed to get template", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Template retrieved successfully", fiber.Map{
+		"template": found,
+	}))
+}
+
+func (h *TemplateHandler) UpdateTemplate(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.UpdateTemplateInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	updated, appErr := h.templateService.Update(context.Background(), inst, c.Params("id"), req)
+	if appErr != nil {
+		return h.fail(c, "Failed to update template", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Template updated successfully", fiber.Map{
+		"template": updated,
+	}))
+}
+
+func (h *TemplateHandler) DeleteTemplate(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	if appErr := h.templateService.Delete(context.Background(), inst, c.Params("id")); appErr != nil {
+		return h.fail(c, "Failed to delete template", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Template deleted successfully", nil))
+}
+
+func (h *TemplateHandler) RenderTemplate(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.RenderTemplateInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	rendered, appErr := h.templateService.Render(context.Background(), inst, c.Params("id"), req)
+	if appErr != nil {
+		return h.fail(c, "Failed to render template", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Template rendered successfully", fiber.Map{
+		"rendered": rendered,
+	}))
+}
+
+func (h *TemplateHandler) SendTemplate(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+	var req input.SendFromTemplateInput
+
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewInvalidJSONResponse())
+	}
+
+	msg, appErr := h.templateService.Send(context.Background(), inst, c.Params("id"), req)
+	if appErr != nil {
+		if appErr.Code == app.CodeRateLimited || appErr.Code == app.CodeRecipientCooldown {
+			if after := service.RetryAfter(appErr); after > 0 {
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(after.Seconds()))))
+			}
+			return c.Status(fiber.StatusTooManyRequests).JSON(http.NewErrorResponse("Send rate limit reached", appErr))
+		}
+		return h.fail(c, "Failed to send template", appErr)
+	}
+
+	return c.JSON(http.NewSuccessResponse("Message sent successfully", fiber.Map{
+		"message": msg,
+	}))
+}
+
+func (h *TemplateHandler) fail(c fiber.Ctx, msg string, appErr *app.AppError) error {
+	switch appErr.Code {
+	case app.CodeTemplateNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Template not found", appErr))
+	case app.CodeTemplateNameTaken:
+		return c.Status(fiber.StatusConflict).JSON(http.NewErrorResponse(msg, appErr))
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse(msg, appErr))
+}
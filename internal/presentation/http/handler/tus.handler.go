@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/tus"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+)
+
+const (
+	tusOffsetContentType = "application/offset+octet-stream"
+	tusTimeFormat        = "Mon, 02 Jan 2006 15:04:05 GMT"
+)
+
+var (
+	errTusVersion     = errors.New("unsupported tus version, expected " + tus.Version)
+	errTusContentType = errors.New("chunks must be sent as " + tusOffsetContentType)
+)
+
+type TusHandler struct {
+	tusService *service.TusService
+}
+
+func NewTusHandler(tusService *service.TusService) *TusHandler {
+	return &TusHandler{
+		tusService: tusService,
+	}
+}
+
+func (h *TusHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
+	// discovery carries no credentials
+	r.Options("/uploads/tus", h.Options)
+
+	up := r.Group("/uploads/tus", h.resumable, authMiddleware.Authenticate(), instMiddleware.AttachInstance())
+
+	up.Post("/", h.Create)
+	up.Head("/:id", h.Head)
+	up.Patch("/:id", h.Append)
+	up.Delete("/:id", h.Terminate)
+}
+
+func (h *TusHandler) Options(c fiber.Ctx) error {
+	c.Set(http.HeaderTusResumable, tus.Version)
+	c.Set(http.HeaderTusVersion, tus.Version)
+	c.Set(http.HeaderTusExtension, tus.Extensions)
+	if max := h.tusService.MaxSize(); max > 0 {
+		c.Set(http.HeaderTusMaxSize, strconv.FormatUint(max, 10))
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *TusHandler) Create(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	length, err := strconv.ParseUint(c.Get(http.HeaderUploadLength), 10, 64)
+	if err != nil {
+		return h.fail(c, "Invalid upload length", app.TranslateError("tus handler", tus.ErrInvalidLength))
+	}
+
+	u, appErr := h.tusService.Create(context.Background(), inst, input.CreateTusUpload{
+		Length:   length,
+		Metadata: c.Get(http.HeaderUploadMeta),
+	})
+	if appErr != nil {
+		return h.fail(c, "Failed to create upload", appErr)
+	}
+
+	c.Set(fiber.HeaderLocation, c.BaseURL()+"/uploads/tus/"+u.ID)
+	c.Set(http.HeaderUploadOffset, "0")
+	c.Set(http.HeaderUploadExpires, u.ExpiresAt.UTC().Format(tusTimeFormat))
+
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+func (h *TusHandler) Head(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	u, appErr := h.tusService.Get(context.Background(), inst, c.Params("id"))
+	if appErr != nil {
+		return h.fail(c, "Failed to get upload", appErr)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(http.HeaderUploadOffset, strconv.FormatUint(u.Offset, 10))
+	c.Set(http.HeaderUploadLength, strconv.FormatUint(u.Length, 10))
+	if len(u.Metadata) > 0 {
+		c.Set(http.HeaderUploadMeta, tus.EncodeMetadata(u.Metadata))
+	}
+	if u.FileID != nil {
+		c.Set(http.HeaderFileID, *u.FileID)
+	} else {
+		c.Set(http.HeaderUploadExpires, u.ExpiresAt.UTC().Format(tusTimeFormat))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *TusHandler) Append(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	if c.Get(fiber.HeaderContentType) != tusOffsetContentType {
+		appErr := app.NewAppError("tus handler", app.CodeInvalidChunkType, errTusContentType)
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(http.NewErrorResponse("Invalid content type", appErr))
+	}
+
+	offset, err := strconv.ParseUint(c.Get(http.HeaderUploadOffset), 10, 64)
+	if err != nil {
+		return h.fail(c, "Invalid upload offset", app.TranslateError("tus handler", tus.ErrOffsetMismatch))
+	}
+
+	u, appErr := h.tusService.Append(context.Background(), inst, input.AppendTusUpload{
+		ID:     c.Params("id"),
+		Offset: offset,
+		Chunk:  c.Body(),
+	})
+	if appErr != nil {
+		return h.fail(c, "Failed to write chunk", appErr)
+	}
+
+	c.Set(http.HeaderUploadOffset, strconv.FormatUint(u.Offset, 10))
+	if u.FileID != nil {
+		c.Set(http.HeaderFileID, *u.FileID)
+	} else {
+		c.Set(http.HeaderUploadExpires, u.ExpiresAt.UTC().Format(tusTimeFormat))
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *TusHandler) Terminate(c fiber.Ctx) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	if appErr := h.tusService.Terminate(context.Background(), inst, c.Params("id")); appErr != nil {
+		return h.fail(c, "Failed to terminate upload", appErr)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// resumable refuses clients speaking another version of the protocol, every answer tells which one is spoken
+func (h *TusHandler) resumable(c fiber.Ctx) error {
+	c.Set(http.HeaderTusResumable, tus.Version)
+
+	if c.Get(http.HeaderTusResumable) != tus.Version {
+		c.Set(http.HeaderTusVersion, tus.Version)
+		appErr := app.NewAppError("tus handler", app.CodeUnsupportedTusVersion, errTusVersion)
+		return c.Status(fiber.StatusPreconditionFailed).JSON(http.NewErrorResponse("Unsupported tus version", appErr))
+	}
+
+	return c.Next()
+}
+
+func (h *TusHandler) fail(c fiber.Ctx, msg string, appErr *app.AppError) error {
+	switch appErr.Code {
+	case app.CodeUploadNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("Upload not found", appErr))
+	case app.CodeUploadExpired:
+		return c.Status(fiber.StatusGone).JSON(http.NewErrorResponse("Upload expired", appErr))
+	case app.CodeUploadOffsetMismatch:
+		return c.Status(fiber.StatusConflict).JSON(http.NewErrorResponse(msg, appErr))
+	case app.CodeUploadLocked:
+		return c.Status(fiber.StatusLocked).JSON(http.NewErrorResponse(msg, appErr))
+	case app.CodeUploadTooLarge, app.CodeUploadChunkTooLarge:
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(http.NewErrorResponse(msg, appErr))
+	case app.CodeInvalidUploadLength, app.CodeInvalidUploadMetadata:
+		return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse(msg, appErr))
+	case app.CodeQuotaBytesExceeded, app.CodeQuotaFilesExceeded:
+		return c.Status(fiber.StatusInsufficientStorage).JSON(http.NewErrorResponse("Storage quota exceeded", appErr))
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("tus handler", appErr))
+}
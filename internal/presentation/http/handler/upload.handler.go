@@ -27,12 +27,16 @@ func NewUploadHandler(uploadService *service.UploadService) *UploadHandler {
 }
 
 func (h *UploadHandler) RegisterRoutes(r fiber.Router, authMiddleware *middleware.AuthMiddleware, instMiddleware *middleware.InstanceMiddleware) {
-	up := r.Group("/uploads", authMiddleware.Authenticate(), instMiddleware.AttachInstance())
-
-	up.Get("/", h.ListUploads)
-	up.Post("/", h.UploadFile)
-	up.Get("/:id", h.Get)
-	up.Delete("/:id", h.Delete)
+	// the middleware goes on each route and ids are uuids, so the other routes under /uploads are never taken
+	up := r.Group("/uploads")
+	auth, attach := authMiddleware.Authenticate(), instMiddleware.AttachInstance()
+
+	up.Get("/", auth, attach, h.ListUploads)
+	up.Post("/", auth, attach, h.UploadFile)
+	up.Get("/:id<guid>", auth, attach, h.Get)
+	up.Delete("/:id<guid>", auth, attach, h.Delete)
+	up.Post("/:id<guid>/pin", auth, attach, h.Pin)
+	up.Delete("/:id<guid>/pin", auth, attach, h.Unpin)
 }
 
 func (h *UploadHandler) ListUploads(c fiber.Ctx) error {
@@ -121,6 +125,9 @@ func (h *UploadHandler) UploadFile(c fiber.Ctx) error {
 		ThumbnailID: utils.StringPtr(thumbnailID),
 	})
 	if appErr != nil {
+		if appErr.Code == app.CodeQuotaBytesExceeded || appErr.Code == app.CodeQuotaFilesExceeded {
+			return c.Status(fiber.StatusInsufficientStorage).JSON(http.NewErrorResponse("Storage quota exceeded", appErr))
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("upload handler", appErr))
 	}
 
@@ -169,3 +176,35 @@ func (h *UploadHandler) Delete(c fiber.Ctx) error {
 
 	return c.JSON(http.NewSuccessResponse("File deleted successfully", nil))
 }
+
+func (h *UploadHandler) Pin(c fiber.Ctx) error {
+	return h.pin(c, true)
+}
+
+func (h *UploadHandler) Unpin(c fiber.Ctx) error {
+	return h.pin(c, false)
+}
+
+func (h *UploadHandler) pin(c fiber.Ctx, pinned bool) error {
+	inst := c.Locals("instance").(*instance.Instance)
+
+	file, appErr := h.uploadService.PinUpload(context.Background(), inst, input.PinUpload{
+		FileID: c.Params("id"),
+		Pinned: pinned,
+	})
+	if appErr != nil {
+		if appErr.Code == app.CodeFileNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(http.NewErrorResponse("File not found", appErr))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(http.NewInternalErrorResponse("upload handler", appErr))
+	}
+
+	msg := "File pinned successfully"
+	if !pinned {
+		msg = "File unpinned successfully"
+	}
+
+	return c.JSON(http.NewSuccessResponse(msg, fiber.Map{
+		"file": file,
+	}))
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/idempotency"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+)
+
+type IdempotencyMiddleware struct {
+	idempotencyService *service.IdempotencyService
+}
+
+func NewIdempotencyMiddleware(idempotencyService *service.IdempotencyService) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		idempotencyService: idempotencyService,
+	}
+}
+
+// Idempotent answers a retried request with the response of the first one, requests without the
+// Idempotency-Key header go on as usual. A request refused with a 4xx sent nothing and frees the key so it can
+// be retried, any other response is kept, a 5xx may come after the message went out and a retry must not send
+// it twice. It must run after AttachInstance, keys are scoped to the instance.
+func (m *IdempotencyMiddleware) Idempotent() fiber.Handler {
+	l := app.GetMiddlewareLogger()
+
+	return func(c fiber.Ctx) error {
+		key := strings.TrimSpace(c.Get(http.HeaderIdempotencyKey))
+		if key == "" {
+			return c.Next()
+		}
+
+		inst := c.Locals("instance").(*instance.Instance)
+		fingerprint := idempotency.Fingerprint(c.Method(), c.OriginalURL(), c.Body())
+
+		rec, appErr := m.idempotencyService.Begin(context.Background(), inst, key, fingerprint)
+		if appErr != nil {
+			switch appErr.Code {
+			case app.CodeIdempotencyKeyInUse:
+				return c.Status(fiber.StatusConflict).JSON(http.NewErrorResponse("Request with this idempotency key is in progress", appErr))
+			case app.CodeIdempotencyKeyReused:
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(http.NewErrorResponse("Idempotency key already used for a different request", appErr))
+			case app.CodeInvalidIdempotencyKey:
+				return c.Status(fiber.StatusBadRequest).JSON(http.NewErrorResponse("Invalid idempotency key", appErr))
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(http.NewErrorResponse("Failed to check idempotency key", appErr))
+		}
+
+		if rec.IsCompleted() {
+			c.Set(http.HeaderIdempotentReplayed, "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+			return c.Status(rec.ResponseStatus).Send(rec.ResponseBody)
+		}
+
+		if err := c.Next(); err != nil {
+			// the error handler writes the response now, so it is kept like the ones the handlers write
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				m.idempotencyService.Release(context.Background(), rec)
+				return err
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusBadRequest && status < fiber.StatusInternalServerError {
+			l.Debug("Request refused, releasing idempotency key", "instance", inst.ID, "status", status)
+			m.idempotencyService.Release(context.Background(), rec)
+			return nil
+		}
+
+		// the body belongs to the response buffer, it is copied before being stored
+		m.idempotencyService.Complete(context.Background(), rec, status, bytes.Clone(c.Response().Body()))
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/logger"
+	"github.com/mauriciorobertodev/whappy-go/internal/app/service"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/idempotency"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/instance"
+	"github.com/mauriciorobertodev/whappy-go/internal/fake"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/config"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/database"
+	"github.com/mauriciorobertodev/whappy-go/internal/infra/repository"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
+	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http/middleware"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idempotency Middleware", func() {
+	config.LoadLoggers(logger.LevelNone)
+
+	db := database.New(&config.DatabaseConfig{
+		Driver: config.DatabaseDriverSQLite,
+		DbName: "test",
+	})
+
+	instRepo := repository.NewInstanceRepository(db)
+	idempotencyService := service.NewIdempotencyService(repository.NewIdempotencyRepository(db), time.Hour)
+	migrator := database.NewMigrator(db, db.DriverName())
+
+	var (
+		inst  *instance.Instance
+		app   *fiber.App
+		calls int
+	)
+
+	send := func(key string, body string) *nethttp.Response {
+		req := httptest.NewRequest(fiber.MethodPost, "/messages", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(http.HeaderIdempotencyKey, key)
+		}
+
+		res, err := app.Test(req)
+		Expect(err).ToNot(HaveOccurred())
+		return res
+	}
+
+	read := func(res *nethttp.Response) string {
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return string(body)
+	}
+
+	BeforeEach(func() {
+		migrator.Reset()
+		calls = 0
+
+		inst = fake.InstanceFactory().Create()
+		Expect(instRepo.Insert(inst)).To(Succeed())
+
+		app = fiber.New()
+		app.Post("/messages", func(c fiber.Ctx) error {
+			c.Locals("instance", inst)
+			return c.Next()
+		}, middleware.NewIdempotencyMiddleware(idempotencyService).Idempotent(), func(c fiber.Ctx) error {
+			calls++
+			if strings.Contains(string(c.Body()), "fail") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"call": calls})
+			}
+			if strings.Contains(string(c.Body()), "crash") {
+				return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"call": calls})
+			}
+			if strings.Contains(string(c.Body()), "panic") {
+				return fiber.NewError(fiber.StatusInternalServerError, "handler failed")
+			}
+			return c.Status(fiber.StatusCreated).JSON(fiber.Map{"call": calls})
+		})
+	})
+
+	It("should replay the response of a retried request", func() {
+		first := send("key-1", `{"text":"hi"}`)
+		Expect(first.StatusCode).To(Equal(fiber.StatusCreated))
+		Expect(read(first)).To(Equal(`{"call":1}`))
+
+		retry := send("key-1", `{"text":"hi"}`)
+		Expect(retry.StatusCode).To(Equal(fiber.StatusCreated))
+		Expect(retry.Header.Get(http.HeaderIdempotentReplayed)).To(Equal("true"))
+		Expect(read(retry)).To(Equal(`{"call":1}`))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should handle every request without a key", func() {
+		Expect(send("", `{"text":"hi"}`).StatusCode).To(Equal(fiber.StatusCreated))
+		Expect(send("", `{"text":"hi"}`).StatusCode).To(Equal(fiber.StatusCreated))
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should reject a key reused for a different request", func() {
+		Expect(send("key-1", `{"text":"hi"}`).StatusCode).To(Equal(fiber.StatusCreated))
+		Expect(send("key-1", `{"text":"bye"}`).StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should free the key of a failed request so it can be retried", func() {
+		Expect(send("key-1", `{"text":"fail"}`).StatusCode).To(Equal(fiber.StatusBadRequest))
+
+		retry := send("key-1", `{"text":"fail"}`)
+		Expect(retry.StatusCode).To(Equal(fiber.StatusBadRequest))
+		Expect(retry.Header.Get(http.HeaderIdempotentReplayed)).To(BeEmpty())
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should keep the response of a request that failed after it may have sent", func() {
+		Expect(send("key-1", `{"text":"crash"}`).StatusCode).To(Equal(fiber.StatusBadGateway))
+
+		retry := send("key-1", `{"text":"crash"}`)
+		Expect(retry.StatusCode).To(Equal(fiber.StatusBadGateway))
+		Expect(retry.Header.Get(http.HeaderIdempotentReplayed)).To(Equal("true"))
+		Expect(read(retry)).To(Equal(`{"call":1}`))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should keep the response the error handler writes for an error", func() {
+		Expect(send("key-1", `{"text":"panic"}`).StatusCode).To(Equal(fiber.StatusInternalServerError))
+
+		retry := send("key-1", `{"text":"panic"}`)
+		Expect(retry.StatusCode).To(Equal(fiber.StatusInternalServerError))
+		Expect(retry.Header.Get(http.HeaderIdempotentReplayed)).To(Equal("true"))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should answer a conflict while the first request is in progress", func() {
+		fingerprint := idempotency.Fingerprint(fiber.MethodPost, "/messages", []byte(`{"text":"hi"}`))
+		_, appErr := idempotencyService.Begin(context.Background(), inst, "key-1", fingerprint)
+		Expect(appErr).To(BeNil())
+
+		Expect(send("key-1", `{"text":"hi"}`).StatusCode).To(Equal(fiber.StatusConflict))
+		Expect(calls).To(Equal(0))
+	})
+
+	It("should reject an invalid key", func() {
+		Expect(send(strings.Repeat("k", 1000), `{"text":"hi"}`).StatusCode).To(Equal(fiber.StatusBadRequest))
+		Expect(calls).To(Equal(0))
+	})
+})
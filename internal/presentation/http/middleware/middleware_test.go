@@ -0,0 +1,18 @@
+package middleware_test
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMiddlewares(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Middlewares Suite")
+}
+
+var _ = AfterSuite(func() {
+	_ = os.Remove("test.db")
+})
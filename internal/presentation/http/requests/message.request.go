@@ -2,6 +2,7 @@ package requests
 
 import (
 	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/message"
 	"github.com/mauriciorobertodev/whappy-go/internal/presentation/http"
 )
 
@@ -64,3 +65,36 @@ func (r *SendReactionMessageInput) ToInput() input.SendReactionInput {
 		Emoji:   r.Emoji,
 	}
 }
+
+type EditMessageRequest struct {
+	Chat string              `json:"chat"`
+	Kind message.MessageKind `json:"kind"`
+	Text string              `json:"text"`
+}
+
+func (r *EditMessageRequest) Validate() *http.ErrorBag {
+	var bag = http.NewErrorBag()
+
+	if r.Chat == "" {
+		bag.Add("chat", "chat is required")
+	}
+
+	if r.Kind != "" && !message.IsEditable(r.Kind) {
+		bag.Add("kind", "only text, image, video and document messages can be edited")
+	}
+
+	if r.Text == "" {
+		bag.Add("text", "text is required")
+	}
+
+	return bag
+}
+
+func (r *EditMessageRequest) ToInput(id string) input.EditMessageInput {
+	return input.EditMessageInput{
+		ID:   id,
+		Chat: r.Chat,
+		Kind: r.Kind,
+		Text: r.Text,
+	}
+}
@@ -0,0 +1,39 @@
+package requests
+
+import (
+	"github.com/mauriciorobertodev/whappy-go/internal/app/input"
+	"github.com/mauriciorobertodev/whappy-go/internal/domain/file"
+)
+
+type PresignUploadRequest struct {
+	Mime string `json:"mime"`
+}
+
+func (r *PresignUploadRequest) ToInput() input.PresignUpload {
+	return input.PresignUpload{
+		Mime: r.Mime,
+	}
+}
+
+type CompletePresignedUploadRequest struct {
+	Name        *string `json:"name"`
+	Width       *uint32 `json:"width"`
+	Height      *uint32 `json:"height"`
+	Duration    *uint32 `json:"duration"`
+	Pages       *uint32 `json:"pages"`
+	ThumbnailID *string `json:"thumbnail_id"`
+}
+
+func (r *CompletePresignedUploadRequest) ToInput(id string) input.CompletePresignedUpload {
+	return input.CompletePresignedUpload{
+		FileID: id,
+		Metadata: file.Metadata{
+			Name:     r.Name,
+			Width:    r.Width,
+			Height:   r.Height,
+			Duration: r.Duration,
+			Pages:    r.Pages,
+		},
+		ThumbnailID: r.ThumbnailID,
+	}
+}
@@ -7,7 +7,22 @@ import (
 	"github.com/mauriciorobertodev/whappy-go/internal/app"
 )
 
-const HeaderInstanceID = "X-Instance-ID"
+const (
+	HeaderInstanceID         = "X-Instance-ID"
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed" // set on responses replayed for a retried idempotency key
+
+	// tus resumable upload protocol, see https://tus.io/protocols/resumable-upload
+	HeaderTusResumable  = "Tus-Resumable"
+	HeaderTusVersion    = "Tus-Version"
+	HeaderTusExtension  = "Tus-Extension"
+	HeaderTusMaxSize    = "Tus-Max-Size"
+	HeaderUploadLength  = "Upload-Length"
+	HeaderUploadOffset  = "Upload-Offset"
+	HeaderUploadMeta    = "Upload-Metadata"
+	HeaderUploadExpires = "Upload-Expires"
+	HeaderFileID        = "File-ID" // set on the chunk that completes a tus upload
+)
 
 func NewFiberApp(appName, appVersion string, isProduction bool) *fiber.App {
 	app := fiber.New(fiber.Config{
@@ -29,7 +44,14 @@ func NewFiberApp(appName, appVersion string, isProduction bool) *fiber.App {
 		// TimeZone: "UTC",
 	}))
 
-	app.Use(cors.New())
+	app.Use(cors.New(cors.Config{
+		// browsers only let clients read these when exposed, tus clients need them to resume
+		ExposeHeaders: []string{
+			fiber.HeaderLocation,
+			HeaderTusResumable, HeaderTusVersion, HeaderTusExtension, HeaderTusMaxSize,
+			HeaderUploadLength, HeaderUploadOffset, HeaderUploadMeta, HeaderUploadExpires, HeaderFileID,
+		},
+	}))
 
 	if isProduction {
 		// app.Use(recoverer.New())